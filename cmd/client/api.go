@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -9,24 +10,151 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
-// Login performs user authentication and stores the received access token in the current context.
+// MFARequiredError is returned by Login when the account has two-factor authentication enabled.
+// The login is completed by VerifyMFA with the challenge token.
+type MFARequiredError struct {
+	Token string
+}
+
+func (e *MFARequiredError) Error() string {
+	return "требуется код двухфакторной аутентификации"
+}
+
+// Login performs user authentication and stores the received token pair in the current context.
 func (g *GophKeeper) Login(login, password string) (string, error) {
 	resp, err := g.client.Login(g.rootCtx, &pb.LoginRequest{
-		Login:    login,
-		Password: g.hashPassword(password),
+		Login:         login,
+		Password:      g.hashPassword(password),
+		DeviceName:    deviceName(),
+		ClientVersion: buildVersion,
 	})
 	if err != nil {
 		return "", errors.Wrap(err, "login")
 	}
 
-	err = g.storage.SaveContext(login, resp.Token)
+	if resp.MfaRequired {
+		return "", &MFARequiredError{Token: resp.MfaToken}
+	}
+
+	return g.saveTokens(login, resp)
+}
+
+// VerifyMFA completes a login with a TOTP or recovery code and stores the received token pair.
+func (g *GophKeeper) VerifyMFA(login, mfaToken, code string) (string, error) {
+	resp, err := g.client.VerifyMFA(g.rootCtx, &pb.VerifyMFARequest{
+		MfaToken:      mfaToken,
+		Code:          code,
+		DeviceName:    deviceName(),
+		ClientVersion: buildVersion,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "verify mfa")
+	}
+
+	return g.saveTokens(login, resp)
+}
+
+// saveTokens stores the token pair of a successful login in the login context.
+func (g *GophKeeper) saveTokens(login string, resp *pb.LoginResponse) (string, error) {
+	err := g.storage.SaveContext(login, resp.Token)
 	if err != nil {
 		return "", errors.Wrap(err, "save context")
 	}
 
+	err = g.storage.SaveRefreshToken(login, resp.RefreshToken)
+	if err != nil {
+		return "", errors.Wrap(err, "save refresh token")
+	}
+
 	return resp.Token, nil
 }
 
+// EnrollMFA starts two-factor enrollment and returns the TOTP secret with its otpauth URI.
+func (g *GophKeeper) EnrollMFA() (*pb.EnrollMFAResponse, error) {
+	return authorized(g, func(ctx context.Context) (*pb.EnrollMFAResponse, error) {
+		return g.client.EnrollMFA(ctx, &emptypb.Empty{})
+	})
+}
+
+// ConfirmMFA finishes two-factor enrollment with a code and returns the recovery codes.
+func (g *GophKeeper) ConfirmMFA(code string) ([]string, error) {
+	resp, err := authorized(g, func(ctx context.Context) (*pb.ConfirmMFAResponse, error) {
+		return g.client.ConfirmMFA(ctx, &pb.ConfirmMFARequest{Code: code})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.RecoveryCodes, nil
+}
+
+// Logout revokes the current session on the server and forgets its tokens.
+func (g *GophKeeper) Logout() error {
+	name, _, err := g.storage.GetCurrentContext()
+	if err != nil {
+		return errors.Wrap(err, "get context")
+	}
+
+	_, err = authorized(g, func(ctx context.Context) (*emptypb.Empty, error) {
+		return g.client.Logout(ctx, &emptypb.Empty{})
+	})
+	if err != nil {
+		return errors.Wrap(err, "logout")
+	}
+
+	return errors.Wrap(g.storage.SaveContextTokens(name, "", ""), "save context")
+}
+
+// SessionList retrieves active sessions of the authenticated user.
+func (g *GophKeeper) SessionList() (*pb.ListSessionsResponse, error) {
+	return authorized(g, func(ctx context.Context) (*pb.ListSessionsResponse, error) {
+		return g.client.ListSessions(ctx, &emptypb.Empty{})
+	})
+}
+
+// SessionRevoke ends a session of the authenticated user by its ID.
+func (g *GophKeeper) SessionRevoke(id uint64) (*emptypb.Empty, error) {
+	return authorized(g, func(ctx context.Context) (*emptypb.Empty, error) {
+		return g.client.RevokeSession(ctx, &pb.RevokeSessionRequest{
+			SessionId: id,
+		})
+	})
+}
+
+// refreshTokens exchanges the refresh token of the current context for a new
+// token pair after the server rejected the access token rejected. Refreshes
+// are serialized and the tokens re-read under the lock: the server takes a
+// refresh token presented twice for a stolen one and ends the session, so a
+// caller that finds the pair already rotated, in the shell by the watch
+// stream or another call, uses the new pair as it is.
+func (g *GophKeeper) refreshTokens(rejected string) error {
+	g.refreshMu.Lock()
+	defer g.refreshMu.Unlock()
+
+	if g.currentToken() != rejected {
+		return nil
+	}
+	refresh, err := g.storage.GetCurrentRefreshToken()
+	if err != nil {
+		return err
+	}
+	if refresh == "" {
+		return errors.New("no refresh token")
+	}
+
+	resp, err := g.client.RefreshToken(g.rootCtx, &pb.RefreshTokenRequest{RefreshToken: refresh})
+	if err != nil {
+		return errors.Wrap(err, "refresh token")
+	}
+
+	name, _, err := g.storage.GetCurrentContext()
+	if err != nil {
+		return errors.Wrap(err, "get context")
+	}
+
+	return errors.Wrap(g.storage.SaveContextTokens(name, resp.Token, resp.RefreshToken), "save context")
+}
+
 // Register creates a new user account and returns the generated mnemonic for local key storage.
 func (g *GophKeeper) Register(login, password string) ([]string, error) {
 	_, err := g.client.Register(g.rootCtx, &pb.RegisterRequest{
@@ -55,26 +183,221 @@ func (g *GophKeeper) Register(login, password string) ([]string, error) {
 
 // VaultList retrieves the list of vault records for the authenticated user.
 func (g *GophKeeper) VaultList() (*pb.ListVaultsResponse, error) {
-	return g.client.ListVaults(g.authCtx(), &pb.ListVaultsRequest{})
+	return g.revealList(authorized(g, func(ctx context.Context) (*pb.ListVaultsResponse, error) {
+		return g.client.ListVaults(ctx, &pb.ListVaultsRequest{})
+	}))
 }
 
-// VaultCreate creates a new vault record using the provided data.
-func (g *GophKeeper) VaultCreate(v *pb.VaultRecord) (*emptypb.Empty, error) {
-	return g.client.CreateVault(g.authCtx(), &pb.CreateVaultRequest{
-		Record: v,
+// revealList opens the sealed titles and metadata of the listed records.
+func (g *GophKeeper) revealList(resp *pb.ListVaultsResponse, err error) (*pb.ListVaultsResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err = g.reveal(resp.Vaults...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VaultSummaries retrieves a page of the vault records of the authenticated user without their contents.
+func (g *GophKeeper) VaultSummaries(req *pb.ListVaultsRequest) (*pb.ListVaultSummariesResponse, error) {
+	return authorized(g, func(ctx context.Context) (*pb.ListVaultSummariesResponse, error) {
+		return g.client.ListVaultSummaries(ctx, req)
+	})
+}
+
+// VaultCreate creates a new vault record using the provided data and returns
+// it with the ID the server gave it. The blind index tokens of the record are
+// sent along, see VaultFind; with private metadata the title and metadata are
+// sent sealed.
+func (g *GophKeeper) VaultCreate(v *pb.VaultRecord) (*pb.VaultRecord, error) {
+	v.BlindTokens = g.index.tokens(v)
+	sent, err := g.concealed(v)
+	if err != nil {
+		return nil, err
+	}
+	created, err := authorized(g, func(ctx context.Context) (*pb.VaultRecord, error) {
+		return g.client.CreateVault(ctx, &pb.CreateVaultRequest{
+			Record: sent,
+		})
 	})
+	if err != nil {
+		return nil, err
+	}
+	g.index.put(created.GetId(), v)
+	if err = g.storage.PinUUID(created.GetId(), sent.Uuid); err != nil {
+		return created, err
+	}
+	return created, g.reveal(created)
+}
+
+// VaultFind retrieves the vault records having all the blind index tokens.
+func (g *GophKeeper) VaultFind(tokens []string) (*pb.ListVaultsResponse, error) {
+	return g.revealList(authorized(g, func(ctx context.Context) (*pb.ListVaultsResponse, error) {
+		return g.client.FindVaults(ctx, &pb.FindVaultsRequest{Tokens: tokens})
+	}))
 }
 
-// VaultGet retrieves a specific vault record by its ID.
+// VaultGet retrieves a specific vault record by its ID, with its title and
+// metadata decrypted if they are sealed.
 func (g *GophKeeper) VaultGet(id uint64) (*pb.VaultRecord, error) {
-	return g.client.GetVault(g.authCtx(), &pb.GetVaultRequest{
-		VaultId: id,
+	v, err := authorized(g, func(ctx context.Context) (*pb.VaultRecord, error) {
+		return g.client.GetVault(ctx, &pb.GetVaultRequest{
+			VaultId: id,
+		})
+	})
+	if err == nil {
+		err = g.reveal(v)
+	}
+	if err == nil {
+		err = g.storage.PinUUID(v.Id, v.Uuid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	g.index.put(v.Id, v)
+	return v, nil
+}
+
+// VaultUpdate overwrites a vault record. v.Revision must be the revision the
+// record was read at, otherwise the server rejects the update as a conflict.
+// The blind index tokens of the record are replaced with its current ones.
+func (g *GophKeeper) VaultUpdate(v *pb.VaultRecord) (*emptypb.Empty, error) {
+	v.BlindTokens = g.index.tokens(v)
+	sent, err := g.concealed(v)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := authorized(g, func(ctx context.Context) (*emptypb.Empty, error) {
+		return g.client.UpdateVault(ctx, sent)
 	})
+	if err != nil {
+		return nil, err
+	}
+	g.index.put(v.Id, v)
+	return resp, g.storage.PinUUID(v.Id, sent.Uuid)
 }
 
-// VaultDelete deletes a vault record by its ID.
+// VaultDelete moves a vault record to the trash by its ID.
 func (g *GophKeeper) VaultDelete(id uint64) (*emptypb.Empty, error) {
-	return g.client.DeleteVault(g.authCtx(), &pb.DeleteVaultRequest{
-		VaultId: id,
+	resp, err := authorized(g, func(ctx context.Context) (*emptypb.Empty, error) {
+		return g.client.DeleteVault(ctx, &pb.DeleteVaultRequest{
+			VaultId: id,
+		})
+	})
+	if err == nil {
+		g.index.remove(id)
+	}
+	return resp, err
+}
+
+// VaultHistory lists previous versions of a vault record, with their titles
+// and metadata decrypted if they are sealed.
+func (g *GophKeeper) VaultHistory(id uint64) (*pb.ListVaultVersionsResponse, error) {
+	resp, err := authorized(g, func(ctx context.Context) (*pb.ListVaultVersionsResponse, error) {
+		return g.client.ListVaultVersions(ctx, &pb.ListVaultVersionsRequest{
+			VaultId: id,
+		})
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ver := range resp.Versions {
+		v := &pb.VaultRecord{Id: id, Uuid: ver.Uuid, Type: ver.Type, SealedMeta: ver.SealedMeta}
+		if err = g.reveal(v); err != nil {
+			return nil, err
+		}
+		if len(ver.SealedMeta) > 0 {
+			ver.Title, ver.Metadata, ver.SealedMeta = v.Title, v.Metadata, nil
+		}
+	}
+	return resp, nil
+}
+
+// VaultRestore makes a previous version of a vault record current again.
+func (g *GophKeeper) VaultRestore(id, version uint64) (*pb.VaultRecord, error) {
+	v, err := authorized(g, func(ctx context.Context) (*pb.VaultRecord, error) {
+		return g.client.RestoreVaultVersion(ctx, &pb.RestoreVaultVersionRequest{
+			VaultId: id,
+			Version: version,
+		})
+	})
+	if err == nil {
+		err = g.reveal(v)
+	}
+	if err == nil {
+		err = g.storage.PinUUID(v.Id, v.Uuid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	g.index.put(v.Id, v)
+	return v, nil
+}
+
+// VaultTrash lists vault records in the trash.
+func (g *GophKeeper) VaultTrash() (*pb.ListVaultsResponse, error) {
+	return g.revealList(authorized(g, func(ctx context.Context) (*pb.ListVaultsResponse, error) {
+		return g.client.ListTrash(ctx, &emptypb.Empty{})
+	}))
+}
+
+// TrashRestore moves a vault record from the trash back to the vault and back
+// into the search index.
+func (g *GophKeeper) TrashRestore(id uint64) (*emptypb.Empty, error) {
+	resp, err := authorized(g, func(ctx context.Context) (*emptypb.Empty, error) {
+		return g.client.RestoreVault(ctx, &pb.RestoreVaultRequest{
+			VaultId: id,
+		})
+	})
+	if err != nil || g.index == nil {
+		return resp, err
+	}
+
+	// the record is back, fetch it to index it; the next sync catches up if this fails
+	if _, err = g.VaultGet(id); err != nil {
+		g.index.log.Warnf("index restored record %d: %v", id, err)
+	}
+	return resp, nil
+}
+
+// TrashPurge permanently deletes a vault record from the trash.
+func (g *GophKeeper) TrashPurge(id uint64) (*emptypb.Empty, error) {
+	return authorized(g, func(ctx context.Context) (*emptypb.Empty, error) {
+		return g.client.PurgeVault(ctx, &pb.PurgeVaultRequest{
+			VaultId: id,
+		})
+	})
+}
+
+// VaultSync returns one page of changes of the vault records since the cursor.
+func (g *GophKeeper) VaultSync(since uint64, limit int32) (*pb.SyncResponse, error) {
+	return authorized(g, func(ctx context.Context) (*pb.SyncResponse, error) {
+		return g.client.Sync(ctx, &pb.SyncRequest{
+			SinceCursor: since,
+			Limit:       limit,
+		})
+	})
+}
+
+// syncChanges pulls all changes since the cursor page by page, passing each
+// page to apply, and returns the cursor to continue from next time.
+func (g *GophKeeper) syncChanges(since uint64, apply func(page *pb.SyncResponse) error) (uint64, error) {
+	for {
+		page, err := g.VaultSync(since, 0)
+		if err != nil {
+			return since, err
+		}
+
+		if err = apply(page); err != nil {
+			return since, err
+		}
+		g.index.page(page)
+		since = page.Cursor
+
+		if !page.HasMore {
+			return since, nil
+		}
+	}
 }
@@ -3,13 +3,17 @@ package main
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
 	"github.com/wickedv43/go-goph-keeper/internal/config"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/mocks"
@@ -37,15 +41,21 @@ func TestGophKeeper_Login(t *testing.T) {
 
 		mockClient.EXPECT().
 			Login(gomock.Any(), &pb.LoginRequest{
-				Login:    login,
-				Password: gk.hashPassword(password),
+				Login:         login,
+				Password:      gk.hashPassword(password),
+				DeviceName:    deviceName(),
+				ClientVersion: buildVersion,
 			}).
-			Return(&pb.LoginResponse{Token: expectedToken}, nil)
+			Return(&pb.LoginResponse{Token: expectedToken, RefreshToken: "some-refresh"}, nil)
 
 		mockStorage.EXPECT().
 			SaveContext(login, expectedToken).
 			Return(nil)
 
+		mockStorage.EXPECT().
+			SaveRefreshToken(login, "some-refresh").
+			Return(nil)
+
 		token, err := gk.Login(login, password)
 		require.NoError(t, err)
 		require.NotNil(t, token)
@@ -236,6 +246,38 @@ func TestGophKeeper_VaultList(t *testing.T) {
 	})
 }
 
+func TestGophKeeper_VaultSummaries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	gk := &GophKeeper{
+		client:  mockClient,
+		storage: mockStorage,
+		cfg:     &config.Config{},
+		rootCtx: context.Background(),
+	}
+
+	req := &pb.ListVaultsRequest{Type: "note", PageToken: "next"}
+	expectedResp := &pb.ListVaultSummariesResponse{Vaults: []*pb.VaultSummary{{Id: 1, Title: "note", Size: 12}}}
+
+	mockStorage.EXPECT().GetCurrentToken().Return("token", nil)
+	mockClient.EXPECT().
+		ListVaultSummaries(gomock.Any(), req).
+		DoAndReturn(func(ctx context.Context, _ *pb.ListVaultsRequest, _ ...grpc.CallOption) (*pb.ListVaultSummariesResponse, error) {
+			md, ok := metadata.FromOutgoingContext(ctx)
+			require.True(t, ok)
+			require.Equal(t, []string{"Bearer token"}, md["authorization"])
+			return expectedResp, nil
+		})
+
+	resp, err := gk.VaultSummaries(req)
+	require.NoError(t, err)
+	require.Equal(t, expectedResp, resp)
+}
+
 func TestGophKeeper_VaultCreate(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -266,13 +308,17 @@ func TestGophKeeper_VaultCreate(t *testing.T) {
 			CreateVault(gomock.Any(), &pb.CreateVaultRequest{
 				Record: testRecord,
 			}).
-			DoAndReturn(func(ctx context.Context, req *pb.CreateVaultRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+			DoAndReturn(func(ctx context.Context, req *pb.CreateVaultRequest, _ ...grpc.CallOption) (*pb.VaultRecord, error) {
 				md, ok := metadata.FromOutgoingContext(ctx)
 				require.True(t, ok)
 				require.Equal(t, []string{"Bearer " + expectedToken}, md["authorization"])
-				return &emptypb.Empty{}, nil
+				return &pb.VaultRecord{}, nil
 			})
 
+		mockStorage.EXPECT().
+			PinUUID(uint64(0), "").
+			Return(nil)
+
 		resp, err := gk.VaultCreate(testRecord)
 		require.NoError(t, err)
 		require.NotNil(t, resp)
@@ -303,12 +349,16 @@ func TestGophKeeper_VaultCreate(t *testing.T) {
 			CreateVault(gomock.Any(), &pb.CreateVaultRequest{
 				Record: testRecord,
 			}).
-			DoAndReturn(func(ctx context.Context, req *pb.CreateVaultRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+			DoAndReturn(func(ctx context.Context, req *pb.CreateVaultRequest, _ ...grpc.CallOption) (*pb.VaultRecord, error) {
 				md, _ := metadata.FromOutgoingContext(ctx)
 				require.Nil(t, md)
-				return &emptypb.Empty{}, nil
+				return &pb.VaultRecord{}, nil
 			})
 
+		mockStorage.EXPECT().
+			PinUUID(uint64(0), "").
+			Return(nil)
+
 		_, err := gk.VaultCreate(testRecord)
 		require.NoError(t, err)
 	})
@@ -352,6 +402,10 @@ func TestGophKeeper_VaultGet(t *testing.T) {
 				return expectedRecord, nil
 			})
 
+		mockStorage.EXPECT().
+			PinUUID(vaultID, expectedRecord.Uuid).
+			Return(nil)
+
 		record, err := gk.VaultGet(vaultID)
 		require.NoError(t, err)
 		require.Equal(t, expectedRecord, record)
@@ -385,6 +439,10 @@ func TestGophKeeper_VaultGet(t *testing.T) {
 				return expectedRecord, nil
 			})
 
+		mockStorage.EXPECT().
+			PinUUID(vaultID, expectedRecord.Uuid).
+			Return(nil)
+
 		record, err := gk.VaultGet(vaultID)
 		require.NoError(t, err)
 		require.Equal(t, expectedRecord, record)
@@ -461,3 +519,241 @@ func TestGophKeeper_VaultDelete(t *testing.T) {
 		require.NotNil(t, resp)
 	})
 }
+
+func TestGophKeeper_RefreshOnUnauthenticated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("refreshes tokens and retries", func(t *testing.T) {
+		mockClient := mocks.NewMockGophKeeperClient(ctrl)
+		mockStorage := mocks.NewMockStorage(ctrl)
+
+		gk := &GophKeeper{
+			client:  mockClient,
+			storage: mockStorage,
+			cfg:     &config.Config{},
+			rootCtx: context.Background(),
+		}
+
+		gomock.InOrder(
+			mockStorage.EXPECT().GetCurrentToken().Return("expired", nil),
+			mockClient.EXPECT().
+				ListVaults(gomock.Any(), gomock.Any()).
+				Return(nil, status.Error(codes.Unauthenticated, "unauthenticated")),
+			mockStorage.EXPECT().GetCurrentToken().Return("expired", nil),
+			mockStorage.EXPECT().GetCurrentRefreshToken().Return("refresh-1", nil),
+			mockClient.EXPECT().
+				RefreshToken(gomock.Any(), &pb.RefreshTokenRequest{RefreshToken: "refresh-1"}).
+				Return(&pb.LoginResponse{Token: "fresh", RefreshToken: "refresh-2"}, nil),
+			mockStorage.EXPECT().GetCurrentContext().Return("prod", kv.Context{Login: "alice"}, nil),
+			mockStorage.EXPECT().SaveContextTokens("prod", "fresh", "refresh-2").Return(nil),
+			mockStorage.EXPECT().GetCurrentToken().Return("fresh", nil),
+			mockClient.EXPECT().
+				ListVaults(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, _ *pb.ListVaultsRequest, _ ...grpc.CallOption) (*pb.ListVaultsResponse, error) {
+					md, _ := metadata.FromOutgoingContext(ctx)
+					require.Equal(t, []string{"Bearer fresh"}, md["authorization"])
+					return &pb.ListVaultsResponse{}, nil
+				}),
+		)
+
+		_, err := gk.VaultList()
+		require.NoError(t, err)
+	})
+
+	t.Run("returns original error when refresh fails", func(t *testing.T) {
+		mockClient := mocks.NewMockGophKeeperClient(ctrl)
+		mockStorage := mocks.NewMockStorage(ctrl)
+
+		gk := &GophKeeper{
+			client:  mockClient,
+			storage: mockStorage,
+			cfg:     &config.Config{},
+			rootCtx: context.Background(),
+		}
+
+		mockStorage.EXPECT().GetCurrentToken().Return("expired", nil).Times(2)
+		mockClient.EXPECT().
+			GetVault(gomock.Any(), gomock.Any()).
+			Return(nil, status.Error(codes.Unauthenticated, "unauthenticated"))
+		mockStorage.EXPECT().GetCurrentRefreshToken().Return("", nil)
+
+		_, err := gk.VaultGet(1)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("concurrent callers refresh once", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+		require.NoError(t, store.SaveContextTokens("alice", "token", "refresh-1"))
+
+		// both calls are rejected before either refreshes, as with the watch
+		// stream and a command of the shell when the token expires
+		var rejected sync.WaitGroup
+		rejected.Add(2)
+		mockClient.EXPECT().ListVaults(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ *pb.ListVaultsRequest, _ ...grpc.CallOption) (*pb.ListVaultsResponse, error) {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				if md["authorization"][0] == "Bearer fresh" {
+					return &pb.ListVaultsResponse{}, nil
+				}
+				rejected.Done()
+				rejected.Wait()
+				return nil, status.Error(codes.Unauthenticated, "expired")
+			}).Times(4)
+		mockClient.EXPECT().
+			RefreshToken(gomock.Any(), &pb.RefreshTokenRequest{RefreshToken: "refresh-1"}).
+			Return(&pb.LoginResponse{Token: "fresh", RefreshToken: "refresh-2"}, nil)
+
+		errs := make(chan error, 2)
+		for range 2 {
+			go func() {
+				_, err := authorized(gk, func(ctx context.Context) (*pb.ListVaultsResponse, error) {
+					return gk.client.ListVaults(ctx, &pb.ListVaultsRequest{})
+				})
+				errs <- err
+			}()
+		}
+		require.NoError(t, <-errs)
+		require.NoError(t, <-errs)
+
+		refresh, err := store.GetCurrentRefreshToken()
+		require.NoError(t, err)
+		require.Equal(t, "refresh-2", refresh)
+	})
+}
+
+func TestGophKeeper_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	gk := &GophKeeper{
+		client:  mockClient,
+		storage: mockStorage,
+		cfg:     &config.Config{},
+		rootCtx: context.Background(),
+	}
+
+	mockStorage.EXPECT().GetCurrentContext().Return("prod", kv.Context{Login: "alice"}, nil)
+	mockStorage.EXPECT().GetCurrentToken().Return("token", nil)
+	mockClient.EXPECT().Logout(gomock.Any(), gomock.Any()).Return(&emptypb.Empty{}, nil)
+	mockStorage.EXPECT().SaveContextTokens("prod", "", "").Return(nil)
+
+	require.NoError(t, gk.Logout())
+}
+
+func TestGophKeeper_LoginMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+	gk := &GophKeeper{
+		client:  mockClient,
+		storage: mockStorage,
+		cfg:     &config.Config{Master: "test"},
+		rootCtx: context.Background(),
+	}
+
+	mockClient.EXPECT().
+		Login(gomock.Any(), gomock.Any()).
+		Return(&pb.LoginResponse{MfaRequired: true, MfaToken: "challenge"}, nil)
+
+	_, err := gk.Login("alice", "pass")
+	var mfa *MFARequiredError
+	require.ErrorAs(t, err, &mfa)
+	require.Equal(t, "challenge", mfa.Token)
+
+	mockClient.EXPECT().
+		VerifyMFA(gomock.Any(), &pb.VerifyMFARequest{
+			MfaToken:      "challenge",
+			Code:          "123456",
+			DeviceName:    deviceName(),
+			ClientVersion: buildVersion,
+		}).
+		Return(&pb.LoginResponse{Token: "access", RefreshToken: "refresh"}, nil)
+	mockStorage.EXPECT().SaveContext("alice", "access").Return(nil)
+	mockStorage.EXPECT().SaveRefreshToken("alice", "refresh").Return(nil)
+
+	token, err := gk.VerifyMFA("alice", mfa.Token, "123456")
+	require.NoError(t, err)
+	require.Equal(t, "access", token)
+}
+
+func TestGophKeeper_EnrollAndConfirmMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+	gk := &GophKeeper{
+		client:  mockClient,
+		storage: mockStorage,
+		cfg:     &config.Config{},
+		rootCtx: context.Background(),
+	}
+
+	mockStorage.EXPECT().GetCurrentToken().Return("token", nil).Times(2)
+	mockClient.EXPECT().
+		EnrollMFA(gomock.Any(), gomock.Any()).
+		Return(&pb.EnrollMFAResponse{Secret: "SECRET", OtpauthUri: "otpauth://totp/x"}, nil)
+	mockClient.EXPECT().
+		ConfirmMFA(gomock.Any(), &pb.ConfirmMFARequest{Code: "123456"}).
+		Return(&pb.ConfirmMFAResponse{RecoveryCodes: []string{"aaaaa-bbbbb"}}, nil)
+
+	enroll, err := gk.EnrollMFA()
+	require.NoError(t, err)
+	require.Equal(t, "SECRET", enroll.Secret)
+
+	codes, err := gk.ConfirmMFA("123456")
+	require.NoError(t, err)
+	require.Equal(t, []string{"aaaaa-bbbbb"}, codes)
+}
+
+func TestGophKeeper_SyncChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	gk := &GophKeeper{
+		client:  mockClient,
+		storage: mockStorage,
+		cfg:     &config.Config{},
+		rootCtx: context.Background(),
+	}
+
+	mockStorage.EXPECT().GetCurrentToken().Return("token", nil).AnyTimes()
+
+	t.Run("follows pages", func(t *testing.T) {
+		gomock.InOrder(
+			mockClient.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{SinceCursor: 4}).
+				Return(&pb.SyncResponse{Changed: []*pb.VaultRecord{{Id: 1}}, Cursor: 6, HasMore: true}, nil),
+			mockClient.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{SinceCursor: 6}).
+				Return(&pb.SyncResponse{Deleted: []uint64{2}, Cursor: 9}, nil),
+		)
+
+		var pages int
+		cursor, err := gk.syncChanges(4, func(page *pb.SyncResponse) error {
+			pages++
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(9), cursor)
+		require.Equal(t, 2, pages)
+	})
+
+	t.Run("keeps the cursor of the last applied page", func(t *testing.T) {
+		mockClient.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{SinceCursor: 9}).
+			Return(&pb.SyncResponse{Cursor: 12, HasMore: true}, nil)
+
+		cursor, err := gk.syncChanges(9, func(page *pb.SyncResponse) error {
+			return errors.New("disk full")
+		})
+		require.Error(t, err)
+		require.Equal(t, uint64(9), cursor)
+	})
+}
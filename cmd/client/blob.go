@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// transferRetries is how many times a transfer cut off by a lost connection is resumed.
+	transferRetries = 3
+	// transferRetryInterval is the pause before resuming a transfer.
+	transferRetryInterval = 2 * time.Second
+)
+
+// uploadFile encrypts the file chunk by chunk and uploads it as a blob,
+// showing the progress. An upload cut off by a lost connection is resumed
+// from the last chunk the server has, right away or by a later call for the
+// same unchanged file.
+func (g *GophKeeper) uploadFile(out io.Writer, path string) (kv.Binary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return kv.Binary{}, fmt.Errorf("не удалось открыть файл: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return kv.Binary{}, fmt.Errorf("не удалось прочитать файл: %w", err)
+	}
+
+	key, err := g.storage.GetCurrentKey()
+	if err != nil {
+		return kv.Binary{}, err
+	}
+
+	up, err := g.storage.Upload(path)
+	if err == nil && (up.Size != info.Size() || !up.ModTime.Equal(info.ModTime())) {
+		err = kv.ErrNoUpload // файл изменился, начинаем заново
+	}
+	if errors.Is(err, kv.ErrNoUpload) {
+		up, err = newUpload(key, path, info)
+		if err == nil {
+			err = g.storage.PutUpload(up)
+		}
+	}
+	if err != nil {
+		return kv.Binary{}, err
+	}
+
+	stream, err := crypto.OpenStream(key, up.Header)
+	if err != nil {
+		return kv.Binary{}, err
+	}
+
+	p := newProgress(out, "⬆️", up.Size)
+	defer p.finish()
+
+	for attempt := 0; ; attempt++ {
+		var st *pb.UploadStatus
+		st, err = g.sendChunks(f, stream, up, p)
+		if err == nil && !st.Complete {
+			err = errors.Errorf("сервер принял %d из %d кусков", st.Received, crypto.StreamChunks(up.Size))
+		}
+		if err == nil {
+			if err = g.storage.DropUpload(path); err != nil {
+				return kv.Binary{}, err
+			}
+			return kv.Binary{Blob: up.Ref, Size: up.Size, Header: up.Header}, nil
+		}
+
+		if !offline(err) || attempt == transferRetries {
+			return kv.Binary{}, err
+		}
+		g.waitTransferRetry()
+	}
+}
+
+// newUpload starts an upload of the file with a fresh blob reference and stream.
+func newUpload(key, path string, info os.FileInfo) (*kv.Upload, error) {
+	ref := make([]byte, 16)
+	if _, err := rand.Read(ref); err != nil {
+		return nil, err
+	}
+
+	stream, err := crypto.NewStream(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kv.Upload{
+		Ref:     hex.EncodeToString(ref),
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Header:  stream.Header(),
+	}, nil
+}
+
+// sendChunks uploads the chunks of the file the server does not have yet.
+func (g *GophKeeper) sendChunks(f *os.File, s *crypto.Stream, up *kv.Upload, p *progress) (*pb.UploadStatus, error) {
+	chunks := crypto.StreamChunks(up.Size)
+
+	return authorized(g, func(ctx context.Context) (*pb.UploadStatus, error) {
+		var offset uint32
+		st, err := g.client.GetUpload(ctx, &pb.GetUploadRequest{BlobId: up.Ref})
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return nil, err
+		default:
+			offset = st.Received
+		}
+		p.set(min(int64(offset)*crypto.StreamChunkSize, up.Size))
+
+		stream, err := g.client.UploadBlob(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		err = stream.Send(&pb.BlobPart{Part: &pb.BlobPart_Header{Header: &pb.BlobHeader{
+			BlobId: up.Ref,
+			Header: up.Header,
+			Size:   up.Size,
+			Chunks: chunks,
+			Offset: offset,
+		}}})
+
+		buf := make([]byte, crypto.StreamChunkSize)
+		for seq := offset; err == nil && seq < chunks; seq++ {
+			start := int64(seq) * crypto.StreamChunkSize
+			n := int(min(up.Size-start, crypto.StreamChunkSize))
+			if _, err = f.ReadAt(buf[:n], start); err != nil {
+				return nil, fmt.Errorf("не удалось прочитать файл: %w", err)
+			}
+
+			sealed := s.Seal(seq, buf[:n], seq+1 == chunks)
+			if err = stream.Send(&pb.BlobPart{Part: &pb.BlobPart_Chunk{Chunk: sealed}}); err == nil {
+				p.set(start + int64(n))
+			}
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		// при io.EOF сервер закрыл поток, причину вернёт CloseAndRecv
+		return stream.CloseAndRecv()
+	})
+}
+
+// downloadFile downloads the blob of a file record and decrypts it into path,
+// showing the progress. The file is written next to path and moved there only
+// when complete; a lost connection resumes the download from the last chunk.
+func (g *GophKeeper) downloadFile(out io.Writer, b kv.Binary, path string) error {
+	key, err := g.storage.GetCurrentKey()
+	if err != nil {
+		return err
+	}
+	s, err := crypto.OpenStream(key, b.Header)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("не удалось создать файл: %w", err)
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	p := newProgress(out, "⬇️", b.Size)
+	defer p.finish()
+
+	var next uint32
+	for attempt := 0; ; attempt++ {
+		err = g.receiveChunks(f, s, b, &next, p)
+		if err == nil {
+			break
+		}
+		if !offline(err) || attempt == transferRetries {
+			return err
+		}
+		g.waitTransferRetry()
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// receiveChunks downloads the chunks of the blob from *next on, advancing it
+// after each one written to f.
+func (g *GophKeeper) receiveChunks(f *os.File, s *crypto.Stream, b kv.Binary, next *uint32, p *progress) error {
+	_, err := authorized(g, func(ctx context.Context) (struct{}, error) {
+		stream, err := g.client.DownloadBlob(ctx, &pb.DownloadBlobRequest{BlobId: b.Blob, Offset: *next})
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		part, err := stream.Recv()
+		if err != nil {
+			return struct{}{}, err
+		}
+		hdr := part.GetHeader()
+		if hdr == nil || hdr.Size != b.Size || hdr.Chunks != crypto.StreamChunks(b.Size) {
+			return struct{}{}, errors.New("файл на сервере не совпадает с записью")
+		}
+
+		for *next < hdr.Chunks {
+			if part, err = stream.Recv(); err != nil {
+				if errors.Is(err, io.EOF) {
+					err = io.ErrUnexpectedEOF
+				}
+				return struct{}{}, err
+			}
+
+			plain, err := s.Open(*next, part.GetChunk(), *next+1 == hdr.Chunks)
+			if err != nil {
+				return struct{}{}, err
+			}
+			if _, err = f.WriteAt(plain, int64(*next)*crypto.StreamChunkSize); err != nil {
+				return struct{}{}, fmt.Errorf("не удалось записать файл: %w", err)
+			}
+
+			*next++
+			p.set(min(int64(*next)*crypto.StreamChunkSize, b.Size))
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// waitTransferRetry pauses before resuming a transfer.
+func (g *GophKeeper) waitTransferRetry() {
+	wait := g.transferRetry
+	if wait == 0 {
+		wait = transferRetryInterval
+	}
+	time.Sleep(wait)
+}
+
+// progress prints how much of a transfer is done, updating one line.
+type progress struct {
+	out   io.Writer
+	label string
+	total int64
+	shown int // last printed percentage
+}
+
+func newProgress(out io.Writer, label string, total int64) *progress {
+	return &progress{out: out, label: label, total: total, shown: -1}
+}
+
+// set reports that done bytes are transferred.
+func (p *progress) set(done int64) {
+	pct := 100
+	if p.total > 0 {
+		pct = int(done * 100 / p.total)
+	}
+	if pct == p.shown {
+		return
+	}
+	p.shown = pct
+	fmt.Fprintf(p.out, "\r%s %3d%% (%s из %s)", p.label, pct, formatSize(done), formatSize(p.total))
+}
+
+// finish ends the progress line.
+func (p *progress) finish() {
+	if p.shown >= 0 {
+		fmt.Fprintln(p.out)
+	}
+}
+
+// formatSize returns a human-readable size.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d Б", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cБ", float64(n)/float64(div), []rune("КМГТ")[exp])
+}
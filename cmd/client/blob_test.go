@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/mocks"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blobServer keeps one blob in memory and plays the server side of its transfers.
+type blobServer struct {
+	header *pb.BlobHeader
+	chunks [][]byte
+
+	// the next transfer fails with cut after this many chunks
+	cutAfter int
+	cut      error
+}
+
+// expect makes the mocked client transfer blobs through the server.
+func (s *blobServer) expect(mockClient *mocks.MockGophKeeperClient) {
+	mockClient.EXPECT().GetUpload(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.GetUploadRequest, _ ...grpc.CallOption) (*pb.UploadStatus, error) {
+			if s.header == nil || s.header.BlobId != in.BlobId {
+				return nil, status.Error(codes.NotFound, "файл не найден")
+			}
+			return &pb.UploadStatus{BlobId: in.BlobId, Received: uint32(len(s.chunks))}, nil
+		}).AnyTimes()
+	mockClient.EXPECT().UploadBlob(gomock.Any()).
+		DoAndReturn(func(context.Context, ...grpc.CallOption) (grpc.ClientStreamingClient[pb.BlobPart, pb.UploadStatus], error) {
+			return &uploadClient{srv: s}, nil
+		}).AnyTimes()
+	mockClient.EXPECT().DownloadBlob(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.DownloadBlobRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[pb.BlobPart], error) {
+			return &downloadClient{srv: s, next: in.Offset}, nil
+		}).AnyTimes()
+}
+
+// interrupted reports whether the transfer that has moved n chunks is cut now.
+func (s *blobServer) interrupted(n int) error {
+	if s.cut == nil || n < s.cutAfter {
+		return nil
+	}
+	err := s.cut
+	s.cut = nil
+	return err
+}
+
+type uploadClient struct {
+	grpc.ClientStream
+	srv  *blobServer
+	sent int
+	err  error
+}
+
+func (u *uploadClient) Send(p *pb.BlobPart) error {
+	if h := p.GetHeader(); h != nil {
+		if u.srv.header == nil || u.srv.header.BlobId != h.BlobId {
+			u.srv.header, u.srv.chunks = h, nil
+		}
+		if int(h.Offset) != len(u.srv.chunks) {
+			u.err = status.Error(codes.FailedPrecondition, "wrong offset")
+			return io.EOF
+		}
+		return nil
+	}
+
+	if u.err = u.srv.interrupted(u.sent); u.err != nil {
+		return io.EOF
+	}
+	u.srv.chunks = append(u.srv.chunks, p.GetChunk())
+	u.sent++
+	return nil
+}
+
+func (u *uploadClient) CloseAndRecv() (*pb.UploadStatus, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	received := uint32(len(u.srv.chunks))
+	return &pb.UploadStatus{BlobId: u.srv.header.BlobId, Received: received, Complete: received == u.srv.header.Chunks}, nil
+}
+
+type downloadClient struct {
+	grpc.ClientStream
+	srv        *blobServer
+	next       uint32
+	sentHeader bool
+	sent       int
+}
+
+func (d *downloadClient) Recv() (*pb.BlobPart, error) {
+	if !d.sentHeader {
+		d.sentHeader = true
+		return &pb.BlobPart{Part: &pb.BlobPart_Header{Header: d.srv.header}}, nil
+	}
+	if err := d.srv.interrupted(d.sent); err != nil {
+		return nil, err
+	}
+	if int(d.next) == len(d.srv.chunks) {
+		return nil, io.EOF
+	}
+	chunk := d.srv.chunks[d.next]
+	d.next++
+	d.sent++
+	return &pb.BlobPart{Part: &pb.BlobPart_Chunk{Chunk: chunk}}, nil
+}
+
+// writeTestFile writes size random bytes into a new file.
+func writeTestFile(t *testing.T, size int) (string, []byte) {
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "movie.mkv")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path, data
+}
+
+func TestBlobTransfer(t *testing.T) {
+	t.Run("round trip over a flaky connection", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+		gk.transferRetry = time.Millisecond
+		srv := &blobServer{cutAfter: 1, cut: errOffline}
+		srv.expect(mockClient)
+
+		path, data := writeTestFile(t, 2*crypto.StreamChunkSize+100)
+
+		var buf bytes.Buffer
+		b, err := gk.uploadFile(&buf, path)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(data)), b.Size)
+		require.Len(t, srv.chunks, 3)
+		require.Contains(t, buf.String(), "100%")
+		require.NotContains(t, string(bytes.Join(srv.chunks, nil)), string(data[:64]), "chunks are encrypted")
+
+		_, err = store.Upload(path)
+		require.ErrorIs(t, err, kv.ErrNoUpload, "finished uploads are forgotten")
+
+		srv.cutAfter, srv.cut = 2, errOffline
+		save := filepath.Join(t.TempDir(), "copy.mkv")
+		require.NoError(t, gk.downloadFile(&buf, b, save))
+
+		got, err := os.ReadFile(save)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+		require.NoFileExists(t, save+".part")
+	})
+
+	t.Run("upload resumed by a later call", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+		srv := &blobServer{cutAfter: 1, cut: status.Error(codes.Internal, "disk full")}
+		srv.expect(mockClient)
+
+		path, _ := writeTestFile(t, 2*crypto.StreamChunkSize)
+
+		_, err := gk.uploadFile(io.Discard, path)
+		require.Error(t, err)
+		require.Len(t, srv.chunks, 1)
+
+		up, err := store.Upload(path)
+		require.NoError(t, err)
+
+		b, err := gk.uploadFile(io.Discard, path)
+		require.NoError(t, err)
+		require.Equal(t, up.Ref, b.Blob, "continues the same blob")
+		require.Len(t, srv.chunks, 2)
+	})
+
+	t.Run("changed file starts over", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+		srv := &blobServer{}
+		srv.expect(mockClient)
+
+		path, _ := writeTestFile(t, 10)
+		require.NoError(t, store.PutUpload(&kv.Upload{Ref: "stale", Path: path, Size: 3, Header: make([]byte, crypto.StreamHeaderSize)}))
+
+		b, err := gk.uploadFile(io.Discard, path)
+		require.NoError(t, err)
+		require.NotEqual(t, "stale", b.Blob)
+		require.Len(t, srv.chunks, 1)
+	})
+
+	t.Run("tampered download", func(t *testing.T) {
+		gk, mockClient, _ := newCacheTestKeeper(t)
+		srv := &blobServer{}
+		srv.expect(mockClient)
+
+		path, _ := writeTestFile(t, crypto.StreamChunkSize+1)
+		b, err := gk.uploadFile(io.Discard, path)
+		require.NoError(t, err)
+
+		save := filepath.Join(t.TempDir(), "copy.mkv")
+
+		srv.chunks[0], srv.chunks[1] = srv.chunks[1], srv.chunks[0]
+		require.Error(t, gk.downloadFile(io.Discard, b, save))
+		require.NoFileExists(t, save)
+		require.NoFileExists(t, save+".part")
+
+		b.Size++
+		require.ErrorContains(t, gk.downloadFile(io.Discard, b, save), "не совпадает")
+	})
+}
+
+func TestFormatSize(t *testing.T) {
+	require.Equal(t, "512 Б", formatSize(512))
+	require.Equal(t, "1.5 КБ", formatSize(1536))
+	require.Equal(t, "3.0 МБ", formatSize(3<<20))
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Messages shown when the server is unreachable.
+const (
+	offlineNotice = "📴 Сервер недоступен — показаны сохранённые данные."
+	offlineSaved  = "📴 Сервер недоступен — изменение сохранено локально и будет отправлено при подключении (см. sync)."
+)
+
+// offline reports whether err means the server could not be reached.
+func offline(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// refreshCache sends the writes queued while offline and pulls the server
+// changes into the local mirror. It returns false without an error when the
+// server is unreachable, so the caller can fall back to the mirror.
+func (g *GophKeeper) refreshCache(out io.Writer) (bool, error) {
+	if err := g.flushOutbox(out); err != nil {
+		if offline(err) {
+			return false, nil
+		}
+		return true, err
+	}
+
+	err := g.pullChanges()
+	if offline(err) {
+		return false, nil
+	}
+	return true, err
+}
+
+// pullChanges brings the server changes since the last pull into the mirror.
+// It is safe to call while the shell watches for changes (see watch).
+func (g *GophKeeper) pullChanges() error {
+	g.syncMu.Lock()
+	defer g.syncMu.Unlock()
+
+	cursor, err := g.storage.SyncCursor()
+	if err != nil {
+		return err
+	}
+
+	_, err = g.syncChanges(cursor, g.storage.ApplySync)
+	return err
+}
+
+// flushOutbox replays the queued writes in the order they were made. Sent
+// writes leave the outbox; an update made against an older revision is merged
+// with the server version (see reconcile). A write the server rejects for
+// another reason stays there with the reason, so it is visible and retried
+// next time. Replaying stops as soon as the server turns out to be unreachable.
+func (g *GophKeeper) flushOutbox(out io.Writer) error {
+	entries, err := g.storage.Outbox()
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		e := &entries[i]
+
+		err = g.replay(e)
+		if _, ok := revisionConflict(err); ok && e.Op == kv.OpUpdate {
+			err = g.reconcileQueued(out, e)
+		}
+
+		switch {
+		case err == nil:
+			if err = g.storage.DropOutbox(e.Seq); err != nil {
+				return err
+			}
+		case offline(err):
+			return err
+		default:
+			e.Error = status.Convert(err).Message()
+			if err = g.storage.PutOutbox(e); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// replay sends one queued write to the server.
+func (g *GophKeeper) replay(e *kv.OutboxEntry) error {
+	var err error
+	switch e.Op {
+	case kv.OpCreate:
+		_, err = g.VaultCreate(e.Record)
+		if status.Code(err) == codes.AlreadyExists {
+			return nil // sent before, the response was lost
+		}
+	case kv.OpUpdate:
+		_, err = g.VaultUpdate(e.Record)
+	case kv.OpDelete:
+		_, err = g.VaultDelete(e.Record.Id)
+		if status.Code(err) == codes.NotFound {
+			return nil // already gone
+		}
+	default:
+		return fmt.Errorf("неизвестная операция: %s", e.Op)
+	}
+	return err
+}
+
+// reconcileQueued merges a queued update with the newer server revision and
+// tells how to finish the merge when fields clash.
+func (g *GophKeeper) reconcileQueued(out io.Writer, e *kv.OutboxEntry) error {
+	c, err := g.reconcile(out, e.Base, e.Record)
+	if err != nil || c == nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "⚠️ Запись %d изменили и здесь, и на другом устройстве. Выберите значения полей: conflicts resolve %d\n",
+		c.VaultID, c.VaultID)
+	return nil
+}
+
+// queueWrite keeps a write made offline for replay and applies it to the
+// mirror right away. base is the record an update was made from. Repeated
+// edits of a record are merged into one queued update, so they replay against
+// the revision the first edit was based on.
+func (g *GophKeeper) queueWrite(op string, v, base *pb.VaultRecord) error {
+	entry := &kv.OutboxEntry{Op: op, Record: v, Base: base}
+
+	if v.Id != 0 {
+		queued, err := g.storage.Outbox()
+		if err != nil {
+			return err
+		}
+		for _, e := range queued {
+			if e.Op != kv.OpUpdate || e.Record.Id != v.Id {
+				continue
+			}
+			if op == kv.OpUpdate {
+				entry.Seq = e.Seq
+				entry.Base = e.Base
+				v.Revision = e.Record.Revision
+			} else if err = g.storage.DropOutbox(e.Seq); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := g.storage.PutOutbox(entry); err != nil {
+		return fmt.Errorf("не удалось сохранить запись локально: %w", err)
+	}
+
+	switch op {
+	case kv.OpUpdate:
+		return g.storage.CacheVault(v)
+	case kv.OpDelete:
+		return g.storage.UncacheVault(v.Id)
+	}
+	return nil
+}
+
+// vaultGetCached fetches a record from the server, or from the mirror when the server is unreachable.
+func (g *GophKeeper) vaultGetCached(out io.Writer, id uint64) (*pb.VaultRecord, error) {
+	v, err := g.VaultGet(id)
+	if !offline(err) {
+		return v, err
+	}
+
+	v, cacheErr := g.storage.CachedVault(id)
+	if cacheErr != nil {
+		return nil, err
+	}
+	fmt.Fprintln(out, offlineNotice)
+	return v, g.reveal(v)
+}
+
+// printOutbox shows the writes that have not reached the server yet.
+func printOutbox(out io.Writer, entries []kv.OutboxEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "⏳ Ожидают отправки на сервер: %d\n", len(entries))
+	for _, e := range entries {
+		switch e.Op {
+		case kv.OpCreate:
+			fmt.Fprintf(out, "  + новая запись %q\n", e.Record.Title)
+		case kv.OpUpdate:
+			fmt.Fprintf(out, "  ~ запись %d %q\n", e.Record.Id, e.Record.Title)
+		case kv.OpDelete:
+			fmt.Fprintf(out, "  - удаление записи %d\n", e.Record.Id)
+		}
+		if e.Error != "" {
+			fmt.Fprintf(out, "    ❌ %s\n", e.Error)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/samber/do/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/mocks"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var errOffline = status.Error(codes.Unavailable, "connection refused")
+
+const testKey = "6368616e676520746869732070617373"
+
+// testRecordKey is the record key of the context of newCacheTestKeeper.
+var testRecordKey = recordKey{seed: testKey, owner: "alice"}
+
+// testUUID returns the UUID of test record id, the same on every call as the
+// UUID of a record never changes.
+func testUUID(id uint64) string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", id)
+}
+
+// sealedNote returns a note record encrypted with testKey.
+func sealedNote(t *testing.T, title, text string, revision uint64) *pb.VaultRecord {
+	data, err := json.Marshal(kv.Note{Text: text})
+	require.NoError(t, err)
+	v := &pb.VaultRecord{Id: 5, Uuid: testUUID(5), Type: "note", Title: title, Metadata: "{}", Revision: revision}
+	require.NoError(t, sealRecord(v, data, testRecordKey, crypto.AES256GCM))
+	return v
+}
+
+// openNote returns the title and text of a note record encrypted with testKey.
+func openNote(t *testing.T, v *pb.VaultRecord) (string, string) {
+	data, err := openRecord(v, testRecordKey)
+	require.NoError(t, err)
+	var n kv.Note
+	require.NoError(t, json.Unmarshal(data, &n))
+	return v.Title, n.Text
+}
+
+// newCacheTestKeeper returns a client backed by a real local store with one context.
+func newCacheTestKeeper(t *testing.T) (*GophKeeper, *mocks.MockGophKeeperClient, *kv.KV) {
+	ctrl := gomock.NewController(t)
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+
+	store := provideTestKV(t, do.New())
+	require.NoError(t, store.SetConfig(kv.Config{
+		Current:  "alice",
+		Contexts: map[string]kv.Context{"alice": {Login: "alice", Token: "token", Key: testKey}},
+	}))
+
+	return &GophKeeper{
+		client:  mockClient,
+		storage: store,
+		rootCtx: context.Background(),
+		cfg:     &config.Config{},
+	}, mockClient, store
+}
+
+func TestQueueWrite(t *testing.T) {
+	gk, _, store := newCacheTestKeeper(t)
+	require.NoError(t, store.CacheVault(&pb.VaultRecord{Id: 5, Title: "old", Revision: 3}))
+
+	require.NoError(t, gk.queueWrite(kv.OpCreate, &pb.VaultRecord{Title: "draft"}, nil))
+	base := &pb.VaultRecord{Id: 5, Title: "old", Revision: 3}
+	require.NoError(t, gk.queueWrite(kv.OpUpdate, &pb.VaultRecord{Id: 5, Title: "first", Revision: 3}, base))
+	require.NoError(t, gk.queueWrite(kv.OpUpdate, &pb.VaultRecord{Id: 5, Title: "second", Revision: 4}, &pb.VaultRecord{Id: 5, Title: "first"}))
+
+	queued, err := store.Outbox()
+	require.NoError(t, err)
+	require.Len(t, queued, 2, "edits of one record are merged")
+	require.Equal(t, "second", queued[1].Record.Title)
+	require.Equal(t, uint64(3), queued[1].Record.Revision, "keeps the revision the first edit was based on")
+	require.Equal(t, "old", queued[1].Base.Title, "keeps the record the first edit was made from")
+
+	cached, err := store.CachedVault(5)
+	require.NoError(t, err)
+	require.Equal(t, "second", cached.Title)
+
+	require.NoError(t, gk.queueWrite(kv.OpDelete, &pb.VaultRecord{Id: 5}, nil))
+
+	queued, err = store.Outbox()
+	require.NoError(t, err)
+	require.Len(t, queued, 2)
+	require.Equal(t, kv.OpDelete, queued[1].Op, "a delete replaces the queued update")
+
+	_, err = store.CachedVault(5)
+	require.ErrorIs(t, err, kv.ErrNotCached)
+}
+
+func TestFlushOutbox(t *testing.T) {
+	t.Run("sent and rejected", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+
+		require.NoError(t, gk.queueWrite(kv.OpCreate, &pb.VaultRecord{Title: "draft"}, nil))
+		require.NoError(t, gk.queueWrite(kv.OpUpdate, &pb.VaultRecord{Id: 5, Revision: 3}, nil))
+		require.NoError(t, gk.queueWrite(kv.OpDelete, &pb.VaultRecord{Id: 6}, nil))
+
+		mockClient.EXPECT().CreateVault(gomock.Any(), gomock.Any()).Return(&pb.VaultRecord{}, nil)
+		mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).
+			Return(nil, status.Error(codes.InvalidArgument, "title is too long"))
+		mockClient.EXPECT().DeleteVault(gomock.Any(), &pb.DeleteVaultRequest{VaultId: 6}).
+			Return(nil, status.Error(codes.NotFound, "not found"))
+
+		require.NoError(t, gk.flushOutbox(io.Discard))
+
+		queued, err := store.Outbox()
+		require.NoError(t, err)
+		require.Len(t, queued, 1)
+		require.Equal(t, uint64(5), queued[0].Record.Id)
+		require.Equal(t, "title is too long", queued[0].Error)
+	})
+
+	t.Run("create sent before", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+
+		require.NoError(t, gk.queueWrite(kv.OpCreate, &pb.VaultRecord{Title: "draft"}, nil))
+
+		mockClient.EXPECT().CreateVault(gomock.Any(), gomock.Any()).
+			Return(nil, status.Error(codes.AlreadyExists, "exists"))
+
+		require.NoError(t, gk.flushOutbox(io.Discard))
+
+		queued, err := store.Outbox()
+		require.NoError(t, err)
+		require.Empty(t, queued, "the record was created when the response got lost")
+	})
+
+	t.Run("merges a stale update", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+
+		base := sealedNote(t, "todo", "bread", 3)
+		require.NoError(t, gk.queueWrite(kv.OpUpdate, sealedNote(t, "groceries", "bread", 3), base))
+
+		gomock.InOrder(
+			mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Aborted, "conflict")),
+			mockClient.EXPECT().GetVault(gomock.Any(), &pb.GetVaultRequest{VaultId: 5}).Return(sealedNote(t, "todo", "eggs", 4), nil),
+			mockClient.EXPECT().
+				UpdateVault(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, v *pb.VaultRecord, _ ...any) (*emptypb.Empty, error) {
+					require.Equal(t, uint64(4), v.Revision)
+					title, text := openNote(t, v)
+					require.Equal(t, "groceries", title)
+					require.Equal(t, "eggs", text)
+					return &emptypb.Empty{}, nil
+				}),
+		)
+
+		var buf bytes.Buffer
+		require.NoError(t, gk.flushOutbox(&buf))
+		require.Contains(t, buf.String(), "изменения объединены")
+
+		queued, err := store.Outbox()
+		require.NoError(t, err)
+		require.Empty(t, queued)
+	})
+
+	t.Run("stores clashing fields as a conflict", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+
+		base := sealedNote(t, "todo", "bread", 3)
+		require.NoError(t, gk.queueWrite(kv.OpUpdate, sealedNote(t, "todo", "milk", 3), base))
+
+		mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Aborted, "conflict"))
+		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(sealedNote(t, "todo", "eggs", 4), nil)
+
+		var buf bytes.Buffer
+		require.NoError(t, gk.flushOutbox(&buf))
+		require.Contains(t, buf.String(), "conflicts resolve 5")
+
+		queued, err := store.Outbox()
+		require.NoError(t, err)
+		require.Empty(t, queued)
+
+		c, err := store.Conflict(5)
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), c.Remote.Revision)
+	})
+
+	t.Run("keeps both copies of a binary record", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+
+		base := &pb.VaultRecord{Id: 8, Type: "binary", Title: "photo", Revision: 1}
+		local := &pb.VaultRecord{Id: 8, Type: "binary", Title: "photo", Revision: 1}
+		require.NoError(t, sealRecord(local, []byte("file"), testRecordKey, crypto.AES256GCM))
+		require.NoError(t, gk.queueWrite(kv.OpUpdate, local, base))
+
+		mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Aborted, "conflict"))
+		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).
+			Return(&pb.VaultRecord{Id: 8, Type: "binary", Title: "photo", Revision: 2}, nil)
+		mockClient.EXPECT().
+			CreateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, in *pb.CreateVaultRequest, _ ...any) (*pb.VaultRecord, error) {
+				require.Zero(t, in.Record.Id)
+				require.Equal(t, "photo (конфликт)", in.Record.Title)
+				require.NotEmpty(t, in.Record.Uuid)
+				require.NotEqual(t, local.Uuid, in.Record.Uuid, "a new record gets its own UUID")
+				data, err := crypto.Open(in.Record.EncryptedData, testKey, crypto.RecordAAD(in.Record.Uuid, "binary", "alice"))
+				require.NoError(t, err, "sealed again for the new record")
+				require.Equal(t, []byte("file"), data)
+				return &pb.VaultRecord{}, nil
+			})
+
+		var buf bytes.Buffer
+		require.NoError(t, gk.flushOutbox(&buf))
+		require.Contains(t, buf.String(), "отдельной записью")
+
+		queued, err := store.Outbox()
+		require.NoError(t, err)
+		require.Empty(t, queued)
+	})
+
+	t.Run("offline stops replay", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+
+		require.NoError(t, gk.queueWrite(kv.OpCreate, &pb.VaultRecord{Title: "one"}, nil))
+		require.NoError(t, gk.queueWrite(kv.OpCreate, &pb.VaultRecord{Title: "two"}, nil))
+
+		mockClient.EXPECT().CreateVault(gomock.Any(), gomock.Any()).Return(nil, errOffline)
+
+		err := gk.flushOutbox(io.Discard)
+		require.True(t, offline(err))
+
+		queued, err := store.Outbox()
+		require.NoError(t, err)
+		require.Len(t, queued, 2)
+		require.Empty(t, queued[0].Error)
+	})
+}
+
+func TestSyncCMD(t *testing.T) {
+	t.Run("online", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+
+		mockClient.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{}).Return(&pb.SyncResponse{
+			Changed:    []*pb.VaultRecord{{Id: 1, Title: "one"}},
+			Cursor:     3,
+			FullResync: true,
+		}, nil)
+
+		var buf bytes.Buffer
+		cmd := gk.SyncCMD()
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, nil))
+		require.Contains(t, buf.String(), "Локальная копия обновлена")
+
+		cursor, err := store.SyncCursor()
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), cursor)
+
+		list, err := store.CachedVaults()
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+	})
+
+	t.Run("offline", func(t *testing.T) {
+		gk, mockClient, _ := newCacheTestKeeper(t)
+
+		require.NoError(t, gk.queueWrite(kv.OpCreate, &pb.VaultRecord{Title: "draft"}, nil))
+		mockClient.EXPECT().CreateVault(gomock.Any(), gomock.Any()).Return(nil, errOffline)
+
+		var buf bytes.Buffer
+		cmd := gk.SyncCMD()
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, nil))
+		require.Contains(t, buf.String(), "Сервер недоступен")
+		require.Contains(t, buf.String(), `+ новая запись "draft"`)
+	})
+}
+
+func TestVaultGetCached(t *testing.T) {
+	gk, mockClient, store := newCacheTestKeeper(t)
+	require.NoError(t, store.CacheVault(&pb.VaultRecord{Id: 7, Title: "cached"}))
+
+	mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(nil, errOffline).Times(2)
+
+	var buf bytes.Buffer
+	v, err := gk.vaultGetCached(&buf, 7)
+	require.NoError(t, err)
+	require.Equal(t, "cached", v.Title)
+	require.Contains(t, buf.String(), offlineNotice)
+
+	_, err = gk.vaultGetCached(&buf, 8)
+	require.True(t, offline(err), "a record missing from the mirror reports the connection error")
+}
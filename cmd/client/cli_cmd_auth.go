@@ -30,7 +30,18 @@ func (g *GophKeeper) LoginCMD() *cobra.Command {
 			}
 			_, _ = fmt.Fprintln(out, "")
 
-			if _, err = g.Login(login, password); err != nil {
+			_, err = g.Login(login, password)
+			var mfa *MFARequiredError
+			if errors.As(err, &mfa) {
+				var code string
+				_, _ = fmt.Fprint(out, "🔑 Код 2FA или код восстановления: ")
+				if _, err = fmt.Scanln(&code); err != nil {
+					return fmt.Errorf("ошибка чтения кода: %w", err)
+				}
+
+				_, err = g.VerifyMFA(login, mfa.Token, code)
+			}
+			if err != nil {
 				return err
 			}
 
@@ -99,3 +110,65 @@ func (g *GophKeeper) RegisterCMD() *cobra.Command {
 
 	return cmd
 }
+
+func (g *GophKeeper) LogoutCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Выход из GophKeeper",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := g.Logout(); err != nil {
+				return fmt.Errorf("ошибка выхода: %w", err)
+			}
+
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "👋 Сессия завершена")
+			return nil
+		},
+	}
+}
+
+func (g *GophKeeper) MFACMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mfa",
+		Short: "Двухфакторная аутентификация",
+	}
+	cmd.AddCommand(g.MFAEnableCMD())
+
+	return cmd
+}
+
+func (g *GophKeeper) MFAEnableCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "enable",
+		Short: "Подключить двухфакторную аутентификацию (TOTP)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			enroll, err := g.EnrollMFA()
+			if err != nil {
+				return fmt.Errorf("ошибка подключения 2FA: %w", err)
+			}
+
+			_, _ = fmt.Fprintln(out, "📱 Добавьте аккаунт в приложение-аутентификатор:")
+			_, _ = fmt.Fprintln(out, enroll.OtpauthUri)
+			_, _ = fmt.Fprintf(out, "🔑 Или введите секрет вручную: %s\n", enroll.Secret)
+
+			var code string
+			_, _ = fmt.Fprint(out, "🔢 Код из приложения: ")
+			if _, err = fmt.Scanln(&code); err != nil {
+				return fmt.Errorf("ошибка чтения кода: %w", err)
+			}
+
+			recovery, err := g.ConfirmMFA(code)
+			if err != nil {
+				return fmt.Errorf("ошибка подтверждения 2FA: %w", err)
+			}
+
+			_, _ = fmt.Fprintln(out, "✅ 2FA включена. 💾 Сохраните коды восстановления, каждый работает один раз:")
+			for _, c := range recovery {
+				_, _ = fmt.Fprintf(out, "  %s\n", c)
+			}
+
+			return nil
+		},
+	}
+}
@@ -48,13 +48,17 @@ func TestLoginCMD(t *testing.T) {
 		}()
 
 		mockClient.EXPECT().
-			Login(gomock.Any(), &pb.LoginRequest{Login: "login", Password: gk.hashPassword("pass")}).
-			Return(&pb.LoginResponse{Token: "token123"}, nil)
+			Login(gomock.Any(), &pb.LoginRequest{Login: "login", Password: gk.hashPassword("pass"), DeviceName: deviceName(), ClientVersion: buildVersion}).
+			Return(&pb.LoginResponse{Token: "token123", RefreshToken: "refresh123"}, nil)
 
 		mockStorage.EXPECT().
 			SaveContext("login", "token123").
 			Return(nil)
 
+		mockStorage.EXPECT().
+			SaveRefreshToken("login", "refresh123").
+			Return(nil)
+
 		mockStorage.EXPECT().
 			GetCurrentKey().
 			Return("already-there", nil)
@@ -95,7 +99,7 @@ func TestLoginCMD(t *testing.T) {
 		}()
 
 		mockClient.EXPECT().
-			Login(gomock.Any(), &pb.LoginRequest{Login: "login", Password: gk.hashPassword("pass")}).
+			Login(gomock.Any(), &pb.LoginRequest{Login: "login", Password: gk.hashPassword("pass"), DeviceName: deviceName(), ClientVersion: buildVersion}).
 			Return(&pb.LoginResponse{}, errors.New("not found"))
 
 		cmd := gk.LoginCMD()
@@ -117,13 +121,17 @@ func TestLoginCMD(t *testing.T) {
 		}()
 
 		mockClient.EXPECT().
-			Login(gomock.Any(), &pb.LoginRequest{Login: "login", Password: gk.hashPassword("pass")}).
-			Return(&pb.LoginResponse{Token: "token123"}, nil)
+			Login(gomock.Any(), &pb.LoginRequest{Login: "login", Password: gk.hashPassword("pass"), DeviceName: deviceName(), ClientVersion: buildVersion}).
+			Return(&pb.LoginResponse{Token: "token123", RefreshToken: "refresh123"}, nil)
 
 		mockStorage.EXPECT().
 			SaveContext("login", "token123").
 			Return(nil)
 
+		mockStorage.EXPECT().
+			SaveRefreshToken("login", "refresh123").
+			Return(nil)
+
 		mockStorage.EXPECT().
 			GetCurrentKey().
 			Return("", kv.ErrEmptyKey)
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+)
+
+// ConflictsCMD returns a Cobra command that groups conflict subcommands.
+// Without a subcommand it lists the unresolved conflicts.
+func (g *GophKeeper) ConflictsCMD() *cobra.Command {
+	list := g.ConflictsListCMD()
+
+	cmd := &cobra.Command{
+		Use:   "conflicts",
+		Short: "Конфликты изменений с другими устройствами",
+		RunE:  list.RunE,
+	}
+	cmd.AddCommand(list, g.ConflictsResolveCMD())
+
+	return cmd
+}
+
+// ConflictsListCMD returns a Cobra command that lists edits waiting to be merged by hand.
+func (g *GophKeeper) ConflictsListCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Показать неразрешённые конфликты",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := g.storage.Conflicts()
+			if err != nil {
+				return fmt.Errorf("ошибка чтения конфликтов: %w", err)
+			}
+
+			if len(list) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "Конфликтов нет ✅")
+				return nil
+			}
+
+			key, err := currentRecordKey(g.storage)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTITLE\tFIELDS\tDETECTED AT")
+			for _, c := range list {
+				fields := "?"
+				if m, err := mergeRecords(c.Base, c.Local, c.Remote, key); err == nil {
+					names := make([]string, 0, len(m.Conflicts))
+					for _, f := range m.Conflicts {
+						names = append(names, f.Field)
+					}
+					fields = strings.Join(names, ", ")
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", c.VaultID, c.Local.Title, fields, c.DetectedAt.Format("02.01.2006 15:04"))
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+// ConflictsResolveCMD returns a Cobra command that walks through the
+// conflicting fields of a record, or of every record when no ID is given.
+func (g *GophKeeper) ConflictsResolveCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "resolve [id]",
+		Short: "Выбрать значения конфликтующих полей",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var list []kv.Conflict
+			if len(args) > 0 {
+				id, err := strconv.ParseUint(args[len(args)-1], 10, 64)
+				if err != nil {
+					return fmt.Errorf("неверный ID: %w", err)
+				}
+
+				c, err := g.storage.Conflict(id)
+				if errors.Is(err, kv.ErrNoConflict) {
+					fmt.Fprintf(cmd.OutOrStdout(), "У записи %d нет конфликтов.\n", id)
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("ошибка чтения конфликта: %w", err)
+				}
+				list = append(list, *c)
+			} else {
+				var err error
+				if list, err = g.storage.Conflicts(); err != nil {
+					return fmt.Errorf("ошибка чтения конфликтов: %w", err)
+				}
+				if len(list) == 0 {
+					fmt.Fprintln(cmd.OutOrStdout(), "Конфликтов нет ✅")
+					return nil
+				}
+			}
+
+			for i := range list {
+				if _, err := g.resolveConflict(cmd, &list[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// resolveConflict asks which value to keep for every conflicting field of
+// the record and saves the result over the server revision. It returns false
+// when the user postpones the decision; the conflict then stays stored.
+func (g *GophKeeper) resolveConflict(cmd *cobra.Command, c *kv.Conflict) (bool, error) {
+	out := cmd.OutOrStdout()
+
+	key, err := currentRecordKey(g.storage)
+	if err != nil {
+		return false, err
+	}
+
+	for {
+		m, err := mergeRecords(c.Base, c.Local, c.Remote, key)
+		if err != nil {
+			return false, err
+		}
+
+		if len(m.Conflicts) > 0 {
+			fmt.Fprintf(out, "⚠️ Запись %d %q изменена и у вас, и на другом устройстве (ревизия %d).\n",
+				c.VaultID, c.Remote.Title, c.Remote.Revision)
+		}
+		for _, f := range m.Conflicts {
+			fmt.Fprintf(out, "%s:\n  [1] ваша версия: %s\n  [2] на сервере:  %s\n", f.Field, f.Local, f.Remote)
+			fmt.Fprint(out, "Оставить [1/2], [s] решить позже: ")
+
+			var answer string
+			_, _ = fmt.Fscanln(cmd.InOrStdin(), &answer)
+
+			switch strings.TrimSpace(answer) {
+			case "1":
+				m.set(f.Field, f.Local)
+			case "2":
+				m.set(f.Field, f.Remote)
+			default:
+				fmt.Fprintf(out, "Отложено. Вернуться к конфликту: conflicts resolve %d\n", c.VaultID)
+				return false, nil
+			}
+		}
+
+		resolved, err := m.seal(key, g.recordCipher())
+		if err != nil {
+			return false, err
+		}
+
+		_, err = g.VaultUpdate(resolved)
+		if _, ok := revisionConflict(err); ok {
+			// The record changed again: merge the resolved version with the new revision.
+			remote, err := g.VaultGet(c.VaultID)
+			if err != nil {
+				return false, fmt.Errorf("не удалось получить запись: %w", err)
+			}
+			c.Base, c.Local, c.Remote = c.Remote, resolved, remote
+			if err = g.storage.PutConflict(c); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("ошибка обновления: %w", err)
+		}
+
+		if err = g.storage.DropConflict(c.VaultID); err != nil {
+			return false, err
+		}
+		fmt.Fprintf(out, "✅ Конфликт записи %d разрешён.\n", c.VaultID)
+		return true, nil
+	}
+}
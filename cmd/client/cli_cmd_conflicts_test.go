@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestConflictsCMD(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		gk, _, store := newCacheTestKeeper(t)
+
+		var buf bytes.Buffer
+		cmd := gk.ConflictsCMD()
+		cmd.SetOut(&buf)
+		require.NoError(t, cmd.RunE(cmd, nil))
+		require.Contains(t, buf.String(), "Конфликтов нет")
+
+		require.NoError(t, store.PutConflict(&kv.Conflict{
+			VaultID: 5,
+			Base:    sealedNote(t, "todo", "bread", 3),
+			Local:   sealedNote(t, "mine", "milk", 3),
+			Remote:  sealedNote(t, "theirs", "eggs", 4),
+		}))
+
+		buf.Reset()
+		require.NoError(t, cmd.RunE(cmd, nil))
+		require.Contains(t, buf.String(), "title, text")
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+
+		require.NoError(t, store.PutConflict(&kv.Conflict{
+			VaultID: 5,
+			Base:    sealedNote(t, "todo", "bread", 3),
+			Local:   sealedNote(t, "mine", "milk", 3),
+			Remote:  sealedNote(t, "theirs", "eggs", 4),
+		}))
+
+		mockClient.EXPECT().
+			UpdateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *pb.VaultRecord, _ ...any) (*emptypb.Empty, error) {
+				require.Equal(t, uint64(4), v.Revision)
+				title, text := openNote(t, v)
+				require.Equal(t, "theirs", title)
+				require.Equal(t, "milk", text)
+				return &emptypb.Empty{}, nil
+			})
+
+		var buf bytes.Buffer
+		cmd := gk.ConflictsResolveCMD()
+		cmd.SetOut(&buf)
+		cmd.SetIn(strings.NewReader("2\n1\n"))
+
+		require.NoError(t, cmd.RunE(cmd, []string{"5"}))
+		require.Contains(t, buf.String(), "Конфликт записи 5 разрешён")
+
+		_, err := store.Conflict(5)
+		require.ErrorIs(t, err, kv.ErrNoConflict)
+	})
+
+	t.Run("resolve against a newer revision", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+
+		require.NoError(t, store.PutConflict(&kv.Conflict{
+			VaultID: 5,
+			Base:    sealedNote(t, "todo", "bread", 3),
+			Local:   sealedNote(t, "todo", "milk", 3),
+			Remote:  sealedNote(t, "todo", "eggs", 4),
+		}))
+
+		gomock.InOrder(
+			mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Aborted, "conflict")),
+			mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(sealedNote(t, "renamed", "eggs", 5), nil),
+			mockClient.EXPECT().
+				UpdateVault(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, v *pb.VaultRecord, _ ...any) (*emptypb.Empty, error) {
+					require.Equal(t, uint64(5), v.Revision)
+					title, text := openNote(t, v)
+					require.Equal(t, "renamed", title)
+					require.Equal(t, "milk", text)
+					return &emptypb.Empty{}, nil
+				}),
+		)
+
+		var buf bytes.Buffer
+		cmd := gk.ConflictsResolveCMD()
+		cmd.SetOut(&buf)
+		cmd.SetIn(strings.NewReader("1\n"))
+
+		require.NoError(t, cmd.RunE(cmd, nil))
+		require.Contains(t, buf.String(), "разрешён")
+	})
+
+	t.Run("postpone", func(t *testing.T) {
+		gk, _, store := newCacheTestKeeper(t)
+
+		require.NoError(t, store.PutConflict(&kv.Conflict{
+			VaultID: 5,
+			Base:    sealedNote(t, "todo", "bread", 3),
+			Local:   sealedNote(t, "todo", "milk", 3),
+			Remote:  sealedNote(t, "todo", "eggs", 4),
+		}))
+
+		var buf bytes.Buffer
+		cmd := gk.ConflictsResolveCMD()
+		cmd.SetOut(&buf)
+		cmd.SetIn(strings.NewReader("s\n"))
+
+		require.NoError(t, cmd.RunE(cmd, []string{"5"}))
+		require.Contains(t, buf.String(), "Отложено")
+
+		_, err := store.Conflict(5)
+		require.NoError(t, err)
+	})
+
+	t.Run("no conflict", func(t *testing.T) {
+		gk, _, _ := newCacheTestKeeper(t)
+
+		var buf bytes.Buffer
+		cmd := gk.ConflictsResolveCMD()
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, []string{"9"}))
+		require.Contains(t, buf.String(), "нет конфликтов")
+
+		require.Error(t, cmd.RunE(cmd, []string{"abc"}))
+	})
+}
@@ -2,8 +2,14 @@ package main
 
 import (
 	"fmt"
+	"net"
+	"strconv"
+	"text/tabwriter"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
 )
 
 // ContextUseCMD returns a Cobra command that switches the current context to the specified name.
@@ -13,7 +19,7 @@ func (g *GophKeeper) ContextUseCMD() *cobra.Command {
 		Short: "Switch context",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			name := args[1]
+			name := args[len(args)-1]
 			cfg, err := g.storage.GetConfig()
 			if err != nil {
 				return err
@@ -28,6 +34,10 @@ func (g *GophKeeper) ContextUseCMD() *cobra.Command {
 				return fmt.Errorf("не удалось сохранить конфиг: %w", err)
 			}
 
+			if err = g.connect(cfg.Contexts[name]); err != nil {
+				return err
+			}
+
 			fmt.Fprintf(cmd.OutOrStdout(), "Context switched ✅")
 
 			return nil
@@ -51,15 +61,162 @@ func (g *GophKeeper) ContextListCMD() *cobra.Command {
 				return nil
 			}
 
-			for name := range cfg.Contexts {
+			for name, c := range cfg.Contexts {
 				active := ""
 				if name == cfg.Current {
 					active = " (in use)"
 				}
-				fmt.Fprintf(cmd.OutOrStdout(), "  - %s%s\n", name, active)
+
+				endpoint := ""
+				if c.Server != "" {
+					endpoint = " → " + c.Server
+					if c.TLS.Enabled {
+						endpoint += " (tls)"
+					}
+				}
+				if c.Login != "" && c.Login != name {
+					endpoint += " as " + c.Login
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "  - %s%s%s\n", name, active, endpoint)
+			}
+
+			return nil
+		},
+	}
+}
+
+// ContextCMD returns a Cobra command that groups context management subcommands.
+func (g *GophKeeper) ContextCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Manage contexts",
+	}
+	cmd.AddCommand(g.ContextAddCMD())
+
+	return cmd
+}
+
+// ContextAddCMD returns a Cobra command that creates a context for another server.
+// The next login in the context binds it to the account.
+func (g *GophKeeper) ContextAddCMD() *cobra.Command {
+	var c kv.Context
+
+	cmd := &cobra.Command{
+		Use:   "add [context-name]",
+		Short: "Add context",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[len(args)-1]
+			if c.Server == "" {
+				return errors.New("укажите адрес сервера: --server host:port")
+			}
+			if _, _, err := net.SplitHostPort(c.Server); err != nil {
+				return fmt.Errorf("неверный адрес сервера %q: %w", c.Server, err)
+			}
+
+			if err := g.storage.AddContext(name, c); err != nil {
+				if errors.Is(err, kv.ErrContextExists) {
+					return fmt.Errorf("контекст %q уже существует", name)
+				}
+				return fmt.Errorf("не удалось сохранить контекст: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Context %s added ✅ Switch with `use %s` and log in\n", name, name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&c.Server, "server", "", "server address, host:port")
+	cmd.Flags().BoolVar(&c.TLS.Enabled, "tls", false, "connect over TLS")
+	cmd.Flags().StringVar(&c.TLS.CAFile, "ca", "", "CA certificate the server must be signed by")
+	cmd.Flags().StringVar(&c.TLS.CertFile, "cert", "", "client certificate for mutual TLS")
+	cmd.Flags().StringVar(&c.TLS.KeyFile, "key", "", "client certificate key")
+	cmd.Flags().StringVar(&c.TLS.ServerName, "server-name", "", "name expected in the server certificate")
+
+	return cmd
+}
+
+// SessionsCMD returns a Cobra command that groups session management subcommands.
+func (g *GophKeeper) SessionsCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Manage active sessions",
+	}
+	cmd.AddCommand(g.SessionListCMD(), g.SessionRevokeCMD())
+
+	return cmd
+}
+
+// SessionListCMD returns a Cobra command that lists active sessions of the current account.
+func (g *GophKeeper) SessionListCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Sessions list",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := g.SessionList()
+			if err != nil {
+				return fmt.Errorf("ошибка получения списка сессий: %w", err)
+			}
+
+			if len(resp.Sessions) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "Sessions empty 📭")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tDEVICE\tVERSION\tIP\tCREATED AT\tLAST SEEN")
+
+			for _, s := range resp.Sessions {
+				current := ""
+				if s.Current {
+					current = " (current)"
+				}
+				fmt.Fprintf(w, "%d%s\t%s\t%s\t%s\t%s\t%s\n",
+					s.Id, current, orDash(s.DeviceName), orDash(s.ClientVersion), orDash(s.Ip),
+					formatTime(s.CreatedAt), formatTime(s.LastSeenAt))
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+// SessionRevokeCMD returns a Cobra command that ends a session by its ID, e.g. of a lost device.
+func (g *GophKeeper) SessionRevokeCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke [session-id]",
+		Short: "Revoke session",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseUint(args[len(args)-1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("неверный ID сессии: %w", err)
 			}
 
+			if _, err = g.SessionRevoke(id); err != nil {
+				return fmt.Errorf("ошибка завершения сессии: %w", err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "Session revoked ✅")
 			return nil
 		},
 	}
 }
+
+// formatTime renders an RFC 3339 timestamp from the server in local time.
+func formatTime(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return "-"
+	}
+	return t.Local().Format("02-01-2006 15:04")
+}
+
+// orDash replaces an empty value with a dash.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -10,8 +10,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
 	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/mocks"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
 	"github.com/wickedv43/go-goph-keeper/internal/config"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 func TestContextUseCMD(t *testing.T) {
@@ -158,3 +160,169 @@ func TestContextListCMD(t *testing.T) {
 	})
 
 }
+
+func TestSessionListCMD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	gk := &GophKeeper{
+		client:  mockClient,
+		storage: mockStorage,
+		rootCtx: context.Background(),
+		cfg:     &config.Config{},
+	}
+
+	t.Run("session_list_success", func(t *testing.T) {
+		mockStorage.EXPECT().GetCurrentToken().Return("token", nil)
+		mockClient.EXPECT().ListSessions(gomock.Any(), gomock.Any()).Return(&pb.ListSessionsResponse{
+			Sessions: []*pb.Session{
+				{Id: 5, DeviceName: "laptop", ClientVersion: "v1.0.0", Ip: "10.0.0.1", Current: true,
+					CreatedAt: "2025-07-01T10:00:00Z", LastSeenAt: "2025-07-02T10:00:00Z"},
+				{Id: 6, DeviceName: "phone"},
+			},
+		}, nil)
+
+		cmd := gk.SessionListCMD()
+
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		require.NoError(t, cmd.RunE(cmd, nil))
+
+		out := buf.String()
+		require.Contains(t, out, "5 (current)")
+		require.Contains(t, out, "laptop")
+		require.Contains(t, out, "10.0.0.1")
+		require.Contains(t, out, "phone")
+	})
+
+	t.Run("session_list_error", func(t *testing.T) {
+		mockStorage.EXPECT().GetCurrentToken().Return("token", nil)
+		mockClient.EXPECT().ListSessions(gomock.Any(), gomock.Any()).Return(nil, errors.New("boom"))
+
+		cmd := gk.SessionListCMD()
+		require.ErrorContains(t, cmd.RunE(cmd, nil), "boom")
+	})
+}
+
+func TestSessionRevokeCMD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	gk := &GophKeeper{
+		client:  mockClient,
+		storage: mockStorage,
+		rootCtx: context.Background(),
+		cfg:     &config.Config{},
+	}
+
+	t.Run("session_revoke_success", func(t *testing.T) {
+		mockStorage.EXPECT().GetCurrentToken().Return("token", nil)
+		mockClient.EXPECT().
+			RevokeSession(gomock.Any(), &pb.RevokeSessionRequest{SessionId: 6}).
+			Return(&emptypb.Empty{}, nil)
+
+		cmd := gk.SessionRevokeCMD()
+
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		require.NoError(t, cmd.RunE(cmd, []string{"6"}))
+		require.Contains(t, buf.String(), "Session revoked")
+	})
+
+	t.Run("session_revoke_bad_id", func(t *testing.T) {
+		cmd := gk.SessionRevokeCMD()
+		require.ErrorContains(t, cmd.RunE(cmd, []string{"abc"}), "неверный ID сессии")
+	})
+}
+
+func TestContextAddCMD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	gk := &GophKeeper{
+		storage: mockStorage,
+		rootCtx: context.Background(),
+		cfg:     &config.Config{},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockStorage.EXPECT().AddContext("prod", kv.Context{
+			Server: "keeper.example.com:443",
+			TLS:    kv.TLS{Enabled: true, CAFile: "ca.pem"},
+		}).Return(nil)
+
+		cmd := gk.ContextAddCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{"prod", "--server", "keeper.example.com:443", "--tls", "--ca", "ca.pem"})
+
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, buf.String(), "Context prod added")
+	})
+
+	t.Run("no server", func(t *testing.T) {
+		cmd := gk.ContextAddCMD()
+		cmd.SetArgs([]string{"prod"})
+		cmd.SilenceUsage = true
+
+		require.Error(t, cmd.Execute())
+	})
+
+	t.Run("bad server address", func(t *testing.T) {
+		cmd := gk.ContextAddCMD()
+		cmd.SetArgs([]string{"prod", "--server", "keeper.example.com"})
+		cmd.SilenceUsage = true
+
+		require.Error(t, cmd.Execute())
+	})
+
+	t.Run("already exists", func(t *testing.T) {
+		mockStorage.EXPECT().AddContext("prod", gomock.Any()).Return(kv.ErrContextExists)
+
+		cmd := gk.ContextAddCMD()
+		cmd.SetArgs([]string{"prod", "--server", "keeper.example.com:443"})
+		cmd.SilenceUsage = true
+
+		require.ErrorContains(t, cmd.Execute(), "уже существует")
+	})
+}
+
+func TestContextUseCMD_Redial(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	gk := &GophKeeper{
+		storage: mockStorage,
+		rootCtx: context.Background(),
+		cfg:     &config.Config{Server: config.Server{Port: "8080"}},
+	}
+	require.NoError(t, gk.connect(kv.Context{}))
+	require.Equal(t, "localhost:8080", gk.conn.Target())
+
+	cfg := kv.Config{
+		Current: "staging",
+		Contexts: map[string]kv.Context{
+			"staging": {},
+			"prod":    {Server: "keeper.example.com:443", Login: "alice"},
+		},
+	}
+	mockStorage.EXPECT().GetConfig().Return(cfg, nil)
+	mockStorage.EXPECT().SetConfig(gomock.Any()).Return(nil)
+
+	// as run by gk use prod, with the command name already stripped
+	cmd := gk.ContextUseCMD()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"prod"})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, "keeper.example.com:443", gk.conn.Target())
+}
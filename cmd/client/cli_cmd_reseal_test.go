@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestResealCMD(t *testing.T) {
+	gk, mockClient, store := newCacheTestKeeper(t)
+	gk.rootCmd = &cobra.Command{}
+	gk.privateMeta = true
+
+	legacyData, err := crypto.Seal([]byte(`{"text":"old"}`), testKey, crypto.AES256GCM, crypto.RecordIDAAD(5, "note"))
+	require.NoError(t, err)
+	legacy := &pb.VaultRecord{Id: 5, Type: "note", Title: "old", Metadata: "{}", Revision: 2, EncryptedData: legacyData}
+
+	ancientData, err := crypto.Seal([]byte(`{"text":"older"}`), testKey, crypto.AES256GCM, crypto.RecordIDAAD(0, "note"))
+	require.NoError(t, err)
+	ancient := &pb.VaultRecord{Id: 8, Type: "note", Title: "older", Metadata: "{}", Revision: 1, EncryptedData: ancientData}
+
+	// a record seen with a UUID that the server now returns without one
+	require.NoError(t, store.PinUUID(9, testUUID(9)))
+	stripped := &pb.VaultRecord{Id: 9, Type: "note", Title: "moved", Metadata: "{}", Revision: 1, EncryptedData: ancientData}
+
+	plain := &pb.VaultRecord{Id: 6, Type: "note", Title: "bank", Metadata: "{}", Revision: 1}
+	require.NoError(t, sealRecord(plain, []byte(`{"text":"pin"}`), testRecordKey, crypto.AES256GCM))
+
+	private := &pb.VaultRecord{Id: 7, Type: "note", Title: "diary", Metadata: "{}", Revision: 4}
+	require.NoError(t, sealRecord(private, []byte("{}"), testRecordKey, crypto.AES256GCM))
+	private, err = sealMeta(private, testRecordKey, crypto.AES256GCM)
+	require.NoError(t, err)
+
+	gomock.InOrder(
+		mockClient.EXPECT().ListVaults(gomock.Any(), gomock.Any()).Return(&pb.ListVaultsResponse{
+			Vaults:        []*pb.VaultRecord{legacy, plain, ancient, stripped},
+			NextPageToken: "next",
+		}, nil),
+		mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, in *pb.VaultRecord, _ ...any) (*emptypb.Empty, error) {
+				require.Equal(t, uint64(5), in.Id)
+				require.Equal(t, uint64(2), in.Revision)
+				require.Len(t, in.Uuid, 36, "the old record is bound to a UUID")
+				require.Empty(t, in.Title)
+
+				data, err := openRecord(in, testRecordKey)
+				require.NoError(t, err)
+				require.Equal(t, `{"text":"old"}`, string(data))
+				m, err := openMeta(in, testRecordKey)
+				require.NoError(t, err)
+				require.Equal(t, "old", m.Title)
+				return &emptypb.Empty{}, nil
+			}),
+		mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, in *pb.VaultRecord, _ ...any) (*emptypb.Empty, error) {
+				require.Equal(t, uint64(6), in.Id)
+				require.Equal(t, plain.Uuid, in.Uuid)
+				require.Empty(t, in.Title)
+				require.NotEmpty(t, in.SealedMeta)
+				return &emptypb.Empty{}, nil
+			}),
+		mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, in *pb.VaultRecord, _ ...any) (*emptypb.Empty, error) {
+				require.Equal(t, uint64(8), in.Id)
+				require.Len(t, in.Uuid, 36, "sealed before the ID was known")
+
+				data, err := openRecord(in, testRecordKey)
+				require.NoError(t, err)
+				require.Equal(t, `{"text":"older"}`, string(data))
+				return &emptypb.Empty{}, nil
+			}),
+		mockClient.EXPECT().ListVaults(gomock.Any(), &pb.ListVaultsRequest{PageToken: "next"}).
+			Return(&pb.ListVaultsResponse{Vaults: []*pb.VaultRecord{private}}, nil),
+		mockClient.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(&pb.SyncResponse{Cursor: 3}, nil),
+	)
+
+	cmd := gk.ResealCMD()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	require.Contains(t, buf.String(), "Перешифровано записей: 3")
+	require.Contains(t, buf.String(), "Запись 9 пропущена")
+
+	pinned, err := store.PinnedUUID(8)
+	require.NoError(t, err)
+	require.Len(t, pinned, 36, "a resealed record is never read the old way again")
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// SyncCMD returns a Cobra command that sends the writes made offline and
+// brings the local copy of the vault up to date.
+func (g *GophKeeper) SyncCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync",
+		Short: "Синхронизировать локальную копию с сервером",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			online, err := g.refreshCache(out)
+			if err != nil {
+				return fmt.Errorf("ошибка синхронизации: %w", err)
+			}
+			if online {
+				fmt.Fprintln(out, "✅ Локальная копия обновлена.")
+			} else {
+				fmt.Fprintln(out, "📴 Сервер недоступен, изменения остаются в очереди.")
+			}
+
+			pending, err := g.storage.Outbox()
+			if err != nil {
+				return fmt.Errorf("ошибка чтения очереди отправки: %w", err)
+			}
+			printOutbox(out, pending)
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// TrashCMD returns a Cobra command that groups trash subcommands.
+// Without a subcommand it lists the records in the trash.
+func (g *GophKeeper) TrashCMD() *cobra.Command {
+	list := g.TrashListCMD()
+
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "Корзина: удалённые записи",
+		RunE:  list.RunE,
+	}
+	cmd.AddCommand(list, g.TrashRestoreCMD(), g.TrashPurgeCMD())
+
+	return cmd
+}
+
+// TrashListCMD returns a Cobra command that lists records in the trash.
+func (g *GophKeeper) TrashListCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Показать записи в корзине",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := g.VaultTrash()
+			if err != nil {
+				return fmt.Errorf("ошибка получения корзины: %w", err)
+			}
+
+			if len(resp.Vaults) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "Корзина пуста 🗑")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTYPE\tTITLE\tDELETED AT")
+			for _, v := range resp.Vaults {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", v.Id, v.Type, v.Title, formatTime(v.DeletedAt))
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+// TrashRestoreCMD returns a Cobra command that moves a record from the trash back to the vault.
+func (g *GophKeeper) TrashRestoreCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore [id]",
+		Short: "Восстановить запись из корзины",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseUint(args[len(args)-1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("неверный ID: %w", err)
+			}
+
+			if _, err = g.TrashRestore(id); err != nil {
+				return fmt.Errorf("ошибка восстановления: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Запись %d восстановлена из корзины.\n", id)
+			return nil
+		},
+	}
+}
+
+// TrashPurgeCMD returns a Cobra command that permanently deletes a record from the trash.
+func (g *GophKeeper) TrashPurgeCMD() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "purge [id]",
+		Short: "Удалить запись из корзины навсегда",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			id, err := strconv.ParseUint(args[len(args)-1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("неверный ID: %w", err)
+			}
+
+			if !yes && !confirm(cmd, fmt.Sprintf("⚠️ Удалить запись %d навсегда? Это действие нельзя отменить. (y/n): ", id)) {
+				fmt.Fprintln(out, "Отменено.")
+				return nil
+			}
+
+			if _, err = g.TrashPurge(id); err != nil {
+				return fmt.Errorf("ошибка удаления: %w", err)
+			}
+
+			fmt.Fprintf(out, "✅ Запись %d удалена навсегда.\n", id)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "не спрашивать подтверждение")
+
+	return cmd
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/mocks"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestTrashCMD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	gk := &GophKeeper{
+		rootCmd: &cobra.Command{},
+		client:  mockClient,
+		storage: mockStorage,
+		rootCtx: context.Background(),
+		cfg:     &config.Config{},
+	}
+
+	mockStorage.EXPECT().GetCurrentToken().Return("token123", nil).AnyTimes()
+
+	t.Run("list", func(t *testing.T) {
+		mockClient.EXPECT().
+			ListTrash(gomock.Any(), &emptypb.Empty{}).
+			Return(&pb.ListVaultsResponse{Vaults: []*pb.VaultRecord{
+				{Id: 7, Type: "note", Title: "old note", DeletedAt: "2025-07-01T12:00:00Z"},
+			}}, nil)
+
+		cmd := gk.TrashCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, nil))
+		require.Contains(t, buf.String(), "DELETED AT")
+		require.Contains(t, buf.String(), "old note")
+	})
+
+	t.Run("list_empty", func(t *testing.T) {
+		mockClient.EXPECT().ListTrash(gomock.Any(), gomock.Any()).Return(&pb.ListVaultsResponse{}, nil)
+
+		cmd := gk.TrashListCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, nil))
+		require.Contains(t, buf.String(), "Корзина пуста")
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		mockClient.EXPECT().
+			RestoreVault(gomock.Any(), &pb.RestoreVaultRequest{VaultId: 7}).
+			Return(&emptypb.Empty{}, nil)
+
+		cmd := gk.TrashRestoreCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, []string{"7"}))
+		require.Contains(t, buf.String(), "восстановлена")
+	})
+
+	t.Run("restore_error", func(t *testing.T) {
+		mockClient.EXPECT().RestoreVault(gomock.Any(), gomock.Any()).Return(nil, errors.New("not found"))
+
+		cmd := gk.TrashRestoreCMD()
+		require.Error(t, cmd.RunE(cmd, []string{"7"}))
+	})
+
+	t.Run("purge_confirmed", func(t *testing.T) {
+		mockClient.EXPECT().
+			PurgeVault(gomock.Any(), &pb.PurgeVaultRequest{VaultId: 7}).
+			Return(&emptypb.Empty{}, nil)
+
+		cmd := gk.TrashPurgeCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetIn(strings.NewReader("y\n"))
+
+		require.NoError(t, cmd.RunE(cmd, []string{"7"}))
+		require.Contains(t, buf.String(), "удалена навсегда")
+	})
+
+	t.Run("purge_cancelled", func(t *testing.T) {
+		cmd := gk.TrashPurgeCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetIn(strings.NewReader("\n"))
+
+		require.NoError(t, cmd.RunE(cmd, []string{"7"}))
+		require.Contains(t, buf.String(), "Отменено")
+	})
+
+	t.Run("shell_purge_yes", func(t *testing.T) {
+		mockClient.EXPECT().
+			PurgeVault(gomock.Any(), &pb.PurgeVaultRequest{VaultId: 9}).
+			Return(&emptypb.Empty{}, nil)
+
+		require.NoError(t, gk.processShellCommand([]string{"trash", "purge", "9", "-y"}))
+	})
+
+	t.Run("shell_bad_subcommand", func(t *testing.T) {
+		require.Error(t, gk.processShellCommand([]string{"trash", "empty"}))
+		require.Error(t, gk.processShellCommand([]string{"trash", "restore"}))
+	})
+}
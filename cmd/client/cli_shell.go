@@ -29,16 +29,32 @@ func (g *GophKeeper) shellLoop() error {
 
 	cfg, _ := g.storage.GetConfig()
 	currentCtx := cfg.Current
+	prompt := fmt.Sprintf("[%s] > ", currentCtx)
+
+	stopWatch := g.startWatch(os.Stdout, prompt)
+	defer func() { stopWatch() }()
 
 	for {
-		fmt.Printf("[%s] > ", currentCtx)
+		fmt.Print(prompt)
 		if !reader.Scan() {
 			break
 		}
 		line := strings.TrimSpace(reader.Text())
 		args := strings.Split(line, " ")
 
-		if err := g.processShellCommand(args); err != nil {
+		// смена аккаунта или сервера переподключает наблюдение за изменениями
+		restart := switchesAccount(args[0])
+		if restart {
+			stopWatch()
+		}
+
+		err := g.processShellCommand(args)
+
+		if restart {
+			stopWatch = g.startWatch(os.Stdout, prompt)
+		}
+
+		if err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
@@ -49,6 +65,17 @@ func (g *GophKeeper) shellLoop() error {
 	return reader.Err()
 }
 
+// switchesAccount reports whether the shell command may change the account or
+// the server the client works with.
+func switchesAccount(command string) bool {
+	switch command {
+	case "login", "logout", "register", "use", "context":
+		return true
+	default:
+		return false
+	}
+}
+
 // change case to map
 func (g *GophKeeper) processShellCommand(args []string) error {
 	switch args[0] {
@@ -58,17 +85,62 @@ func (g *GophKeeper) processShellCommand(args []string) error {
 
 	case "login":
 		return g.LoginCMD().RunE(g.rootCmd, args)
+	case "logout":
+		return g.LogoutCMD().RunE(g.rootCmd, args)
 	case "register":
 		return g.RegisterCMD().RunE(g.rootCmd, args)
+	case "mfa":
+		return g.MFAEnableCMD().RunE(g.rootCmd, args)
 	case "contexts":
 		return g.ContextListCMD().RunE(g.rootCmd, args)
+	case "context":
+		if len(args) < 3 || args[1] != "add" {
+			return errors.New("пример: context add <name> --server host:port")
+		}
+		cmd := g.ContextAddCMD()
+		if err := cmd.ParseFlags(args[2:]); err != nil {
+			return err
+		}
+		return cmd.RunE(cmd, cmd.Flags().Args())
 	case "use":
 		if len(args) < 2 {
 			return errors.New("пример: use <ctx name>")
 		}
 		return g.ContextUseCMD().RunE(g.rootCmd, args)
+	case "sessions":
+		if len(args) > 1 && args[1] == "revoke" {
+			if len(args) < 3 {
+				return errors.New("пример: sessions revoke <id>")
+			}
+			return g.SessionRevokeCMD().RunE(g.rootCmd, args[2:])
+		}
+		return g.SessionListCMD().RunE(g.rootCmd, nil)
 	case "list":
-		return g.VaultListCMD().RunE(g.rootCmd, nil)
+		cmd := g.VaultListCMD()
+		if err := cmd.ParseFlags(args[1:]); err != nil {
+			return err
+		}
+		return cmd.RunE(cmd, nil)
+
+	case "search":
+		cmd := g.SearchCMD()
+		if err := cmd.ParseFlags(args[1:]); err != nil {
+			return err
+		}
+		return cmd.RunE(cmd, cmd.Flags().Args())
+
+	case "find":
+		cmd := g.FindCMD()
+		if err := cmd.ParseFlags(args[1:]); err != nil {
+			return err
+		}
+		return cmd.RunE(cmd, cmd.Flags().Args())
+
+	case "sync":
+		return g.SyncCMD().RunE(g.rootCmd, nil)
+
+	case "reseal":
+		return g.ResealCMD().RunE(g.rootCmd, nil)
 
 	case "create":
 		return g.NewVaultCMD().RunE(g.rootCmd, nil)
@@ -76,11 +148,68 @@ func (g *GophKeeper) processShellCommand(args []string) error {
 	case "get":
 		return g.VaultShowCMD().RunE(g.rootCmd, args)
 
+	case "edit":
+		if len(args) < 2 {
+			return errors.New("пример: edit <id>")
+		}
+		return g.VaultEditCMD().RunE(g.rootCmd, args)
+
 	case "delete":
 		if len(args) < 2 {
 			return errors.New("пример: delete <id>")
 		}
-		return g.VaultDeleteCMD().RunE(g.rootCmd, args)
+		cmd := g.VaultDeleteCMD()
+		if err := cmd.ParseFlags(args[1:]); err != nil {
+			return err
+		}
+		return cmd.RunE(cmd, cmd.Flags().Args())
+
+	case "trash":
+		if len(args) < 2 {
+			return g.TrashListCMD().RunE(g.rootCmd, nil)
+		}
+		var cmd *cobra.Command
+		switch args[1] {
+		case "restore":
+			cmd = g.TrashRestoreCMD()
+		case "purge":
+			cmd = g.TrashPurgeCMD()
+		default:
+			return errors.New("пример: trash [restore|purge] <id>")
+		}
+		if err := cmd.ParseFlags(args[2:]); err != nil {
+			return err
+		}
+		if len(cmd.Flags().Args()) == 0 {
+			return fmt.Errorf("пример: trash %s <id>", args[1])
+		}
+		return cmd.RunE(cmd, cmd.Flags().Args())
+
+	case "conflicts":
+		if len(args) < 2 {
+			return g.ConflictsListCMD().RunE(g.rootCmd, nil)
+		}
+		if args[1] != "resolve" {
+			return errors.New("пример: conflicts resolve [id]")
+		}
+		cmd := g.ConflictsResolveCMD()
+		return cmd.RunE(cmd, args[2:])
+
+	case "history":
+		if len(args) < 2 {
+			return errors.New("пример: history <id>")
+		}
+		return g.VaultHistoryCMD().RunE(g.rootCmd, args)
+
+	case "restore":
+		if len(args) < 2 {
+			return errors.New("пример: restore <id> --version N")
+		}
+		cmd := g.VaultRestoreCMD()
+		if err := cmd.ParseFlags(args[1:]); err != nil {
+			return err
+		}
+		return cmd.RunE(cmd, cmd.Flags().Args())
 
 	case "help", "?", "version", "v":
 		g.printBanner()
@@ -97,12 +226,29 @@ func (g *GophKeeper) processShellCommand(args []string) error {
 func printHelp() {
 	fmt.Println(`🔧 Команды:
 login              войти в аккаунт или создать новый
+logout             завершить текущую сессию
 register           зарегистрировать новый аккаунт
+mfa                подключить двухфакторную аутентификацию
 contexts           список всех контекстов
 use <name>         сменить контекст
-list               показать все записи
+context add <name> добавить контекст другого сервера (--server host:port [--tls --ca <file>])
+sessions           список активных сессий
+sessions revoke <id> завершить сессию, например, на потерянном устройстве
+list [--type T] [--limit N] [--sort title|-updated|...] показать записи
+search <query> [--reindex] найти записи, например: github type:login url:github.com
+find [--login L] [--url U] найти на сервере записи с точным логином или сайтом
+sync               отправить офлайн-изменения и обновить локальную копию
+reseal             перешифровать старые записи: привязать к UUID и скрыть названия
 get <id>           показать запись по ID
-delete <id>        удалить запись по ID
+edit <id>          изменить запись по ID
+delete <id> [-y]   переместить запись в корзину
+trash              показать записи в корзине
+trash restore <id> восстановить запись из корзины
+trash purge <id> [-y] удалить запись из корзины навсегда
+conflicts          показать конфликты изменений с другими устройствами
+conflicts resolve [id] выбрать значения конфликтующих полей
+history <id>       показать предыдущие версии записи
+restore <id> --version N  восстановить версию записи
 create             создать новую запись
 exit / quit / q    выйти из программы
 help / version / ? список команд`)
@@ -40,6 +40,10 @@ func TestGophKeeper_ShellCMD_Success(t *testing.T) {
 		Return(kv.Config{Current: "testctx"}, nil).
 		AnyTimes()
 
+	// наблюдение за изменениями работает, пока открыта оболочка
+	mockStorage.EXPECT().GetCurrentToken().Return("token", nil).AnyTimes()
+	mockClient.EXPECT().Watch(gomock.Any(), gomock.Any()).DoAndReturn(watchReturns(nil)).AnyTimes()
+
 	// Подмена stdout для проверки вывода
 	var buf bytes.Buffer
 	cmd := gk.ShellCMD()
@@ -122,16 +126,10 @@ func TestProcessShellCommand(t *testing.T) {
 	})
 
 	t.Run("processShellCommand_list", func(t *testing.T) {
-		// Ожидаемый вызов получения ключа
-		mockStorage.EXPECT().
-			GetCurrentKey().
-			Return("6368616e676520746869732070617373", nil).AnyTimes()
-
+		mockStorage.EXPECT().Outbox().Return(nil, nil).Times(2)
 		mockClient.EXPECT().
-			ListVaults(gomock.Any(), gomock.Any()).
-			Return(&pb.ListVaultsResponse{
-				Vaults: []*pb.VaultRecord{},
-			}, nil)
+			ListVaultSummaries(gomock.Any(), gomock.Any()).
+			Return(&pb.ListVaultSummariesResponse{}, nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
@@ -152,12 +150,16 @@ func TestProcessShellCommand(t *testing.T) {
 
 		mockClient.EXPECT().
 			CreateVault(gomock.Any(), gomock.Any()).
-			Return(&emptypb.Empty{}, nil)
+			Return(&pb.VaultRecord{}, nil)
+
+		mockStorage.EXPECT().
+			PinUUID(gomock.Any(), gomock.Any()).
+			Return(nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
-			GetCurrentKey().
-			Return("6368616e676520746869732070617373", nil).AnyTimes()
+			GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: "6368616e676520746869732070617373"}, nil).AnyTimes()
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
@@ -176,7 +178,7 @@ func TestProcessShellCommand(t *testing.T) {
 		data, err := json.Marshal(note)
 		require.NoError(t, err)
 
-		crypted, err := crypto.EncryptWithSeed(data, key)
+		crypted, err := crypto.Seal(data, key, crypto.AES256GCM, crypto.RecordIDAAD(1, "note"))
 		require.NoError(t, err)
 
 		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(&pb.VaultRecord{
@@ -186,12 +188,15 @@ func TestProcessShellCommand(t *testing.T) {
 			EncryptedData: crypted,
 		}, nil)
 
-		mockStorage.EXPECT().GetCurrentKey().
-			Return(key, nil).AnyTimes()
+		mockStorage.EXPECT().GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: key}, nil).AnyTimes()
 
 		mockStorage.EXPECT().GetCurrentToken().
 			Return(key, nil).AnyTimes()
 
+		mockStorage.EXPECT().PinUUID(uint64(1), "").Return(nil)
+		mockStorage.EXPECT().PinnedUUID(uint64(1)).Return("", nil)
+
 		err = gk.processShellCommand([]string{"get", "1"})
 		require.NoError(t, err)
 	})
@@ -210,12 +215,20 @@ func TestProcessShellCommand(t *testing.T) {
 		mockStorage.EXPECT().GetConfig().Return(kv.Config{Current: "testctx"}, nil).AnyTimes()
 
 		// передаём фейковые аргументы (id как строка)
-		args := []string{"delete", strconv.FormatUint(vaultID, 10)}
+		args := []string{"delete", strconv.FormatUint(vaultID, 10), "-y"}
 
 		err := gk.processShellCommand(args)
 		require.Error(t, err)
 	})
 
+	t.Run("processShellCommand_conflicts", func(t *testing.T) {
+		mockStorage.EXPECT().Conflicts().Return(nil, nil).Times(2)
+
+		require.NoError(t, gk.processShellCommand([]string{"conflicts"}))
+		require.NoError(t, gk.processShellCommand([]string{"conflicts", "resolve"}))
+		require.Error(t, gk.processShellCommand([]string{"conflicts", "drop"}))
+	})
+
 	t.Run("processShellCommand_help", func(t *testing.T) {
 		mockStorage.EXPECT().GetCurrentKey().Return("", nil).AnyTimes()
 
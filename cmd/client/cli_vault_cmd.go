@@ -1,16 +1,19 @@
 package main
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/sqweek/dialog"
 	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
@@ -48,24 +51,30 @@ func (g *GophKeeper) NewVaultCMD() *cobra.Command {
 					return err
 				}
 			case "binary":
-				v, err = vaultBinary(v)
+				v, err = g.vaultBinary(out, v)
 				if err != nil {
 					return err
 				}
 			}
 
 			//crypto
-			key, err := g.storage.GetCurrentKey()
+			key, err := currentRecordKey(g.storage)
 			if err != nil {
 				return err
 			}
 
-			v.EncryptedData, err = crypto.EncryptWithSeed(v.EncryptedData, key)
-			if err != nil {
+			if err = sealRecord(v, v.EncryptedData, key, g.recordCipher()); err != nil {
 				return err
 			}
 
 			_, err = g.VaultCreate(v)
+			if offline(err) {
+				if err = g.queueWrite(kv.OpCreate, v, nil); err != nil {
+					return err
+				}
+				fmt.Fprintln(out, offlineSaved)
+				return nil
+			}
 			if err != nil {
 				return err
 			}
@@ -165,18 +174,24 @@ func vaultCard(v *pb.VaultRecord) (*pb.VaultRecord, error) {
 	return v, nil
 }
 
-func vaultBinary(v *pb.VaultRecord) (*pb.VaultRecord, error) {
+// vaultBinary uploads the chosen file as a blob and puts the reference to it
+// into the record.
+func (g *GophKeeper) vaultBinary(out io.Writer, v *pb.VaultRecord) (*pb.VaultRecord, error) {
 	path, err := dialog.File().Title("Выберите файл").Load()
 	if err != nil {
 		return v, err
 	}
 
-	data, err := os.ReadFile(path)
+	b, err := g.uploadFile(out, path)
 	if err != nil {
-		return v, fmt.Errorf("не удалось прочитать файл: %w", err)
+		return v, fmt.Errorf("не удалось загрузить файл: %w", err)
 	}
 
-	v.EncryptedData = data
+	v.EncryptedData, err = json.Marshal(b)
+	if err != nil {
+		return v, err
+	}
+	v.BlobRef = b.Blob
 
 	// например, сохранить имя файла в metadata
 	meta := map[string]string{"filename": filepath.Base(path)}
@@ -187,25 +202,37 @@ func vaultBinary(v *pb.VaultRecord) (*pb.VaultRecord, error) {
 }
 
 func (g *GophKeeper) VaultListCMD() *cobra.Command {
-	return &cobra.Command{
+	var opts listOptions
+
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "Показать все записи в хранилище",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			out := cmd.OutOrStdout()
-			resp, err := g.VaultList()
+			vaults, err := g.listSummaries(out, opts)
 			if err != nil {
-				return fmt.Errorf("ошибка получения списка записей: %w", err)
+				return err
+			}
+			pending, err := g.storage.Outbox()
+			if err != nil {
+				return fmt.Errorf("ошибка чтения очереди отправки: %w", err)
 			}
 
-			if len(resp.Vaults) == 0 {
+			if len(vaults) == 0 {
 				fmt.Fprintln(out, "🔒 Хранилище пусто.")
+				printOutbox(out, pending)
 				return nil
 			}
 
 			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "ID\tTYPE\tTITLE\tUPDATED AT\tTAGS")
+			fmt.Fprintln(w, "ID\tTYPE\tTITLE\tSIZE\tUPDATED AT\tTAGS")
 
-			for _, v := range resp.Vaults {
+			queued := make(map[uint64]bool, len(pending))
+			for _, e := range pending {
+				queued[e.Record.Id] = true
+			}
+
+			for _, v := range vaults {
 				// Парсим дату
 				var formatted string
 				if t, err := time.Parse(time.RFC3339, v.UpdatedAt); err == nil {
@@ -227,14 +254,210 @@ func (g *GophKeeper) VaultListCMD() *cobra.Command {
 					tags = "-"
 				}
 
-				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", v.Id, v.Type, v.Title, formatted, tags)
+				title := v.Title
+				if queued[v.Id] {
+					title = "⏳ " + title
+				}
+
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", v.Id, v.Type, title, formatSize(v.Size), formatted, tags)
 			}
 
 			w.Flush()
+			printOutbox(out, pending)
 
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVar(&opts.Type, "type", "", "только записи этого типа: login, note, card, binary")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 0, "показать не больше N записей")
+	cmd.Flags().StringVar(&opts.Sort, "sort", "id", "порядок: id, title, updated или created; -title — по убыванию")
+	return cmd
+}
+
+// listOptions filter and order the records shown by list.
+type listOptions struct {
+	Type  string
+	Limit int
+	Sort  string
+}
+
+// listSorts compare records by the keys list can sort by; equal keys keep the ID order.
+var listSorts = map[string]func(a, b *pb.VaultSummary) int{
+	"id": func(a, b *pb.VaultSummary) int { return 0 },
+	"title": func(a, b *pb.VaultSummary) int {
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	},
+	"updated": func(a, b *pb.VaultSummary) int { return compareTimes(a.UpdatedAt, b.UpdatedAt) },
+	"created": func(a, b *pb.VaultSummary) int { return compareTimes(a.CreatedAt, b.CreatedAt) },
+}
+
+// listServerSorts are the server orders of the keys list can sort by.
+var listServerSorts = map[string]pb.VaultSort{
+	"id":      pb.VaultSort_VAULT_SORT_ID,
+	"title":   pb.VaultSort_VAULT_SORT_TITLE,
+	"updated": pb.VaultSort_VAULT_SORT_UPDATED_AT,
+	"created": pb.VaultSort_VAULT_SORT_CREATED_AT,
+}
+
+// compareTimes compares two RFC 3339 times; unparsable ones go first.
+func compareTimes(a, b string) int {
+	ta, _ := time.Parse(time.RFC3339, a)
+	tb, _ := time.Parse(time.RFC3339, b)
+	return ta.Compare(tb)
+}
+
+// sortKey returns the key and the direction of the order of the options.
+func (o listOptions) sortKey() (string, bool, error) {
+	key, desc := strings.CutPrefix(o.Sort, "-")
+	if key == "" {
+		key = "id"
+	}
+	if _, ok := listSorts[key]; !ok {
+		return "", false, fmt.Errorf("неизвестный порядок сортировки: %s", o.Sort)
+	}
+	if o.Limit < 0 {
+		return "", false, fmt.Errorf("--limit не может быть отрицательным")
+	}
+	return key, desc, nil
+}
+
+// request returns the ListVaultSummaries request of the first page of the options.
+func (o listOptions) request() (*pb.ListVaultsRequest, error) {
+	key, desc, err := o.sortKey()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListVaultsRequest{
+		Type:       o.Type,
+		Sort:       listServerSorts[key],
+		Descending: desc,
+		PageSize:   int32(o.Limit),
+	}, nil
+}
+
+// apply returns the records of the type, in the order and up to the limit of the options.
+func (o listOptions) apply(vaults []*pb.VaultSummary) ([]*pb.VaultSummary, error) {
+	key, desc, err := o.sortKey()
+	if err != nil {
+		return nil, err
+	}
+	byKey := listSorts[key]
+
+	list := make([]*pb.VaultSummary, 0, len(vaults))
+	for _, v := range vaults {
+		if o.Type == "" || v.Type == o.Type {
+			list = append(list, v)
+		}
+	}
+
+	slices.SortStableFunc(list, func(a, b *pb.VaultSummary) int {
+		c := byKey(a, b)
+		if c == 0 {
+			c = cmp.Compare(a.Id, b.Id)
+		}
+		if desc {
+			c = -c
+		}
+		return c
+	})
+
+	if o.Limit > 0 && len(list) > o.Limit {
+		list = list[:o.Limit]
+	}
+	return list, nil
+}
+
+// listSummaries sends the queued writes and pages the record summaries from
+// the server, filtered and ordered there, so list never downloads the
+// contents. Offline it reads them from the local mirror instead. The server
+// cannot order or show sealed titles, so with private metadata, or once it
+// returns a record with sealed ones, the mirror is brought up to date and
+// listed instead.
+func (g *GophKeeper) listSummaries(out io.Writer, o listOptions) ([]*pb.VaultSummary, error) {
+	req, err := o.request()
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.flushOutbox(out)
+	if err != nil && !offline(err) {
+		return nil, fmt.Errorf("ошибка синхронизации: %w", err)
+	}
+	if err == nil && g.privateMeta {
+		return g.listMirror(out, o)
+	}
+
+	var list []*pb.VaultSummary
+	for err == nil {
+		var resp *pb.ListVaultSummariesResponse
+		if resp, err = g.VaultSummaries(req); err != nil {
+			break
+		}
+		if slices.ContainsFunc(resp.Vaults, (*pb.VaultSummary).GetSealedMeta) {
+			return g.listMirror(out, o)
+		}
+		list = append(list, resp.Vaults...)
+		if resp.NextPageToken == "" || (o.Limit > 0 && len(list) >= o.Limit) {
+			if o.Limit > 0 && len(list) > o.Limit {
+				list = list[:o.Limit]
+			}
+			return list, nil
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	if !offline(err) {
+		return nil, fmt.Errorf("не удалось получить список: %w", err)
+	}
+
+	fmt.Fprintln(out, offlineNotice)
+	return g.cachedSummaries(o)
+}
+
+// listMirror pulls the server changes into the local mirror and lists the
+// records from it; offline the mirror is listed as it is.
+func (g *GophKeeper) listMirror(out io.Writer, o listOptions) ([]*pb.VaultSummary, error) {
+	err := g.pullChanges()
+	switch {
+	case offline(err):
+		fmt.Fprintln(out, offlineNotice)
+	case err != nil:
+		return nil, fmt.Errorf("ошибка синхронизации: %w", err)
+	}
+	return g.cachedSummaries(o)
+}
+
+// cachedSummaries returns the summaries of the mirrored records, with sealed
+// titles and metadata decrypted, filtered and ordered by the options.
+func (g *GophKeeper) cachedSummaries(o listOptions) ([]*pb.VaultSummary, error) {
+	vaults, err := g.storage.CachedVaults()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения локальной копии: %w", err)
+	}
+	if err = g.reveal(vaults...); err != nil {
+		return nil, err
+	}
+
+	list := make([]*pb.VaultSummary, 0, len(vaults))
+	for _, v := range vaults {
+		list = append(list, summarize(v))
+	}
+	return o.apply(list)
+}
+
+// summarize returns the summary of a cached record. The mirror does not know
+// the size of uploaded files, so it is the size of the ciphertext.
+func summarize(v *pb.VaultRecord) *pb.VaultSummary {
+	return &pb.VaultSummary{
+		Id:        v.Id,
+		Type:      v.Type,
+		Title:     v.Title,
+		Metadata:  v.Metadata,
+		Size:      int64(len(v.EncryptedData)),
+		Revision:  v.Revision,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
 }
 
 func (g *GophKeeper) VaultShowCMD() *cobra.Command {
@@ -249,16 +472,22 @@ func (g *GophKeeper) VaultShowCMD() *cobra.Command {
 				return fmt.Errorf("❌ Неверный ID: %w", err)
 			}
 
-			v, err := g.VaultGet(id)
+			v, err := g.vaultGetCached(out, id)
 			if err != nil {
 				return fmt.Errorf("не удалось получить запись: %w", err)
 			}
 
-			key, err := g.storage.GetCurrentKey()
+			key, err := currentRecordKey(g.storage)
 			if err != nil {
 				return err
 			}
-			v.EncryptedData, err = crypto.DecryptWithSeed(v.EncryptedData, key)
+			v.EncryptedData, err = openRecord(v, key)
+			if errors.Is(err, crypto.ErrNotAuthentic) {
+				return fmt.Errorf("❌ Запись %d не открыта: её содержимое повреждено или перенесено из другой записи", id)
+			}
+			if errors.Is(err, errLegacyRecord) {
+				return fmt.Errorf("❌ Запись %d не открыта: %w", id, err)
+			}
 			if err != nil {
 				return err
 			}
@@ -320,12 +549,17 @@ func (g *GophKeeper) VaultShowCMD() *cobra.Command {
 				}
 
 			case "binary":
+				// записи до появления блобов хранят сам файл
+				var b kv.Binary
+				if json.Unmarshal(v.EncryptedData, &b) != nil || b.Blob == "" {
+					b = kv.Binary{Size: int64(len(v.EncryptedData))}
+				}
 
 				filename := "file.bin"
 				if meta != nil && meta["filename"] != "" {
 					filename = meta["filename"]
 				}
-				fmt.Fprintf(out, " 📎 File      : %s (%d байт)\n", filename, len(v.EncryptedData))
+				fmt.Fprintf(out, " 📎 File      : %s (%s)\n", filename, formatSize(b.Size))
 
 				fmt.Fprintln(out, "💾 Download? (y/n): ")
 				var answer string
@@ -346,7 +580,12 @@ func (g *GophKeeper) VaultShowCMD() *cobra.Command {
 					savePath += filepath.Ext(filename)
 				}
 
-				if err = os.WriteFile(savePath, v.EncryptedData, 0644); err != nil {
+				if b.Blob != "" {
+					err = g.downloadFile(out, b, savePath)
+				} else {
+					err = os.WriteFile(savePath, v.EncryptedData, 0644)
+				}
+				if err != nil {
 					fmt.Fprintln(out, "❌ Ошибка сохранения:", err)
 				} else {
 					fmt.Fprintln(out, "✅ Файл сохранён в", savePath)
@@ -361,23 +600,200 @@ func (g *GophKeeper) VaultShowCMD() *cobra.Command {
 	}
 }
 
-func (g *GophKeeper) VaultDeleteCMD() *cobra.Command {
+func (g *GophKeeper) VaultEditCMD() *cobra.Command {
 	return &cobra.Command{
+		Use:   "edit [id]",
+		Short: "Изменить запись по ID",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			id, err := strconv.ParseUint(args[len(args)-1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("неверный ID: %w", err)
+			}
+
+			v, err := g.vaultGetCached(out, id)
+			if err != nil {
+				return fmt.Errorf("не удалось получить запись: %w", err)
+			}
+
+			key, err := currentRecordKey(g.storage)
+			if err != nil {
+				return err
+			}
+
+			edited, err := g.editVaultRecord(out, v)
+			if err != nil {
+				return err
+			}
+
+			if err = sealRecord(edited, edited.EncryptedData, key, g.recordCipher()); err != nil {
+				return err
+			}
+
+			_, err = g.VaultUpdate(edited)
+			if offline(err) {
+				if err = g.queueWrite(kv.OpUpdate, edited, v); err != nil {
+					return err
+				}
+				fmt.Fprintln(out, offlineSaved)
+				return nil
+			}
+
+			conflict, ok := revisionConflict(err)
+			if !ok {
+				if err != nil {
+					return fmt.Errorf("ошибка обновления: %w", err)
+				}
+				fmt.Fprintln(out, "✅ Запись обновлена.")
+				return nil
+			}
+
+			fmt.Fprintf(out, "⚠️ Запись изменили на другом устройстве (ревизия %d, вы редактировали %d).\n",
+				conflict.CurrentRevision, edited.Revision)
+
+			c, err := g.reconcile(out, v, edited)
+			if err != nil {
+				return fmt.Errorf("ошибка объединения: %w", err)
+			}
+			if c != nil {
+				_, err = g.resolveConflict(cmd, c)
+			}
+			return err
+		},
+	}
+}
+
+// editVaultRecord asks for the new title and contents of v and returns the
+// edited copy with the plaintext contents in EncryptedData.
+func (g *GophKeeper) editVaultRecord(out io.Writer, v *pb.VaultRecord) (*pb.VaultRecord, error) {
+	edited := &pb.VaultRecord{
+		Id:       v.Id,
+		Uuid:     v.Uuid,
+		Type:     v.Type,
+		Title:    v.Title,
+		Metadata: v.Metadata,
+		Revision: v.Revision,
+	}
+
+	fmt.Fprintf(out, "Title [%s]: ", v.Title)
+	var title string
+	if _, err := fmt.Scanln(&title); err == nil && title != "" {
+		edited.Title = title
+	}
+
+	switch v.Type {
+	case "login":
+		return vaultLoginPass(edited)
+	case "note":
+		return vaultNote(edited)
+	case "card":
+		return vaultCard(edited)
+	case "binary":
+		return g.vaultBinary(out, edited)
+	default:
+		return nil, fmt.Errorf("неизвестный тип записи: %s", v.Type)
+	}
+}
+
+func (g *GophKeeper) VaultDeleteCMD() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
 		Use:   "delete [id]",
-		Short: "Удалить запись по ID",
-		Args:  cobra.ExactArgs(1),
+		Short: "Переместить запись в корзину",
+		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			out := cmd.OutOrStdout()
-			id, err := strconv.ParseUint(args[1], 10, 64)
+			id, err := strconv.ParseUint(args[len(args)-1], 10, 64)
 			if err != nil {
 				return fmt.Errorf("неверный ID: %w", err)
 			}
+			if !yes && !confirm(cmd, fmt.Sprintf("🗑 Переместить запись %d в корзину? (y/n): ", id)) {
+				_, _ = fmt.Fprintln(out, "Отменено.")
+				return nil
+			}
 			_, err = g.VaultDelete(id)
+			if offline(err) {
+				if err = g.queueWrite(kv.OpDelete, &pb.VaultRecord{Id: id}, nil); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintln(out, offlineSaved)
+				return nil
+			}
 			if err != nil {
 				return fmt.Errorf("ошибка удаления: %w", err)
 			}
-			_, _ = fmt.Fprintln(out, "✅ Запись удалена.")
+			_, _ = fmt.Fprintln(out, "✅ Запись перемещена в корзину. Восстановить: trash restore", id)
 			return nil
 		},
 	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "не спрашивать подтверждение")
+
+	return cmd
+}
+
+func (g *GophKeeper) VaultHistoryCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "history [id]",
+		Short: "Показать предыдущие версии записи",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			id, err := strconv.ParseUint(args[len(args)-1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("неверный ID: %w", err)
+			}
+
+			resp, err := g.VaultHistory(id)
+			if err != nil {
+				return fmt.Errorf("ошибка получения истории: %w", err)
+			}
+
+			if len(resp.Versions) == 0 {
+				fmt.Fprintln(out, "🕓 У записи нет предыдущих версий.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tTYPE\tTITLE\tSIZE\tSAVED AT\tREPLACED AT")
+			for _, v := range resp.Versions {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\n",
+					v.Version, v.Type, v.Title, v.Size, formatTime(v.SavedAt), formatTime(v.ArchivedAt))
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+func (g *GophKeeper) VaultRestoreCMD() *cobra.Command {
+	var version uint64
+
+	cmd := &cobra.Command{
+		Use:   "restore [id] --version N",
+		Short: "Восстановить предыдущую версию записи",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			id, err := strconv.ParseUint(args[len(args)-1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("неверный ID: %w", err)
+			}
+			if version == 0 {
+				return fmt.Errorf("укажите версию: --version N (см. history %d)", id)
+			}
+
+			v, err := g.VaultRestore(id, version)
+			if err != nil {
+				return fmt.Errorf("ошибка восстановления: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(out, "✅ Запись %d восстановлена из версии %d: %s\n", v.Id, version, v.Title)
+			return nil
+		},
+	}
+	cmd.Flags().Uint64Var(&version, "version", 0, "номер версии из history")
+
+	return cmd
 }
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -18,6 +19,9 @@ import (
 	"github.com/wickedv43/go-goph-keeper/internal/config"
 	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -55,12 +59,16 @@ func TestNewVaultsCMD(t *testing.T) {
 
 		mockClient.EXPECT().
 			CreateVault(gomock.Any(), gomock.Any()).
-			Return(&emptypb.Empty{}, nil)
+			Return(&pb.VaultRecord{}, nil)
+
+		mockStorage.EXPECT().
+			PinUUID(gomock.Any(), gomock.Any()).
+			Return(nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
-			GetCurrentKey().
-			Return("6368616e676520746869732070617373", nil)
+			GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: "6368616e676520746869732070617373"}, nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
@@ -83,12 +91,12 @@ func TestNewVaultsCMD(t *testing.T) {
 
 		mockClient.EXPECT().
 			CreateVault(gomock.Any(), gomock.Any()).
-			Return(&emptypb.Empty{}, errors.New("bad request"))
+			Return(&pb.VaultRecord{}, errors.New("bad request"))
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
-			GetCurrentKey().
-			Return("6368616e676520746869732070617373", nil)
+			GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: "6368616e676520746869732070617373"}, nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
@@ -110,12 +118,16 @@ func TestNewVaultsCMD(t *testing.T) {
 
 		mockClient.EXPECT().
 			CreateVault(gomock.Any(), gomock.Any()).
-			Return(&emptypb.Empty{}, nil)
+			Return(&pb.VaultRecord{}, nil)
+
+		mockStorage.EXPECT().
+			PinUUID(gomock.Any(), gomock.Any()).
+			Return(nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
-			GetCurrentKey().
-			Return("6368616e676520746869732070617373", nil)
+			GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: "6368616e676520746869732070617373"}, nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
@@ -137,12 +149,12 @@ func TestNewVaultsCMD(t *testing.T) {
 
 		mockClient.EXPECT().
 			CreateVault(gomock.Any(), gomock.Any()).
-			Return(&emptypb.Empty{}, errors.New("bad request"))
+			Return(&pb.VaultRecord{}, errors.New("bad request"))
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
-			GetCurrentKey().
-			Return("6368616e676520746869732070617373", nil)
+			GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: "6368616e676520746869732070617373"}, nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
@@ -164,12 +176,16 @@ func TestNewVaultsCMD(t *testing.T) {
 
 		mockClient.EXPECT().
 			CreateVault(gomock.Any(), gomock.Any()).
-			Return(&emptypb.Empty{}, nil)
+			Return(&pb.VaultRecord{}, nil)
+
+		mockStorage.EXPECT().
+			PinUUID(gomock.Any(), gomock.Any()).
+			Return(nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
-			GetCurrentKey().
-			Return("6368616e676520746869732070617373", nil)
+			GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: "6368616e676520746869732070617373"}, nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
@@ -193,12 +209,16 @@ func TestNewVaultsCMD(t *testing.T) {
 
 		mockClient.EXPECT().
 			CreateVault(gomock.Any(), gomock.Any()).
-			Return(&emptypb.Empty{}, nil)
+			Return(&pb.VaultRecord{}, nil)
+
+		mockStorage.EXPECT().
+			PinUUID(gomock.Any(), gomock.Any()).
+			Return(nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
-			GetCurrentKey().
-			Return("6368616e676520746869732070617373", nil)
+			GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: "6368616e676520746869732070617373"}, nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
@@ -222,12 +242,12 @@ func TestNewVaultsCMD(t *testing.T) {
 
 		mockClient.EXPECT().
 			CreateVault(gomock.Any(), gomock.Any()).
-			Return(&emptypb.Empty{}, errors.New("bad request"))
+			Return(&pb.VaultRecord{}, errors.New("bad request"))
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
-			GetCurrentKey().
-			Return("6368616e676520746869732070617373", nil)
+			GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: "6368616e676520746869732070617373"}, nil)
 
 		// Ожидаемый вызов получения ключа
 		mockStorage.EXPECT().
@@ -251,13 +271,27 @@ func TestNewVaultsCMD(t *testing.T) {
 			GetCurrentKey().
 			Return("6368616e676520746869732070617373", nil)
 
+		mockStorage.EXPECT().
+			GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: "6368616e676520746869732070617373"}, nil)
+
 		mockStorage.EXPECT().
 			GetCurrentToken().
-			Return("6368616e676520746869732070617373", nil)
+			Return("6368616e676520746869732070617373", nil).
+			AnyTimes()
+
+		// файл загружается отдельно от записи
+		mockStorage.EXPECT().Upload(gomock.Any()).Return(nil, kv.ErrNoUpload)
+		mockStorage.EXPECT().PutUpload(gomock.Any()).Return(nil)
+		mockStorage.EXPECT().DropUpload(gomock.Any()).Return(nil)
+		(&blobServer{}).expect(mockClient)
 
 		mockClient.EXPECT().
 			CreateVault(gomock.Any(), gomock.Any()).
-			Return(&emptypb.Empty{}, errors.New("bad request")) // проверим поведение при ошибке
+			DoAndReturn(func(_ context.Context, in *pb.CreateVaultRequest, _ ...grpc.CallOption) (*pb.VaultRecord, error) {
+				require.NotEmpty(t, in.Record.BlobRef, "запись ссылается на загруженный файл")
+				return &pb.VaultRecord{}, errors.New("bad request") // проверим поведение при ошибке
+			})
 
 		cmd := gk.NewVaultCMD()
 		cmd.SetOut(io.Discard)
@@ -345,48 +379,32 @@ func TestVaultListCMD(t *testing.T) {
 		cfg:     &config.Config{},
 	}
 
-	t.Run("new_vault_list_success", func(t *testing.T) {
-		// Ожидаемый вызов получения ключа
-		mockStorage.EXPECT().
-			GetCurrentKey().
-			Return("6368616e676520746869732070617373", nil).AnyTimes()
+	mockStorage.EXPECT().GetCurrentToken().Return("token123", nil).AnyTimes()
 
-		mockClient.EXPECT().
-			ListVaults(gomock.Any(), gomock.Any()).
-			Return(&pb.ListVaultsResponse{
-				Vaults: []*pb.VaultRecord{
-					{Id: 1, Title: "Test1", Type: "note"},
-					{Id: 2, Title: "Test2", Type: "login"},
+	t.Run("new_vault_list_success", func(t *testing.T) {
+		mockStorage.EXPECT().Outbox().Return(nil, nil).Times(2)
+		mockClient.EXPECT().ListVaultSummaries(gomock.Any(), &pb.ListVaultsRequest{}).
+			Return(&pb.ListVaultSummariesResponse{
+				Vaults: []*pb.VaultSummary{
+					{Id: 1, Title: "Test1", Type: "note", Size: 42},
+					{Id: 2, Title: "Test2", Type: "binary", Size: 3 << 20},
 				},
 			}, nil)
 
-		// Ожидаемый вызов получения ключа
-		mockStorage.EXPECT().
-			GetCurrentToken().
-			Return("6368616e676520746869732070617373", nil)
-
+		var buf bytes.Buffer
 		cmd := gk.VaultListCMD()
+		cmd.SetOut(&buf)
 
 		err := cmd.RunE(cmd, nil)
 		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Test2")
+		require.Contains(t, buf.String(), formatSize(3<<20))
+		require.NotContains(t, buf.String(), "недоступен")
 	})
 
 	t.Run("new_vault_list_error", func(t *testing.T) {
-		// Ожидаемый вызов получения ключа
-		mockStorage.EXPECT().
-			GetCurrentKey().
-			Return("6368616e676520746869732070617373", nil).AnyTimes()
-
-		mockClient.EXPECT().
-			ListVaults(gomock.Any(), gomock.Any()).
-			Return(&pb.ListVaultsResponse{
-				Vaults: []*pb.VaultRecord{},
-			}, nil)
-
-		// Ожидаемый вызов получения ключа
-		mockStorage.EXPECT().
-			GetCurrentToken().
-			Return("6368616e676520746869732070617373", nil)
+		mockStorage.EXPECT().Outbox().Return(nil, nil).Times(2)
+		mockClient.EXPECT().ListVaultSummaries(gomock.Any(), gomock.Any()).Return(&pb.ListVaultSummariesResponse{}, nil)
 
 		var buf bytes.Buffer
 		cmd := gk.VaultListCMD()
@@ -399,6 +417,145 @@ func TestVaultListCMD(t *testing.T) {
 		require.Contains(t, list, "пусто")
 
 	})
+
+	t.Run("pages", func(t *testing.T) {
+		mockStorage.EXPECT().Outbox().Return(nil, nil).Times(2)
+		gomock.InOrder(
+			mockClient.EXPECT().ListVaultSummaries(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, in *pb.ListVaultsRequest, _ ...grpc.CallOption) (*pb.ListVaultSummariesResponse, error) {
+					require.Empty(t, in.PageToken)
+					return &pb.ListVaultSummariesResponse{
+						Vaults:        []*pb.VaultSummary{{Id: 1, Title: "first"}},
+						NextPageToken: "next",
+					}, nil
+				}),
+			mockClient.EXPECT().ListVaultSummaries(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, in *pb.ListVaultsRequest, _ ...grpc.CallOption) (*pb.ListVaultSummariesResponse, error) {
+					require.Equal(t, "next", in.PageToken)
+					return &pb.ListVaultSummariesResponse{Vaults: []*pb.VaultSummary{{Id: 2, Title: "second"}}}, nil
+				}),
+		)
+
+		var buf bytes.Buffer
+		cmd := gk.VaultListCMD()
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, nil))
+		require.Contains(t, buf.String(), "first")
+		require.Contains(t, buf.String(), "second")
+	})
+
+	t.Run("offline_list_from_cache", func(t *testing.T) {
+		mockStorage.EXPECT().Outbox().Return(nil, nil)
+		mockClient.EXPECT().ListVaultSummaries(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "no route"))
+		mockStorage.EXPECT().CachedVaults().Return([]*pb.VaultRecord{{Id: 1, Title: "cached", Type: "note"}}, nil)
+		mockStorage.EXPECT().Outbox().Return([]kv.OutboxEntry{
+			{Seq: 1, Op: kv.OpUpdate, Record: &pb.VaultRecord{Id: 1, Title: "cached"}},
+			{Seq: 2, Op: kv.OpCreate, Record: &pb.VaultRecord{Title: "draft"}},
+		}, nil)
+
+		var buf bytes.Buffer
+		cmd := gk.VaultListCMD()
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, nil))
+		require.Contains(t, buf.String(), "Сервер недоступен")
+		require.Contains(t, buf.String(), "⏳ cached")
+		require.Contains(t, buf.String(), "Ожидают отправки на сервер: 2")
+		require.Contains(t, buf.String(), "draft")
+	})
+
+	t.Run("list_error", func(t *testing.T) {
+		mockStorage.EXPECT().Outbox().Return(nil, nil)
+		mockClient.EXPECT().ListVaultSummaries(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Internal, "db down"))
+
+		cmd := gk.VaultListCMD()
+		require.Error(t, cmd.RunE(cmd, nil))
+	})
+
+	t.Run("type_sort_limit", func(t *testing.T) {
+		mockStorage.EXPECT().Outbox().Return(nil, nil).Times(2)
+		mockClient.EXPECT().ListVaultSummaries(gomock.Any(), &pb.ListVaultsRequest{
+			Type:       "login",
+			Sort:       pb.VaultSort_VAULT_SORT_UPDATED_AT,
+			Descending: true,
+			PageSize:   2,
+		}).Return(&pb.ListVaultSummariesResponse{
+			Vaults: []*pb.VaultSummary{
+				{Id: 3, Title: "bank", Type: "login"},
+				{Id: 1, Title: "gmail", Type: "login"},
+			},
+			NextPageToken: "more",
+		}, nil)
+
+		var buf bytes.Buffer
+		cmd := gk.VaultListCMD()
+		cmd.SetOut(&buf)
+		require.NoError(t, cmd.ParseFlags([]string{"--type", "login", "--sort", "-updated", "--limit", "2"}))
+
+		require.NoError(t, cmd.RunE(cmd, nil), "the next page is not fetched past the limit")
+		require.Less(t, strings.Index(buf.String(), "bank"), strings.Index(buf.String(), "gmail"))
+	})
+
+	t.Run("offline_type_sort_limit", func(t *testing.T) {
+		mockStorage.EXPECT().Outbox().Return(nil, nil).Times(2)
+		mockClient.EXPECT().ListVaultSummaries(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "no route"))
+		mockStorage.EXPECT().CachedVaults().Return([]*pb.VaultRecord{
+			{Id: 1, Title: "gmail", Type: "login", UpdatedAt: "2025-07-01T10:00:00Z"},
+			{Id: 2, Title: "todo", Type: "note", UpdatedAt: "2025-07-03T10:00:00Z"},
+			{Id: 3, Title: "bank", Type: "login", UpdatedAt: "2025-07-02T10:00:00Z"},
+			{Id: 4, Title: "github", Type: "login", UpdatedAt: "2025-06-30T10:00:00Z"},
+		}, nil)
+
+		var buf bytes.Buffer
+		cmd := gk.VaultListCMD()
+		cmd.SetOut(&buf)
+		require.NoError(t, cmd.ParseFlags([]string{"--type", "login", "--sort", "-updated", "--limit", "2"}))
+
+		require.NoError(t, cmd.RunE(cmd, nil))
+		out := buf.String()
+		require.NotContains(t, out, "todo")
+		require.NotContains(t, out, "github", "cut off by --limit")
+		require.Less(t, strings.Index(out, "bank"), strings.Index(out, "gmail"), "newest first")
+	})
+
+	t.Run("invalid_sort", func(t *testing.T) {
+		cmd := gk.VaultListCMD()
+		require.NoError(t, cmd.ParseFlags([]string{"--sort", "size"}))
+		require.Error(t, cmd.RunE(cmd, nil))
+	})
+}
+
+func TestListOptions(t *testing.T) {
+	vaults := []*pb.VaultSummary{
+		{Id: 2, Title: "b", Type: "note"},
+		{Id: 1, Title: "B", Type: "login"},
+		{Id: 3, Title: "a", Type: "note"},
+	}
+	ids := func(list []*pb.VaultSummary) []uint64 {
+		var res []uint64
+		for _, v := range list {
+			res = append(res, v.Id)
+		}
+		return res
+	}
+
+	list, err := listOptions{}.apply(vaults)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2, 3}, ids(list))
+
+	list, err = listOptions{Sort: "title"}.apply(vaults)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 1, 2}, ids(list), "case-insensitive, equal titles by ID")
+
+	list, err = listOptions{Sort: "-id", Type: "note"}.apply(vaults)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 2}, ids(list))
+
+	_, err = listOptions{Sort: "size"}.apply(vaults)
+	require.Error(t, err)
+	_, err = listOptions{Limit: -1}.apply(vaults)
+	require.Error(t, err)
 }
 
 func TestVaultShowCMD(t *testing.T) {
@@ -414,6 +571,9 @@ func TestVaultShowCMD(t *testing.T) {
 		rootCtx: context.Background(),
 		cfg:     &config.Config{},
 	}
+	// the local UUID pins are covered with a real store, see TestUUIDPins
+	mockStorage.EXPECT().PinUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockStorage.EXPECT().PinnedUUID(gomock.Any()).Return("", nil).AnyTimes()
 
 	// Создаём pipe
 	r, _, _ := os.Pipe()
@@ -433,7 +593,7 @@ func TestVaultShowCMD(t *testing.T) {
 		data, err := json.Marshal(note)
 		require.NoError(t, err)
 
-		crypted, err := crypto.EncryptWithSeed(data, key)
+		crypted, err := crypto.Seal(data, key, crypto.AES256GCM, crypto.RecordIDAAD(1, "note"))
 		require.NoError(t, err)
 
 		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(&pb.VaultRecord{
@@ -443,8 +603,8 @@ func TestVaultShowCMD(t *testing.T) {
 			EncryptedData: crypted,
 		}, nil)
 
-		mockStorage.EXPECT().GetCurrentKey().
-			Return(key, nil)
+		mockStorage.EXPECT().GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: key}, nil)
 
 		mockStorage.EXPECT().GetCurrentToken().
 			Return(key, nil)
@@ -473,7 +633,7 @@ func TestVaultShowCMD(t *testing.T) {
 		data, err := json.Marshal(note)
 		require.NoError(t, err)
 
-		crypted, err := crypto.EncryptWithSeed(data, key1)
+		crypted, err := crypto.Seal(data, key1, crypto.AES256GCM, crypto.RecordIDAAD(1, "note"))
 		require.NoError(t, err)
 
 		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(&pb.VaultRecord{
@@ -483,8 +643,8 @@ func TestVaultShowCMD(t *testing.T) {
 			EncryptedData: crypted,
 		}, nil)
 
-		mockStorage.EXPECT().GetCurrentKey().
-			Return(key, nil)
+		mockStorage.EXPECT().GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: key}, nil)
 
 		mockStorage.EXPECT().GetCurrentToken().
 			Return(key, nil)
@@ -509,7 +669,7 @@ func TestVaultShowCMD(t *testing.T) {
 		data, err := json.Marshal(log)
 		require.NoError(t, err)
 
-		crypted, err := crypto.EncryptWithSeed(data, key)
+		crypted, err := crypto.Seal(data, key, crypto.AES256GCM, crypto.RecordIDAAD(1, "login"))
 		require.NoError(t, err)
 
 		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(&pb.VaultRecord{
@@ -519,8 +679,8 @@ func TestVaultShowCMD(t *testing.T) {
 			EncryptedData: crypted,
 		}, nil)
 
-		mockStorage.EXPECT().GetCurrentKey().
-			Return(key, nil)
+		mockStorage.EXPECT().GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: key}, nil)
 
 		mockStorage.EXPECT().GetCurrentToken().
 			Return(key, nil)
@@ -551,7 +711,7 @@ func TestVaultShowCMD(t *testing.T) {
 		data, err := json.Marshal(log)
 		require.NoError(t, err)
 
-		crypted, err := crypto.EncryptWithSeed(data, key1)
+		crypted, err := crypto.Seal(data, key1, crypto.AES256GCM, crypto.RecordIDAAD(1, "login"))
 		require.NoError(t, err)
 
 		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(&pb.VaultRecord{
@@ -561,8 +721,8 @@ func TestVaultShowCMD(t *testing.T) {
 			EncryptedData: crypted,
 		}, nil)
 
-		mockStorage.EXPECT().GetCurrentKey().
-			Return(key, nil)
+		mockStorage.EXPECT().GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: key}, nil)
 
 		mockStorage.EXPECT().GetCurrentToken().
 			Return(key, nil)
@@ -588,7 +748,7 @@ func TestVaultShowCMD(t *testing.T) {
 		data, err := json.Marshal(card)
 		require.NoError(t, err)
 
-		crypted, err := crypto.EncryptWithSeed(data, key)
+		crypted, err := crypto.Seal(data, key, crypto.AES256GCM, crypto.RecordIDAAD(1, "card"))
 		require.NoError(t, err)
 
 		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(&pb.VaultRecord{
@@ -598,8 +758,8 @@ func TestVaultShowCMD(t *testing.T) {
 			EncryptedData: crypted,
 		}, nil)
 
-		mockStorage.EXPECT().GetCurrentKey().
-			Return(key, nil)
+		mockStorage.EXPECT().GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: key}, nil)
 
 		mockStorage.EXPECT().GetCurrentToken().
 			Return(key, nil)
@@ -633,7 +793,7 @@ func TestVaultShowCMD(t *testing.T) {
 		data, err := json.Marshal(card)
 		require.NoError(t, err)
 
-		crypted, err := crypto.EncryptWithSeed(data, key1)
+		crypted, err := crypto.Seal(data, key1, crypto.AES256GCM, crypto.RecordIDAAD(1, "card"))
 		require.NoError(t, err)
 
 		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(&pb.VaultRecord{
@@ -643,8 +803,8 @@ func TestVaultShowCMD(t *testing.T) {
 			EncryptedData: crypted,
 		}, nil)
 
-		mockStorage.EXPECT().GetCurrentKey().
-			Return(key, nil)
+		mockStorage.EXPECT().GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: key}, nil)
 
 		mockStorage.EXPECT().GetCurrentToken().
 			Return(key, nil)
@@ -660,6 +820,222 @@ func TestVaultShowCMD(t *testing.T) {
 		require.NoError(t, err)
 
 	})
+
+	t.Run("show_refuses_moved_contents", func(t *testing.T) {
+		key := "6368616e676520746869732070617373"
+
+		bank := &pb.VaultRecord{Type: "login", Title: "bank"}
+		require.NoError(t, sealRecord(bank, []byte(`{"login":"me","password":"secret"}`), testRecordKey, crypto.AES256GCM))
+		test := &pb.VaultRecord{Type: "login", Title: "test"}
+		require.NoError(t, sealRecord(test, []byte(`{"login":"test","password":"test"}`), testRecordKey, crypto.AES256GCM))
+
+		// сервер подменил содержимое записи test содержимым bank
+		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(&pb.VaultRecord{
+			Id:            2,
+			Uuid:          test.Uuid,
+			Type:          "login",
+			Title:         "test",
+			EncryptedData: bank.EncryptedData,
+		}, nil)
+
+		mockStorage.EXPECT().GetCurrentContext().
+			Return("alice", kv.Context{Login: "alice", Key: key}, nil)
+
+		mockStorage.EXPECT().GetCurrentToken().
+			Return(key, nil)
+
+		args := []string{"get", "2"}
+		cmd := gk.VaultShowCMD()
+
+		var b bytes.Buffer
+		cmd.SetOut(&b)
+
+		err := cmd.RunE(cmd, args)
+		require.ErrorContains(t, err, "перенесено из другой записи")
+		require.NotContains(t, b.String(), "secret")
+	})
+}
+
+func TestVaultEditCMD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	gk := &GophKeeper{
+		client:  mockClient,
+		storage: mockStorage,
+		rootCtx: context.Background(),
+		cfg:     &config.Config{},
+	}
+	// the local UUID pins are covered with a real store, see TestUUIDPins
+	mockStorage.EXPECT().PinUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockStorage.EXPECT().PinnedUUID(gomock.Any()).Return("", nil).AnyTimes()
+
+	const key = "6368616e676520746869732070617373"
+
+	note := func(text string, revision uint64) *pb.VaultRecord {
+		data, err := json.Marshal(kv.Note{Text: text})
+		require.NoError(t, err)
+		v := &pb.VaultRecord{Id: 5, Type: "note", Title: "todo", Metadata: "{}", Revision: revision}
+		require.NoError(t, sealRecord(v, data, testRecordKey, crypto.AES256GCM))
+		return v
+	}
+
+	conflict := func(current, expected uint64) error {
+		st, err := status.New(codes.Aborted, "conflict").WithDetails(&pb.RevisionConflict{
+			VaultId:          5,
+			CurrentRevision:  current,
+			ExpectedRevision: expected,
+		})
+		require.NoError(t, err)
+		return st.Err()
+	}
+
+	r, w, _ := os.Pipe()
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() {
+		os.Stdin = origStdin
+	}()
+
+	mockStorage.EXPECT().GetCurrentToken().Return("token123", nil).AnyTimes()
+	mockStorage.EXPECT().GetCurrentContext().Return("alice", kv.Context{Login: "alice", Key: key}, nil).AnyTimes()
+
+	t.Run("edit_success", func(t *testing.T) {
+		go func() {
+			fmt.Fprintln(w, "groceries")
+			fmt.Fprintln(w, "milk")
+		}()
+
+		current := note("bread", 3)
+		mockClient.EXPECT().GetVault(gomock.Any(), &pb.GetVaultRequest{VaultId: 5}).Return(current, nil)
+		mockClient.EXPECT().
+			UpdateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *pb.VaultRecord, _ ...any) (*emptypb.Empty, error) {
+				require.Equal(t, uint64(3), v.Revision)
+				require.Equal(t, "groceries", v.Title)
+				require.Equal(t, current.Uuid, v.Uuid, "the UUID never changes")
+
+				data, err := openRecord(v, testRecordKey)
+				require.NoError(t, err)
+				require.JSONEq(t, `{"text":"milk"}`, string(data))
+				return &emptypb.Empty{}, nil
+			})
+
+		cmd := gk.VaultEditCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, []string{"edit", "5"}))
+		require.Contains(t, buf.String(), "Запись обновлена")
+	})
+
+	t.Run("edit_conflict_merged", func(t *testing.T) {
+		go func() {
+			fmt.Fprintln(w, "groceries")
+			fmt.Fprintln(w, "bread")
+		}()
+
+		gomock.InOrder(
+			mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(note("bread", 3), nil),
+			mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(nil, conflict(4, 3)),
+			mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(note("eggs", 4), nil),
+			mockClient.EXPECT().
+				UpdateVault(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, v *pb.VaultRecord, _ ...any) (*emptypb.Empty, error) {
+					require.Equal(t, uint64(4), v.Revision)
+					require.Equal(t, "groceries", v.Title)
+
+					data, err := openRecord(v, testRecordKey)
+					require.NoError(t, err)
+					require.JSONEq(t, `{"text":"eggs"}`, string(data))
+					return &emptypb.Empty{}, nil
+				}),
+		)
+
+		cmd := gk.VaultEditCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, []string{"5"}))
+		require.Contains(t, buf.String(), "ревизия 4")
+		require.Contains(t, buf.String(), "изменения объединены")
+	})
+
+	t.Run("edit_conflict_pick_field", func(t *testing.T) {
+		go func() {
+			fmt.Fprintln(w, "")
+			fmt.Fprintln(w, "milk")
+		}()
+
+		gomock.InOrder(
+			mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(note("bread", 3), nil),
+			mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(nil, conflict(4, 3)),
+			mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(note("eggs", 4), nil),
+			mockClient.EXPECT().
+				UpdateVault(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, v *pb.VaultRecord, _ ...any) (*emptypb.Empty, error) {
+					require.Equal(t, uint64(4), v.Revision)
+
+					data, err := openRecord(v, testRecordKey)
+					require.NoError(t, err)
+					require.JSONEq(t, `{"text":"milk"}`, string(data))
+					return &emptypb.Empty{}, nil
+				}),
+		)
+		mockStorage.EXPECT().PutConflict(gomock.Any()).Return(nil)
+		mockStorage.EXPECT().DropConflict(uint64(5)).Return(nil)
+
+		cmd := gk.VaultEditCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetIn(strings.NewReader("1\n"))
+
+		require.NoError(t, cmd.RunE(cmd, []string{"5"}))
+		require.Contains(t, buf.String(), "[1] ваша версия: milk")
+		require.Contains(t, buf.String(), "[2] на сервере:  eggs")
+		require.Contains(t, buf.String(), "Конфликт записи 5 разрешён")
+	})
+
+	t.Run("edit_conflict_postpone", func(t *testing.T) {
+		go func() {
+			fmt.Fprintln(w, "")
+			fmt.Fprintln(w, "milk")
+		}()
+
+		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(note("bread", 3), nil)
+		mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(nil, conflict(4, 3))
+		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(note("eggs", 4), nil)
+		mockStorage.EXPECT().PutConflict(gomock.Any()).Return(nil)
+
+		cmd := gk.VaultEditCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetIn(strings.NewReader("s\n"))
+
+		require.NoError(t, cmd.RunE(cmd, []string{"5"}))
+		require.Contains(t, buf.String(), "conflicts resolve 5")
+	})
+
+	t.Run("edit_update_error", func(t *testing.T) {
+		go func() {
+			fmt.Fprintln(w, "")
+			fmt.Fprintln(w, "milk")
+		}()
+
+		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(note("bread", 3), nil)
+		mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+
+		cmd := gk.VaultEditCMD()
+		require.Error(t, cmd.RunE(cmd, []string{"5"}))
+	})
+
+	t.Run("edit_bad_id", func(t *testing.T) {
+		cmd := gk.VaultEditCMD()
+		require.Error(t, cmd.RunE(cmd, []string{"edit", "abc"}))
+	})
 }
 
 func TestVaultDeleteCMD(t *testing.T) {
@@ -694,6 +1070,7 @@ func TestVaultDeleteCMD(t *testing.T) {
 		// передаём фейковые аргументы (id как строка)
 		args := []string{"delete", strconv.FormatUint(vaultID, 10)}
 		cmd.SetArgs(args)
+		cmd.SetIn(strings.NewReader("y\n"))
 
 		err := cmd.RunE(cmd, args)
 		require.NoError(t, err)
@@ -716,8 +1093,126 @@ func TestVaultDeleteCMD(t *testing.T) {
 		// передаём фейковые аргументы (id как строка)
 		args := []string{"delete", strconv.FormatUint(vaultID, 10)}
 		cmd.SetArgs(args)
+		cmd.SetIn(strings.NewReader("y\n"))
 
 		err := cmd.RunE(cmd, args)
 		require.Error(t, err)
 	})
+
+	t.Run("delete_cancelled", func(t *testing.T) {
+		cmd := gk.VaultDeleteCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetIn(strings.NewReader("n\n"))
+
+		require.NoError(t, cmd.RunE(cmd, []string{"delete", "123"}))
+		require.Contains(t, buf.String(), "Отменено")
+	})
+}
+
+func TestVaultHistoryCMD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	gk := &GophKeeper{
+		client:  mockClient,
+		storage: mockStorage,
+		rootCtx: context.Background(),
+		cfg:     &config.Config{},
+	}
+
+	t.Run("history_success", func(t *testing.T) {
+		mockStorage.EXPECT().GetCurrentToken().Return("token123", nil)
+		mockClient.EXPECT().
+			ListVaultVersions(gomock.Any(), &pb.ListVaultVersionsRequest{VaultId: 7}).
+			Return(&pb.ListVaultVersionsResponse{Versions: []*pb.VaultVersion{
+				{Version: 2, Type: "note", Title: "second", Size: 10, ArchivedAt: "2025-07-01T12:00:00Z"},
+				{Version: 1, Type: "note", Title: "first", Size: 4},
+			}}, nil)
+
+		cmd := gk.VaultHistoryCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, []string{"history", "7"}))
+		require.Contains(t, buf.String(), "VERSION")
+		require.Contains(t, buf.String(), "second")
+		require.Contains(t, buf.String(), "first")
+	})
+
+	t.Run("history_empty", func(t *testing.T) {
+		mockStorage.EXPECT().GetCurrentToken().Return("token123", nil)
+		mockClient.EXPECT().
+			ListVaultVersions(gomock.Any(), gomock.Any()).
+			Return(&pb.ListVaultVersionsResponse{}, nil)
+
+		cmd := gk.VaultHistoryCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+
+		require.NoError(t, cmd.RunE(cmd, []string{"7"}))
+		require.Contains(t, buf.String(), "нет предыдущих версий")
+	})
+
+	t.Run("history_bad_id", func(t *testing.T) {
+		cmd := gk.VaultHistoryCMD()
+		require.Error(t, cmd.RunE(cmd, []string{"history", "abc"}))
+	})
+}
+
+func TestVaultRestoreCMD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockGophKeeperClient(ctrl)
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	gk := &GophKeeper{
+		client:  mockClient,
+		storage: mockStorage,
+		rootCtx: context.Background(),
+		cfg:     &config.Config{},
+	}
+	// the local UUID pins are covered with a real store, see TestUUIDPins
+	mockStorage.EXPECT().PinUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockStorage.EXPECT().PinnedUUID(gomock.Any()).Return("", nil).AnyTimes()
+
+	t.Run("restore_success", func(t *testing.T) {
+		mockStorage.EXPECT().GetCurrentToken().Return("token123", nil)
+		mockClient.EXPECT().
+			RestoreVaultVersion(gomock.Any(), &pb.RestoreVaultVersionRequest{VaultId: 7, Version: 2}).
+			Return(&pb.VaultRecord{Id: 7, Title: "second"}, nil)
+
+		cmd := gk.VaultRestoreCMD()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{"7", "--version", "2"})
+
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, buf.String(), "восстановлена из версии 2")
+	})
+
+	t.Run("restore_without_version", func(t *testing.T) {
+		cmd := gk.VaultRestoreCMD()
+		cmd.SetArgs([]string{"7"})
+		cmd.SilenceUsage = true
+
+		require.ErrorContains(t, cmd.Execute(), "--version")
+	})
+
+	t.Run("restore_error", func(t *testing.T) {
+		mockStorage.EXPECT().GetCurrentToken().Return("token123", nil)
+		mockClient.EXPECT().
+			RestoreVaultVersion(gomock.Any(), gomock.Any()).
+			Return(nil, status.Error(codes.NotFound, "версия не найдена"))
+
+		cmd := gk.VaultRestoreCMD()
+		cmd.SetArgs([]string{"7", "--version", "9"})
+		cmd.SilenceUsage = true
+
+		require.Error(t, cmd.Execute())
+	})
 }
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/samber/do/v2"
 	"github.com/spf13/cobra"
@@ -12,7 +15,10 @@ import (
 	"github.com/wickedv43/go-goph-keeper/internal/api"
 	"github.com/wickedv43/go-goph-keeper/internal/config"
 	"github.com/wickedv43/go-goph-keeper/internal/logger"
+	"github.com/wickedv43/go-goph-keeper/internal/tlsutil"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	pb "github.com/wickedv43/go-goph-keeper/internal/api"
@@ -22,7 +28,23 @@ type GophKeeper struct {
 	rootCmd *cobra.Command
 
 	client  api.GophKeeperClient
+	conn    *grpc.ClientConn
 	storage kv.Storage
+	// index keeps the search index in line with the writes, nil disables it.
+	index *indexer
+	// cipher seals new record contents, zero means crypto.AES256GCM.
+	cipher crypto.Alg
+	// privateMeta seals the titles and metadata of the records written.
+	privateMeta bool
+
+	// syncMu serializes pulls of server changes into the mirror.
+	syncMu sync.Mutex
+	// refreshMu serializes token refreshes, see refreshTokens.
+	refreshMu sync.Mutex
+	// watchRetry overrides watchRetryInterval.
+	watchRetry time.Duration
+	// transferRetry overrides transferRetryInterval.
+	transferRetry time.Duration
 
 	cfg *config.Config
 	log *logger.Logger
@@ -36,30 +58,83 @@ func NewGophKeeper(i do.Injector) (*GophKeeper, error) {
 	log := do.MustInvoke[*logger.Logger](i)
 	kv := do.MustInvoke[*kv.KV](i)
 
-	target := fmt.Sprintf("localhost:%s", cfg.Server.Port)
-	cc, err := grpc.Dial(
-		target,
-		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	alg, err := crypto.ParseAlg(cfg.Encryption.Cipher)
 	if err != nil {
 		return nil, err
 	}
 
-	client := pb.NewGophKeeperClient(cc)
 	ctx, cancel := context.WithCancel(context.Background())
 
 	g := &GophKeeper{
-		storage: kv,
-		cfg:     cfg,
-		log:     log,
-		client:  client,
+		storage:     kv,
+		index:       &indexer{storage: kv, log: log.Named("search")},
+		cipher:      alg,
+		privateMeta: cfg.Encryption.PrivateMetadata,
+		cfg:         cfg,
+		log:         log,
 
 		rootCtx:   ctx,
 		cancelCtx: cancel,
 	}
 
+	// без сохранённого контекста работаем с сервером из конфига
+	_, current, _ := kv.GetCurrentContext()
+	if err := g.connect(current); err != nil {
+		cancel()
+		return nil, err
+	}
+
 	return g, nil
 }
 
+// connect dials the server of the context, replacing the previous connection.
+func (g *GophKeeper) connect(c kv.Context) error {
+	target, tlsCfg := serverTarget(g.cfg.Server), g.cfg.Server.TLS
+	if c.Server != "" {
+		target, tlsCfg = c.Server, c.TLS.Config()
+	}
+
+	creds, err := transportCredentials(tlsCfg)
+	if err != nil {
+		return err
+	}
+
+	cc, err := grpc.Dial(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("подключение к %s: %w", target, err)
+	}
+
+	if g.conn != nil {
+		_ = g.conn.Close()
+	}
+	g.conn = cc
+	g.client = pb.NewGophKeeperClient(cc)
+
+	return nil
+}
+
+// serverTarget returns the address of the server, localhost unless server.host is set.
+func serverTarget(cfg config.Server) string {
+	host := cfg.Host
+	if host == "" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, cfg.Port)
+}
+
+// transportCredentials returns TLS credentials when server.tls is enabled.
+func transportCredentials(cfg config.TLS) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsCfg, err := tlsutil.ClientConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("настройка TLS: %w", err)
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
+
 func (g *GophKeeper) Start() {
 	args := os.Args[1:]
 
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/samber/do/v2"
 	"github.com/spf13/cobra"
@@ -14,8 +16,13 @@ import (
 	pb "github.com/wickedv43/go-goph-keeper/internal/api"
 	"github.com/wickedv43/go-goph-keeper/internal/config"
 	"github.com/wickedv43/go-goph-keeper/internal/logger"
+	"github.com/wickedv43/go-goph-keeper/internal/tlsutil"
 	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
 func TestNewGophKeeper(t *testing.T) {
@@ -43,8 +50,7 @@ func TestNewGophKeeper(t *testing.T) {
 	log, err := logger.NewLogger(injector) // если нельзя — замокай
 	do.ProvideValue(injector, log)
 
-	memKV, err := kv.NewRoseDB(injector) // или мок
-	do.ProvideValue(injector, memKV)
+	memKV := provideTestKV(t, injector)
 
 	// Шаг 3: создаём объект
 	gk, err := NewGophKeeper(injector)
@@ -60,6 +66,102 @@ func TestNewGophKeeper(t *testing.T) {
 	require.NotNil(t, gk.cancelCtx)
 }
 
+func TestNewGophKeeper_TLS(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, tlsutil.GenerateDevCerts(dir, []string{"localhost"}))
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	srvTLS, err := tlsutil.ServerConfig(config.TLS{
+		CertFile:   path(tlsutil.ServerFile),
+		KeyFile:    path(tlsutil.ServerKeyFile),
+		CAFile:     path(tlsutil.CAFile),
+		ClientAuth: true,
+	})
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := lis.Addr().(*net.TCPAddr).Port
+
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(srvTLS)))
+	pb.RegisterGophKeeperServer(grpcServer, pb.UnimplementedGophKeeperServer{})
+
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	newClient := func(t *testing.T, tlsCfg config.TLS) *GophKeeper {
+		injector := do.New()
+		do.ProvideValue(injector, &config.Config{Server: config.Server{
+			Host: "localhost",
+			Port: fmt.Sprintf("%d", port),
+			TLS:  tlsCfg,
+		}})
+		do.ProvideValue(injector, &logger.Logger{SugaredLogger: zap.NewNop().Sugar()})
+		provideTestKV(t, injector)
+
+		gk, err := NewGophKeeper(injector)
+		require.NoError(t, err)
+		return gk
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("client certificate accepted", func(t *testing.T) {
+		gk := newClient(t, config.TLS{
+			Enabled:  true,
+			CAFile:   path(tlsutil.CAFile),
+			CertFile: path(tlsutil.ClientFile),
+			KeyFile:  path(tlsutil.ClientKeyFile),
+		})
+
+		_, err := gk.client.Login(ctx, &pb.LoginRequest{})
+		require.Equal(t, codes.Unimplemented, status.Code(err), "request reached the server: %v", err)
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		gk := newClient(t, config.TLS{Enabled: true, CAFile: path(tlsutil.CAFile)})
+
+		_, err := gk.client.Login(ctx, &pb.LoginRequest{})
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("bad ca file", func(t *testing.T) {
+		injector := do.New()
+		do.ProvideValue(injector, &config.Config{Server: config.Server{TLS: config.TLS{
+			Enabled: true,
+			CAFile:  path("missing.pem"),
+		}}})
+		do.ProvideValue(injector, &logger.Logger{SugaredLogger: zap.NewNop().Sugar()})
+		provideTestKV(t, injector)
+
+		_, err := NewGophKeeper(injector)
+		require.Error(t, err)
+	})
+}
+
+// provideTestKV registers a RoseDB storage in a temporary directory.
+func provideTestKV(t *testing.T, injector do.Injector) *kv.KV {
+	t.Helper()
+
+	kvInj := do.New()
+	do.ProvideValue(kvInj, &config.Config{KV: config.KV{DirPath: t.TempDir()}})
+	do.ProvideValue(kvInj, &logger.Logger{SugaredLogger: zap.NewNop().Sugar()})
+
+	store, err := kv.NewRoseDB(kvInj)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Shutdown() })
+
+	do.ProvideValue(injector, store)
+	return store
+}
+
+func TestServerTarget(t *testing.T) {
+	require.Equal(t, "localhost:8080", serverTarget(config.Server{Port: "8080"}))
+	require.Equal(t, "keeper.example.com:443", serverTarget(config.Server{Host: "keeper.example.com", Port: "443"}))
+	require.Equal(t, "[::1]:8080", serverTarget(config.Server{Host: "::1", Port: "8080"}))
+}
+
 func TestGophKeeper_Run(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
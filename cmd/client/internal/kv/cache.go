@@ -0,0 +1,388 @@
+package kv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rosedblabs/rosedb/v2"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNotCached indicates that the record is not in the local mirror.
+var ErrNotCached = errors.New("record not cached")
+
+// Outbox operations.
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Keys of the local mirror. Every context has its own namespace, so switching
+// between accounts or servers never mixes their records:
+//
+//	cache:<context>:cursor       the sync cursor
+//	cache:<context>:vault:<id>   a record as the server last returned it
+//	cache:<context>:outbox:<seq> a write waiting to be sent
+//	cache:<context>:conflict:<id> an edit waiting for the user to merge it
+//	cache:<context>:index:<name> an entry of the search index
+//	cache:<context>:uuid:<id>    the UUID the record was first seen with, see PinnedUUID
+const nsCache = "cache:"
+
+// OutboxEntry is a write made while the server was unreachable.
+// Record holds the full record for create and update and only the ID for delete.
+// Base is the record an update was made from, the ancestor for a merge.
+type OutboxEntry struct {
+	Seq      uint64          `json:"seq"`
+	Op       string          `json:"op"`
+	Record   *pb.VaultRecord `json:"-"`
+	Base     *pb.VaultRecord `json:"-"`
+	QueuedAt time.Time       `json:"queued_at"`
+	Error    string          `json:"error,omitempty"` // why the last replay failed
+}
+
+// outboxRow is the stored form of OutboxEntry.
+type outboxRow struct {
+	OutboxEntry
+	Record []byte `json:"record"`
+	Base   []byte `json:"base,omitempty"`
+}
+
+// CachedVaults returns the mirrored records of the current context, ordered by ID.
+func (s *KV) CachedVaults() ([]*pb.VaultRecord, error) {
+	prefix, err := s.cacheKey("vault:")
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*pb.VaultRecord
+	err = s.scan(prefix, func(_, val []byte) error {
+		v := &pb.VaultRecord{}
+		if err := proto.Unmarshal(val, v); err != nil {
+			return errors.Wrap(err, "decode cached vault")
+		}
+		list = append(list, v)
+		return nil
+	})
+	return list, err
+}
+
+// CachedVault returns a mirrored record of the current context.
+func (s *KV) CachedVault(id uint64) (*pb.VaultRecord, error) {
+	key, err := s.cacheKey(vaultKey(id))
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := s.db.Get(key)
+	if errors.Is(err, rosedb.ErrKeyNotFound) {
+		return nil, ErrNotCached
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get cached vault")
+	}
+
+	v := &pb.VaultRecord{}
+	if err = proto.Unmarshal(val, v); err != nil {
+		return nil, errors.Wrap(err, "decode cached vault")
+	}
+	return v, nil
+}
+
+// CacheVault stores a record in the mirror of the current context and pins
+// its UUID.
+func (s *KV) CacheVault(v *pb.VaultRecord) error {
+	key, err := s.cacheKey(vaultKey(v.Id))
+	if err != nil {
+		return err
+	}
+
+	val, err := proto.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "encode vault")
+	}
+	if err = s.db.Put(key, val); err != nil {
+		return errors.Wrap(err, "put cached vault")
+	}
+	return s.PinUUID(v.Id, v.Uuid)
+}
+
+// PinnedUUID returns the UUID a record of the current context was first seen
+// with, or an empty string for a record never seen with one. Pins outlive the
+// mirror: once a record has a UUID, a copy without it, or with another one,
+// did not come from the owner.
+func (s *KV) PinnedUUID(id uint64) (string, error) {
+	key, err := s.cacheKey(uuidKey(id))
+	if err != nil {
+		return "", err
+	}
+
+	val, err := s.db.Get(key)
+	if errors.Is(err, rosedb.ErrKeyNotFound) {
+		return "", nil
+	}
+	return string(val), errors.Wrap(err, "get pinned uuid")
+}
+
+// PinUUID pins the UUID of a record of the current context unless it is
+// pinned already. An empty UUID or ID is ignored.
+func (s *KV) PinUUID(id uint64, uuid string) error {
+	if id == 0 || uuid == "" {
+		return nil
+	}
+	pinned, err := s.PinnedUUID(id)
+	if err != nil || pinned != "" {
+		return err
+	}
+
+	key, err := s.cacheKey(uuidKey(id))
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(s.db.Put(key, []byte(uuid)), "pin uuid")
+}
+
+// UncacheVault removes a record from the mirror of the current context.
+func (s *KV) UncacheVault(id uint64) error {
+	key, err := s.cacheKey(vaultKey(id))
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(s.db.Delete(key), "delete cached vault")
+}
+
+// SyncCursor returns the sync cursor of the current context, zero before the first sync.
+func (s *KV) SyncCursor() (uint64, error) {
+	key, err := s.cacheKey("cursor")
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := s.db.Get(key)
+	if errors.Is(err, rosedb.ErrKeyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "get cursor")
+	}
+	return strconv.ParseUint(string(val), 10, 64)
+}
+
+// ApplySync applies a page of server changes to the mirror of the current
+// context and advances its cursor, all in one batch. The UUIDs of the changed
+// records are pinned; a full resync drops the records, not the pins.
+func (s *KV) ApplySync(page *pb.SyncResponse) error {
+	prefix, err := s.cacheKey("")
+	if err != nil {
+		return err
+	}
+
+	// read before the batch: it holds the database lock until it ends
+	var stale [][]byte
+	if page.FullResync {
+		err = s.scan(append(bytes.Clone(prefix), "vault:"...), func(key, _ []byte) error {
+			stale = append(stale, bytes.Clone(key))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	pins := make(map[uint64]string)
+	for _, v := range page.Changed {
+		if v.Uuid == "" || pins[v.Id] != "" {
+			continue
+		}
+		pinned, err := s.PinnedUUID(v.Id)
+		if err != nil {
+			return err
+		}
+		if pinned == "" {
+			pins[v.Id] = v.Uuid
+		}
+	}
+
+	batch := s.db.NewBatch(rosedb.DefaultBatchOptions)
+
+	for _, key := range stale {
+		if err = batch.Delete(key); err != nil {
+			_ = batch.Rollback()
+			return errors.Wrap(err, "drop mirror")
+		}
+	}
+
+	for _, v := range page.Changed {
+		val, err := proto.Marshal(v)
+		if err == nil {
+			err = batch.Put(append(bytes.Clone(prefix), vaultKey(v.Id)...), val)
+		}
+		if err != nil {
+			_ = batch.Rollback()
+			return errors.Wrap(err, "cache vault")
+		}
+	}
+
+	for id, uuid := range pins {
+		if err = batch.Put(append(bytes.Clone(prefix), uuidKey(id)...), []byte(uuid)); err != nil {
+			_ = batch.Rollback()
+			return errors.Wrap(err, "pin uuid")
+		}
+	}
+
+	for _, id := range page.Deleted {
+		if err = batch.Delete(append(bytes.Clone(prefix), vaultKey(id)...)); err != nil {
+			_ = batch.Rollback()
+			return errors.Wrap(err, "uncache vault")
+		}
+	}
+
+	cursor := strconv.FormatUint(page.Cursor, 10)
+	if err = batch.Put(append(bytes.Clone(prefix), "cursor"...), []byte(cursor)); err != nil {
+		_ = batch.Rollback()
+		return errors.Wrap(err, "put cursor")
+	}
+
+	return errors.Wrap(batch.Commit(), "commit sync")
+}
+
+// PutOutbox queues a write in the outbox of the current context, or replaces
+// an already queued one. A new entry gets the next sequence number.
+func (s *KV) PutOutbox(e *OutboxEntry) error {
+	if e.Seq == 0 {
+		seq, err := s.nextOutboxSeq()
+		if err != nil {
+			return err
+		}
+		e.Seq = seq
+	}
+	if e.QueuedAt.IsZero() {
+		e.QueuedAt = time.Now()
+	}
+
+	key, err := s.cacheKey(outboxKey(e.Seq))
+	if err != nil {
+		return err
+	}
+
+	rec, err := proto.Marshal(e.Record)
+	if err != nil {
+		return errors.Wrap(err, "encode outbox record")
+	}
+	row := outboxRow{OutboxEntry: *e, Record: rec}
+	if e.Base != nil {
+		if row.Base, err = proto.Marshal(e.Base); err != nil {
+			return errors.Wrap(err, "encode outbox base")
+		}
+	}
+
+	val, err := json.Marshal(row)
+	if err != nil {
+		return errors.Wrap(err, "encode outbox entry")
+	}
+	return errors.Wrap(s.db.Put(key, val), "put outbox entry")
+}
+
+// Outbox returns the queued writes of the current context in the order they were made.
+func (s *KV) Outbox() ([]OutboxEntry, error) {
+	prefix, err := s.cacheKey("outbox:")
+	if err != nil {
+		return nil, err
+	}
+
+	var list []OutboxEntry
+	err = s.scan(prefix, func(_, val []byte) error {
+		var row outboxRow
+		if err := json.Unmarshal(val, &row); err != nil {
+			return errors.Wrap(err, "decode outbox entry")
+		}
+		row.OutboxEntry.Record = &pb.VaultRecord{}
+		if err := proto.Unmarshal(row.Record, row.OutboxEntry.Record); err != nil {
+			return errors.Wrap(err, "decode outbox record")
+		}
+		if row.Base != nil {
+			row.OutboxEntry.Base = &pb.VaultRecord{}
+			if err := proto.Unmarshal(row.Base, row.OutboxEntry.Base); err != nil {
+				return errors.Wrap(err, "decode outbox base")
+			}
+		}
+		list = append(list, row.OutboxEntry)
+		return nil
+	})
+	return list, err
+}
+
+// DropOutbox removes a write from the outbox of the current context.
+func (s *KV) DropOutbox(seq uint64) error {
+	key, err := s.cacheKey(outboxKey(seq))
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(s.db.Delete(key), "delete outbox entry")
+}
+
+// nextOutboxSeq returns the next sequence number of the current context's outbox.
+func (s *KV) nextOutboxSeq() (uint64, error) {
+	key, err := s.cacheKey("outbox-seq")
+	if err != nil {
+		return 0, err
+	}
+
+	var seq uint64
+	val, err := s.db.Get(key)
+	switch {
+	case errors.Is(err, rosedb.ErrKeyNotFound):
+	case err != nil:
+		return 0, errors.Wrap(err, "get outbox seq")
+	default:
+		seq = binary.BigEndian.Uint64(val)
+	}
+
+	seq++
+	if err = s.db.Put(key, binary.BigEndian.AppendUint64(nil, seq)); err != nil {
+		return 0, errors.Wrap(err, "put outbox seq")
+	}
+	return seq, nil
+}
+
+// cacheKey returns the key of name in the namespace of the current context.
+func (s *KV) cacheKey(name string) ([]byte, error) {
+	cfg, _ := s.GetConfig()
+	if _, ok := cfg.Contexts[cfg.Current]; !ok {
+		return nil, ErrEmptyContext
+	}
+	return []byte(nsCache + cfg.Current + ":" + name), nil
+}
+
+// scan calls fn for every key with the prefix, in key order.
+func (s *KV) scan(prefix []byte, fn func(key, val []byte) error) error {
+	var fnErr error
+	s.db.AscendGreaterOrEqual(prefix, func(k, v []byte) (bool, error) {
+		if !bytes.HasPrefix(k, prefix) {
+			return false, nil
+		}
+		if fnErr = fn(k, v); fnErr != nil {
+			return false, nil
+		}
+		return true, nil
+	})
+	return fnErr
+}
+
+// vaultKey and outboxKey pad numbers so that keys sort in numeric order.
+func vaultKey(id uint64) string {
+	return fmt.Sprintf("vault:%020d", id)
+}
+
+func uuidKey(id uint64) string {
+	return fmt.Sprintf("uuid:%020d", id)
+}
+
+func outboxKey(seq uint64) string {
+	return fmt.Sprintf("outbox:%020d", seq)
+}
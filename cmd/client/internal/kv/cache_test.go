@@ -0,0 +1,245 @@
+package kv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+)
+
+func TestVaultCache(t *testing.T) {
+	kv := setupTestKV(t)
+
+	_, err := kv.CachedVaults()
+	require.ErrorIs(t, err, ErrEmptyContext, "no context yet")
+
+	require.NoError(t, kv.SetConfig(Config{
+		Current:  "alice",
+		Contexts: map[string]Context{"alice": {Login: "alice"}, "bob": {Login: "bob"}},
+	}))
+
+	cursor, err := kv.SyncCursor()
+	require.NoError(t, err)
+	require.Zero(t, cursor)
+
+	t.Run("apply pages", func(t *testing.T) {
+		require.NoError(t, kv.ApplySync(&pb.SyncResponse{
+			Changed:    []*pb.VaultRecord{{Id: 10, Title: "ten"}, {Id: 2, Title: "two"}},
+			Cursor:     5,
+			FullResync: true,
+		}))
+		require.NoError(t, kv.ApplySync(&pb.SyncResponse{
+			Changed: []*pb.VaultRecord{{Id: 2, Title: "two v2", Revision: 2}},
+			Deleted: []uint64{10},
+			Cursor:  7,
+		}))
+
+		list, err := kv.CachedVaults()
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		require.Equal(t, "two v2", list[0].Title)
+
+		cursor, err := kv.SyncCursor()
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), cursor)
+
+		_, err = kv.CachedVault(10)
+		require.ErrorIs(t, err, ErrNotCached)
+	})
+
+	t.Run("full resync drops the mirror", func(t *testing.T) {
+		require.NoError(t, kv.CacheVault(&pb.VaultRecord{Id: 3, Title: "three"}))
+		require.NoError(t, kv.ApplySync(&pb.SyncResponse{
+			Changed:    []*pb.VaultRecord{{Id: 11, Title: "eleven"}},
+			Cursor:     1,
+			FullResync: true,
+		}))
+
+		list, err := kv.CachedVaults()
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		require.Equal(t, uint64(11), list[0].Id)
+	})
+
+	t.Run("ordered by id", func(t *testing.T) {
+		require.NoError(t, kv.CacheVault(&pb.VaultRecord{Id: 9}))
+		require.NoError(t, kv.CacheVault(&pb.VaultRecord{Id: 100}))
+
+		list, err := kv.CachedVaults()
+		require.NoError(t, err)
+		require.Equal(t, []uint64{9, 11, 100}, []uint64{list[0].Id, list[1].Id, list[2].Id})
+
+		require.NoError(t, kv.UncacheVault(100))
+		v, err := kv.CachedVault(9)
+		require.NoError(t, err)
+		require.Equal(t, uint64(9), v.Id)
+	})
+
+	t.Run("contexts do not share the mirror", func(t *testing.T) {
+		require.NoError(t, kv.UseContext("bob"))
+		defer func() { require.NoError(t, kv.UseContext("alice")) }()
+
+		list, err := kv.CachedVaults()
+		require.NoError(t, err)
+		require.Empty(t, list)
+
+		cursor, err := kv.SyncCursor()
+		require.NoError(t, err)
+		require.Zero(t, cursor)
+	})
+}
+
+func TestUUIDPins(t *testing.T) {
+	kv := setupTestKV(t)
+	require.NoError(t, kv.SetConfig(Config{
+		Current:  "alice",
+		Contexts: map[string]Context{"alice": {Login: "alice"}, "bob": {Login: "bob"}},
+	}))
+
+	const first, second = "00000000-0000-4000-8000-000000000001", "00000000-0000-4000-8000-000000000002"
+
+	pinned, err := kv.PinnedUUID(4)
+	require.NoError(t, err)
+	require.Empty(t, pinned)
+
+	require.NoError(t, kv.ApplySync(&pb.SyncResponse{
+		Changed: []*pb.VaultRecord{{Id: 3}, {Id: 4, Uuid: first}},
+		Cursor:  1,
+	}))
+	require.NoError(t, kv.CacheVault(&pb.VaultRecord{Id: 4, Uuid: second}))
+	require.NoError(t, kv.PinUUID(4, ""))
+	require.NoError(t, kv.ApplySync(&pb.SyncResponse{
+		Changed:    []*pb.VaultRecord{{Id: 4}},
+		Cursor:     2,
+		FullResync: true,
+	}))
+
+	pinned, err = kv.PinnedUUID(4)
+	require.NoError(t, err)
+	require.Equal(t, first, pinned, "the first UUID stays, through a full resync")
+	pinned, err = kv.PinnedUUID(3)
+	require.NoError(t, err)
+	require.Empty(t, pinned)
+
+	require.NoError(t, kv.PinUUID(3, second))
+	pinned, err = kv.PinnedUUID(3)
+	require.NoError(t, err)
+	require.Equal(t, second, pinned)
+
+	require.NoError(t, kv.UseContext("bob"))
+	pinned, err = kv.PinnedUUID(4)
+	require.NoError(t, err)
+	require.Empty(t, pinned, "contexts do not share pins")
+}
+
+func TestOutbox(t *testing.T) {
+	kv := setupTestKV(t)
+	require.NoError(t, kv.SetConfig(Config{
+		Current:  "alice",
+		Contexts: map[string]Context{"alice": {Login: "alice"}, "bob": {Login: "bob"}},
+	}))
+
+	create := &OutboxEntry{Op: OpCreate, Record: &pb.VaultRecord{Title: "new", EncryptedData: []byte{1, 2}}}
+	update := &OutboxEntry{Op: OpUpdate, Record: &pb.VaultRecord{Id: 4, Revision: 3}}
+	require.NoError(t, kv.PutOutbox(create))
+	require.NoError(t, kv.PutOutbox(update))
+	require.Equal(t, uint64(1), create.Seq)
+	require.Equal(t, uint64(2), update.Seq)
+
+	update.Error = "conflict"
+	require.NoError(t, kv.PutOutbox(update))
+
+	list, err := kv.Outbox()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, OpCreate, list[0].Op)
+	require.Equal(t, []byte{1, 2}, list[0].Record.EncryptedData)
+	require.False(t, list[0].QueuedAt.IsZero())
+	require.Equal(t, "conflict", list[1].Error)
+	require.Equal(t, uint64(3), list[1].Record.Revision)
+
+	require.NoError(t, kv.DropOutbox(create.Seq))
+	list, err = kv.Outbox()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, kv.UseContext("bob"))
+	list, err = kv.Outbox()
+	require.NoError(t, err)
+	require.Empty(t, list)
+
+	other := &OutboxEntry{Op: OpDelete, Record: &pb.VaultRecord{Id: 1}}
+	require.NoError(t, kv.PutOutbox(other))
+	require.Equal(t, uint64(1), other.Seq, "every context numbers its own outbox")
+}
+
+func TestConflicts(t *testing.T) {
+	kv := setupTestKV(t)
+	require.NoError(t, kv.SetConfig(Config{
+		Current:  "alice",
+		Contexts: map[string]Context{"alice": {Login: "alice"}, "bob": {Login: "bob"}},
+	}))
+
+	_, err := kv.Conflict(7)
+	require.ErrorIs(t, err, ErrNoConflict)
+
+	c := &Conflict{
+		VaultID: 7,
+		Base:    &pb.VaultRecord{Id: 7, Revision: 1},
+		Local:   &pb.VaultRecord{Id: 7, Title: "mine", Revision: 1},
+		Remote:  &pb.VaultRecord{Id: 7, Title: "theirs", Revision: 2},
+	}
+	require.NoError(t, kv.PutConflict(c))
+	require.NoError(t, kv.PutConflict(&Conflict{VaultID: 3, Base: c.Base, Local: c.Local, Remote: c.Remote}))
+
+	got, err := kv.Conflict(7)
+	require.NoError(t, err)
+	require.Equal(t, "mine", got.Local.Title)
+	require.Equal(t, uint64(2), got.Remote.Revision)
+	require.False(t, got.DetectedAt.IsZero())
+
+	list, err := kv.Conflicts()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, uint64(3), list[0].VaultID)
+
+	require.NoError(t, kv.DropConflict(3))
+	list, err = kv.Conflicts()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, kv.UseContext("bob"))
+	list, err = kv.Conflicts()
+	require.NoError(t, err)
+	require.Empty(t, list)
+}
+
+func TestUploads(t *testing.T) {
+	kv := setupTestKV(t)
+	require.NoError(t, kv.SetConfig(Config{
+		Current:  "alice",
+		Contexts: map[string]Context{"alice": {Login: "alice"}, "bob": {Login: "bob"}},
+	}))
+
+	_, err := kv.Upload("/tmp/movie.mkv")
+	require.ErrorIs(t, err, ErrNoUpload)
+
+	modTime := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, kv.PutUpload(&Upload{Ref: "f00d", Path: "/tmp/movie.mkv", Size: 100, ModTime: modTime, Header: []byte("hdr")}))
+
+	u, err := kv.Upload("/tmp/movie.mkv")
+	require.NoError(t, err)
+	require.Equal(t, "f00d", u.Ref)
+	require.True(t, modTime.Equal(u.ModTime))
+	require.Equal(t, []byte("hdr"), u.Header)
+
+	require.NoError(t, kv.UseContext("bob"))
+	_, err = kv.Upload("/tmp/movie.mkv")
+	require.ErrorIs(t, err, ErrNoUpload, "uploads belong to their context")
+
+	require.NoError(t, kv.UseContext("alice"))
+	require.NoError(t, kv.DropUpload("/tmp/movie.mkv"))
+	_, err = kv.Upload("/tmp/movie.mkv")
+	require.ErrorIs(t, err, ErrNoUpload)
+}
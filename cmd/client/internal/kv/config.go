@@ -1,22 +1,63 @@
 package kv
 
 import (
+	"cmp"
 	"encoding/json"
+	"slices"
+	"strconv"
 
 	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
 )
 
 var (
 	ErrEmptyKey        = errors.New("empty key")
 	ErrEmptyContext    = errors.New("empty context")
 	ErrContextNotFound = errors.New("context not found")
+	ErrContextExists   = errors.New("context already exists")
 )
 
 const nsConfig = "config:"
 
+// Context is a login on a particular server. Server and TLS are empty for
+// contexts that use the endpoint from the client config file.
 type Context struct {
-	Token string `json:"token"`
-	Key   string `json:"key"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Key          string `json:"key"`
+	Login        string `json:"login,omitempty"`
+	Server       string `json:"server,omitempty"`
+	TLS          TLS    `json:"tls,omitempty"`
+}
+
+// TLS holds the transport settings of a context.
+type TLS struct {
+	Enabled    bool   `json:"enabled,omitempty"`
+	CAFile     string `json:"ca_file,omitempty"`
+	CertFile   string `json:"cert_file,omitempty"`
+	KeyFile    string `json:"key_file,omitempty"`
+	ServerName string `json:"server_name,omitempty"`
+}
+
+// Config converts the settings to the form used by the transport.
+func (t TLS) Config() config.TLS {
+	return config.TLS{
+		Enabled:    t.Enabled,
+		CAFile:     t.CAFile,
+		CertFile:   t.CertFile,
+		KeyFile:    t.KeyFile,
+		ServerName: t.ServerName,
+	}
+}
+
+// Owner returns the login the context belongs to, or "" for a context
+// added without a login yet. Contexts saved before logins were recorded
+// are named after their login.
+func (c Context) Owner(name string) string {
+	if c.Login == "" && c.Token != "" {
+		return name
+	}
+	return c.Login
 }
 
 type Config struct {
@@ -56,46 +97,141 @@ func (s *KV) GetConfig() (Config, error) {
 	return c, nil
 }
 
-func (s *KV) SaveContext(login, token string) error {
+// loadConfig returns the stored config, or an empty one if there is none yet.
+func (s *KV) loadConfig() Config {
 	cfg, err := s.GetConfig()
 	if err != nil {
-		var c Config
-		c.Contexts = make(map[string]Context)
-		cfg = c
+		cfg = Config{}
 	}
 	if cfg.Contexts == nil {
 		cfg.Contexts = make(map[string]Context)
 	}
-	key, err := s.GetCurrentKey()
-	if err != nil {
-		key = ""
+	return cfg
+}
+
+// contextFor returns the name of the context the login is saved to: the current
+// context if it belongs to the login or has no login yet, otherwise another
+// context of the login on the same server, preferably the one named after it.
+// The login of a context that has one is never changed. A new context inherits
+// the endpoint of the current one and is named after the login, or after the
+// login and the server when that name is taken.
+func contextFor(cfg Config, login string) (string, Context) {
+	cur, ok := cfg.Contexts[cfg.Current]
+	if ok {
+		switch cur.Owner(cfg.Current) {
+		case "":
+			cur.Login = login
+			return cfg.Current, cur
+		case login:
+			return cfg.Current, cur
+		}
+	}
+
+	names := make([]string, 0, len(cfg.Contexts))
+	for name := range cfg.Contexts {
+		names = append(names, name)
 	}
+	slices.Sort(names)
+	slices.SortStableFunc(names, func(a, b string) int {
+		return cmp.Compare(boolRank(a != login), boolRank(b != login))
+	})
+	for _, name := range names {
+		if c := cfg.Contexts[name]; c.Server == cur.Server && c.Owner(name) == login {
+			return name, c
+		}
+	}
+
+	name := login
+	for i := 1; ; i++ {
+		if _, taken := cfg.Contexts[name]; !taken {
+			break
+		}
+		name = login + "@" + cmp.Or(cur.Server, "default")
+		if i > 1 {
+			name += "-" + strconv.Itoa(i)
+		}
+	}
+	return name, Context{Login: login, Server: cur.Server, TLS: cur.TLS}
+}
 
-	cfg.Contexts[login] = Context{Token: token, Key: key}
-	cfg.Current = login
+// boolRank orders false before true.
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// SaveContext stores the access token of the login and makes its context current.
+func (s *KV) SaveContext(login, token string) error {
+	cfg := s.loadConfig()
+
+	name, c := contextFor(cfg, login)
+	c.Token = token
+	cfg.Contexts[name] = c
+	cfg.Current = name
 	return s.SetConfig(cfg)
 }
 
+// SaveKey stores the encryption key of the login and makes its context current.
 func (s *KV) SaveKey(login, key string) error {
-	cfg, err := s.GetConfig()
-	if err != nil {
-		var c Config
-		c.Contexts = make(map[string]Context)
-		cfg = c
+	cfg := s.loadConfig()
+
+	name, c := contextFor(cfg, login)
+	c.Key = key
+	cfg.Contexts[name] = c
+	cfg.Current = name
+	return s.SetConfig(cfg)
+}
+
+// AddContext creates a context without a login, to be filled by the next login.
+func (s *KV) AddContext(name string, c Context) error {
+	cfg := s.loadConfig()
+	if _, ok := cfg.Contexts[name]; ok {
+		return ErrContextExists
 	}
-	if cfg.Contexts == nil {
-		cfg.Contexts = make(map[string]Context)
+
+	cfg.Contexts[name] = c
+	return s.SetConfig(cfg)
+}
+
+// SaveRefreshToken stores the refresh token of the login context.
+func (s *KV) SaveRefreshToken(login, refresh string) error {
+	cfg := s.loadConfig()
+	name, c := contextFor(cfg, login)
+	if _, ok := cfg.Contexts[name]; !ok {
+		return ErrContextNotFound
 	}
-	token, err := s.GetCurrentToken()
-	if err != nil {
-		token = ""
+
+	c.RefreshToken = refresh
+	cfg.Contexts[name] = c
+	return s.SetConfig(cfg)
+}
+
+// SaveContextTokens replaces the token pair of the context by its name, without
+// touching its login. Refreshing tokens and logging out use it, as they act on
+// the context as it is.
+func (s *KV) SaveContextTokens(name, token, refresh string) error {
+	cfg := s.loadConfig()
+	c, ok := cfg.Contexts[name]
+	if !ok {
+		return ErrContextNotFound
 	}
 
-	cfg.Contexts[login] = Context{Token: token, Key: key}
-	cfg.Current = login
+	c.Token, c.RefreshToken = token, refresh
+	cfg.Contexts[name] = c
 	return s.SetConfig(cfg)
 }
 
+// GetCurrentContext returns the name and settings of the current context.
+func (s *KV) GetCurrentContext() (string, Context, error) {
+	cfg, _ := s.GetConfig()
+	if c, ok := cfg.Contexts[cfg.Current]; ok {
+		return cfg.Current, c, nil
+	}
+	return "", Context{}, ErrEmptyContext
+}
+
 func (s *KV) UseContext(name string) error {
 	cfg, _ := s.GetConfig()
 	if _, ok := cfg.Contexts[name]; !ok {
@@ -113,6 +249,16 @@ func (s *KV) GetCurrentToken() (string, error) {
 	return "", ErrEmptyContext
 }
 
+// GetCurrentRefreshToken returns the refresh token of the current context.
+func (s *KV) GetCurrentRefreshToken() (string, error) {
+	cfg, _ := s.GetConfig()
+	if ctx, ok := cfg.Contexts[cfg.Current]; ok {
+		return ctx.RefreshToken, nil
+	}
+
+	return "", ErrEmptyContext
+}
+
 func (s *KV) GetCurrentKey() (string, error) {
 	cfg, _ := s.GetConfig()
 	if ctx, ok := cfg.Contexts[cfg.Current]; ok {
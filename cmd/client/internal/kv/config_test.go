@@ -91,3 +91,129 @@ func TestUseContext(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "tokenA", token)
 }
+
+func TestSaveRefreshToken(t *testing.T) {
+	kv := setupTestKV(t)
+
+	// unknown context
+	require.ErrorIs(t, kv.SaveRefreshToken("ghost", "r"), ErrContextNotFound)
+
+	require.NoError(t, kv.SaveContext("carol", "access-1"))
+	require.NoError(t, kv.SaveRefreshToken("carol", "refresh-1"))
+
+	refresh, err := kv.GetCurrentRefreshToken()
+	require.NoError(t, err)
+	require.Equal(t, "refresh-1", refresh)
+
+	// a new access token keeps the refresh token
+	require.NoError(t, kv.SaveContext("carol", "access-2"))
+	refresh, err = kv.GetCurrentRefreshToken()
+	require.NoError(t, err)
+	require.Equal(t, "refresh-1", refresh)
+}
+
+func TestContextEndpoints(t *testing.T) {
+	kv := setupTestKV(t)
+
+	staging := Context{Server: "staging.example.com:8443", TLS: TLS{Enabled: true, CAFile: "/etc/gk/ca.pem"}}
+	require.NoError(t, kv.AddContext("staging", staging))
+	require.ErrorIs(t, kv.AddContext("staging", Context{}), ErrContextExists)
+
+	_, _, err := kv.GetCurrentContext()
+	require.ErrorIs(t, err, ErrEmptyContext, "adding does not switch")
+
+	require.NoError(t, kv.UseContext("staging"))
+
+	// the first login fills the added context
+	require.NoError(t, kv.SaveContext("alice", "token-a"))
+	require.NoError(t, kv.SaveRefreshToken("alice", "refresh-a"))
+	require.NoError(t, kv.SaveKey("alice", "key-a"))
+
+	name, c, err := kv.GetCurrentContext()
+	require.NoError(t, err)
+	require.Equal(t, "staging", name)
+	require.Equal(t, Context{
+		Token:        "token-a",
+		RefreshToken: "refresh-a",
+		Key:          "key-a",
+		Login:        "alice",
+		Server:       staging.Server,
+		TLS:          staging.TLS,
+	}, c)
+
+	// another login on the same server gets its own context
+	require.NoError(t, kv.SaveContext("bob", "token-b"))
+
+	name, c, err = kv.GetCurrentContext()
+	require.NoError(t, err)
+	require.Equal(t, "bob", name)
+	require.Equal(t, staging.Server, c.Server)
+	require.Equal(t, staging.TLS, c.TLS)
+
+	cfg, err := kv.GetConfig()
+	require.NoError(t, err)
+	require.Equal(t, "token-a", cfg.Contexts["staging"].Token, "the first login keeps its context")
+}
+
+func TestContextEndpoints_Legacy(t *testing.T) {
+	kv := setupTestKV(t)
+
+	// contexts saved before logins were recorded are named after the login
+	require.NoError(t, kv.SetConfig(Config{
+		Current:  "alice",
+		Contexts: map[string]Context{"alice": {Token: "old", Key: "key-a"}},
+	}))
+
+	require.NoError(t, kv.SaveContext("bob", "token-b"))
+
+	cfg, err := kv.GetConfig()
+	require.NoError(t, err)
+	require.Equal(t, "bob", cfg.Current)
+	require.Equal(t, Context{Token: "old", Key: "key-a"}, cfg.Contexts["alice"])
+	require.Equal(t, "bob", cfg.Contexts["bob"].Login)
+}
+
+func TestContextEndpoints_NamedContext(t *testing.T) {
+	kv := setupTestKV(t)
+
+	prod := Context{Server: "prod.example.com:8443", Login: "alice", Token: "token-1", RefreshToken: "refresh-1"}
+	staging := Context{Server: "staging.example.com:8443"}
+	require.NoError(t, kv.AddContext("prod", prod))
+	require.NoError(t, kv.AddContext("alice", staging))
+	require.NoError(t, kv.UseContext("prod"))
+
+	// refreshing tokens keeps the login of a context named differently
+	require.NoError(t, kv.SaveContextTokens("prod", "token-2", "refresh-2"))
+	require.ErrorIs(t, kv.SaveContextTokens("missing", "", ""), ErrContextNotFound)
+
+	name, c, err := kv.GetCurrentContext()
+	require.NoError(t, err)
+	require.Equal(t, "prod", name)
+	require.Equal(t, "alice", c.Login)
+	require.Equal(t, "alice", c.Owner(name))
+	require.Equal(t, "token-2", c.Token)
+	require.Equal(t, "refresh-2", c.RefreshToken)
+
+	// logging in again stays on the context of the login
+	require.NoError(t, kv.SaveContext("alice", "token-3"))
+
+	name, c, err = kv.GetCurrentContext()
+	require.NoError(t, err)
+	require.Equal(t, "prod", name)
+	require.Equal(t, "token-3", c.Token)
+
+	// the context named after bob belongs to another server and is not reused
+	require.NoError(t, kv.AddContext("bob", Context{Server: staging.Server, Login: "bob"}))
+	require.NoError(t, kv.SaveContext("bob", "token-b"))
+
+	name, c, err = kv.GetCurrentContext()
+	require.NoError(t, err)
+	require.Equal(t, "bob@"+prod.Server, name)
+	require.Equal(t, prod.Server, c.Server)
+	require.Equal(t, "bob", c.Login)
+
+	cfg, err := kv.GetConfig()
+	require.NoError(t, err)
+	require.Equal(t, Context{Server: staging.Server, Login: "bob"}, cfg.Contexts["bob"])
+	require.Equal(t, staging, cfg.Contexts["alice"], "a context of another server is left alone")
+}
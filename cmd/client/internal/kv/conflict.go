@@ -0,0 +1,129 @@
+package kv
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rosedblabs/rosedb/v2"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNoConflict indicates that the record has no unresolved conflict.
+var ErrNoConflict = errors.New("no conflict")
+
+// Conflict is an edit whose fields clash with a newer revision on the server.
+// It keeps the three versions a merge needs until the user picks the fields.
+type Conflict struct {
+	VaultID    uint64          `json:"vault_id"`
+	Base       *pb.VaultRecord `json:"-"` // the revision the edit was based on
+	Local      *pb.VaultRecord `json:"-"` // the edit
+	Remote     *pb.VaultRecord `json:"-"` // the revision on the server
+	DetectedAt time.Time       `json:"detected_at"`
+}
+
+// conflictRow is the stored form of Conflict.
+type conflictRow struct {
+	Conflict
+	Base   []byte `json:"base"`
+	Local  []byte `json:"local"`
+	Remote []byte `json:"remote"`
+}
+
+// PutConflict stores a conflict in the current context, replacing an earlier one of the same record.
+func (s *KV) PutConflict(c *Conflict) error {
+	key, err := s.cacheKey(conflictKey(c.VaultID))
+	if err != nil {
+		return err
+	}
+
+	if c.DetectedAt.IsZero() {
+		c.DetectedAt = time.Now()
+	}
+
+	row := conflictRow{Conflict: *c}
+	for _, f := range []struct {
+		dst *[]byte
+		v   *pb.VaultRecord
+	}{{&row.Base, c.Base}, {&row.Local, c.Local}, {&row.Remote, c.Remote}} {
+		if *f.dst, err = proto.Marshal(f.v); err != nil {
+			return errors.Wrap(err, "encode conflict record")
+		}
+	}
+
+	val, err := json.Marshal(row)
+	if err != nil {
+		return errors.Wrap(err, "encode conflict")
+	}
+	return errors.Wrap(s.db.Put(key, val), "put conflict")
+}
+
+// Conflict returns the unresolved conflict of a record in the current context.
+func (s *KV) Conflict(id uint64) (*Conflict, error) {
+	key, err := s.cacheKey(conflictKey(id))
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := s.db.Get(key)
+	if errors.Is(err, rosedb.ErrKeyNotFound) {
+		return nil, ErrNoConflict
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get conflict")
+	}
+	return decodeConflict(val)
+}
+
+// Conflicts returns the unresolved conflicts of the current context, ordered by record ID.
+func (s *KV) Conflicts() ([]Conflict, error) {
+	prefix, err := s.cacheKey("conflict:")
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Conflict
+	err = s.scan(prefix, func(_, val []byte) error {
+		c, err := decodeConflict(val)
+		if err != nil {
+			return err
+		}
+		list = append(list, *c)
+		return nil
+	})
+	return list, err
+}
+
+// DropConflict removes the conflict of a record from the current context.
+func (s *KV) DropConflict(id uint64) error {
+	key, err := s.cacheKey(conflictKey(id))
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(s.db.Delete(key), "delete conflict")
+}
+
+func decodeConflict(val []byte) (*Conflict, error) {
+	var row conflictRow
+	if err := json.Unmarshal(val, &row); err != nil {
+		return nil, errors.Wrap(err, "decode conflict")
+	}
+
+	c := row.Conflict
+	c.Base, c.Local, c.Remote = &pb.VaultRecord{}, &pb.VaultRecord{}, &pb.VaultRecord{}
+	for _, f := range []struct {
+		src []byte
+		v   *pb.VaultRecord
+	}{{row.Base, c.Base}, {row.Local, c.Local}, {row.Remote, c.Remote}} {
+		if err := proto.Unmarshal(f.src, f.v); err != nil {
+			return nil, errors.Wrap(err, "decode conflict record")
+		}
+	}
+	return &c, nil
+}
+
+func conflictKey(id uint64) string {
+	return fmt.Sprintf("conflict:%020d", id)
+}
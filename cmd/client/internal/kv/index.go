@@ -0,0 +1,76 @@
+package kv
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"github.com/rosedblabs/rosedb/v2"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/search"
+)
+
+// IndexEntry returns an entry of the search index of the current context,
+// search.ErrNotIndexed if there is none.
+func (s *KV) IndexEntry(name string) ([]byte, error) {
+	key, err := s.cacheKey(indexKey(name))
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := s.db.Get(key)
+	if errors.Is(err, rosedb.ErrKeyNotFound) {
+		return nil, search.ErrNotIndexed
+	}
+	return val, errors.Wrap(err, "get index entry")
+}
+
+// UpdateIndex puts and drops entries of the search index of the current context in one batch.
+func (s *KV) UpdateIndex(put map[string][]byte, drop []string) error {
+	prefix, err := s.cacheKey(indexKey(""))
+	if err != nil {
+		return err
+	}
+
+	batch := s.db.NewBatch(rosedb.DefaultBatchOptions)
+	for name, val := range put {
+		if err = batch.Put(append(bytes.Clone(prefix), name...), val); err != nil {
+			_ = batch.Rollback()
+			return errors.Wrap(err, "put index entry")
+		}
+	}
+	for _, name := range drop {
+		if err = batch.Delete(append(bytes.Clone(prefix), name...)); err != nil {
+			_ = batch.Rollback()
+			return errors.Wrap(err, "delete index entry")
+		}
+	}
+	return errors.Wrap(batch.Commit(), "commit index")
+}
+
+// ScanIndex calls fn for the entries of the search index of the current
+// context whose names start with prefix, in name order.
+func (s *KV) ScanIndex(prefix string, fn func(name string, val []byte) error) error {
+	base, err := s.cacheKey(indexKey(""))
+	if err != nil {
+		return err
+	}
+	return s.scan(append(bytes.Clone(base), prefix...), func(key, val []byte) error {
+		return fn(string(key[len(base):]), val)
+	})
+}
+
+// DropIndex removes the search index of the current context.
+func (s *KV) DropIndex() error {
+	var names []string
+	err := s.ScanIndex("", func(name string, _ []byte) error {
+		names = append(names, name)
+		return nil
+	})
+	if err != nil || len(names) == 0 {
+		return err
+	}
+	return s.UpdateIndex(nil, names)
+}
+
+func indexKey(name string) string {
+	return "index:" + name
+}
@@ -0,0 +1,61 @@
+package kv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/search"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+)
+
+func TestSearchIndex(t *testing.T) {
+	kv := setupTestKV(t)
+
+	_, err := kv.IndexEntry("doc:1")
+	require.ErrorIs(t, err, ErrEmptyContext, "no context yet")
+
+	require.NoError(t, kv.SetConfig(Config{
+		Current:  "alice",
+		Contexts: map[string]Context{"alice": {Login: "alice"}, "bob": {Login: "bob"}},
+	}))
+
+	_, err = kv.IndexEntry("doc:1")
+	require.ErrorIs(t, err, search.ErrNotIndexed)
+
+	require.NoError(t, kv.UpdateIndex(map[string][]byte{
+		"doc:1":  []byte("one"),
+		"doc:2":  []byte("two"),
+		"term:a": []byte("a"),
+	}, nil))
+	require.NoError(t, kv.CacheVault(&pb.VaultRecord{Id: 1}), "other keys of the context stay")
+
+	val, err := kv.IndexEntry("doc:2")
+	require.NoError(t, err)
+	require.Equal(t, []byte("two"), val)
+
+	var names []string
+	require.NoError(t, kv.ScanIndex("doc:", func(name string, _ []byte) error {
+		names = append(names, name)
+		return nil
+	}))
+	require.Equal(t, []string{"doc:1", "doc:2"}, names)
+
+	require.NoError(t, kv.UpdateIndex(map[string][]byte{"term:b": []byte("b")}, []string{"doc:1"}))
+	_, err = kv.IndexEntry("doc:1")
+	require.ErrorIs(t, err, search.ErrNotIndexed)
+
+	require.NoError(t, kv.UseContext("bob"))
+	_, err = kv.IndexEntry("doc:2")
+	require.ErrorIs(t, err, search.ErrNotIndexed, "every context has its own index")
+
+	require.NoError(t, kv.UseContext("alice"))
+	require.NoError(t, kv.DropIndex())
+	require.NoError(t, kv.ScanIndex("", func(name string, _ []byte) error {
+		t.Fatalf("%s left after drop", name)
+		return nil
+	}))
+
+	cached, err := kv.CachedVaults()
+	require.NoError(t, err)
+	require.Len(t, cached, 1)
+}
@@ -1,13 +1,47 @@
 package kv
 
+import pb "github.com/wickedv43/go-goph-keeper/internal/api"
+
 type Storage interface {
 	SetConfig(cfg Config) error
 	GetConfig() (Config, error)
 
 	SaveContext(login, token string) error
 	SaveKey(login, key string) error
+	SaveRefreshToken(login, refresh string) error
+	SaveContextTokens(name, token, refresh string) error
+	AddContext(name string, c Context) error
 	UseContext(name string) error
 
 	GetCurrentToken() (string, error)
+	GetCurrentRefreshToken() (string, error)
 	GetCurrentKey() (string, error)
+	GetCurrentContext() (string, Context, error)
+
+	CachedVaults() ([]*pb.VaultRecord, error)
+	CachedVault(id uint64) (*pb.VaultRecord, error)
+	CacheVault(v *pb.VaultRecord) error
+	UncacheVault(id uint64) error
+	SyncCursor() (uint64, error)
+	ApplySync(page *pb.SyncResponse) error
+	PinnedUUID(id uint64) (string, error)
+	PinUUID(id uint64, uuid string) error
+
+	PutOutbox(e *OutboxEntry) error
+	Outbox() ([]OutboxEntry, error)
+	DropOutbox(seq uint64) error
+
+	PutConflict(c *Conflict) error
+	Conflict(id uint64) (*Conflict, error)
+	Conflicts() ([]Conflict, error)
+	DropConflict(id uint64) error
+
+	IndexEntry(name string) ([]byte, error)
+	UpdateIndex(put map[string][]byte, drop []string) error
+	ScanIndex(prefix string, fn func(name string, val []byte) error) error
+	DropIndex() error
+
+	PutUpload(u *Upload) error
+	Upload(path string) (*Upload, error)
+	DropUpload(path string) error
 }
@@ -18,7 +18,10 @@ type Card struct {
 	CVV    string `json:"cvv"`
 }
 
-// Binary represents arbitrary binary data.
+// Binary is the encrypted part of a file record. The file itself is a blob
+// uploaded in chunks; records made before blobs hold the file contents instead.
 type Binary struct {
-	Data []byte `json:"data"`
+	Blob   string `json:"blob"`   // reference of the blob on the server
+	Size   int64  `json:"size"`   // plaintext size in bytes
+	Header []byte `json:"header"` // header of the encrypted stream
 }
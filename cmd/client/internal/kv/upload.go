@@ -0,0 +1,74 @@
+package kv
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rosedblabs/rosedb/v2"
+)
+
+// ErrNoUpload indicates that the file has no unfinished upload.
+var ErrNoUpload = errors.New("no upload")
+
+// Upload is an unfinished upload of a file, kept to resume it later. The
+// blob reference and the stream header let the remaining chunks be encrypted
+// exactly like the ones the server already has.
+type Upload struct {
+	Ref     string    `json:"ref"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"` // a changed file starts a new upload
+	Header  []byte    `json:"header"`
+}
+
+// PutUpload stores the upload of u.Path in the current context.
+func (s *KV) PutUpload(u *Upload) error {
+	key, err := s.cacheKey(uploadKey(u.Path))
+	if err != nil {
+		return err
+	}
+
+	val, err := json.Marshal(u)
+	if err != nil {
+		return errors.Wrap(err, "encode upload")
+	}
+	return errors.Wrap(s.db.Put(key, val), "put upload")
+}
+
+// Upload returns the unfinished upload of the file in the current context.
+func (s *KV) Upload(path string) (*Upload, error) {
+	key, err := s.cacheKey(uploadKey(path))
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := s.db.Get(key)
+	if errors.Is(err, rosedb.ErrKeyNotFound) {
+		return nil, ErrNoUpload
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get upload")
+	}
+
+	var u Upload
+	if err = json.Unmarshal(val, &u); err != nil {
+		return nil, errors.Wrap(err, "decode upload")
+	}
+	return &u, nil
+}
+
+// DropUpload forgets the upload of the file in the current context.
+func (s *KV) DropUpload(path string) error {
+	key, err := s.cacheKey(uploadKey(path))
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(s.db.Delete(key), "delete upload")
+}
+
+func uploadKey(path string) string {
+	return fmt.Sprintf("upload:%x", sha256.Sum256([]byte(path)))
+}
@@ -43,15 +43,35 @@ func (m *MockGophKeeperClient) EXPECT() *MockGophKeeperClientMockRecorder {
 	return m.recorder
 }
 
+// ConfirmMFA mocks base method.
+func (m *MockGophKeeperClient) ConfirmMFA(ctx context.Context, in *api.ConfirmMFARequest, opts ...grpc.CallOption) (*api.ConfirmMFAResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ConfirmMFA", varargs...)
+	ret0, _ := ret[0].(*api.ConfirmMFAResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmMFA indicates an expected call of ConfirmMFA.
+func (mr *MockGophKeeperClientMockRecorder) ConfirmMFA(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmMFA", reflect.TypeOf((*MockGophKeeperClient)(nil).ConfirmMFA), varargs...)
+}
+
 // CreateVault mocks base method.
-func (m *MockGophKeeperClient) CreateVault(ctx context.Context, in *api.CreateVaultRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (m *MockGophKeeperClient) CreateVault(ctx context.Context, in *api.CreateVaultRequest, opts ...grpc.CallOption) (*api.VaultRecord, error) {
 	m.ctrl.T.Helper()
 	varargs := []any{ctx, in}
 	for _, a := range opts {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "CreateVault", varargs...)
-	ret0, _ := ret[0].(*emptypb.Empty)
+	ret0, _ := ret[0].(*api.VaultRecord)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -83,6 +103,86 @@ func (mr *MockGophKeeperClientMockRecorder) DeleteVault(ctx, in any, opts ...any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVault", reflect.TypeOf((*MockGophKeeperClient)(nil).DeleteVault), varargs...)
 }
 
+// DownloadBlob mocks base method.
+func (m *MockGophKeeperClient) DownloadBlob(ctx context.Context, in *api.DownloadBlobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[api.BlobPart], error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DownloadBlob", varargs...)
+	ret0, _ := ret[0].(grpc.ServerStreamingClient[api.BlobPart])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadBlob indicates an expected call of DownloadBlob.
+func (mr *MockGophKeeperClientMockRecorder) DownloadBlob(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadBlob", reflect.TypeOf((*MockGophKeeperClient)(nil).DownloadBlob), varargs...)
+}
+
+// EnrollMFA mocks base method.
+func (m *MockGophKeeperClient) EnrollMFA(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*api.EnrollMFAResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EnrollMFA", varargs...)
+	ret0, _ := ret[0].(*api.EnrollMFAResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollMFA indicates an expected call of EnrollMFA.
+func (mr *MockGophKeeperClientMockRecorder) EnrollMFA(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollMFA", reflect.TypeOf((*MockGophKeeperClient)(nil).EnrollMFA), varargs...)
+}
+
+// FindVaults mocks base method.
+func (m *MockGophKeeperClient) FindVaults(ctx context.Context, in *api.FindVaultsRequest, opts ...grpc.CallOption) (*api.ListVaultsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindVaults", varargs...)
+	ret0, _ := ret[0].(*api.ListVaultsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVaults indicates an expected call of FindVaults.
+func (mr *MockGophKeeperClientMockRecorder) FindVaults(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVaults", reflect.TypeOf((*MockGophKeeperClient)(nil).FindVaults), varargs...)
+}
+
+// GetUpload mocks base method.
+func (m *MockGophKeeperClient) GetUpload(ctx context.Context, in *api.GetUploadRequest, opts ...grpc.CallOption) (*api.UploadStatus, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetUpload", varargs...)
+	ret0, _ := ret[0].(*api.UploadStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUpload indicates an expected call of GetUpload.
+func (mr *MockGophKeeperClientMockRecorder) GetUpload(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpload", reflect.TypeOf((*MockGophKeeperClient)(nil).GetUpload), varargs...)
+}
+
 // GetVault mocks base method.
 func (m *MockGophKeeperClient) GetVault(ctx context.Context, in *api.GetVaultRequest, opts ...grpc.CallOption) (*api.VaultRecord, error) {
 	m.ctrl.T.Helper()
@@ -103,6 +203,86 @@ func (mr *MockGophKeeperClientMockRecorder) GetVault(ctx, in any, opts ...any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVault", reflect.TypeOf((*MockGophKeeperClient)(nil).GetVault), varargs...)
 }
 
+// ListSessions mocks base method.
+func (m *MockGophKeeperClient) ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*api.ListSessionsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListSessions", varargs...)
+	ret0, _ := ret[0].(*api.ListSessionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockGophKeeperClientMockRecorder) ListSessions(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockGophKeeperClient)(nil).ListSessions), varargs...)
+}
+
+// ListTrash mocks base method.
+func (m *MockGophKeeperClient) ListTrash(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*api.ListVaultsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListTrash", varargs...)
+	ret0, _ := ret[0].(*api.ListVaultsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockGophKeeperClientMockRecorder) ListTrash(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockGophKeeperClient)(nil).ListTrash), varargs...)
+}
+
+// ListVaultSummaries mocks base method.
+func (m *MockGophKeeperClient) ListVaultSummaries(ctx context.Context, in *api.ListVaultsRequest, opts ...grpc.CallOption) (*api.ListVaultSummariesResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListVaultSummaries", varargs...)
+	ret0, _ := ret[0].(*api.ListVaultSummariesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVaultSummaries indicates an expected call of ListVaultSummaries.
+func (mr *MockGophKeeperClientMockRecorder) ListVaultSummaries(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaultSummaries", reflect.TypeOf((*MockGophKeeperClient)(nil).ListVaultSummaries), varargs...)
+}
+
+// ListVaultVersions mocks base method.
+func (m *MockGophKeeperClient) ListVaultVersions(ctx context.Context, in *api.ListVaultVersionsRequest, opts ...grpc.CallOption) (*api.ListVaultVersionsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListVaultVersions", varargs...)
+	ret0, _ := ret[0].(*api.ListVaultVersionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVaultVersions indicates an expected call of ListVaultVersions.
+func (mr *MockGophKeeperClientMockRecorder) ListVaultVersions(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaultVersions", reflect.TypeOf((*MockGophKeeperClient)(nil).ListVaultVersions), varargs...)
+}
+
 // ListVaults mocks base method.
 func (m *MockGophKeeperClient) ListVaults(ctx context.Context, in *api.ListVaultsRequest, opts ...grpc.CallOption) (*api.ListVaultsResponse, error) {
 	m.ctrl.T.Helper()
@@ -143,6 +323,66 @@ func (mr *MockGophKeeperClientMockRecorder) Login(ctx, in any, opts ...any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockGophKeeperClient)(nil).Login), varargs...)
 }
 
+// Logout mocks base method.
+func (m *MockGophKeeperClient) Logout(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Logout", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockGophKeeperClientMockRecorder) Logout(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockGophKeeperClient)(nil).Logout), varargs...)
+}
+
+// PurgeVault mocks base method.
+func (m *MockGophKeeperClient) PurgeVault(ctx context.Context, in *api.PurgeVaultRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PurgeVault", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeVault indicates an expected call of PurgeVault.
+func (mr *MockGophKeeperClientMockRecorder) PurgeVault(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeVault", reflect.TypeOf((*MockGophKeeperClient)(nil).PurgeVault), varargs...)
+}
+
+// RefreshToken mocks base method.
+func (m *MockGophKeeperClient) RefreshToken(ctx context.Context, in *api.RefreshTokenRequest, opts ...grpc.CallOption) (*api.LoginResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RefreshToken", varargs...)
+	ret0, _ := ret[0].(*api.LoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockGophKeeperClientMockRecorder) RefreshToken(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockGophKeeperClient)(nil).RefreshToken), varargs...)
+}
+
 // Register mocks base method.
 func (m *MockGophKeeperClient) Register(ctx context.Context, in *api.RegisterRequest, opts ...grpc.CallOption) (*api.RegisterResponse, error) {
 	m.ctrl.T.Helper()
@@ -163,6 +403,86 @@ func (mr *MockGophKeeperClientMockRecorder) Register(ctx, in any, opts ...any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockGophKeeperClient)(nil).Register), varargs...)
 }
 
+// RestoreVault mocks base method.
+func (m *MockGophKeeperClient) RestoreVault(ctx context.Context, in *api.RestoreVaultRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RestoreVault", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreVault indicates an expected call of RestoreVault.
+func (mr *MockGophKeeperClientMockRecorder) RestoreVault(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVault", reflect.TypeOf((*MockGophKeeperClient)(nil).RestoreVault), varargs...)
+}
+
+// RestoreVaultVersion mocks base method.
+func (m *MockGophKeeperClient) RestoreVaultVersion(ctx context.Context, in *api.RestoreVaultVersionRequest, opts ...grpc.CallOption) (*api.VaultRecord, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RestoreVaultVersion", varargs...)
+	ret0, _ := ret[0].(*api.VaultRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreVaultVersion indicates an expected call of RestoreVaultVersion.
+func (mr *MockGophKeeperClientMockRecorder) RestoreVaultVersion(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVaultVersion", reflect.TypeOf((*MockGophKeeperClient)(nil).RestoreVaultVersion), varargs...)
+}
+
+// RevokeSession mocks base method.
+func (m *MockGophKeeperClient) RevokeSession(ctx context.Context, in *api.RevokeSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RevokeSession", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockGophKeeperClientMockRecorder) RevokeSession(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockGophKeeperClient)(nil).RevokeSession), varargs...)
+}
+
+// Sync mocks base method.
+func (m *MockGophKeeperClient) Sync(ctx context.Context, in *api.SyncRequest, opts ...grpc.CallOption) (*api.SyncResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Sync", varargs...)
+	ret0, _ := ret[0].(*api.SyncResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockGophKeeperClientMockRecorder) Sync(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockGophKeeperClient)(nil).Sync), varargs...)
+}
+
 // UpdateVault mocks base method.
 func (m *MockGophKeeperClient) UpdateVault(ctx context.Context, in *api.VaultRecord, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	m.ctrl.T.Helper()
@@ -183,6 +503,66 @@ func (mr *MockGophKeeperClientMockRecorder) UpdateVault(ctx, in any, opts ...any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVault", reflect.TypeOf((*MockGophKeeperClient)(nil).UpdateVault), varargs...)
 }
 
+// UploadBlob mocks base method.
+func (m *MockGophKeeperClient) UploadBlob(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[api.BlobPart, api.UploadStatus], error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadBlob", varargs...)
+	ret0, _ := ret[0].(grpc.ClientStreamingClient[api.BlobPart, api.UploadStatus])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadBlob indicates an expected call of UploadBlob.
+func (mr *MockGophKeeperClientMockRecorder) UploadBlob(ctx any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadBlob", reflect.TypeOf((*MockGophKeeperClient)(nil).UploadBlob), varargs...)
+}
+
+// VerifyMFA mocks base method.
+func (m *MockGophKeeperClient) VerifyMFA(ctx context.Context, in *api.VerifyMFARequest, opts ...grpc.CallOption) (*api.LoginResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VerifyMFA", varargs...)
+	ret0, _ := ret[0].(*api.LoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyMFA indicates an expected call of VerifyMFA.
+func (mr *MockGophKeeperClientMockRecorder) VerifyMFA(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyMFA", reflect.TypeOf((*MockGophKeeperClient)(nil).VerifyMFA), varargs...)
+}
+
+// Watch mocks base method.
+func (m *MockGophKeeperClient) Watch(ctx context.Context, in *api.WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[api.VaultEvent], error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Watch", varargs...)
+	ret0, _ := ret[0].(grpc.ServerStreamingClient[api.VaultEvent])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockGophKeeperClientMockRecorder) Watch(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockGophKeeperClient)(nil).Watch), varargs...)
+}
+
 // MockGophKeeperServer is a mock of GophKeeperServer interface.
 type MockGophKeeperServer struct {
 	ctrl     *gomock.Controller
@@ -207,11 +587,26 @@ func (m *MockGophKeeperServer) EXPECT() *MockGophKeeperServerMockRecorder {
 	return m.recorder
 }
 
+// ConfirmMFA mocks base method.
+func (m *MockGophKeeperServer) ConfirmMFA(arg0 context.Context, arg1 *api.ConfirmMFARequest) (*api.ConfirmMFAResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmMFA", arg0, arg1)
+	ret0, _ := ret[0].(*api.ConfirmMFAResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmMFA indicates an expected call of ConfirmMFA.
+func (mr *MockGophKeeperServerMockRecorder) ConfirmMFA(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmMFA", reflect.TypeOf((*MockGophKeeperServer)(nil).ConfirmMFA), arg0, arg1)
+}
+
 // CreateVault mocks base method.
-func (m *MockGophKeeperServer) CreateVault(arg0 context.Context, arg1 *api.CreateVaultRequest) (*emptypb.Empty, error) {
+func (m *MockGophKeeperServer) CreateVault(arg0 context.Context, arg1 *api.CreateVaultRequest) (*api.VaultRecord, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateVault", arg0, arg1)
-	ret0, _ := ret[0].(*emptypb.Empty)
+	ret0, _ := ret[0].(*api.VaultRecord)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -237,6 +632,65 @@ func (mr *MockGophKeeperServerMockRecorder) DeleteVault(arg0, arg1 any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVault", reflect.TypeOf((*MockGophKeeperServer)(nil).DeleteVault), arg0, arg1)
 }
 
+// DownloadBlob mocks base method.
+func (m *MockGophKeeperServer) DownloadBlob(arg0 *api.DownloadBlobRequest, arg1 grpc.ServerStreamingServer[api.BlobPart]) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadBlob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DownloadBlob indicates an expected call of DownloadBlob.
+func (mr *MockGophKeeperServerMockRecorder) DownloadBlob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadBlob", reflect.TypeOf((*MockGophKeeperServer)(nil).DownloadBlob), arg0, arg1)
+}
+
+// EnrollMFA mocks base method.
+func (m *MockGophKeeperServer) EnrollMFA(arg0 context.Context, arg1 *emptypb.Empty) (*api.EnrollMFAResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollMFA", arg0, arg1)
+	ret0, _ := ret[0].(*api.EnrollMFAResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollMFA indicates an expected call of EnrollMFA.
+func (mr *MockGophKeeperServerMockRecorder) EnrollMFA(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollMFA", reflect.TypeOf((*MockGophKeeperServer)(nil).EnrollMFA), arg0, arg1)
+}
+
+// FindVaults mocks base method.
+func (m *MockGophKeeperServer) FindVaults(arg0 context.Context, arg1 *api.FindVaultsRequest) (*api.ListVaultsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVaults", arg0, arg1)
+	ret0, _ := ret[0].(*api.ListVaultsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVaults indicates an expected call of FindVaults.
+func (mr *MockGophKeeperServerMockRecorder) FindVaults(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVaults", reflect.TypeOf((*MockGophKeeperServer)(nil).FindVaults), arg0, arg1)
+}
+
+// GetUpload mocks base method.
+func (m *MockGophKeeperServer) GetUpload(arg0 context.Context, arg1 *api.GetUploadRequest) (*api.UploadStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUpload", arg0, arg1)
+	ret0, _ := ret[0].(*api.UploadStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUpload indicates an expected call of GetUpload.
+func (mr *MockGophKeeperServerMockRecorder) GetUpload(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpload", reflect.TypeOf((*MockGophKeeperServer)(nil).GetUpload), arg0, arg1)
+}
+
 // GetVault mocks base method.
 func (m *MockGophKeeperServer) GetVault(arg0 context.Context, arg1 *api.GetVaultRequest) (*api.VaultRecord, error) {
 	m.ctrl.T.Helper()
@@ -252,6 +706,66 @@ func (mr *MockGophKeeperServerMockRecorder) GetVault(arg0, arg1 any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVault", reflect.TypeOf((*MockGophKeeperServer)(nil).GetVault), arg0, arg1)
 }
 
+// ListSessions mocks base method.
+func (m *MockGophKeeperServer) ListSessions(arg0 context.Context, arg1 *emptypb.Empty) (*api.ListSessionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", arg0, arg1)
+	ret0, _ := ret[0].(*api.ListSessionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockGophKeeperServerMockRecorder) ListSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockGophKeeperServer)(nil).ListSessions), arg0, arg1)
+}
+
+// ListTrash mocks base method.
+func (m *MockGophKeeperServer) ListTrash(arg0 context.Context, arg1 *emptypb.Empty) (*api.ListVaultsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", arg0, arg1)
+	ret0, _ := ret[0].(*api.ListVaultsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockGophKeeperServerMockRecorder) ListTrash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockGophKeeperServer)(nil).ListTrash), arg0, arg1)
+}
+
+// ListVaultSummaries mocks base method.
+func (m *MockGophKeeperServer) ListVaultSummaries(arg0 context.Context, arg1 *api.ListVaultsRequest) (*api.ListVaultSummariesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVaultSummaries", arg0, arg1)
+	ret0, _ := ret[0].(*api.ListVaultSummariesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVaultSummaries indicates an expected call of ListVaultSummaries.
+func (mr *MockGophKeeperServerMockRecorder) ListVaultSummaries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaultSummaries", reflect.TypeOf((*MockGophKeeperServer)(nil).ListVaultSummaries), arg0, arg1)
+}
+
+// ListVaultVersions mocks base method.
+func (m *MockGophKeeperServer) ListVaultVersions(arg0 context.Context, arg1 *api.ListVaultVersionsRequest) (*api.ListVaultVersionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVaultVersions", arg0, arg1)
+	ret0, _ := ret[0].(*api.ListVaultVersionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVaultVersions indicates an expected call of ListVaultVersions.
+func (mr *MockGophKeeperServerMockRecorder) ListVaultVersions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaultVersions", reflect.TypeOf((*MockGophKeeperServer)(nil).ListVaultVersions), arg0, arg1)
+}
+
 // ListVaults mocks base method.
 func (m *MockGophKeeperServer) ListVaults(arg0 context.Context, arg1 *api.ListVaultsRequest) (*api.ListVaultsResponse, error) {
 	m.ctrl.T.Helper()
@@ -282,6 +796,51 @@ func (mr *MockGophKeeperServerMockRecorder) Login(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockGophKeeperServer)(nil).Login), arg0, arg1)
 }
 
+// Logout mocks base method.
+func (m *MockGophKeeperServer) Logout(arg0 context.Context, arg1 *emptypb.Empty) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", arg0, arg1)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockGophKeeperServerMockRecorder) Logout(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockGophKeeperServer)(nil).Logout), arg0, arg1)
+}
+
+// PurgeVault mocks base method.
+func (m *MockGophKeeperServer) PurgeVault(arg0 context.Context, arg1 *api.PurgeVaultRequest) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeVault", arg0, arg1)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeVault indicates an expected call of PurgeVault.
+func (mr *MockGophKeeperServerMockRecorder) PurgeVault(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeVault", reflect.TypeOf((*MockGophKeeperServer)(nil).PurgeVault), arg0, arg1)
+}
+
+// RefreshToken mocks base method.
+func (m *MockGophKeeperServer) RefreshToken(arg0 context.Context, arg1 *api.RefreshTokenRequest) (*api.LoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(*api.LoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockGophKeeperServerMockRecorder) RefreshToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockGophKeeperServer)(nil).RefreshToken), arg0, arg1)
+}
+
 // Register mocks base method.
 func (m *MockGophKeeperServer) Register(arg0 context.Context, arg1 *api.RegisterRequest) (*api.RegisterResponse, error) {
 	m.ctrl.T.Helper()
@@ -297,6 +856,66 @@ func (mr *MockGophKeeperServerMockRecorder) Register(arg0, arg1 any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockGophKeeperServer)(nil).Register), arg0, arg1)
 }
 
+// RestoreVault mocks base method.
+func (m *MockGophKeeperServer) RestoreVault(arg0 context.Context, arg1 *api.RestoreVaultRequest) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreVault", arg0, arg1)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreVault indicates an expected call of RestoreVault.
+func (mr *MockGophKeeperServerMockRecorder) RestoreVault(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVault", reflect.TypeOf((*MockGophKeeperServer)(nil).RestoreVault), arg0, arg1)
+}
+
+// RestoreVaultVersion mocks base method.
+func (m *MockGophKeeperServer) RestoreVaultVersion(arg0 context.Context, arg1 *api.RestoreVaultVersionRequest) (*api.VaultRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreVaultVersion", arg0, arg1)
+	ret0, _ := ret[0].(*api.VaultRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreVaultVersion indicates an expected call of RestoreVaultVersion.
+func (mr *MockGophKeeperServerMockRecorder) RestoreVaultVersion(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVaultVersion", reflect.TypeOf((*MockGophKeeperServer)(nil).RestoreVaultVersion), arg0, arg1)
+}
+
+// RevokeSession mocks base method.
+func (m *MockGophKeeperServer) RevokeSession(arg0 context.Context, arg1 *api.RevokeSessionRequest) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", arg0, arg1)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockGophKeeperServerMockRecorder) RevokeSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockGophKeeperServer)(nil).RevokeSession), arg0, arg1)
+}
+
+// Sync mocks base method.
+func (m *MockGophKeeperServer) Sync(arg0 context.Context, arg1 *api.SyncRequest) (*api.SyncResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", arg0, arg1)
+	ret0, _ := ret[0].(*api.SyncResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockGophKeeperServerMockRecorder) Sync(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockGophKeeperServer)(nil).Sync), arg0, arg1)
+}
+
 // UpdateVault mocks base method.
 func (m *MockGophKeeperServer) UpdateVault(arg0 context.Context, arg1 *api.VaultRecord) (*emptypb.Empty, error) {
 	m.ctrl.T.Helper()
@@ -312,6 +931,49 @@ func (mr *MockGophKeeperServerMockRecorder) UpdateVault(arg0, arg1 any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVault", reflect.TypeOf((*MockGophKeeperServer)(nil).UpdateVault), arg0, arg1)
 }
 
+// UploadBlob mocks base method.
+func (m *MockGophKeeperServer) UploadBlob(arg0 grpc.ClientStreamingServer[api.BlobPart, api.UploadStatus]) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadBlob", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadBlob indicates an expected call of UploadBlob.
+func (mr *MockGophKeeperServerMockRecorder) UploadBlob(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadBlob", reflect.TypeOf((*MockGophKeeperServer)(nil).UploadBlob), arg0)
+}
+
+// VerifyMFA mocks base method.
+func (m *MockGophKeeperServer) VerifyMFA(arg0 context.Context, arg1 *api.VerifyMFARequest) (*api.LoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyMFA", arg0, arg1)
+	ret0, _ := ret[0].(*api.LoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyMFA indicates an expected call of VerifyMFA.
+func (mr *MockGophKeeperServerMockRecorder) VerifyMFA(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyMFA", reflect.TypeOf((*MockGophKeeperServer)(nil).VerifyMFA), arg0, arg1)
+}
+
+// Watch mocks base method.
+func (m *MockGophKeeperServer) Watch(arg0 *api.WatchRequest, arg1 grpc.ServerStreamingServer[api.VaultEvent]) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockGophKeeperServerMockRecorder) Watch(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockGophKeeperServer)(nil).Watch), arg0, arg1)
+}
+
 // mustEmbedUnimplementedGophKeeperServer mocks base method.
 func (m *MockGophKeeperServer) mustEmbedUnimplementedGophKeeperServer() {
 	m.ctrl.T.Helper()
@@ -13,6 +13,7 @@ import (
 	reflect "reflect"
 
 	kv "github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+	api "github.com/wickedv43/go-goph-keeper/internal/api"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +41,164 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 	return m.recorder
 }
 
+// AddContext mocks base method.
+func (m *MockStorage) AddContext(name string, c kv.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddContext", name, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddContext indicates an expected call of AddContext.
+func (mr *MockStorageMockRecorder) AddContext(name, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddContext", reflect.TypeOf((*MockStorage)(nil).AddContext), name, c)
+}
+
+// ApplySync mocks base method.
+func (m *MockStorage) ApplySync(page *api.SyncResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplySync", page)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplySync indicates an expected call of ApplySync.
+func (mr *MockStorageMockRecorder) ApplySync(page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplySync", reflect.TypeOf((*MockStorage)(nil).ApplySync), page)
+}
+
+// CacheVault mocks base method.
+func (m *MockStorage) CacheVault(v *api.VaultRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheVault", v)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CacheVault indicates an expected call of CacheVault.
+func (mr *MockStorageMockRecorder) CacheVault(v any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheVault", reflect.TypeOf((*MockStorage)(nil).CacheVault), v)
+}
+
+// CachedVault mocks base method.
+func (m *MockStorage) CachedVault(id uint64) (*api.VaultRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CachedVault", id)
+	ret0, _ := ret[0].(*api.VaultRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CachedVault indicates an expected call of CachedVault.
+func (mr *MockStorageMockRecorder) CachedVault(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CachedVault", reflect.TypeOf((*MockStorage)(nil).CachedVault), id)
+}
+
+// CachedVaults mocks base method.
+func (m *MockStorage) CachedVaults() ([]*api.VaultRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CachedVaults")
+	ret0, _ := ret[0].([]*api.VaultRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CachedVaults indicates an expected call of CachedVaults.
+func (mr *MockStorageMockRecorder) CachedVaults() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CachedVaults", reflect.TypeOf((*MockStorage)(nil).CachedVaults))
+}
+
+// Conflict mocks base method.
+func (m *MockStorage) Conflict(id uint64) (*kv.Conflict, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Conflict", id)
+	ret0, _ := ret[0].(*kv.Conflict)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Conflict indicates an expected call of Conflict.
+func (mr *MockStorageMockRecorder) Conflict(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Conflict", reflect.TypeOf((*MockStorage)(nil).Conflict), id)
+}
+
+// Conflicts mocks base method.
+func (m *MockStorage) Conflicts() ([]kv.Conflict, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Conflicts")
+	ret0, _ := ret[0].([]kv.Conflict)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Conflicts indicates an expected call of Conflicts.
+func (mr *MockStorageMockRecorder) Conflicts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Conflicts", reflect.TypeOf((*MockStorage)(nil).Conflicts))
+}
+
+// DropConflict mocks base method.
+func (m *MockStorage) DropConflict(id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropConflict", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropConflict indicates an expected call of DropConflict.
+func (mr *MockStorageMockRecorder) DropConflict(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropConflict", reflect.TypeOf((*MockStorage)(nil).DropConflict), id)
+}
+
+// DropIndex mocks base method.
+func (m *MockStorage) DropIndex() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropIndex")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropIndex indicates an expected call of DropIndex.
+func (mr *MockStorageMockRecorder) DropIndex() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropIndex", reflect.TypeOf((*MockStorage)(nil).DropIndex))
+}
+
+// DropOutbox mocks base method.
+func (m *MockStorage) DropOutbox(seq uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropOutbox", seq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropOutbox indicates an expected call of DropOutbox.
+func (mr *MockStorageMockRecorder) DropOutbox(seq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropOutbox", reflect.TypeOf((*MockStorage)(nil).DropOutbox), seq)
+}
+
+// DropUpload mocks base method.
+func (m *MockStorage) DropUpload(path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropUpload", path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropUpload indicates an expected call of DropUpload.
+func (mr *MockStorageMockRecorder) DropUpload(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropUpload", reflect.TypeOf((*MockStorage)(nil).DropUpload), path)
+}
+
 // GetConfig mocks base method.
 func (m *MockStorage) GetConfig() (kv.Config, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +214,22 @@ func (mr *MockStorageMockRecorder) GetConfig() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockStorage)(nil).GetConfig))
 }
 
+// GetCurrentContext mocks base method.
+func (m *MockStorage) GetCurrentContext() (string, kv.Context, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentContext")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(kv.Context)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCurrentContext indicates an expected call of GetCurrentContext.
+func (mr *MockStorageMockRecorder) GetCurrentContext() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentContext", reflect.TypeOf((*MockStorage)(nil).GetCurrentContext))
+}
+
 // GetCurrentKey mocks base method.
 func (m *MockStorage) GetCurrentKey() (string, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +245,21 @@ func (mr *MockStorageMockRecorder) GetCurrentKey() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentKey", reflect.TypeOf((*MockStorage)(nil).GetCurrentKey))
 }
 
+// GetCurrentRefreshToken mocks base method.
+func (m *MockStorage) GetCurrentRefreshToken() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentRefreshToken")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentRefreshToken indicates an expected call of GetCurrentRefreshToken.
+func (mr *MockStorageMockRecorder) GetCurrentRefreshToken() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentRefreshToken", reflect.TypeOf((*MockStorage)(nil).GetCurrentRefreshToken))
+}
+
 // GetCurrentToken mocks base method.
 func (m *MockStorage) GetCurrentToken() (string, error) {
 	m.ctrl.T.Helper()
@@ -85,6 +275,107 @@ func (mr *MockStorageMockRecorder) GetCurrentToken() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentToken", reflect.TypeOf((*MockStorage)(nil).GetCurrentToken))
 }
 
+// IndexEntry mocks base method.
+func (m *MockStorage) IndexEntry(name string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IndexEntry", name)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IndexEntry indicates an expected call of IndexEntry.
+func (mr *MockStorageMockRecorder) IndexEntry(name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexEntry", reflect.TypeOf((*MockStorage)(nil).IndexEntry), name)
+}
+
+// Outbox mocks base method.
+func (m *MockStorage) Outbox() ([]kv.OutboxEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Outbox")
+	ret0, _ := ret[0].([]kv.OutboxEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Outbox indicates an expected call of Outbox.
+func (mr *MockStorageMockRecorder) Outbox() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Outbox", reflect.TypeOf((*MockStorage)(nil).Outbox))
+}
+
+// PinUUID mocks base method.
+func (m *MockStorage) PinUUID(id uint64, uuid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinUUID", id, uuid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PinUUID indicates an expected call of PinUUID.
+func (mr *MockStorageMockRecorder) PinUUID(id, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinUUID", reflect.TypeOf((*MockStorage)(nil).PinUUID), id, uuid)
+}
+
+// PinnedUUID mocks base method.
+func (m *MockStorage) PinnedUUID(id uint64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinnedUUID", id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PinnedUUID indicates an expected call of PinnedUUID.
+func (mr *MockStorageMockRecorder) PinnedUUID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinnedUUID", reflect.TypeOf((*MockStorage)(nil).PinnedUUID), id)
+}
+
+// PutConflict mocks base method.
+func (m *MockStorage) PutConflict(c *kv.Conflict) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutConflict", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutConflict indicates an expected call of PutConflict.
+func (mr *MockStorageMockRecorder) PutConflict(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutConflict", reflect.TypeOf((*MockStorage)(nil).PutConflict), c)
+}
+
+// PutOutbox mocks base method.
+func (m *MockStorage) PutOutbox(e *kv.OutboxEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutOutbox", e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutOutbox indicates an expected call of PutOutbox.
+func (mr *MockStorageMockRecorder) PutOutbox(e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutOutbox", reflect.TypeOf((*MockStorage)(nil).PutOutbox), e)
+}
+
+// PutUpload mocks base method.
+func (m *MockStorage) PutUpload(u *kv.Upload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutUpload", u)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutUpload indicates an expected call of PutUpload.
+func (mr *MockStorageMockRecorder) PutUpload(u any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutUpload", reflect.TypeOf((*MockStorage)(nil).PutUpload), u)
+}
+
 // SaveContext mocks base method.
 func (m *MockStorage) SaveContext(login, token string) error {
 	m.ctrl.T.Helper()
@@ -99,6 +390,20 @@ func (mr *MockStorageMockRecorder) SaveContext(login, token any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveContext", reflect.TypeOf((*MockStorage)(nil).SaveContext), login, token)
 }
 
+// SaveContextTokens mocks base method.
+func (m *MockStorage) SaveContextTokens(name, token, refresh string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveContextTokens", name, token, refresh)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveContextTokens indicates an expected call of SaveContextTokens.
+func (mr *MockStorageMockRecorder) SaveContextTokens(name, token, refresh any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveContextTokens", reflect.TypeOf((*MockStorage)(nil).SaveContextTokens), name, token, refresh)
+}
+
 // SaveKey mocks base method.
 func (m *MockStorage) SaveKey(login, key string) error {
 	m.ctrl.T.Helper()
@@ -113,6 +418,34 @@ func (mr *MockStorageMockRecorder) SaveKey(login, key any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKey", reflect.TypeOf((*MockStorage)(nil).SaveKey), login, key)
 }
 
+// SaveRefreshToken mocks base method.
+func (m *MockStorage) SaveRefreshToken(login, refresh string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRefreshToken", login, refresh)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRefreshToken indicates an expected call of SaveRefreshToken.
+func (mr *MockStorageMockRecorder) SaveRefreshToken(login, refresh any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefreshToken", reflect.TypeOf((*MockStorage)(nil).SaveRefreshToken), login, refresh)
+}
+
+// ScanIndex mocks base method.
+func (m *MockStorage) ScanIndex(prefix string, fn func(string, []byte) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanIndex", prefix, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScanIndex indicates an expected call of ScanIndex.
+func (mr *MockStorageMockRecorder) ScanIndex(prefix, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanIndex", reflect.TypeOf((*MockStorage)(nil).ScanIndex), prefix, fn)
+}
+
 // SetConfig mocks base method.
 func (m *MockStorage) SetConfig(cfg kv.Config) error {
 	m.ctrl.T.Helper()
@@ -127,6 +460,64 @@ func (mr *MockStorageMockRecorder) SetConfig(cfg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConfig", reflect.TypeOf((*MockStorage)(nil).SetConfig), cfg)
 }
 
+// SyncCursor mocks base method.
+func (m *MockStorage) SyncCursor() (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncCursor")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncCursor indicates an expected call of SyncCursor.
+func (mr *MockStorageMockRecorder) SyncCursor() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncCursor", reflect.TypeOf((*MockStorage)(nil).SyncCursor))
+}
+
+// UncacheVault mocks base method.
+func (m *MockStorage) UncacheVault(id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UncacheVault", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UncacheVault indicates an expected call of UncacheVault.
+func (mr *MockStorageMockRecorder) UncacheVault(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UncacheVault", reflect.TypeOf((*MockStorage)(nil).UncacheVault), id)
+}
+
+// UpdateIndex mocks base method.
+func (m *MockStorage) UpdateIndex(put map[string][]byte, drop []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateIndex", put, drop)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateIndex indicates an expected call of UpdateIndex.
+func (mr *MockStorageMockRecorder) UpdateIndex(put, drop any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIndex", reflect.TypeOf((*MockStorage)(nil).UpdateIndex), put, drop)
+}
+
+// Upload mocks base method.
+func (m *MockStorage) Upload(path string) (*kv.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", path)
+	ret0, _ := ret[0].(*kv.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockStorageMockRecorder) Upload(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockStorage)(nil).Upload), path)
+}
+
 // UseContext mocks base method.
 func (m *MockStorage) UseContext(name string) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,283 @@
+// Package search keeps an encrypted inverted index of the vault records on
+// the client. The server only stores ciphertext, so this is the only place
+// where the records can be searched.
+package search
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+)
+
+// ErrNotIndexed is returned by a Store for a missing entry.
+var ErrNotIndexed = errors.New("not indexed")
+
+// Store keeps the entries of the index. Entry values are encrypted by the index.
+type Store interface {
+	// IndexEntry returns the entry name or ErrNotIndexed.
+	IndexEntry(name string) ([]byte, error)
+	// UpdateIndex puts and drops entries at once.
+	UpdateIndex(put map[string][]byte, drop []string) error
+	// ScanIndex calls fn for the entries whose names start with prefix.
+	ScanIndex(prefix string, fn func(name string, val []byte) error) error
+	// DropIndex removes all entries.
+	DropIndex() error
+}
+
+// Doc is a decrypted record as the index sees it: the title and the text of
+// the searchable fields.
+type Doc struct {
+	ID     uint64
+	Type   string
+	Title  string
+	Fields map[string][]string // field name to its values
+}
+
+// Index is the search index of one vault. It is stored as two kinds of entries:
+//
+//	doc:<id>    the terms of a record, to unindex them when it changes
+//	term:<mac>  the records containing a term, keyed by its HMAC
+//
+// Both are sealed with AES-256-GCM, so the store sees neither the terms nor
+// which records share them. Keys are derived from the seed with HKDF and are
+// separate from the key of the records.
+type Index struct {
+	store Store
+	mac   []byte
+	aead  cipher.AEAD
+}
+
+// macInfo and sealInfo separate the index keys from other keys derived from the seed.
+const (
+	macInfo  = "gophkeeper search mac v1"
+	sealInfo = "gophkeeper search seal v1"
+)
+
+// New returns the index in the store for the vault of the seed.
+func New(store Store, seedHex string) (*Index, error) {
+	mac, err := crypto.DeriveKey(seedHex, nil, macInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.DeriveKey(seedHex, nil, sealInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &Index{store: store, mac: mac}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if x.aead, err = cipher.NewGCM(block); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// docEntry is the stored form of a Doc.
+type docEntry struct {
+	Type  string              `json:"type"`
+	Title string              `json:"title"`
+	Terms map[string][]string `json:"terms"` // field name to its terms
+}
+
+// posting is an occurrence of a term in a field of a record.
+type posting struct {
+	ID    uint64 `json:"id"`
+	Field string `json:"f"`
+}
+
+// termEntry is the stored form of a term. It keeps the term itself, which
+// fuzzy matching compares the query with.
+type termEntry struct {
+	Term     string    `json:"term"`
+	Postings []posting `json:"postings"`
+}
+
+// Put indexes the record, replacing what was indexed for it before.
+func (x *Index) Put(d Doc) error {
+	entry := docEntry{Type: d.Type, Title: d.Title, Terms: make(map[string][]string)}
+	for field, values := range d.Fields {
+		for _, v := range values {
+			entry.Terms[field] = append(entry.Terms[field], Tokenize(v)...)
+		}
+	}
+	if d.Type != "" {
+		entry.Terms[FieldType] = []string{d.Type}
+	}
+	return x.update(d.ID, &entry)
+}
+
+// Remove unindexes the record.
+func (x *Index) Remove(id uint64) error {
+	return x.update(id, nil)
+}
+
+// Rebuild replaces the whole index with the records.
+func (x *Index) Rebuild(docs []Doc) error {
+	if err := x.store.DropIndex(); err != nil {
+		return err
+	}
+	for _, d := range docs {
+		if err := x.Put(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Empty reports whether no record is indexed.
+func (x *Index) Empty() (bool, error) {
+	empty := true
+	err := x.store.ScanIndex(docPrefix, func(string, []byte) error {
+		empty = false
+		return errStop
+	})
+	if errors.Is(err, errStop) {
+		err = nil
+	}
+	return empty, err
+}
+
+// errStop ends a scan early.
+var errStop = errors.New("stop")
+
+// update moves the postings of the record from the terms it had to the terms
+// of entry, nil to unindex it, and stores everything in one update.
+func (x *Index) update(id uint64, entry *docEntry) error {
+	var old docEntry
+	if err := x.get(docName(id), &old); err != nil && !errors.Is(err, ErrNotIndexed) {
+		return err
+	}
+
+	// the fields of every term before and after
+	type change struct{ before, after []string }
+	changes := make(map[string]*change)
+	add := func(terms map[string][]string, after bool) {
+		for field, list := range terms {
+			for _, t := range list {
+				c := changes[t]
+				if c == nil {
+					c = &change{}
+					changes[t] = c
+				}
+				if after {
+					c.after = append(c.after, field)
+				} else {
+					c.before = append(c.before, field)
+				}
+			}
+		}
+	}
+	add(old.Terms, false)
+	if entry != nil {
+		add(entry.Terms, true)
+	}
+
+	put := make(map[string][]byte)
+	var drop []string
+	for term, c := range changes {
+		slices.Sort(c.before)
+		c.before = slices.Compact(c.before)
+		slices.Sort(c.after)
+		c.after = slices.Compact(c.after)
+		if slices.Equal(c.before, c.after) {
+			continue
+		}
+
+		name := x.termName(term)
+		te := termEntry{Term: term}
+		if err := x.get(name, &te); err != nil && !errors.Is(err, ErrNotIndexed) {
+			return err
+		}
+		te.Postings = slices.DeleteFunc(te.Postings, func(p posting) bool { return p.ID == id })
+		for _, field := range c.after {
+			te.Postings = append(te.Postings, posting{ID: id, Field: field})
+		}
+
+		if len(te.Postings) == 0 {
+			drop = append(drop, name)
+			continue
+		}
+		val, err := x.seal(&te)
+		if err != nil {
+			return err
+		}
+		put[name] = val
+	}
+
+	if entry == nil {
+		drop = append(drop, docName(id))
+	} else {
+		val, err := x.seal(entry)
+		if err != nil {
+			return err
+		}
+		put[docName(id)] = val
+	}
+	return x.store.UpdateIndex(put, drop)
+}
+
+// get reads and opens an entry into v.
+func (x *Index) get(name string, v any) error {
+	val, err := x.store.IndexEntry(name)
+	if err != nil {
+		return err
+	}
+	return x.open(val, v)
+}
+
+// seal encrypts v with a random nonce in front.
+func (x *Index) seal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, x.aead.NonceSize(), x.aead.NonceSize()+len(data)+x.aead.Overhead())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return x.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// open decrypts a sealed entry into v.
+func (x *Index) open(val []byte, v any) error {
+	n := x.aead.NonceSize()
+	if len(val) < n {
+		return errors.New("search index entry is too short")
+	}
+	data, err := x.aead.Open(nil, val[:n], val[n:], nil)
+	if err != nil {
+		return errors.Wrap(err, "open search index entry")
+	}
+	return json.Unmarshal(data, v)
+}
+
+// termName returns the name of the entry of a term.
+func (x *Index) termName(term string) string {
+	mac := hmac.New(sha256.New, x.mac)
+	mac.Write([]byte(term))
+	return termPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+const (
+	docPrefix  = "doc:"
+	termPrefix = "term:"
+)
+
+// docName pads the ID so that docs are stored in ID order.
+func docName(id uint64) string {
+	return fmt.Sprintf("%s%020d", docPrefix, id)
+}
@@ -0,0 +1,191 @@
+package search
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSeed = "6368616e676520746869732070617373"
+
+// memStore is a Store in a map.
+type memStore map[string][]byte
+
+func (m memStore) IndexEntry(name string) ([]byte, error) {
+	val, ok := m[name]
+	if !ok {
+		return nil, ErrNotIndexed
+	}
+	return val, nil
+}
+
+func (m memStore) UpdateIndex(put map[string][]byte, drop []string) error {
+	for name, val := range put {
+		m[name] = val
+	}
+	for _, name := range drop {
+		delete(m, name)
+	}
+	return nil
+}
+
+func (m memStore) ScanIndex(prefix string, fn func(name string, val []byte) error) error {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := fn(name, m[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m memStore) DropIndex() error {
+	clear(m)
+	return nil
+}
+
+func newTestIndex(t *testing.T) (*Index, memStore) {
+	store := memStore{}
+	x, err := New(store, testSeed)
+	require.NoError(t, err)
+	return x, store
+}
+
+func ids(results []Result) []uint64 {
+	var list []uint64
+	for _, r := range results {
+		list = append(list, r.ID)
+	}
+	return list
+}
+
+func TestIndex(t *testing.T) {
+	x, store := newTestIndex(t)
+
+	empty, err := x.Empty()
+	require.NoError(t, err)
+	require.True(t, empty)
+
+	require.NoError(t, x.Put(Doc{ID: 1, Type: "login", Title: "GitHub", Fields: map[string][]string{
+		FieldTitle: {"GitHub"},
+		FieldLogin: {"octocat"},
+		FieldURL:   {"https://github.com/login"},
+	}}))
+	require.NoError(t, x.Put(Doc{ID: 2, Type: "note", Title: "Wi-Fi", Fields: map[string][]string{
+		FieldTitle: {"Wi-Fi"},
+		FieldNote:  {"password of the github office network"},
+		FieldTag:   {"place", "office"},
+	}}))
+	require.NoError(t, x.Put(Doc{ID: 3, Type: "card", Title: "Visa", Fields: map[string][]string{
+		FieldTitle: {"Visa"},
+	}}))
+
+	empty, err = x.Empty()
+	require.NoError(t, err)
+	require.False(t, empty)
+
+	t.Run("entries are sealed", func(t *testing.T) {
+		for name, val := range store {
+			require.False(t, bytes.Contains(val, []byte("octocat")), name)
+			require.NotContains(t, name, "github")
+		}
+	})
+
+	t.Run("queries", func(t *testing.T) {
+		for query, want := range map[string][]uint64{
+			"github":               {1, 2},
+			"GITHUB octocat":       {1},
+			"url:github.com":       {1},
+			"note:github":          {2},
+			"type:card":            {3},
+			"type:car":             nil,
+			"tag:office":           {2},
+			"octo":                 {1},
+			"githbu":               {1, 2},
+			"offise type:note":     {2},
+			"visa type:login":      nil,
+			"nothing":              nil,
+			"   ":                  nil,
+			"title:wi fi":          {2},
+			"unknown:github":       nil,
+			"https github.com/log": {1},
+		} {
+			results, err := x.Search(query)
+			require.NoError(t, err, query)
+			require.Equal(t, want, ids(results), query)
+		}
+	})
+
+	t.Run("better matches first", func(t *testing.T) {
+		results, err := x.Search("github")
+		require.NoError(t, err)
+		require.Equal(t, Result{ID: 1, Type: "login", Title: "GitHub", Score: scoreExact}, results[0])
+
+		results, err = x.Search("gith")
+		require.NoError(t, err)
+		require.Equal(t, []uint64{1, 2}, ids(results), "equal scores by ID")
+	})
+
+	t.Run("put replaces the record", func(t *testing.T) {
+		require.NoError(t, x.Put(Doc{ID: 1, Type: "login", Title: "GitLab", Fields: map[string][]string{
+			FieldTitle: {"GitLab"},
+			FieldURL:   {"https://gitlab.com"},
+		}}))
+
+		results, err := x.Search("url:github")
+		require.NoError(t, err)
+		require.Empty(t, results)
+
+		results, err = x.Search("gitlab")
+		require.NoError(t, err)
+		require.Equal(t, []uint64{1}, ids(results))
+		require.Equal(t, "GitLab", results[0].Title)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		require.NoError(t, x.Remove(2))
+		require.NoError(t, x.Remove(2), "removing twice is fine")
+
+		results, err := x.Search("office")
+		require.NoError(t, err)
+		require.Empty(t, results)
+
+		_, ok := store[docName(2)]
+		require.False(t, ok)
+	})
+
+	t.Run("rebuild", func(t *testing.T) {
+		require.NoError(t, x.Rebuild([]Doc{{ID: 9, Type: "note", Title: "todo", Fields: map[string][]string{FieldTitle: {"todo"}}}}))
+
+		results, err := x.Search("gitlab")
+		require.NoError(t, err)
+		require.Empty(t, results)
+
+		results, err = x.Search("todo")
+		require.NoError(t, err)
+		require.Equal(t, []uint64{9}, ids(results))
+	})
+
+	t.Run("another seed cannot read it", func(t *testing.T) {
+		other, err := New(store, "00112233445566778899aabbccddeeff")
+		require.NoError(t, err)
+		_, err = other.Search("todo")
+		require.Error(t, err)
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(memStore{}, "not hex")
+	require.Error(t, err)
+	_, err = New(memStore{}, "abcd")
+	require.Error(t, err)
+}
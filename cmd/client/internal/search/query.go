@@ -0,0 +1,205 @@
+package search
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Fields of the index. A query qualifies a word with one of them, for
+// example type:card or url:github.com.
+const (
+	FieldType  = "type"
+	FieldTitle = "title"
+	FieldLogin = "login"
+	FieldURL   = "url"
+	FieldNote  = "note"
+	FieldTag   = "tag"
+)
+
+var fields = []string{FieldType, FieldTitle, FieldLogin, FieldURL, FieldNote, FieldTag}
+
+// Match scores, summed over the words of a query.
+const (
+	scoreExact  = 3
+	scorePrefix = 2
+	scoreFuzzy  = 1
+)
+
+// Result is a record matching a query.
+type Result struct {
+	ID    uint64
+	Type  string
+	Title string
+	Score int
+}
+
+// term is a word of a query, possibly limited to a field.
+type term struct {
+	field string
+	text  string
+}
+
+// Search returns the records matching every word of the query, best matches
+// first. A word matches a term that equals it, starts with it or, for words
+// of four letters and more, differs from it by a typo or two. A word
+// qualified with a field, field:value, only matches the terms of that field.
+func (x *Index) Search(query string) ([]Result, error) {
+	terms := parseQuery(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var vocab []termEntry
+	err := x.store.ScanIndex(termPrefix, func(_ string, val []byte) error {
+		var te termEntry
+		if err := x.open(val, &te); err != nil {
+			return err
+		}
+		vocab = append(vocab, te)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var scores map[uint64]int
+	for _, q := range terms {
+		best := make(map[uint64]int)
+		for _, te := range vocab {
+			score := matchTerm(q, te.Term)
+			if score == 0 {
+				continue
+			}
+			for _, p := range te.Postings {
+				if q.field == "" || q.field == p.Field {
+					best[p.ID] = max(best[p.ID], score)
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = best
+			continue
+		}
+		for id, score := range scores {
+			if b, ok := best[id]; ok {
+				scores[id] = score + b
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		var d docEntry
+		if err = x.get(docName(id), &d); err != nil {
+			return nil, err
+		}
+		results = append(results, Result{ID: id, Type: d.Type, Title: d.Title, Score: score})
+	}
+	slices.SortFunc(results, func(a, b Result) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return results, nil
+}
+
+// parseQuery splits the query into terms the way Put splits the fields.
+func parseQuery(query string) []term {
+	var terms []term
+	for _, word := range strings.Fields(query) {
+		field, value, ok := strings.Cut(word, ":")
+		field = strings.ToLower(field)
+		if !ok || !slices.Contains(fields, field) {
+			field, value = "", word
+		}
+		for _, t := range Tokenize(value) {
+			terms = append(terms, term{field: field, text: t})
+		}
+	}
+	return terms
+}
+
+// matchTerm scores how well the word matches an indexed term, 0 for no match.
+// Types are matched exactly.
+func matchTerm(q term, indexed string) int {
+	switch {
+	case q.text == indexed:
+		return scoreExact
+	case q.field == FieldType:
+		return 0
+	case strings.HasPrefix(indexed, q.text):
+		return scorePrefix
+	}
+
+	word := []rune(q.text)
+	typos := 0
+	switch {
+	case len(word) >= 8:
+		typos = 2
+	case len(word) >= 4:
+		typos = 1
+	}
+	if typos > 0 && distance(word, []rune(indexed), typos) <= typos {
+		return scoreFuzzy
+	}
+	return 0
+}
+
+// distance returns the edit distance of a and b, counting a swap of adjacent
+// letters as one edit (optimal string alignment), or limit+1 once it is
+// known to exceed limit.
+func distance(a, b []rune, limit int) int {
+	if d := len(a) - len(b); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	// rows i-2, i-1 and i of the distance matrix
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		least := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			least = min(least, cur[j])
+		}
+		if least > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// Tokenize splits text into lower-case words of letters and digits, each once.
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(words))
+	list := words[:0]
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			list = append(list, w)
+		}
+	}
+	return list
+}
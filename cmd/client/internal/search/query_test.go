@@ -0,0 +1,52 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	require.Equal(t, []string{"https", "github", "com", "login"}, Tokenize("https://GitHub.com/login?login"))
+	require.Equal(t, []string{"почта", "2fa"}, Tokenize("Почта (2FA)"))
+	require.Empty(t, Tokenize(" -- "))
+}
+
+func TestParseQuery(t *testing.T) {
+	require.Equal(t, []term{
+		{field: FieldType, text: "card"},
+		{field: FieldURL, text: "github"},
+		{field: FieldURL, text: "com"},
+		{text: "bank"},
+		{text: "foo"},
+		{text: "bar"},
+	}, parseQuery("TYPE:card url:github.com bank foo:bar"))
+}
+
+func TestMatchTerm(t *testing.T) {
+	for _, tc := range []struct {
+		query   term
+		indexed string
+		want    int
+	}{
+		{term{text: "github"}, "github", scoreExact},
+		{term{text: "git"}, "github", scorePrefix},
+		{term{text: "githib"}, "github", scoreFuzzy},
+		{term{text: "gihtub"}, "github", scoreFuzzy},
+		{term{text: "gthb"}, "github", 0},
+		{term{text: "passwrod"}, "password", scoreFuzzy},
+		{term{text: "cat"}, "cut", 0},
+		{term{field: FieldType, text: "car"}, "card", 0},
+		{term{field: FieldType, text: "card"}, "card", scoreExact},
+	} {
+		require.Equal(t, tc.want, matchTerm(tc.query, tc.indexed), "%s ~ %s", tc.query.text, tc.indexed)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	require.Equal(t, 0, distance([]rune("abc"), []rune("abc"), 2))
+	require.Equal(t, 1, distance([]rune("abc"), []rune("abd"), 2))
+	require.Equal(t, 1, distance([]rune("abcd"), []rune("abdc"), 2), "a swap is one edit")
+	require.Equal(t, 3, distance([]rune("kitten"), []rune("sitting"), 3))
+	require.Equal(t, 3, distance([]rune("a"), []rune("abcdef"), 2), "cut off above the limit")
+}
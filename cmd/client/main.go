@@ -50,12 +50,25 @@ func main() {
 	gophKeeper.rootCmd = rootCmd
 
 	gophKeeper.rootCmd.AddCommand(gophKeeper.LoginCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.LogoutCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.MFACMD())
 	gophKeeper.rootCmd.AddCommand(gophKeeper.NewVaultCMD())
 	gophKeeper.rootCmd.AddCommand(gophKeeper.VaultListCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.SearchCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.FindCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.SyncCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.ResealCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.ConflictsCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.VaultEditCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.VaultHistoryCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.VaultRestoreCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.TrashCMD())
 
 	//ctx
 	gophKeeper.rootCmd.AddCommand(gophKeeper.ContextListCMD())
 	gophKeeper.rootCmd.AddCommand(gophKeeper.ContextUseCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.ContextCMD())
+	gophKeeper.rootCmd.AddCommand(gophKeeper.SessionsCMD())
 
 	gophKeeper.Start()
 
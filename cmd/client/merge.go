@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+	"google.golang.org/protobuf/proto"
+)
+
+// errUnmergeable means that two versions of a record cannot be merged field by field.
+var errUnmergeable = errors.New("запись нельзя объединить по полям")
+
+// mergeFields lists the fields of the record types that are merged field by
+// field, in the order they are shown. Other types, such as binary, are only
+// ever replaced as a whole.
+var mergeFields = map[string][]string{
+	"login": {"title", "metadata", "login", "password"},
+	"card":  {"title", "metadata", "number", "date", "cvv"},
+	"note":  {"title", "metadata", "text"},
+}
+
+// fieldConflict is a field changed differently on both sides of a merge.
+type fieldConflict struct {
+	Field  string
+	Local  string
+	Remote string
+}
+
+// merge is a three-way merge of a record. Fields changed on one side only are
+// taken from that side; a conflicting field holds the remote value until the
+// user picks one with set.
+type merge struct {
+	remote    *pb.VaultRecord
+	fields    map[string]string
+	Conflicts []fieldConflict
+}
+
+// mergeRecords merges the local edit of base with the remote revision of the
+// same record. It returns errUnmergeable for record types without fields.
+func mergeRecords(base, local, remote *pb.VaultRecord, key recordKey) (*merge, error) {
+	names, ok := mergeFields[remote.Type]
+	if !ok || base == nil || base.Type != remote.Type || local.Type != remote.Type {
+		return nil, errUnmergeable
+	}
+
+	var sides [3]map[string]string
+	for i, v := range []*pb.VaultRecord{base, local, remote} {
+		f, err := openFields(v, key)
+		if err != nil {
+			return nil, err
+		}
+		sides[i] = f
+	}
+	b, l, r := sides[0], sides[1], sides[2]
+
+	m := &merge{remote: remote, fields: make(map[string]string, len(names))}
+	for _, name := range names {
+		switch {
+		case l[name] == r[name], l[name] == b[name]:
+			m.fields[name] = r[name]
+		case r[name] == b[name]:
+			m.fields[name] = l[name]
+		default:
+			m.fields[name] = r[name]
+			m.Conflicts = append(m.Conflicts, fieldConflict{Field: name, Local: l[name], Remote: r[name]})
+		}
+	}
+
+	return m, nil
+}
+
+// set picks the value of a field.
+func (m *merge) set(field, value string) {
+	m.fields[field] = value
+}
+
+// seal returns the merged record, encrypted and based on the remote revision.
+func (m *merge) seal(key recordKey, alg crypto.Alg) (*pb.VaultRecord, error) {
+	var (
+		payload any
+		f       = m.fields
+	)
+	switch m.remote.Type {
+	case "login":
+		payload = kv.LoginPass{Login: f["login"], Password: f["password"]}
+	case "card":
+		payload = kv.Card{Number: f["number"], Date: f["date"], CVV: f["cvv"]}
+	case "note":
+		payload = kv.Note{Text: f["text"]}
+	default:
+		return nil, errUnmergeable
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	v := proto.Clone(m.remote).(*pb.VaultRecord)
+	v.Title = f["title"]
+	v.Metadata = f["metadata"]
+	if err = sealRecord(v, data, key, alg); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// openFields decrypts a record and returns its fields by name.
+func openFields(v *pb.VaultRecord, key recordKey) (map[string]string, error) {
+	data, err := openRecord(v, key)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось расшифровать запись %d: %w", v.Id, err)
+	}
+
+	f := map[string]string{"title": v.Title, "metadata": v.Metadata}
+	switch v.Type {
+	case "login":
+		var d kv.LoginPass
+		err = json.Unmarshal(data, &d)
+		f["login"], f["password"] = d.Login, d.Password
+	case "card":
+		var d kv.Card
+		err = json.Unmarshal(data, &d)
+		f["number"], f["date"], f["cvv"] = d.Number, d.Date, d.CVV
+	case "note":
+		var d kv.Note
+		err = json.Unmarshal(data, &d)
+		f["text"] = d.Text
+	default:
+		return nil, errUnmergeable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось разобрать запись %d: %w", v.Id, err)
+	}
+
+	return f, nil
+}
+
+// reconcile handles an edit of base rejected because the record has a newer
+// revision on the server. A clean merge is saved right away. Fields changed on
+// both sides are stored as a conflict, which is returned for the user to
+// resolve. A record that cannot be merged is kept twice: the edit is saved as
+// a new record next to the server version.
+func (g *GophKeeper) reconcile(out io.Writer, base, local *pb.VaultRecord) (*kv.Conflict, error) {
+	key, err := currentRecordKey(g.storage)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		remote, err := g.VaultGet(local.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := mergeRecords(base, local, remote, key)
+		if errors.Is(err, errUnmergeable) {
+			return nil, g.keepBoth(out, local)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(m.Conflicts) > 0 {
+			c := &kv.Conflict{VaultID: local.Id, Base: base, Local: local, Remote: remote}
+			return c, g.storage.PutConflict(c)
+		}
+
+		merged, err := m.seal(key, g.recordCipher())
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = g.VaultUpdate(merged)
+		if _, ok := revisionConflict(err); ok {
+			continue // changed once more in the meantime
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(out, "🔀 Запись %d изменили на другом устройстве — изменения объединены.\n", local.Id)
+		return nil, nil
+	}
+}
+
+// keepBoth saves a local edit that cannot be merged as a new record.
+func (g *GophKeeper) keepBoth(out io.Writer, local *pb.VaultRecord) error {
+	cp := proto.Clone(local).(*pb.VaultRecord)
+	cp.Id, cp.Revision, cp.Uuid = 0, 0, ""
+	cp.Title += " (конфликт)"
+
+	// the contents are bound to the UUID of the original
+	key, err := currentRecordKey(g.storage)
+	if err != nil {
+		return err
+	}
+	data, err := openRecord(local, key)
+	if err != nil {
+		return fmt.Errorf("не удалось расшифровать запись %d: %w", local.Id, err)
+	}
+	if err = sealRecord(cp, data, key, g.recordCipher()); err != nil {
+		return err
+	}
+
+	if _, err = g.VaultCreate(cp); err != nil {
+		return fmt.Errorf("не удалось сохранить копию записи %d: %w", local.Id, err)
+	}
+
+	fmt.Fprintf(out, "⚠️ Запись %d изменили на другом устройстве, а объединить её по полям нельзя. "+
+		"Ваша версия сохранена отдельной записью %q.\n", local.Id, cp.Title)
+	return nil
+}
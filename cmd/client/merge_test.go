@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+)
+
+func TestMergeRecords(t *testing.T) {
+	login := func(title, user, pass string, revision uint64) *pb.VaultRecord {
+		data, err := json.Marshal(kv.LoginPass{Login: user, Password: pass})
+		require.NoError(t, err)
+		v := &pb.VaultRecord{Id: 3, Type: "login", Title: title, Revision: revision}
+		require.NoError(t, sealRecord(v, data, testRecordKey, crypto.AES256GCM))
+		return v
+	}
+
+	tests := []struct {
+		name      string
+		local     *pb.VaultRecord
+		remote    *pb.VaultRecord
+		want      kv.LoginPass
+		wantTitle string
+		conflicts []fieldConflict
+	}{
+		{
+			name:      "different fields",
+			local:     login("mail", "bob", "new", 1),
+			remote:    login("work mail", "alice", "old", 2),
+			want:      kv.LoginPass{Login: "bob", Password: "new"},
+			wantTitle: "work mail",
+		},
+		{
+			name:      "same change on both sides",
+			local:     login("mail", "alice", "same", 1),
+			remote:    login("mail", "alice", "same", 2),
+			want:      kv.LoginPass{Login: "alice", Password: "same"},
+			wantTitle: "mail",
+		},
+		{
+			name:      "clashing field",
+			local:     login("mail", "alice", "mine", 1),
+			remote:    login("mail", "carol", "theirs", 2),
+			want:      kv.LoginPass{Login: "carol", Password: "theirs"},
+			wantTitle: "mail",
+			conflicts: []fieldConflict{{Field: "password", Local: "mine", Remote: "theirs"}},
+		},
+	}
+
+	base := login("mail", "alice", "old", 1)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := mergeRecords(base, tt.local, tt.remote, testRecordKey)
+			require.NoError(t, err)
+			require.Equal(t, tt.conflicts, m.Conflicts)
+
+			v, err := m.seal(testRecordKey, crypto.AES256GCM)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantTitle, v.Title)
+			require.Equal(t, uint64(2), v.Revision, "based on the remote revision")
+
+			data, err := openRecord(v, testRecordKey)
+			require.NoError(t, err)
+			var got kv.LoginPass
+			require.NoError(t, json.Unmarshal(data, &got))
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("pick local value", func(t *testing.T) {
+		m, err := mergeRecords(base, login("mail", "alice", "mine", 1), login("mail", "alice", "theirs", 2), testRecordKey)
+		require.NoError(t, err)
+		m.set("password", "mine")
+
+		v, err := m.seal(testRecordKey, crypto.AES256GCM)
+		require.NoError(t, err)
+		data, err := openRecord(v, testRecordKey)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"login":"alice","password":"mine"}`, string(data))
+	})
+
+	t.Run("unmergeable", func(t *testing.T) {
+		bin := &pb.VaultRecord{Id: 3, Type: "binary"}
+		_, err := mergeRecords(bin, bin, bin, testRecordKey)
+		require.ErrorIs(t, err, errUnmergeable)
+
+		_, err = mergeRecords(nil, base, base, testRecordKey)
+		require.ErrorIs(t, err, errUnmergeable, "no common ancestor")
+	})
+}
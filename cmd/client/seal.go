@@ -0,0 +1,282 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+	"google.golang.org/protobuf/proto"
+)
+
+// recordKey is what the contents of the records of a context are sealed
+// with: the seed, and the owner the contents are bound to along with the
+// identity of the record. pins, when set, holds the UUIDs the records of the
+// context were seen with, see kv.KV.PinnedUUID.
+type recordKey struct {
+	seed  string
+	owner string
+	pins  uuidPins
+}
+
+// uuidPins looks up the UUID a record was first seen with.
+type uuidPins interface {
+	PinnedUUID(id uint64) (string, error)
+}
+
+// pinned returns the UUID record id was first seen with, if any.
+func (k recordKey) pinned(id uint64) (string, error) {
+	if k.pins == nil {
+		return "", nil
+	}
+	return k.pins.PinnedUUID(id)
+}
+
+// currentRecordKey returns the record key of the current context.
+func currentRecordKey(storage kv.Storage) (recordKey, error) {
+	name, c, err := storage.GetCurrentContext()
+	if err != nil {
+		return recordKey{}, err
+	}
+	if c.Key == "" {
+		return recordKey{}, kv.ErrEmptyKey
+	}
+	return recordKey{seed: c.Key, owner: c.Owner(name), pins: storage}, nil
+}
+
+// recordCipher returns the cipher new record contents are sealed with.
+func (g *GophKeeper) recordCipher() crypto.Alg {
+	if g.cipher == 0 {
+		return crypto.AES256GCM
+	}
+	return g.cipher
+}
+
+// sealRecord encrypts data as the contents of v, bound to its UUID, type and
+// owner. A record without a UUID, a new one or one made before UUIDs, gets one.
+func sealRecord(v *pb.VaultRecord, data []byte, key recordKey, alg crypto.Alg) error {
+	if v.Uuid == "" {
+		id, err := newUUID()
+		if err != nil {
+			return err
+		}
+		v.Uuid = id
+	}
+
+	sealed, err := crypto.Seal(data, key.seed, alg, crypto.RecordAAD(v.Uuid, v.Type, key.owner))
+	if err != nil {
+		return err
+	}
+	v.EncryptedData = sealed
+	return nil
+}
+
+// errLegacyRecord is returned for the contents of a record made before UUIDs
+// that are not bound to its ID: they are only read by reseal.
+var errLegacyRecord = errors.New("запись создана старой версией клиента, выполните gk reseal")
+
+// openRecord decrypts the contents of v and checks that they belong to it.
+// Contents of a record with a UUID must be bound to it; anything else was
+// moved from another record. A record once seen with a UUID must keep it: a
+// copy without it, or with another one, is refused. Records made before UUIDs
+// are read bound to their server ID; older contents, bound to ID 0 or not
+// bound at all, are only read by reseal, see openLegacyRecord.
+func openRecord(v *pb.VaultRecord, key recordKey) ([]byte, error) {
+	if err := checkPin(v, key); err != nil {
+		return nil, err
+	}
+	if !crypto.IsEnvelope(v.EncryptedData) {
+		if v.Uuid == "" {
+			return nil, errLegacyRecord
+		}
+		return nil, crypto.ErrNotAuthentic
+	}
+	if v.Uuid != "" {
+		return crypto.Open(v.EncryptedData, key.seed, crypto.RecordAAD(v.Uuid, v.Type, key.owner))
+	}
+
+	data, err := crypto.Open(v.EncryptedData, key.seed, crypto.RecordIDAAD(v.Id, v.Type))
+	if errors.Is(err, crypto.ErrNotAuthentic) {
+		return nil, errLegacyRecord
+	}
+	if errors.Is(err, crypto.ErrWrongKey) {
+		// contents sealed before envelopes may happen to start like one
+		if _, legacyErr := crypto.OpenLegacy(v.EncryptedData, key.seed); legacyErr == nil {
+			return nil, errLegacyRecord
+		}
+	}
+	return data, err
+}
+
+// openLegacyRecord decrypts the contents of a record made before UUIDs the
+// way openRecord does and, failing that, the way the oldest clients sealed
+// them: bound to ID 0 on creation or, before envelopes, not bound at all.
+// Such contents may have been moved from another record, so they are only
+// read to be bound to a UUID right away, once per record; a record seen with
+// a UUID is never read this way.
+func openLegacyRecord(v *pb.VaultRecord, key recordKey) ([]byte, error) {
+	data, err := openRecord(v, key)
+	if !errors.Is(err, errLegacyRecord) {
+		return data, err
+	}
+	if data, err = crypto.Open(v.EncryptedData, key.seed, crypto.RecordIDAAD(0, v.Type)); err == nil {
+		return data, nil
+	}
+	if data, legacyErr := crypto.OpenLegacy(v.EncryptedData, key.seed); legacyErr == nil {
+		return data, nil
+	}
+	return nil, err
+}
+
+// checkPin refuses v if its record was seen with another UUID.
+func checkPin(v *pb.VaultRecord, key recordKey) error {
+	pinned, err := key.pinned(v.Id)
+	if err != nil {
+		return err
+	}
+	if pinned != "" && pinned != v.Uuid {
+		return crypto.ErrNotAuthentic
+	}
+	return nil
+}
+
+// recordMeta is what the sealed metadata of a record holds.
+type recordMeta struct {
+	Title    string `json:"title"`
+	Metadata string `json:"metadata"`
+}
+
+// sealMeta returns a copy of v with its title and metadata sealed into
+// SealedMeta, bound like the contents, and left empty in plain text. v must
+// have a UUID, see sealRecord.
+func sealMeta(v *pb.VaultRecord, key recordKey, alg crypto.Alg) (*pb.VaultRecord, error) {
+	if v.Uuid == "" {
+		return nil, errors.New("у записи нет UUID")
+	}
+	data, err := json.Marshal(recordMeta{Title: v.Title, Metadata: v.Metadata})
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := proto.Clone(v).(*pb.VaultRecord)
+	sealed.SealedMeta, err = crypto.Seal(data, key.seed, alg, crypto.MetaAAD(v.Uuid, v.Type, key.owner))
+	if err != nil {
+		return nil, err
+	}
+	sealed.Title, sealed.Metadata = "", "{}" // the server keeps metadata as JSON
+	return sealed, nil
+}
+
+// openMeta returns the title and metadata of v, decrypting them if they are sealed.
+func openMeta(v *pb.VaultRecord, key recordKey) (recordMeta, error) {
+	if len(v.SealedMeta) == 0 {
+		return recordMeta{Title: v.Title, Metadata: v.Metadata}, nil
+	}
+	if v.Uuid == "" {
+		return recordMeta{}, crypto.ErrNotAuthentic
+	}
+
+	data, err := crypto.Open(v.SealedMeta, key.seed, crypto.MetaAAD(v.Uuid, v.Type, key.owner))
+	if err != nil {
+		return recordMeta{}, err
+	}
+	var m recordMeta
+	if err = json.Unmarshal(data, &m); err != nil {
+		return recordMeta{}, errors.Wrap(err, "decode sealed metadata")
+	}
+	return m, nil
+}
+
+// revealMeta puts the sealed title and metadata of v in place of the plain
+// ones and drops the sealed copy, so the record is shown and edited as any
+// other; see GophKeeper.concealed for the way back.
+func revealMeta(v *pb.VaultRecord, key recordKey) error {
+	m, err := openMeta(v, key)
+	if err != nil {
+		return err
+	}
+	v.Title, v.Metadata, v.SealedMeta = m.Title, m.Metadata, nil
+	return nil
+}
+
+// rebind re-seals the contents of a record made before UUIDs bound to a new
+// UUID, so it is protected like any other from then on. Records that have a
+// UUID are left as they are. Only the contents openRecord accepts are
+// rebound; the older ones wait for reseal, see resealRecord.
+func rebind(v *pb.VaultRecord, key recordKey, alg crypto.Alg) error {
+	if v.Uuid != "" {
+		return nil
+	}
+	data, err := openRecord(v, key)
+	if err != nil {
+		return err
+	}
+	return sealRecord(v, data, key, alg)
+}
+
+// resealRecord is rebind for reseal: it also reads the contents sealed by the
+// oldest clients, see openLegacyRecord.
+func resealRecord(v *pb.VaultRecord, key recordKey, alg crypto.Alg) error {
+	if v.Uuid != "" {
+		return nil
+	}
+	data, err := openLegacyRecord(v, key)
+	if err != nil {
+		return err
+	}
+	return sealRecord(v, data, key, alg)
+}
+
+// concealed returns v as it is sent to the server: with the title and
+// metadata sealed when private metadata is on. The contents of a record made
+// before UUIDs are bound to one first, in v itself, as the metadata is sealed
+// bound to it.
+func (g *GophKeeper) concealed(v *pb.VaultRecord) (*pb.VaultRecord, error) {
+	if !g.privateMeta || len(v.SealedMeta) > 0 {
+		return v, nil
+	}
+	key, err := currentRecordKey(g.storage)
+	if err != nil {
+		return nil, err
+	}
+	if err = rebind(v, key, g.recordCipher()); err != nil {
+		return nil, err
+	}
+	return sealMeta(v, key, g.recordCipher())
+}
+
+// reveal opens the sealed titles and metadata of the records in place. The
+// key is only read if some record has them.
+func (g *GophKeeper) reveal(list ...*pb.VaultRecord) error {
+	var key *recordKey
+	for _, v := range list {
+		if len(v.SealedMeta) == 0 {
+			continue
+		}
+		if key == nil {
+			k, err := currentRecordKey(g.storage)
+			if err != nil {
+				return err
+			}
+			key = &k
+		}
+		if err := revealMeta(v, *key); err != nil {
+			return fmt.Errorf("не удалось расшифровать название записи %d: %w", v.Id, err)
+		}
+	}
+	return nil
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
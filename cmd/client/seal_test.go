@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// sealUnbound encrypts data the way clients did before envelopes, with the
+// nonce starting with prefix.
+func sealUnbound(t *testing.T, data []byte, prefix ...byte) []byte {
+	seed, err := hex.DecodeString(testKey)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(seed[:16])
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	nonce := make([]byte, gcm.NonceSize())
+	copy(nonce, prefix)
+	return gcm.Seal(nonce, nonce, data, nil)
+}
+
+func TestSealRecord(t *testing.T) {
+	t.Run("bound to the record", func(t *testing.T) {
+		v := &pb.VaultRecord{Type: "note"}
+		require.NoError(t, sealRecord(v, []byte("text"), testRecordKey, crypto.XChaCha20Poly1305))
+		require.Len(t, v.Uuid, 36, "a new record gets a UUID")
+
+		v.Id = 3
+		data, err := openRecord(v, testRecordKey)
+		require.NoError(t, err)
+		require.Equal(t, []byte("text"), data)
+
+		other := &pb.VaultRecord{Type: "note"}
+		require.NoError(t, sealRecord(other, []byte("other"), testRecordKey, crypto.XChaCha20Poly1305))
+		require.NotEqual(t, v.Uuid, other.Uuid)
+
+		moved := &pb.VaultRecord{Id: 4, Uuid: other.Uuid, Type: "note", EncryptedData: v.EncryptedData}
+		_, err = openRecord(moved, testRecordKey)
+		require.ErrorIs(t, err, crypto.ErrNotAuthentic)
+
+		retyped := &pb.VaultRecord{Id: 3, Uuid: v.Uuid, Type: "login", EncryptedData: v.EncryptedData}
+		_, err = openRecord(retyped, testRecordKey)
+		require.ErrorIs(t, err, crypto.ErrNotAuthentic)
+
+		_, err = openRecord(v, recordKey{seed: testKey, owner: "bob"})
+		require.ErrorIs(t, err, crypto.ErrNotAuthentic)
+	})
+
+	t.Run("keeps the UUID", func(t *testing.T) {
+		v := &pb.VaultRecord{Id: 3, Uuid: "0b5e5e9c-7a47-4a8e-9e3c-1d1f2a3b4c5d", Type: "note"}
+		require.NoError(t, sealRecord(v, []byte("text"), testRecordKey, crypto.AES256GCM))
+		require.Equal(t, "0b5e5e9c-7a47-4a8e-9e3c-1d1f2a3b4c5d", v.Uuid)
+	})
+
+	t.Run("records made before UUIDs", func(t *testing.T) {
+		sealed, err := crypto.Seal([]byte("text"), testKey, crypto.AES256GCM, crypto.RecordIDAAD(9, "note"))
+		require.NoError(t, err)
+		data, err := openRecord(&pb.VaultRecord{Id: 9, Type: "note", EncryptedData: sealed}, testRecordKey)
+		require.NoError(t, err)
+		require.Equal(t, []byte("text"), data)
+
+		_, err = openRecord(&pb.VaultRecord{Id: 8, Type: "note", EncryptedData: sealed}, testRecordKey)
+		require.ErrorIs(t, err, errLegacyRecord, "bound to another ID")
+
+		_, err = openRecord(&pb.VaultRecord{Id: 9, Uuid: testUUID(9), Type: "note", EncryptedData: sealed}, testRecordKey)
+		require.ErrorIs(t, err, crypto.ErrNotAuthentic, "a record with a UUID only opens bound to it")
+	})
+
+	t.Run("oldest records only opened by reseal", func(t *testing.T) {
+		unbound := sealUnbound(t, []byte("text"))
+		lookalike := sealUnbound(t, []byte("text"), 1, byte(crypto.AES256GCM))
+		require.True(t, crypto.IsEnvelope(lookalike))
+		sealed, err := crypto.Seal([]byte("text"), testKey, crypto.AES256GCM, crypto.RecordIDAAD(0, "note"))
+		require.NoError(t, err)
+
+		for name, data := range map[string][]byte{
+			"sealed on creation":                    sealed,
+			"before envelopes":                      unbound,
+			"before envelopes, looks like envelope": lookalike,
+		} {
+			v := &pb.VaultRecord{Id: 9, Type: "note", EncryptedData: data}
+			_, err = openRecord(v, testRecordKey)
+			require.ErrorIs(t, err, errLegacyRecord, name)
+			require.ErrorIs(t, rebind(v, testRecordKey, crypto.AES256GCM), errLegacyRecord, name)
+
+			require.NoError(t, resealRecord(v, testRecordKey, crypto.AES256GCM), name)
+			require.Len(t, v.Uuid, 36, name)
+			opened, err := openRecord(v, testRecordKey)
+			require.NoError(t, err, name)
+			require.Equal(t, []byte("text"), opened, name)
+		}
+
+		moved := &pb.VaultRecord{Id: 9, Uuid: testUUID(9), Type: "note", EncryptedData: lookalike}
+		_, err = openRecord(moved, testRecordKey)
+		require.Error(t, err, "unbound contents never pass for a record with a UUID")
+	})
+
+	t.Run("default cipher", func(t *testing.T) {
+		require.Equal(t, crypto.AES256GCM, (&GophKeeper{}).recordCipher())
+		require.Equal(t, crypto.XChaCha20Poly1305, (&GophKeeper{cipher: crypto.XChaCha20Poly1305}).recordCipher())
+	})
+}
+
+func TestUUIDPins(t *testing.T) {
+	gk, mockClient, store := newCacheTestKeeper(t)
+
+	v := sealedNote(t, "todo", "milk", 1)
+	mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(proto.Clone(v).(*pb.VaultRecord), nil)
+	_, err := gk.VaultGet(5)
+	require.NoError(t, err)
+
+	key, err := currentRecordKey(store)
+	require.NoError(t, err)
+	_, err = openRecord(v, key)
+	require.NoError(t, err)
+
+	t.Run("UUID dropped", func(t *testing.T) {
+		sealed, err := crypto.Seal([]byte(`{"text":"milk"}`), testKey, crypto.AES256GCM, crypto.RecordIDAAD(5, "note"))
+		require.NoError(t, err)
+		stripped := &pb.VaultRecord{Id: 5, Type: "note", EncryptedData: sealed}
+
+		_, err = openRecord(stripped, testRecordKey)
+		require.NoError(t, err, "never seen with a UUID")
+		_, err = openRecord(stripped, key)
+		require.ErrorIs(t, err, crypto.ErrNotAuthentic)
+		require.ErrorIs(t, resealRecord(stripped, key, crypto.AES256GCM), crypto.ErrNotAuthentic)
+	})
+
+	t.Run("UUID changed", func(t *testing.T) {
+		other := &pb.VaultRecord{Id: 5, Uuid: testUUID(6), Type: "note"}
+		require.NoError(t, sealRecord(other, []byte(`{"text":"milk"}`), testRecordKey, crypto.AES256GCM))
+
+		_, err = openRecord(other, key)
+		require.ErrorIs(t, err, crypto.ErrNotAuthentic)
+	})
+
+	t.Run("pinned on create", func(t *testing.T) {
+		created := sealedNote(t, "new", "eggs", 0)
+		created.Id = 0
+		mockClient.EXPECT().CreateVault(gomock.Any(), gomock.Any()).Return(&pb.VaultRecord{Id: 12, Revision: 1}, nil)
+		_, err := gk.VaultCreate(created)
+		require.NoError(t, err)
+
+		pinned, err := store.PinnedUUID(12)
+		require.NoError(t, err)
+		require.Equal(t, created.Uuid, pinned)
+	})
+}
+
+func TestSealMeta(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		v := &pb.VaultRecord{Type: "login", Title: "Chase bank", Metadata: `{"url":"chase.com"}`}
+		require.NoError(t, sealRecord(v, []byte("{}"), testRecordKey, crypto.AES256GCM))
+
+		sealed, err := sealMeta(v, testRecordKey, crypto.AES256GCM)
+		require.NoError(t, err)
+		require.Empty(t, sealed.Title)
+		require.Equal(t, "{}", sealed.Metadata)
+		require.NotContains(t, string(sealed.SealedMeta), "Chase")
+		require.Equal(t, "Chase bank", v.Title, "the record itself is left as it is")
+
+		require.NoError(t, revealMeta(sealed, testRecordKey))
+		require.Equal(t, "Chase bank", sealed.Title)
+		require.Equal(t, `{"url":"chase.com"}`, sealed.Metadata)
+		require.Nil(t, sealed.SealedMeta)
+	})
+
+	t.Run("bound to the record", func(t *testing.T) {
+		v := &pb.VaultRecord{Type: "note", Title: "prod-db-root"}
+		require.NoError(t, sealRecord(v, []byte("{}"), testRecordKey, crypto.AES256GCM))
+		sealed, err := sealMeta(v, testRecordKey, crypto.AES256GCM)
+		require.NoError(t, err)
+
+		other := &pb.VaultRecord{Type: "note"}
+		require.NoError(t, sealRecord(other, []byte("{}"), testRecordKey, crypto.AES256GCM))
+
+		moved := &pb.VaultRecord{Uuid: other.Uuid, Type: "note", SealedMeta: sealed.SealedMeta}
+		_, err = openMeta(moved, testRecordKey)
+		require.ErrorIs(t, err, crypto.ErrNotAuthentic)
+
+		asContents := &pb.VaultRecord{Uuid: v.Uuid, Type: "note", EncryptedData: sealed.SealedMeta}
+		_, err = openRecord(asContents, testRecordKey)
+		require.ErrorIs(t, err, crypto.ErrNotAuthentic, "metadata cannot pass for the contents")
+
+		_, err = sealMeta(&pb.VaultRecord{Type: "note"}, testRecordKey, crypto.AES256GCM)
+		require.Error(t, err, "a record without a UUID cannot be bound")
+	})
+
+	t.Run("plain metadata", func(t *testing.T) {
+		m, err := openMeta(&pb.VaultRecord{Title: "todo", Metadata: "{}"}, testRecordKey)
+		require.NoError(t, err)
+		require.Equal(t, recordMeta{Title: "todo", Metadata: "{}"}, m)
+	})
+}
+
+func TestPrivateMetadata(t *testing.T) {
+	t.Run("sent sealed", func(t *testing.T) {
+		gk, mockClient, _ := newCacheTestKeeper(t)
+		gk.privateMeta = true
+
+		v := &pb.VaultRecord{Type: "note", Title: "Chase bank", Metadata: "{}"}
+		require.NoError(t, sealRecord(v, []byte(`{"text":"pin"}`), testRecordKey, crypto.AES256GCM))
+
+		var stored *pb.VaultRecord
+		mockClient.EXPECT().
+			CreateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, in *pb.CreateVaultRequest, _ ...any) (*pb.VaultRecord, error) {
+				require.Empty(t, in.Record.Title, "the server never sees the title")
+				require.NotEmpty(t, in.Record.SealedMeta)
+				stored = proto.Clone(in.Record).(*pb.VaultRecord)
+				stored.Id = 7
+				return stored, nil
+			})
+
+		created, err := gk.VaultCreate(v)
+		require.NoError(t, err)
+		require.Equal(t, "Chase bank", created.Title)
+		require.Equal(t, "Chase bank", v.Title)
+
+		mockClient.EXPECT().GetVault(gomock.Any(), gomock.Any()).Return(proto.Clone(stored).(*pb.VaultRecord), nil)
+		got, err := gk.VaultGet(7)
+		require.NoError(t, err)
+		require.Equal(t, "Chase bank", got.Title)
+	})
+
+	t.Run("record made before UUIDs", func(t *testing.T) {
+		gk, mockClient, _ := newCacheTestKeeper(t)
+		gk.privateMeta = true
+
+		sealed, err := crypto.Seal([]byte("text"), testKey, crypto.AES256GCM, crypto.RecordIDAAD(9, "note"))
+		require.NoError(t, err)
+		v := &pb.VaultRecord{Id: 9, Type: "note", Title: "old", Metadata: "{}", Revision: 1, EncryptedData: sealed}
+
+		mockClient.EXPECT().
+			UpdateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, in *pb.VaultRecord, _ ...any) (*emptypb.Empty, error) {
+				require.Empty(t, in.Title, "sent sealed, not in plain text")
+				require.Len(t, in.Uuid, 36)
+				data, err := openRecord(in, testRecordKey)
+				require.NoError(t, err)
+				require.Equal(t, []byte("text"), data)
+				return &emptypb.Empty{}, nil
+			})
+
+		_, err = gk.VaultUpdate(v)
+		require.NoError(t, err)
+		require.NotEmpty(t, v.Uuid, "the contents are bound to the UUID they were sent with")
+	})
+
+	t.Run("off", func(t *testing.T) {
+		gk, mockClient, _ := newCacheTestKeeper(t)
+
+		v := &pb.VaultRecord{Type: "note", Title: "todo", Metadata: "{}"}
+		require.NoError(t, sealRecord(v, []byte(`{"text":"milk"}`), testRecordKey, crypto.AES256GCM))
+
+		mockClient.EXPECT().
+			CreateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, in *pb.CreateVaultRequest, _ ...any) (*pb.VaultRecord, error) {
+				require.Equal(t, "todo", in.Record.Title)
+				require.Empty(t, in.Record.SealedMeta)
+				return &pb.VaultRecord{Id: 8}, nil
+			})
+
+		_, err := gk.VaultCreate(v)
+		require.NoError(t, err)
+	})
+
+	t.Run("listed from the mirror", func(t *testing.T) {
+		gk, mockClient, _ := newCacheTestKeeper(t)
+
+		bank := &pb.VaultRecord{Id: 1, Type: "login", Title: "Chase bank", Metadata: `{"url":"chase.com"}`}
+		db := &pb.VaultRecord{Id: 2, Type: "note", Title: "prod-db-root", Metadata: "{}"}
+		for _, v := range []*pb.VaultRecord{bank, db} {
+			require.NoError(t, sealRecord(v, []byte("{}"), testRecordKey, crypto.AES256GCM))
+		}
+		sealedBank, err := sealMeta(bank, testRecordKey, crypto.AES256GCM)
+		require.NoError(t, err)
+
+		// a record sealed on another device makes the client list its mirror
+		mockClient.EXPECT().ListVaultSummaries(gomock.Any(), gomock.Any()).Return(&pb.ListVaultSummariesResponse{
+			Vaults: []*pb.VaultSummary{{Id: 1, Type: "login", Metadata: "{}", SealedMeta: true}, {Id: 2, Type: "note", Title: "prod-db-root"}},
+		}, nil)
+		mockClient.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(&pb.SyncResponse{
+			Changed:    []*pb.VaultRecord{sealedBank, db},
+			Cursor:     2,
+			FullResync: true,
+		}, nil)
+
+		list, err := gk.listSummaries(&bytes.Buffer{}, listOptions{Sort: "title"})
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		require.Equal(t, "Chase bank", list[0].Title)
+		require.Equal(t, `{"url":"chase.com"}`, list[0].Metadata)
+		require.Equal(t, "prod-db-root", list[1].Title)
+	})
+
+	t.Run("listed offline", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+		gk.privateMeta = true
+
+		v := &pb.VaultRecord{Id: 3, Type: "note", Title: "diary", Metadata: "{}"}
+		require.NoError(t, sealRecord(v, []byte("{}"), testRecordKey, crypto.AES256GCM))
+		sealed, err := sealMeta(v, testRecordKey, crypto.AES256GCM)
+		require.NoError(t, err)
+		require.NoError(t, store.CacheVault(sealed))
+
+		mockClient.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(nil, errOffline)
+
+		var buf bytes.Buffer
+		list, err := gk.listSummaries(&buf, listOptions{})
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		require.Equal(t, "diary", list[0].Title)
+		require.Contains(t, buf.String(), offlineNotice)
+	})
+}
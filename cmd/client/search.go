@@ -0,0 +1,345 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/search"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+	"go.uber.org/zap"
+)
+
+// indexer keeps the search index of the current context up to date with the
+// records created, changed and deleted through the GophKeeper methods. A nil
+// indexer does nothing. Failures are only logged: the write itself succeeded,
+// and search --reindex brings the index back in line with the local copy.
+type indexer struct {
+	storage kv.Storage
+	log     *zap.SugaredLogger
+}
+
+// put indexes the encrypted record v under id.
+func (x *indexer) put(id uint64, v *pb.VaultRecord) {
+	if x == nil {
+		return
+	}
+	idx, key, err := openIndex(x.storage)
+	if err == nil {
+		err = idx.Put(searchDoc(id, v, key))
+	}
+	if err != nil {
+		x.log.Warnf("index record %d: %v", id, err)
+	}
+}
+
+// remove unindexes the record.
+func (x *indexer) remove(id uint64) {
+	if x == nil {
+		return
+	}
+	idx, _, err := openIndex(x.storage)
+	if err == nil {
+		err = idx.Remove(id)
+	}
+	if err != nil {
+		x.log.Warnf("unindex record %d: %v", id, err)
+	}
+}
+
+// tokens returns the blind index tokens of the encrypted record v.
+func (x *indexer) tokens(v *pb.VaultRecord) []string {
+	if x == nil {
+		return nil
+	}
+	key, err := currentRecordKey(x.storage)
+	if err != nil {
+		x.log.Warnf("blind tokens: %v", err)
+		return nil
+	}
+	d := searchDoc(v.Id, v, key)
+	tokens, err := blindTokens(key.seed, d.Fields[search.FieldLogin], d.Fields[search.FieldURL])
+	if err != nil {
+		x.log.Warnf("blind tokens: %v", err)
+	}
+	return tokens
+}
+
+// page applies a page of server changes to the index; a full resync rebuilds it.
+func (x *indexer) page(page *pb.SyncResponse) {
+	if x == nil {
+		return
+	}
+	if err := x.apply(page); err != nil {
+		x.log.Warnf("index sync page: %v", err)
+	}
+}
+
+func (x *indexer) apply(page *pb.SyncResponse) error {
+	idx, key, err := openIndex(x.storage)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]search.Doc, 0, len(page.Changed))
+	for _, v := range page.Changed {
+		docs = append(docs, searchDoc(v.Id, v, key))
+	}
+	if page.FullResync {
+		return idx.Rebuild(docs)
+	}
+
+	for _, d := range docs {
+		if err = idx.Put(d); err != nil {
+			return err
+		}
+	}
+	for _, id := range page.Deleted {
+		if err = idx.Remove(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openIndex returns the search index of the current context and the key of its records.
+func openIndex(storage kv.Storage) (*search.Index, recordKey, error) {
+	key, err := currentRecordKey(storage)
+	if err != nil {
+		return nil, key, err
+	}
+	idx, err := search.New(storage, key.seed)
+	return idx, key, err
+}
+
+// searchDoc decrypts the searchable parts of a record: the title, the login,
+// the note text and the metadata, where the url key goes to the url field and
+// the other keys and values are tags. Passwords and cards are never indexed.
+// A record that does not decrypt is indexed by its title and metadata only,
+// one whose sealed title and metadata do not decrypt by its type only.
+func searchDoc(id uint64, v *pb.VaultRecord, key recordKey) search.Doc {
+	m, err := openMeta(v, key)
+	if err != nil {
+		return search.Doc{ID: id, Type: v.Type, Fields: map[string][]string{}}
+	}
+
+	d := search.Doc{
+		ID:     id,
+		Type:   v.Type,
+		Title:  m.Title,
+		Fields: map[string][]string{search.FieldTitle: {m.Title}},
+	}
+
+	var meta map[string]string
+	if json.Unmarshal([]byte(m.Metadata), &meta) == nil {
+		for k, val := range meta {
+			if strings.EqualFold(k, search.FieldURL) {
+				d.Fields[search.FieldURL] = append(d.Fields[search.FieldURL], val)
+			} else {
+				d.Fields[search.FieldTag] = append(d.Fields[search.FieldTag], k, val)
+			}
+		}
+	}
+
+	if v.Type != "login" && v.Type != "note" {
+		return d
+	}
+	data, err := openRecord(v, key)
+	if err != nil {
+		return d
+	}
+
+	switch v.Type {
+	case "login":
+		var lp kv.LoginPass
+		if json.Unmarshal(data, &lp) == nil {
+			d.Fields[search.FieldLogin] = []string{lp.Login}
+		}
+	case "note":
+		var n kv.Note
+		if json.Unmarshal(data, &n) == nil {
+			d.Fields[search.FieldNote] = []string{n.Text}
+		}
+	}
+	return d
+}
+
+// blindTokens returns the blind index tokens of logins and of the hosts of
+// urls, the fields the server can look records up by without seeing them.
+func blindTokens(key string, logins, urls []string) ([]string, error) {
+	bi, err := crypto.NewBlindIndex(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []string
+	for _, login := range logins {
+		if strings.TrimSpace(login) != "" {
+			tokens = append(tokens, bi.Token(search.FieldLogin, login))
+		}
+	}
+	for _, u := range urls {
+		if host := urlHost(u); host != "" {
+			tokens = append(tokens, bi.Token(search.FieldURL, host))
+		}
+	}
+	return tokens, nil
+}
+
+// urlHost returns the host of an address with or without a scheme, without
+// the www. in front, so that all addresses of a site give the same token.
+func urlHost(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// reindex rebuilds the search index from the local copy of the vault.
+func (g *GophKeeper) reindex(idx *search.Index, key recordKey) (int, error) {
+	vaults, err := g.storage.CachedVaults()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка чтения локальной копии: %w", err)
+	}
+
+	docs := make([]search.Doc, 0, len(vaults))
+	for _, v := range vaults {
+		docs = append(docs, searchDoc(v.Id, v, key))
+	}
+	return len(docs), idx.Rebuild(docs)
+}
+
+// SearchCMD returns a Cobra command that searches the records on the client.
+func (g *GophKeeper) SearchCMD() *cobra.Command {
+	var rebuild bool
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Найти записи по названию, логину, адресу, тексту заметки и тегам",
+		Long: `Ищет по зашифрованному индексу на этом устройстве, сервер запрос не видит.
+Слова ищутся во всех полях с опечатками; поле можно указать: type:card, url:github.com,
+title:, login:, note:, tag:.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			query := strings.Join(args, " ")
+			if query == "" && !rebuild {
+				return errors.New("пример: search <запрос>")
+			}
+
+			idx, key, err := openIndex(g.storage)
+			if err != nil {
+				return err
+			}
+
+			empty, err := idx.Empty()
+			if err != nil {
+				return err
+			}
+			if rebuild || empty {
+				n, err := g.reindex(idx, key)
+				if err != nil {
+					return fmt.Errorf("не удалось построить индекс: %w", err)
+				}
+				if rebuild {
+					fmt.Fprintf(out, "🔎 Индекс перестроен, записей: %d\n", n)
+				}
+			}
+			if query == "" {
+				return nil
+			}
+
+			results, err := idx.Search(query)
+			if err != nil {
+				return fmt.Errorf("ошибка поиска: %w", err)
+			}
+			if len(results) == 0 {
+				fmt.Fprintln(out, "🔍 Ничего не найдено.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTYPE\tTITLE")
+			for _, r := range results {
+				fmt.Fprintf(w, "%d\t%s\t%s\n", r.ID, r.Type, r.Title)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&rebuild, "reindex", false, "перестроить индекс по локальной копии")
+	return cmd
+}
+
+// FindCMD returns a Cobra command that asks the server for the records with
+// the given login or site. The server compares blind index tokens and learns
+// neither.
+func (g *GophKeeper) FindCMD() *cobra.Command {
+	var login, site string
+
+	cmd := &cobra.Command{
+		Use:   "find --login <логин> --url <адрес>",
+		Short: "Найти записи на сервере по точному логину или сайту",
+		Long: `Ищет на сервере записи с точно таким логином и/или сайтом. Сервер получает
+только ключевые хэши значений и не узнаёт ни логин, ни адрес.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+
+			var logins, urls []string
+			if login != "" {
+				logins = append(logins, login)
+			}
+			if site != "" {
+				if urlHost(site) == "" {
+					return fmt.Errorf("не удалось разобрать адрес %q", site)
+				}
+				urls = append(urls, site)
+			}
+			if len(logins)+len(urls) == 0 {
+				return errors.New("укажите --login и/или --url")
+			}
+
+			key, err := g.storage.GetCurrentKey()
+			if err != nil {
+				return err
+			}
+			tokens, err := blindTokens(key, logins, urls)
+			if err != nil {
+				return err
+			}
+
+			resp, err := g.VaultFind(tokens)
+			if err != nil {
+				return fmt.Errorf("ошибка поиска: %w", err)
+			}
+			if len(resp.Vaults) == 0 {
+				fmt.Fprintln(out, "🔍 Ничего не найдено.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTYPE\tTITLE")
+			for _, v := range resp.Vaults {
+				fmt.Fprintf(w, "%d\t%s\t%s\n", v.Id, v.Type, v.Title)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&login, "login", "", "логин записи")
+	cmd.Flags().StringVar(&site, "url", "", "сайт записи, сравнивается по имени хоста")
+	return cmd
+}
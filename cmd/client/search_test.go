@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/cmd/client/internal/kv"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// sealedLogin returns a login record encrypted with testKey.
+func sealedLogin(t *testing.T, id uint64, title, login, url string) *pb.VaultRecord {
+	data, err := json.Marshal(kv.LoginPass{Login: login, Password: "hunter2"})
+	require.NoError(t, err)
+	meta, err := json.Marshal(map[string]string{"url": url, "team": "infra"})
+	require.NoError(t, err)
+	v := &pb.VaultRecord{Id: id, Uuid: testUUID(id), Type: "login", Title: title, Metadata: string(meta)}
+	require.NoError(t, sealRecord(v, data, testRecordKey, crypto.AES256GCM))
+	return v
+}
+
+// runSearch runs the search command and returns its output.
+func runSearch(t *testing.T, gk *GophKeeper, args ...string) string {
+	var buf bytes.Buffer
+	cmd := gk.SearchCMD()
+	cmd.SetOut(&buf)
+	require.NoError(t, cmd.ParseFlags(args))
+	require.NoError(t, cmd.RunE(cmd, cmd.Flags().Args()))
+	return buf.String()
+}
+
+func TestSearchIndexUpdates(t *testing.T) {
+	gk, mockClient, store := newCacheTestKeeper(t)
+	gk.index = &indexer{storage: store, log: zap.NewNop().Sugar()}
+
+	// the index exists, so search does not build it from the empty mirror
+	gk.index.put(100, &pb.VaultRecord{Type: "note", Title: "placeholder"})
+
+	t.Run("create", func(t *testing.T) {
+		v := sealedLogin(t, 7, "GitHub", "octocat", "https://github.com")
+		v.Id = 0
+		mockClient.EXPECT().CreateVault(gomock.Any(), gomock.Any()).Return(&pb.VaultRecord{Id: 7, Revision: 1}, nil)
+
+		created, err := gk.VaultCreate(v)
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), created.Id)
+
+		out := runSearch(t, gk, "type:login", "url:github.com")
+		require.Contains(t, out, "GitHub")
+		require.Contains(t, runSearch(t, gk, "octocta"), "GitHub", "fuzzy")
+		require.Contains(t, runSearch(t, gk, "tag:infra"), "GitHub")
+		require.Contains(t, runSearch(t, gk, "hunter2"), "Ничего не найдено", "passwords are not indexed")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		v := sealedLogin(t, 7, "GitLab", "octocat", "https://gitlab.com")
+		mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(&emptypb.Empty{}, nil)
+
+		_, err := gk.VaultUpdate(v)
+		require.NoError(t, err)
+
+		require.Contains(t, runSearch(t, gk, "url:github"), "Ничего не найдено")
+		require.Contains(t, runSearch(t, gk, "gitlab"), "GitLab")
+	})
+
+	t.Run("failed write leaves the index", func(t *testing.T) {
+		mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(nil, errOffline)
+
+		_, err := gk.VaultUpdate(sealedLogin(t, 7, "Bitbucket", "octocat", ""))
+		require.Error(t, err)
+		require.Contains(t, runSearch(t, gk, "gitlab"), "GitLab")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockClient.EXPECT().DeleteVault(gomock.Any(), &pb.DeleteVaultRequest{VaultId: 7}).Return(&emptypb.Empty{}, nil)
+
+		_, err := gk.VaultDelete(7)
+		require.NoError(t, err)
+		require.Contains(t, runSearch(t, gk, "gitlab"), "Ничего не найдено")
+	})
+
+	t.Run("restore from the trash", func(t *testing.T) {
+		mockClient.EXPECT().RestoreVault(gomock.Any(), &pb.RestoreVaultRequest{VaultId: 7}).Return(&emptypb.Empty{}, nil)
+		mockClient.EXPECT().GetVault(gomock.Any(), &pb.GetVaultRequest{VaultId: 7}).
+			Return(sealedLogin(t, 7, "GitLab", "octocat", "https://gitlab.com"), nil)
+
+		_, err := gk.TrashRestore(7)
+		require.NoError(t, err)
+		require.Contains(t, runSearch(t, gk, "gitlab"), "GitLab")
+
+		mockClient.EXPECT().DeleteVault(gomock.Any(), gomock.Any()).Return(&emptypb.Empty{}, nil)
+		_, err = gk.VaultDelete(7)
+		require.NoError(t, err)
+	})
+
+	t.Run("sync pages", func(t *testing.T) {
+		gomock.InOrder(
+			mockClient.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{}).Return(&pb.SyncResponse{
+				Changed:    []*pb.VaultRecord{sealedNote(t, "Wi-Fi", "office network", 1)},
+				Cursor:     3,
+				FullResync: true,
+				HasMore:    true,
+			}, nil),
+			mockClient.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{SinceCursor: 3}).Return(&pb.SyncResponse{
+				Changed: []*pb.VaultRecord{sealedLogin(t, 8, "Mail", "alice", "https://mail.example")},
+				Deleted: []uint64{5},
+				Cursor:  4,
+			}, nil),
+		)
+
+		require.NoError(t, gk.pullChanges())
+		require.Contains(t, runSearch(t, gk, "placeholder"), "Ничего не найдено", "a full resync rebuilds the index")
+		require.Contains(t, runSearch(t, gk, "office"), "Ничего не найдено", "deleted on the next page")
+		require.Contains(t, runSearch(t, gk, "mail.example"), "Mail")
+	})
+}
+
+func TestSearchCMD(t *testing.T) {
+	gk, _, store := newCacheTestKeeper(t)
+	require.NoError(t, store.CacheVault(sealedNote(t, "Wi-Fi", "the office network", 1)))
+	require.NoError(t, store.CacheVault(sealedLogin(t, 6, "GitHub", "octocat", "https://github.com")))
+
+	t.Run("builds the index from the local copy", func(t *testing.T) {
+		out := runSearch(t, gk, "network")
+		require.Contains(t, out, "Wi-Fi")
+		require.NotContains(t, out, "GitHub")
+	})
+
+	t.Run("reindex", func(t *testing.T) {
+		require.NoError(t, store.UncacheVault(5))
+
+		require.Contains(t, runSearch(t, gk, "--reindex"), "записей: 1")
+		require.Contains(t, runSearch(t, gk, "network"), "Ничего не найдено")
+		require.Contains(t, runSearch(t, gk, "login:octocat"), "GitHub")
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		cmd := gk.SearchCMD()
+		require.Error(t, cmd.RunE(cmd, nil))
+	})
+}
+
+func TestURLHost(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://www.GitHub.com/login?x=1": "github.com",
+		"github.com/octocat":               "github.com",
+		"http://gitlab.example.org:8080":   "gitlab.example.org",
+		"  ":                               "",
+	} {
+		require.Equal(t, want, urlHost(raw), raw)
+	}
+}
+
+func TestBlindTokens(t *testing.T) {
+	gk, mockClient, store := newCacheTestKeeper(t)
+	gk.index = &indexer{storage: store, log: zap.NewNop().Sugar()}
+
+	bi, err := crypto.NewBlindIndex(testKey)
+	require.NoError(t, err)
+	loginToken, urlToken := bi.Token("login", "octocat"), bi.Token("url", "github.com")
+
+	t.Run("sent with writes", func(t *testing.T) {
+		v := sealedLogin(t, 7, "GitHub", "Octocat", "https://www.github.com/login")
+		v.Id = 0
+		mockClient.EXPECT().CreateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, req *pb.CreateVaultRequest, _ ...any) (*pb.VaultRecord, error) {
+				require.ElementsMatch(t, []string{loginToken, urlToken}, req.Record.BlindTokens)
+				return &pb.VaultRecord{Id: 7, Revision: 1}, nil
+			})
+		_, err := gk.VaultCreate(v)
+		require.NoError(t, err)
+
+		v = sealedLogin(t, 7, "GitHub", "hubot", "github.com")
+		mockClient.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, req *pb.VaultRecord, _ ...any) (*emptypb.Empty, error) {
+				require.ElementsMatch(t, []string{bi.Token("login", "hubot"), urlToken}, req.BlindTokens)
+				return &emptypb.Empty{}, nil
+			})
+		_, err = gk.VaultUpdate(v)
+		require.NoError(t, err)
+	})
+
+	t.Run("find", func(t *testing.T) {
+		mockClient.EXPECT().FindVaults(gomock.Any(), &pb.FindVaultsRequest{Tokens: []string{loginToken, urlToken}}).
+			Return(&pb.ListVaultsResponse{Vaults: []*pb.VaultRecord{{Id: 7, Type: "login", Title: "GitHub"}}}, nil)
+
+		var buf bytes.Buffer
+		cmd := gk.FindCMD()
+		cmd.SetOut(&buf)
+		require.NoError(t, cmd.ParseFlags([]string{"--login", "octocat", "--url", "http://github.com"}))
+		require.NoError(t, cmd.RunE(cmd, nil))
+		require.Contains(t, buf.String(), "GitHub")
+
+		cmd = gk.FindCMD()
+		require.Error(t, cmd.RunE(cmd, nil), "nothing to find")
+	})
+}
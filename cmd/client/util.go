@@ -6,8 +6,15 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/spf13/cobra"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // hashPassword returns an HMAC-SHA256 hash of the given password using the master key.
@@ -17,21 +24,93 @@ func (g *GophKeeper) hashPassword(password string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// confirm asks a yes/no question on the command's input and reports whether the user agreed.
+func confirm(cmd *cobra.Command, prompt string) bool {
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), prompt)
+
+	var answer string
+	if _, err := fmt.Fscanln(cmd.InOrStdin(), &answer); err != nil {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "д", "да":
+		return true
+	default:
+		return false
+	}
+}
+
+// revisionConflict reports whether err is an update rejected because the record
+// was changed concurrently, and returns the details sent by the server.
+func revisionConflict(err error) (*pb.RevisionConflict, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.Aborted {
+		return nil, false
+	}
+
+	for _, d := range st.Details() {
+		if c, ok := d.(*pb.RevisionConflict); ok {
+			return c, true
+		}
+	}
+	return &pb.RevisionConflict{}, true
+}
+
+// deviceName returns the name the server shows for sessions of this machine.
+func deviceName() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "unknown"
+	}
+	return name
+}
+
 // authCtx returns a gRPC context with the current user's authorization token, if available.
 func (g *GophKeeper) authCtx() context.Context {
+	return g.tokenCtx(g.rootCtx)
+}
+
+// tokenCtx adds the current user's authorization token, if available, to ctx.
+func (g *GophKeeper) tokenCtx(ctx context.Context) context.Context {
+	return withToken(ctx, g.currentToken())
+}
+
+// currentToken returns the access token of the current context, empty if there is none.
+func (g *GophKeeper) currentToken() string {
 	token, err := g.storage.GetCurrentToken()
 	if err != nil {
-		return g.rootCtx
+		return ""
 	}
+	return token
+}
 
+// withToken adds the authorization token, unless it is empty, to ctx.
+func withToken(ctx context.Context, token string) context.Context {
 	if token == "" {
-		return g.rootCtx
+		return ctx
 	}
 
 	md := metadata.New(map[string]string{
 		"authorization": "Bearer " + token,
 	})
-	return metadata.NewOutgoingContext(g.rootCtx, md)
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// authorized runs an authenticated call. When the access token is rejected it
+// refreshes the token pair once and repeats the call with the new token.
+func authorized[T any](g *GophKeeper, call func(ctx context.Context) (T, error)) (T, error) {
+	token := g.currentToken()
+	resp, err := call(withToken(g.rootCtx, token))
+	if status.Code(err) != codes.Unauthenticated {
+		return resp, err
+	}
+
+	if g.refreshTokens(token) != nil {
+		return resp, err
+	}
+
+	return call(g.authCtx())
 }
 
 // printBanner prints the ASCII banner and build information to the console.
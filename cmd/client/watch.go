@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/internal/pubsub"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// watchRetryInterval is the pause before reconnecting a broken watch stream.
+const watchRetryInterval = 5 * time.Second
+
+// startWatch runs watch in the background and returns a function that stops it.
+func (g *GophKeeper) startWatch(out io.Writer, prompt string) (stop func()) {
+	ctx, cancel := context.WithCancel(g.rootCtx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		g.watch(ctx, out, prompt)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// watch keeps the local mirror in step with the changes made on other
+// devices, printing a notice and the shell prompt for each of them. A broken
+// stream is reconnected, and the changes missed meanwhile are pulled, until
+// ctx ends or the session can no longer be refreshed.
+func (g *GophKeeper) watch(ctx context.Context, out io.Writer, prompt string) {
+	retry := g.watchRetry
+	if retry == 0 {
+		retry = watchRetryInterval
+	}
+
+	catchUp, refreshed := false, false
+	for {
+		token := g.currentToken()
+		err := g.watchStream(withToken(ctx, token), out, prompt, catchUp)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if status.Code(err) == codes.Unauthenticated {
+			// сессия завершена, если токены не обновить
+			if refreshed || g.refreshTokens(token) != nil {
+				return
+			}
+			refreshed = true
+			continue
+		}
+		catchUp, refreshed = true, false
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+	}
+}
+
+// watchStream receives the events of one Watch stream, opened with the token
+// in ctx, until it breaks. With catchUp it first pulls the changes made while
+// there was no stream.
+func (g *GophKeeper) watchStream(ctx context.Context, out io.Writer, prompt string, catchUp bool) error {
+	stream, err := g.client.Watch(ctx, &pb.WatchRequest{})
+	if err != nil {
+		return err
+	}
+
+	if catchUp {
+		_ = g.pullChanges()
+	}
+
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		// после удаления запись пропадёт из локальной копии
+		title := g.cachedTitle(ev.VaultId)
+		if err = g.pullChanges(); err != nil && !offline(err) {
+			fmt.Fprintf(out, "\n❌ ошибка синхронизации: %v", err)
+		}
+		if title == "" {
+			title = g.cachedTitle(ev.VaultId)
+		}
+
+		fmt.Fprintf(out, "\n🔔 %s\n%s", eventNotice(ev, title), prompt)
+	}
+}
+
+// cachedTitle returns the title of the record in the mirror, empty if it is
+// not there or is sealed and does not decrypt.
+func (g *GophKeeper) cachedTitle(id uint64) string {
+	v, err := g.storage.CachedVault(id)
+	if err != nil || g.reveal(v) != nil {
+		return ""
+	}
+	return v.Title
+}
+
+// eventNotice describes a change made on another device.
+func eventNotice(ev *pb.VaultEvent, title string) string {
+	record := fmt.Sprintf("Запись %d %q", ev.VaultId, title)
+	if title == "" {
+		record = fmt.Sprintf("Запись %d", ev.VaultId)
+	}
+
+	switch ev.Op {
+	case pubsub.OpCreate:
+		return record + " создана на другом устройстве."
+	case pubsub.OpDelete:
+		return record + " перемещена в корзину на другом устройстве."
+	case pubsub.OpRestore:
+		return record + " восстановлена на другом устройстве."
+	default:
+		return record + " изменена на другом устройстве."
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/internal/pubsub"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// eventStream is a Watch stream that delivers the events and then fails with
+// err, or blocks until the call ends when err is nil.
+type eventStream struct {
+	grpc.ClientStream
+	ctx    context.Context
+	events []*pb.VaultEvent
+	err    error
+}
+
+func (s *eventStream) Recv() (*pb.VaultEvent, error) {
+	if len(s.events) > 0 {
+		ev := s.events[0]
+		s.events = s.events[1:]
+		return ev, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	<-s.ctx.Done()
+	return nil, status.FromContextError(s.ctx.Err()).Err()
+}
+
+// watchReturns makes a mocked Watch call return a stream of the events ending with err.
+func watchReturns(err error, events ...*pb.VaultEvent) func(context.Context, *pb.WatchRequest, ...grpc.CallOption) (grpc.ServerStreamingClient[pb.VaultEvent], error) {
+	return func(ctx context.Context, _ *pb.WatchRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[pb.VaultEvent], error) {
+		return &eventStream{ctx: ctx, events: events, err: err}, nil
+	}
+}
+
+func TestWatch(t *testing.T) {
+	t.Run("pulls changes and reconnects", func(t *testing.T) {
+		gk, mockClient, store := newCacheTestKeeper(t)
+		gk.watchRetry = time.Millisecond
+		require.NoError(t, store.CacheVault(&pb.VaultRecord{Id: 3, Title: "old card", Revision: 1}))
+
+		gomock.InOrder(
+			mockClient.EXPECT().Watch(gomock.Any(), gomock.Any()).DoAndReturn(watchReturns(errOffline,
+				&pb.VaultEvent{VaultId: 5, Revision: 4, Op: pubsub.OpCreate},
+				&pb.VaultEvent{VaultId: 3, Op: pubsub.OpDelete},
+			)),
+			mockClient.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(&pb.SyncResponse{
+				Changed: []*pb.VaultRecord{sealedNote(t, "todo", "milk", 4)},
+				Cursor:  10,
+			}, nil),
+			mockClient.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(&pb.SyncResponse{
+				Deleted: []uint64{3},
+				Cursor:  11,
+			}, nil),
+			// the changes missed while reconnecting
+			mockClient.EXPECT().Watch(gomock.Any(), gomock.Any()).DoAndReturn(watchReturns(status.Error(codes.Unauthenticated, "expired"))),
+			mockClient.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(&pb.SyncResponse{Cursor: 11}, nil),
+		)
+
+		var buf bytes.Buffer
+		gk.watch(context.Background(), &buf, "[alice] > ")
+
+		require.Contains(t, buf.String(), `Запись 5 "todo" создана на другом устройстве.`)
+		require.Contains(t, buf.String(), `Запись 3 "old card" перемещена в корзину на другом устройстве.`+"\n[alice] > ")
+
+		_, err := store.CachedVault(3)
+		require.Error(t, err)
+		cursor, err := store.SyncCursor()
+		require.NoError(t, err)
+		require.Equal(t, uint64(11), cursor)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		gk, mockClient, _ := newCacheTestKeeper(t)
+
+		mockClient.EXPECT().Watch(gomock.Any(), gomock.Any()).DoAndReturn(watchReturns(nil))
+
+		var buf bytes.Buffer
+		stop := gk.startWatch(&buf, "[alice] > ")
+		stop()
+		require.Empty(t, buf.String())
+	})
+}
+
+func TestEventNotice(t *testing.T) {
+	require.Equal(t, `Запись 5 "todo" изменена на другом устройстве.`,
+		eventNotice(&pb.VaultEvent{VaultId: 5, Op: pubsub.OpUpdate}, "todo"))
+	require.Equal(t, "Запись 5 восстановлена на другом устройстве.",
+		eventNotice(&pb.VaultEvent{VaultId: 5, Op: pubsub.OpRestore}, ""))
+}
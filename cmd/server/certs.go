@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/wickedv43/go-goph-keeper/internal/tlsutil"
+)
+
+// genCerts handles "gk-server gen-certs": it writes a development CA with
+// server and client certificates so TLS and mTLS can be tried locally.
+func genCerts(args []string) error {
+	fs := flag.NewFlagSet("gen-certs", flag.ContinueOnError)
+	dir := fs.String("dir", "./certs", "directory for generated certificates")
+	hosts := fs.String("hosts", "localhost,127.0.0.1", "comma-separated server host names and IPs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var names []string
+	for _, h := range strings.Split(*hosts, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			names = append(names, h)
+		}
+	}
+
+	if err := tlsutil.GenerateDevCerts(*dir, names); err != nil {
+		return err
+	}
+
+	fmt.Printf("dev certificates written to %s (%s, %s, %s)\n",
+		*dir, tlsutil.CAFile, tlsutil.ServerFile, tlsutil.ClientFile)
+	return nil
+}
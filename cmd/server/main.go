@@ -4,6 +4,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
 	"syscall"
 
@@ -22,6 +23,14 @@ var configPath = flag.String("c", "./config/config.server.yaml", "path to config
 func main() {
 	flag.Parse()
 
+	if flag.Arg(0) == "gen-certs" {
+		if err := genCerts(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	ctx, cancel := context.WithCancel(context.Background()) //root ctx
 	defer cancel()
 
@@ -44,7 +53,12 @@ func main() {
 		return storage.NewStorage(i)
 	})
 
+	do.Provide(i, server.NewTrashPurger)
+	do.Provide(i, server.NewBlobCollector)
+
 	go do.MustInvoke[*server.Server](i).Start()
+	go do.MustInvoke[*server.TrashPurger](i).Start()
+	go do.MustInvoke[*server.BlobCollector](i).Start()
 
 	signals := []os.Signal{syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT, os.Interrupt}
 
@@ -10,6 +10,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,6 +23,59 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// VaultSort is the order of ListVaults; records with equal keys are ordered by id.
+type VaultSort int32
+
+const (
+	VaultSort_VAULT_SORT_ID         VaultSort = 0
+	VaultSort_VAULT_SORT_TITLE      VaultSort = 1
+	VaultSort_VAULT_SORT_UPDATED_AT VaultSort = 2
+	VaultSort_VAULT_SORT_CREATED_AT VaultSort = 3
+)
+
+// Enum value maps for VaultSort.
+var (
+	VaultSort_name = map[int32]string{
+		0: "VAULT_SORT_ID",
+		1: "VAULT_SORT_TITLE",
+		2: "VAULT_SORT_UPDATED_AT",
+		3: "VAULT_SORT_CREATED_AT",
+	}
+	VaultSort_value = map[string]int32{
+		"VAULT_SORT_ID":         0,
+		"VAULT_SORT_TITLE":      1,
+		"VAULT_SORT_UPDATED_AT": 2,
+		"VAULT_SORT_CREATED_AT": 3,
+	}
+)
+
+func (x VaultSort) Enum() *VaultSort {
+	p := new(VaultSort)
+	*p = x
+	return p
+}
+
+func (x VaultSort) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (VaultSort) Descriptor() protoreflect.EnumDescriptor {
+	return file_server_proto_enumTypes[0].Descriptor()
+}
+
+func (VaultSort) Type() protoreflect.EnumType {
+	return &file_server_proto_enumTypes[0]
+}
+
+func (x VaultSort) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use VaultSort.Descriptor instead.
+func (VaultSort) EnumDescriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{0}
+}
+
 type RegisterRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Login         string                 `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`
@@ -122,6 +176,8 @@ type LoginRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Login         string                 `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`
 	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	DeviceName    string                 `protobuf:"bytes,3,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`          // e.g., hostname of the client machine
+	ClientVersion string                 `protobuf:"bytes,4,opt,name=client_version,json=clientVersion,proto3" json:"client_version,omitempty"` // build version of the client
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -170,9 +226,27 @@ func (x *LoginRequest) GetPassword() string {
 	return ""
 }
 
+func (x *LoginRequest) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetClientVersion() string {
+	if x != nil {
+		return x.ClientVersion
+	}
+	return ""
+}
+
 type LoginResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`                                   // short-lived access token
+	RefreshToken  string                 `protobuf:"bytes,2,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"` // single-use token for RefreshToken
+	ExpiresIn     int64                  `protobuf:"varint,3,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"`         // access token lifetime in seconds
+	MfaRequired   bool                   `protobuf:"varint,4,opt,name=mfa_required,json=mfaRequired,proto3" json:"mfa_required,omitempty"`   // tokens are issued by VerifyMFA instead
+	MfaToken      string                 `protobuf:"bytes,5,opt,name=mfa_token,json=mfaToken,proto3" json:"mfa_token,omitempty"`             // short-lived challenge for VerifyMFA
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -214,28 +288,55 @@ func (x *LoginResponse) GetToken() string {
 	return ""
 }
 
-type CreateVaultRequest struct {
+func (x *LoginResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetExpiresIn() int64 {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return 0
+}
+
+func (x *LoginResponse) GetMfaRequired() bool {
+	if x != nil {
+		return x.MfaRequired
+	}
+	return false
+}
+
+func (x *LoginResponse) GetMfaToken() string {
+	if x != nil {
+		return x.MfaToken
+	}
+	return ""
+}
+
+type RefreshTokenRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Record        *VaultRecord           `protobuf:"bytes,2,opt,name=record,proto3" json:"record,omitempty"`
+	RefreshToken  string                 `protobuf:"bytes,1,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateVaultRequest) Reset() {
-	*x = CreateVaultRequest{}
+func (x *RefreshTokenRequest) Reset() {
+	*x = RefreshTokenRequest{}
 	mi := &file_server_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateVaultRequest) String() string {
+func (x *RefreshTokenRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateVaultRequest) ProtoMessage() {}
+func (*RefreshTokenRequest) ProtoMessage() {}
 
-func (x *CreateVaultRequest) ProtoReflect() protoreflect.Message {
+func (x *RefreshTokenRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -247,46 +348,40 @@ func (x *CreateVaultRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateVaultRequest.ProtoReflect.Descriptor instead.
-func (*CreateVaultRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use RefreshTokenRequest.ProtoReflect.Descriptor instead.
+func (*RefreshTokenRequest) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *CreateVaultRequest) GetUserId() uint64 {
-	if x != nil {
-		return x.UserId
-	}
-	return 0
-}
-
-func (x *CreateVaultRequest) GetRecord() *VaultRecord {
+func (x *RefreshTokenRequest) GetRefreshToken() string {
 	if x != nil {
-		return x.Record
+		return x.RefreshToken
 	}
-	return nil
+	return ""
 }
 
-type GetVaultRequest struct {
+type EnrollMFAResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	VaultId       uint64                 `protobuf:"varint,1,opt,name=vault_id,json=vaultId,proto3" json:"vault_id,omitempty"`
+	Secret        string                 `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`                           // base32 TOTP secret
+	OtpauthUri    string                 `protobuf:"bytes,2,opt,name=otpauth_uri,json=otpauthUri,proto3" json:"otpauth_uri,omitempty"` // otpauth://totp/... for authenticator apps
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetVaultRequest) Reset() {
-	*x = GetVaultRequest{}
+func (x *EnrollMFAResponse) Reset() {
+	*x = EnrollMFAResponse{}
 	mi := &file_server_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetVaultRequest) String() string {
+func (x *EnrollMFAResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetVaultRequest) ProtoMessage() {}
+func (*EnrollMFAResponse) ProtoMessage() {}
 
-func (x *GetVaultRequest) ProtoReflect() protoreflect.Message {
+func (x *EnrollMFAResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -298,39 +393,46 @@ func (x *GetVaultRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetVaultRequest.ProtoReflect.Descriptor instead.
-func (*GetVaultRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use EnrollMFAResponse.ProtoReflect.Descriptor instead.
+func (*EnrollMFAResponse) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *GetVaultRequest) GetVaultId() uint64 {
+func (x *EnrollMFAResponse) GetSecret() string {
 	if x != nil {
-		return x.VaultId
+		return x.Secret
 	}
-	return 0
+	return ""
 }
 
-type DeleteVaultRequest struct {
+func (x *EnrollMFAResponse) GetOtpauthUri() string {
+	if x != nil {
+		return x.OtpauthUri
+	}
+	return ""
+}
+
+type ConfirmMFARequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	VaultId       uint64                 `protobuf:"varint,1,opt,name=vault_id,json=vaultId,proto3" json:"vault_id,omitempty"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteVaultRequest) Reset() {
-	*x = DeleteVaultRequest{}
+func (x *ConfirmMFARequest) Reset() {
+	*x = ConfirmMFARequest{}
 	mi := &file_server_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteVaultRequest) String() string {
+func (x *ConfirmMFARequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteVaultRequest) ProtoMessage() {}
+func (*ConfirmMFARequest) ProtoMessage() {}
 
-func (x *DeleteVaultRequest) ProtoReflect() protoreflect.Message {
+func (x *ConfirmMFARequest) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -342,39 +444,39 @@ func (x *DeleteVaultRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteVaultRequest.ProtoReflect.Descriptor instead.
-func (*DeleteVaultRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ConfirmMFARequest.ProtoReflect.Descriptor instead.
+func (*ConfirmMFARequest) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *DeleteVaultRequest) GetVaultId() uint64 {
+func (x *ConfirmMFARequest) GetCode() string {
 	if x != nil {
-		return x.VaultId
+		return x.Code
 	}
-	return 0
+	return ""
 }
 
-type ListVaultsRequest struct {
+type ConfirmMFAResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	RecoveryCodes []string               `protobuf:"bytes,1,rep,name=recovery_codes,json=recoveryCodes,proto3" json:"recovery_codes,omitempty"` // shown once, stored hashed
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListVaultsRequest) Reset() {
-	*x = ListVaultsRequest{}
+func (x *ConfirmMFAResponse) Reset() {
+	*x = ConfirmMFAResponse{}
 	mi := &file_server_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListVaultsRequest) String() string {
+func (x *ConfirmMFAResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListVaultsRequest) ProtoMessage() {}
+func (*ConfirmMFAResponse) ProtoMessage() {}
 
-func (x *ListVaultsRequest) ProtoReflect() protoreflect.Message {
+func (x *ConfirmMFAResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -386,39 +488,42 @@ func (x *ListVaultsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListVaultsRequest.ProtoReflect.Descriptor instead.
-func (*ListVaultsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ConfirmMFAResponse.ProtoReflect.Descriptor instead.
+func (*ConfirmMFAResponse) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ListVaultsRequest) GetUserId() uint64 {
+func (x *ConfirmMFAResponse) GetRecoveryCodes() []string {
 	if x != nil {
-		return x.UserId
+		return x.RecoveryCodes
 	}
-	return 0
+	return nil
 }
 
-type ListVaultsResponse struct {
+type VerifyMFARequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Vaults        []*VaultRecord         `protobuf:"bytes,1,rep,name=vaults,proto3" json:"vaults,omitempty"`
+	MfaToken      string                 `protobuf:"bytes,1,opt,name=mfa_token,json=mfaToken,proto3" json:"mfa_token,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"` // TOTP code or recovery code
+	DeviceName    string                 `protobuf:"bytes,3,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	ClientVersion string                 `protobuf:"bytes,4,opt,name=client_version,json=clientVersion,proto3" json:"client_version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListVaultsResponse) Reset() {
-	*x = ListVaultsResponse{}
+func (x *VerifyMFARequest) Reset() {
+	*x = VerifyMFARequest{}
 	mi := &file_server_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListVaultsResponse) String() string {
+func (x *VerifyMFARequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListVaultsResponse) ProtoMessage() {}
+func (*VerifyMFARequest) ProtoMessage() {}
 
-func (x *ListVaultsResponse) ProtoReflect() protoreflect.Message {
+func (x *VerifyMFARequest) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -430,46 +535,66 @@ func (x *ListVaultsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListVaultsResponse.ProtoReflect.Descriptor instead.
-func (*ListVaultsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use VerifyMFARequest.ProtoReflect.Descriptor instead.
+func (*VerifyMFARequest) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ListVaultsResponse) GetVaults() []*VaultRecord {
+func (x *VerifyMFARequest) GetMfaToken() string {
 	if x != nil {
-		return x.Vaults
+		return x.MfaToken
 	}
-	return nil
+	return ""
 }
 
-type VaultRecord struct {
+func (x *VerifyMFARequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *VerifyMFARequest) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *VerifyMFARequest) GetClientVersion() string {
+	if x != nil {
+		return x.ClientVersion
+	}
+	return ""
+}
+
+type Session struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	UserId        uint64                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // e.g., "login", "note", "card", "binary"
-	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
-	Metadata      string                 `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"` // as JSON string
-	EncryptedData []byte                 `protobuf:"bytes,6,opt,name=encrypted_data,json=encryptedData,proto3" json:"encrypted_data,omitempty"`
-	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // optional ISO format
-	UpdatedAt     string                 `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // optional ISO format
+	DeviceName    string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	ClientVersion string                 `protobuf:"bytes,3,opt,name=client_version,json=clientVersion,proto3" json:"client_version,omitempty"`
+	Ip            string                 `protobuf:"bytes,4,opt,name=ip,proto3" json:"ip,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`      // ISO format
+	LastSeenAt    string                 `protobuf:"bytes,6,opt,name=last_seen_at,json=lastSeenAt,proto3" json:"last_seen_at,omitempty"` // ISO format
+	Current       bool                   `protobuf:"varint,7,opt,name=current,proto3" json:"current,omitempty"`                          // session of the calling token
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *VaultRecord) Reset() {
-	*x = VaultRecord{}
+func (x *Session) Reset() {
+	*x = Session{}
 	mi := &file_server_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VaultRecord) String() string {
+func (x *Session) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VaultRecord) ProtoMessage() {}
+func (*Session) ProtoMessage() {}
 
-func (x *VaultRecord) ProtoReflect() protoreflect.Message {
+func (x *Session) ProtoReflect() protoreflect.Message {
 	mi := &file_server_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -481,163 +606,2093 @@ func (x *VaultRecord) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VaultRecord.ProtoReflect.Descriptor instead.
-func (*VaultRecord) Descriptor() ([]byte, []int) {
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
 	return file_server_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *VaultRecord) GetId() uint64 {
+func (x *Session) GetId() uint64 {
 	if x != nil {
 		return x.Id
 	}
 	return 0
 }
 
-func (x *VaultRecord) GetUserId() uint64 {
+func (x *Session) GetDeviceName() string {
 	if x != nil {
-		return x.UserId
+		return x.DeviceName
 	}
-	return 0
+	return ""
 }
 
-func (x *VaultRecord) GetType() string {
+func (x *Session) GetClientVersion() string {
 	if x != nil {
-		return x.Type
+		return x.ClientVersion
 	}
 	return ""
 }
 
-func (x *VaultRecord) GetTitle() string {
+func (x *Session) GetIp() string {
 	if x != nil {
-		return x.Title
+		return x.Ip
 	}
 	return ""
 }
 
-func (x *VaultRecord) GetMetadata() string {
+func (x *Session) GetCreatedAt() string {
 	if x != nil {
-		return x.Metadata
+		return x.CreatedAt
 	}
 	return ""
 }
 
-func (x *VaultRecord) GetEncryptedData() []byte {
+func (x *Session) GetLastSeenAt() string {
 	if x != nil {
-		return x.EncryptedData
+		return x.LastSeenAt
 	}
-	return nil
+	return ""
 }
 
-func (x *VaultRecord) GetCreatedAt() string {
+func (x *Session) GetCurrent() bool {
 	if x != nil {
-		return x.CreatedAt
+		return x.Current
 	}
-	return ""
+	return false
 }
 
-func (x *VaultRecord) GetUpdatedAt() string {
+type ListSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*Session             `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_server_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[10]
 	if x != nil {
-		return x.UpdatedAt
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-var File_server_proto protoreflect.FileDescriptor
-
-const file_server_proto_rawDesc = "" +
-	"\n" +
-	"\fserver.proto\x12\x03api\x1a\x1bgoogle/protobuf/empty.proto\"C\n" +
-	"\x0fRegisterRequest\x12\x14\n" +
-	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"+\n" +
-	"\x10RegisterResponse\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x04R\x06userId\"@\n" +
-	"\fLoginRequest\x12\x14\n" +
-	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"%\n" +
-	"\rLoginResponse\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\"W\n" +
-	"\x12CreateVaultRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12(\n" +
-	"\x06record\x18\x02 \x01(\v2\x10.api.VaultRecordR\x06record\",\n" +
-	"\x0fGetVaultRequest\x12\x19\n" +
-	"\bvault_id\x18\x01 \x01(\x04R\avaultId\"/\n" +
-	"\x12DeleteVaultRequest\x12\x19\n" +
-	"\bvault_id\x18\x01 \x01(\x04R\avaultId\",\n" +
-	"\x11ListVaultsRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x04R\x06userId\">\n" +
-	"\x12ListVaultsResponse\x12(\n" +
-	"\x06vaults\x18\x01 \x03(\v2\x10.api.VaultRecordR\x06vaults\"\xe1\x01\n" +
-	"\vVaultRecord\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\x04R\x06userId\x12\x12\n" +
-	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
-	"\x05title\x18\x04 \x01(\tR\x05title\x12\x1a\n" +
-	"\bmetadata\x18\x05 \x01(\tR\bmetadata\x12%\n" +
-	"\x0eencrypted_data\x18\x06 \x01(\fR\rencryptedData\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\a \x01(\tR\tcreatedAt\x12\x1d\n" +
-	"\n" +
-	"updated_at\x18\b \x01(\tR\tupdatedAt2\xa1\x03\n" +
-	"\n" +
-	"GophKeeper\x127\n" +
-	"\bRegister\x12\x14.api.RegisterRequest\x1a\x15.api.RegisterResponse\x12.\n" +
-	"\x05Login\x12\x11.api.LoginRequest\x1a\x12.api.LoginResponse\x12>\n" +
-	"\vCreateVault\x12\x17.api.CreateVaultRequest\x1a\x16.google.protobuf.Empty\x122\n" +
-	"\bGetVault\x12\x14.api.GetVaultRequest\x1a\x10.api.VaultRecord\x127\n" +
-	"\vUpdateVault\x12\x10.api.VaultRecord\x1a\x16.google.protobuf.Empty\x12=\n" +
-	"\n" +
-	"ListVaults\x12\x16.api.ListVaultsRequest\x1a\x17.api.ListVaultsResponse\x12>\n" +
-	"\vDeleteVault\x12\x17.api.DeleteVaultRequest\x1a\x16.google.protobuf.EmptyB\x10Z\x0e./internal/apib\x06proto3"
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{10}
+}
 
-var (
-	file_server_proto_rawDescOnce sync.Once
-	file_server_proto_rawDescData []byte
-)
+func (x *ListSessionsResponse) GetSessions() []*Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
 
-func file_server_proto_rawDescGZIP() []byte {
-	file_server_proto_rawDescOnce.Do(func() {
-		file_server_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_server_proto_rawDesc), len(file_server_proto_rawDesc)))
-	})
-	return file_server_proto_rawDescData
+type RevokeSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     uint64                 `protobuf:"varint,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-var file_server_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
-var file_server_proto_goTypes = []any{
-	(*RegisterRequest)(nil),    // 0: api.RegisterRequest
-	(*RegisterResponse)(nil),   // 1: api.RegisterResponse
-	(*LoginRequest)(nil),       // 2: api.LoginRequest
-	(*LoginResponse)(nil),      // 3: api.LoginResponse
-	(*CreateVaultRequest)(nil), // 4: api.CreateVaultRequest
-	(*GetVaultRequest)(nil),    // 5: api.GetVaultRequest
-	(*DeleteVaultRequest)(nil), // 6: api.DeleteVaultRequest
-	(*ListVaultsRequest)(nil),  // 7: api.ListVaultsRequest
-	(*ListVaultsResponse)(nil), // 8: api.ListVaultsResponse
-	(*VaultRecord)(nil),        // 9: api.VaultRecord
-	(*emptypb.Empty)(nil),      // 10: google.protobuf.Empty
+func (x *RevokeSessionRequest) Reset() {
+	*x = RevokeSessionRequest{}
+	mi := &file_server_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
-var file_server_proto_depIdxs = []int32{
-	9,  // 0: api.CreateVaultRequest.record:type_name -> api.VaultRecord
-	9,  // 1: api.ListVaultsResponse.vaults:type_name -> api.VaultRecord
-	0,  // 2: api.GophKeeper.Register:input_type -> api.RegisterRequest
-	2,  // 3: api.GophKeeper.Login:input_type -> api.LoginRequest
-	4,  // 4: api.GophKeeper.CreateVault:input_type -> api.CreateVaultRequest
-	5,  // 5: api.GophKeeper.GetVault:input_type -> api.GetVaultRequest
-	9,  // 6: api.GophKeeper.UpdateVault:input_type -> api.VaultRecord
-	7,  // 7: api.GophKeeper.ListVaults:input_type -> api.ListVaultsRequest
-	6,  // 8: api.GophKeeper.DeleteVault:input_type -> api.DeleteVaultRequest
-	1,  // 9: api.GophKeeper.Register:output_type -> api.RegisterResponse
-	3,  // 10: api.GophKeeper.Login:output_type -> api.LoginResponse
-	10, // 11: api.GophKeeper.CreateVault:output_type -> google.protobuf.Empty
-	9,  // 12: api.GophKeeper.GetVault:output_type -> api.VaultRecord
-	10, // 13: api.GophKeeper.UpdateVault:output_type -> google.protobuf.Empty
-	8,  // 14: api.GophKeeper.ListVaults:output_type -> api.ListVaultsResponse
-	10, // 15: api.GophKeeper.DeleteVault:output_type -> google.protobuf.Empty
-	9,  // [9:16] is the sub-list for method output_type
-	2,  // [2:9] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+
+func (x *RevokeSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeSessionRequest) ProtoMessage() {}
+
+func (x *RevokeSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeSessionRequest.ProtoReflect.Descriptor instead.
+func (*RevokeSessionRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RevokeSessionRequest) GetSessionId() uint64 {
+	if x != nil {
+		return x.SessionId
+	}
+	return 0
+}
+
+type CreateVaultRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Record        *VaultRecord           `protobuf:"bytes,2,opt,name=record,proto3" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateVaultRequest) Reset() {
+	*x = CreateVaultRequest{}
+	mi := &file_server_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateVaultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateVaultRequest) ProtoMessage() {}
+
+func (x *CreateVaultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateVaultRequest.ProtoReflect.Descriptor instead.
+func (*CreateVaultRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateVaultRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *CreateVaultRequest) GetRecord() *VaultRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+type GetVaultRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VaultId       uint64                 `protobuf:"varint,1,opt,name=vault_id,json=vaultId,proto3" json:"vault_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVaultRequest) Reset() {
+	*x = GetVaultRequest{}
+	mi := &file_server_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVaultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVaultRequest) ProtoMessage() {}
+
+func (x *GetVaultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVaultRequest.ProtoReflect.Descriptor instead.
+func (*GetVaultRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetVaultRequest) GetVaultId() uint64 {
+	if x != nil {
+		return x.VaultId
+	}
+	return 0
+}
+
+type DeleteVaultRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VaultId       uint64                 `protobuf:"varint,1,opt,name=vault_id,json=vaultId,proto3" json:"vault_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteVaultRequest) Reset() {
+	*x = DeleteVaultRequest{}
+	mi := &file_server_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteVaultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteVaultRequest) ProtoMessage() {}
+
+func (x *DeleteVaultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteVaultRequest.ProtoReflect.Descriptor instead.
+func (*DeleteVaultRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeleteVaultRequest) GetVaultId() uint64 {
+	if x != nil {
+		return x.VaultId
+	}
+	return 0
+}
+
+type ListVaultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                  // ignored, the caller's records are listed
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`            // the server default when 0
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`          // next_page_token of the previous page, empty for the first
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`                                     // only records of this type
+	TitlePrefix   string                 `protobuf:"bytes,5,opt,name=title_prefix,json=titlePrefix,proto3" json:"title_prefix,omitempty"`    // only records whose title starts with this
+	UpdatedSince  string                 `protobuf:"bytes,6,opt,name=updated_since,json=updatedSince,proto3" json:"updated_since,omitempty"` // RFC 3339, only records changed at or after it
+	MetadataKeys  []string               `protobuf:"bytes,7,rep,name=metadata_keys,json=metadataKeys,proto3" json:"metadata_keys,omitempty"` // only records whose metadata has all of these keys
+	Sort          VaultSort              `protobuf:"varint,8,opt,name=sort,proto3,enum=api.VaultSort" json:"sort,omitempty"`
+	Descending    bool                   `protobuf:"varint,9,opt,name=descending,proto3" json:"descending,omitempty"`
+	Fields        *fieldmaskpb.FieldMask `protobuf:"bytes,10,opt,name=fields,proto3" json:"fields,omitempty"` // VaultRecord fields to fill, all when empty; id is always set
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVaultsRequest) Reset() {
+	*x = ListVaultsRequest{}
+	mi := &file_server_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVaultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVaultsRequest) ProtoMessage() {}
+
+func (x *ListVaultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVaultsRequest.ProtoReflect.Descriptor instead.
+func (*ListVaultsRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListVaultsRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ListVaultsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListVaultsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListVaultsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ListVaultsRequest) GetTitlePrefix() string {
+	if x != nil {
+		return x.TitlePrefix
+	}
+	return ""
+}
+
+func (x *ListVaultsRequest) GetUpdatedSince() string {
+	if x != nil {
+		return x.UpdatedSince
+	}
+	return ""
+}
+
+func (x *ListVaultsRequest) GetMetadataKeys() []string {
+	if x != nil {
+		return x.MetadataKeys
+	}
+	return nil
+}
+
+func (x *ListVaultsRequest) GetSort() VaultSort {
+	if x != nil {
+		return x.Sort
+	}
+	return VaultSort_VAULT_SORT_ID
+}
+
+func (x *ListVaultsRequest) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+func (x *ListVaultsRequest) GetFields() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+// FindVaultsRequest looks records up by blind index tokens: hex HMACs of
+// field values keyed by a secret the server does not know. Tokens are set on
+// CreateVault and UpdateVault and replace the previous ones.
+type FindVaultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []string               `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"` // records having all of them
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindVaultsRequest) Reset() {
+	*x = FindVaultsRequest{}
+	mi := &file_server_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindVaultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindVaultsRequest) ProtoMessage() {}
+
+func (x *FindVaultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindVaultsRequest.ProtoReflect.Descriptor instead.
+func (*FindVaultsRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *FindVaultsRequest) GetTokens() []string {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+type ListVaultsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vaults        []*VaultRecord         `protobuf:"bytes,1,rep,name=vaults,proto3" json:"vaults,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // empty on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVaultsResponse) Reset() {
+	*x = ListVaultsResponse{}
+	mi := &file_server_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVaultsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVaultsResponse) ProtoMessage() {}
+
+func (x *ListVaultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVaultsResponse.ProtoReflect.Descriptor instead.
+func (*ListVaultsResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListVaultsResponse) GetVaults() []*VaultRecord {
+	if x != nil {
+		return x.Vaults
+	}
+	return nil
+}
+
+func (x *ListVaultsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// VaultSummary is a vault record without its contents.
+type VaultSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Metadata      string                 `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"` // of the file of a binary record, of encrypted_data otherwise
+	Revision      uint64                 `protobuf:"varint,6,opt,name=revision,proto3" json:"revision,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`     // ISO format
+	UpdatedAt     string                 `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`     // ISO format
+	SealedMeta    bool                   `protobuf:"varint,9,opt,name=sealed_meta,json=sealedMeta,proto3" json:"sealed_meta,omitempty"` // the title and metadata are encrypted in the record, not shown here
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VaultSummary) Reset() {
+	*x = VaultSummary{}
+	mi := &file_server_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VaultSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultSummary) ProtoMessage() {}
+
+func (x *VaultSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultSummary.ProtoReflect.Descriptor instead.
+func (*VaultSummary) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *VaultSummary) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *VaultSummary) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *VaultSummary) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *VaultSummary) GetMetadata() string {
+	if x != nil {
+		return x.Metadata
+	}
+	return ""
+}
+
+func (x *VaultSummary) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *VaultSummary) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *VaultSummary) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *VaultSummary) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *VaultSummary) GetSealedMeta() bool {
+	if x != nil {
+		return x.SealedMeta
+	}
+	return false
+}
+
+type ListVaultSummariesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vaults        []*VaultSummary        `protobuf:"bytes,1,rep,name=vaults,proto3" json:"vaults,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // empty on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVaultSummariesResponse) Reset() {
+	*x = ListVaultSummariesResponse{}
+	mi := &file_server_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVaultSummariesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVaultSummariesResponse) ProtoMessage() {}
+
+func (x *ListVaultSummariesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVaultSummariesResponse.ProtoReflect.Descriptor instead.
+func (*ListVaultSummariesResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListVaultSummariesResponse) GetVaults() []*VaultSummary {
+	if x != nil {
+		return x.Vaults
+	}
+	return nil
+}
+
+func (x *ListVaultSummariesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type VaultRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        uint64                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // e.g., "login", "note", "card", "binary"
+	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Metadata      string                 `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"` // as JSON string
+	EncryptedData []byte                 `protobuf:"bytes,6,opt,name=encrypted_data,json=encryptedData,proto3" json:"encrypted_data,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`        // optional ISO format
+	UpdatedAt     string                 `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`        // optional ISO format
+	DeletedAt     string                 `protobuf:"bytes,9,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`        // ISO format, set for records in the trash
+	Revision      uint64                 `protobuf:"varint,10,opt,name=revision,proto3" json:"revision,omitempty"`                         // set by the server; UpdateVault fails with ABORTED unless it matches, INVALID_ARGUMENT when unset
+	BlobRef       string                 `protobuf:"bytes,11,opt,name=blob_ref,json=blobRef,proto3" json:"blob_ref,omitempty"`             // blob_id of the uploaded file of a binary record, see UploadBlob
+	BlindTokens   []string               `protobuf:"bytes,12,rep,name=blind_tokens,json=blindTokens,proto3" json:"blind_tokens,omitempty"` // blind index tokens of the record, see FindVaults; never returned
+	Uuid          string                 `protobuf:"bytes,13,opt,name=uuid,proto3" json:"uuid,omitempty"`                                  // chosen by the client on CreateVault, never changes afterwards; unique per user, ALREADY_EXISTS on repeat
+	SealedMeta    []byte                 `protobuf:"bytes,14,opt,name=sealed_meta,json=sealedMeta,proto3" json:"sealed_meta,omitempty"`    // title and metadata encrypted by the client; title and metadata are then empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VaultRecord) Reset() {
+	*x = VaultRecord{}
+	mi := &file_server_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VaultRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultRecord) ProtoMessage() {}
+
+func (x *VaultRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultRecord.ProtoReflect.Descriptor instead.
+func (*VaultRecord) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *VaultRecord) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *VaultRecord) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *VaultRecord) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *VaultRecord) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *VaultRecord) GetMetadata() string {
+	if x != nil {
+		return x.Metadata
+	}
+	return ""
+}
+
+func (x *VaultRecord) GetEncryptedData() []byte {
+	if x != nil {
+		return x.EncryptedData
+	}
+	return nil
+}
+
+func (x *VaultRecord) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *VaultRecord) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *VaultRecord) GetDeletedAt() string {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return ""
+}
+
+func (x *VaultRecord) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *VaultRecord) GetBlobRef() string {
+	if x != nil {
+		return x.BlobRef
+	}
+	return ""
+}
+
+func (x *VaultRecord) GetBlindTokens() []string {
+	if x != nil {
+		return x.BlindTokens
+	}
+	return nil
+}
+
+func (x *VaultRecord) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *VaultRecord) GetSealedMeta() []byte {
+	if x != nil {
+		return x.SealedMeta
+	}
+	return nil
+}
+
+// RevisionConflict is attached to the ABORTED status of UpdateVault
+// when the record was changed since the client read it.
+type RevisionConflict struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	VaultId          uint64                 `protobuf:"varint,1,opt,name=vault_id,json=vaultId,proto3" json:"vault_id,omitempty"`
+	CurrentRevision  uint64                 `protobuf:"varint,2,opt,name=current_revision,json=currentRevision,proto3" json:"current_revision,omitempty"`
+	ExpectedRevision uint64                 `protobuf:"varint,3,opt,name=expected_revision,json=expectedRevision,proto3" json:"expected_revision,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RevisionConflict) Reset() {
+	*x = RevisionConflict{}
+	mi := &file_server_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevisionConflict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevisionConflict) ProtoMessage() {}
+
+func (x *RevisionConflict) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevisionConflict.ProtoReflect.Descriptor instead.
+func (*RevisionConflict) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RevisionConflict) GetVaultId() uint64 {
+	if x != nil {
+		return x.VaultId
+	}
+	return 0
+}
+
+func (x *RevisionConflict) GetCurrentRevision() uint64 {
+	if x != nil {
+		return x.CurrentRevision
+	}
+	return 0
+}
+
+func (x *RevisionConflict) GetExpectedRevision() uint64 {
+	if x != nil {
+		return x.ExpectedRevision
+	}
+	return 0
+}
+
+type ListVaultVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VaultId       uint64                 `protobuf:"varint,1,opt,name=vault_id,json=vaultId,proto3" json:"vault_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVaultVersionsRequest) Reset() {
+	*x = ListVaultVersionsRequest{}
+	mi := &file_server_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVaultVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVaultVersionsRequest) ProtoMessage() {}
+
+func (x *ListVaultVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVaultVersionsRequest.ProtoReflect.Descriptor instead.
+func (*ListVaultVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListVaultVersionsRequest) GetVaultId() uint64 {
+	if x != nil {
+		return x.VaultId
+	}
+	return 0
+}
+
+type VaultVersion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       uint64                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Metadata      string                 `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`                              // size of encrypted_data in bytes
+	SavedAt       string                 `protobuf:"bytes,6,opt,name=saved_at,json=savedAt,proto3" json:"saved_at,omitempty"`          // ISO format, when the contents were written
+	ArchivedAt    string                 `protobuf:"bytes,7,opt,name=archived_at,json=archivedAt,proto3" json:"archived_at,omitempty"` // ISO format, when they were replaced
+	Uuid          string                 `protobuf:"bytes,8,opt,name=uuid,proto3" json:"uuid,omitempty"`                               // the UUID the version is bound to, see VaultRecord
+	SealedMeta    []byte                 `protobuf:"bytes,9,opt,name=sealed_meta,json=sealedMeta,proto3" json:"sealed_meta,omitempty"` // see VaultRecord
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VaultVersion) Reset() {
+	*x = VaultVersion{}
+	mi := &file_server_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VaultVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultVersion) ProtoMessage() {}
+
+func (x *VaultVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultVersion.ProtoReflect.Descriptor instead.
+func (*VaultVersion) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *VaultVersion) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *VaultVersion) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *VaultVersion) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *VaultVersion) GetMetadata() string {
+	if x != nil {
+		return x.Metadata
+	}
+	return ""
+}
+
+func (x *VaultVersion) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *VaultVersion) GetSavedAt() string {
+	if x != nil {
+		return x.SavedAt
+	}
+	return ""
+}
+
+func (x *VaultVersion) GetArchivedAt() string {
+	if x != nil {
+		return x.ArchivedAt
+	}
+	return ""
+}
+
+func (x *VaultVersion) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *VaultVersion) GetSealedMeta() []byte {
+	if x != nil {
+		return x.SealedMeta
+	}
+	return nil
+}
+
+type ListVaultVersionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      []*VaultVersion        `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"` // newest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVaultVersionsResponse) Reset() {
+	*x = ListVaultVersionsResponse{}
+	mi := &file_server_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVaultVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVaultVersionsResponse) ProtoMessage() {}
+
+func (x *ListVaultVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVaultVersionsResponse.ProtoReflect.Descriptor instead.
+func (*ListVaultVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListVaultVersionsResponse) GetVersions() []*VaultVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type RestoreVaultVersionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VaultId       uint64                 `protobuf:"varint,1,opt,name=vault_id,json=vaultId,proto3" json:"vault_id,omitempty"`
+	Version       uint64                 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreVaultVersionRequest) Reset() {
+	*x = RestoreVaultVersionRequest{}
+	mi := &file_server_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreVaultVersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreVaultVersionRequest) ProtoMessage() {}
+
+func (x *RestoreVaultVersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreVaultVersionRequest.ProtoReflect.Descriptor instead.
+func (*RestoreVaultVersionRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RestoreVaultVersionRequest) GetVaultId() uint64 {
+	if x != nil {
+		return x.VaultId
+	}
+	return 0
+}
+
+func (x *RestoreVaultVersionRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type RestoreVaultRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VaultId       uint64                 `protobuf:"varint,1,opt,name=vault_id,json=vaultId,proto3" json:"vault_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreVaultRequest) Reset() {
+	*x = RestoreVaultRequest{}
+	mi := &file_server_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreVaultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreVaultRequest) ProtoMessage() {}
+
+func (x *RestoreVaultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreVaultRequest.ProtoReflect.Descriptor instead.
+func (*RestoreVaultRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RestoreVaultRequest) GetVaultId() uint64 {
+	if x != nil {
+		return x.VaultId
+	}
+	return 0
+}
+
+type PurgeVaultRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VaultId       uint64                 `protobuf:"varint,1,opt,name=vault_id,json=vaultId,proto3" json:"vault_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeVaultRequest) Reset() {
+	*x = PurgeVaultRequest{}
+	mi := &file_server_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeVaultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeVaultRequest) ProtoMessage() {}
+
+func (x *PurgeVaultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeVaultRequest.ProtoReflect.Descriptor instead.
+func (*PurgeVaultRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *PurgeVaultRequest) GetVaultId() uint64 {
+	if x != nil {
+		return x.VaultId
+	}
+	return 0
+}
+
+type SyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SinceCursor   uint64                 `protobuf:"varint,1,opt,name=since_cursor,json=sinceCursor,proto3" json:"since_cursor,omitempty"` // cursor of the previous response, 0 for a full sync
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                                // page size, the server default when 0
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncRequest) Reset() {
+	*x = SyncRequest{}
+	mi := &file_server_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRequest) ProtoMessage() {}
+
+func (x *SyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRequest.ProtoReflect.Descriptor instead.
+func (*SyncRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SyncRequest) GetSinceCursor() uint64 {
+	if x != nil {
+		return x.SinceCursor
+	}
+	return 0
+}
+
+func (x *SyncRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SyncResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changed       []*VaultRecord         `protobuf:"bytes,1,rep,name=changed,proto3" json:"changed,omitempty"`                          // records created, updated or restored since the cursor
+	Deleted       []uint64               `protobuf:"varint,2,rep,packed,name=deleted,proto3" json:"deleted,omitempty"`                  // tombstones: IDs of records that left the vault
+	Cursor        uint64                 `protobuf:"varint,3,opt,name=cursor,proto3" json:"cursor,omitempty"`                           // pass as since_cursor to the next Sync
+	HasMore       bool                   `protobuf:"varint,4,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`          // call Sync again right away for the rest
+	FullResync    bool                   `protobuf:"varint,5,opt,name=full_resync,json=fullResync,proto3" json:"full_resync,omitempty"` // drop the local mirror before applying this response
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncResponse) Reset() {
+	*x = SyncResponse{}
+	mi := &file_server_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncResponse) ProtoMessage() {}
+
+func (x *SyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncResponse.ProtoReflect.Descriptor instead.
+func (*SyncResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SyncResponse) GetChanged() []*VaultRecord {
+	if x != nil {
+		return x.Changed
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetDeleted() []uint64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *SyncResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *SyncResponse) GetFullResync() bool {
+	if x != nil {
+		return x.FullResync
+	}
+	return false
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_server_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{30}
+}
+
+type VaultEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VaultId       uint64                 `protobuf:"varint,1,opt,name=vault_id,json=vaultId,proto3" json:"vault_id,omitempty"`
+	Revision      uint64                 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"` // revision after the change, 0 when the record left the vault
+	Op            string                 `protobuf:"bytes,3,opt,name=op,proto3" json:"op,omitempty"`              // "create", "update", "delete" or "restore"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VaultEvent) Reset() {
+	*x = VaultEvent{}
+	mi := &file_server_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VaultEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultEvent) ProtoMessage() {}
+
+func (x *VaultEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultEvent.ProtoReflect.Descriptor instead.
+func (*VaultEvent) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *VaultEvent) GetVaultId() uint64 {
+	if x != nil {
+		return x.VaultId
+	}
+	return 0
+}
+
+func (x *VaultEvent) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *VaultEvent) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+// BlobHeader opens an upload or a download.
+type BlobHeader struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"` // chosen by the client; repeat it to resume an upload
+	Header        []byte                 `protobuf:"bytes,2,opt,name=header,proto3" json:"header,omitempty"`               // header of the encrypted stream
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`                  // plaintext size in bytes
+	Chunks        uint32                 `protobuf:"varint,4,opt,name=chunks,proto3" json:"chunks,omitempty"`              // number of encrypted chunks
+	Offset        uint32                 `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`              // number of the first chunk that follows
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlobHeader) Reset() {
+	*x = BlobHeader{}
+	mi := &file_server_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlobHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobHeader) ProtoMessage() {}
+
+func (x *BlobHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobHeader.ProtoReflect.Descriptor instead.
+func (*BlobHeader) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *BlobHeader) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *BlobHeader) GetHeader() []byte {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *BlobHeader) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *BlobHeader) GetChunks() uint32 {
+	if x != nil {
+		return x.Chunks
+	}
+	return 0
+}
+
+func (x *BlobHeader) GetOffset() uint32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// BlobPart is a header followed by encrypted chunks, in order.
+type BlobPart struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Part:
+	//
+	//	*BlobPart_Header
+	//	*BlobPart_Chunk
+	Part          isBlobPart_Part `protobuf_oneof:"part"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlobPart) Reset() {
+	*x = BlobPart{}
+	mi := &file_server_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlobPart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobPart) ProtoMessage() {}
+
+func (x *BlobPart) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobPart.ProtoReflect.Descriptor instead.
+func (*BlobPart) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *BlobPart) GetPart() isBlobPart_Part {
+	if x != nil {
+		return x.Part
+	}
+	return nil
+}
+
+func (x *BlobPart) GetHeader() *BlobHeader {
+	if x != nil {
+		if x, ok := x.Part.(*BlobPart_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *BlobPart) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Part.(*BlobPart_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isBlobPart_Part interface {
+	isBlobPart_Part()
+}
+
+type BlobPart_Header struct {
+	Header *BlobHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type BlobPart_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*BlobPart_Header) isBlobPart_Part() {}
+
+func (*BlobPart_Chunk) isBlobPart_Part() {}
+
+type UploadStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	Received      uint32                 `protobuf:"varint,2,opt,name=received,proto3" json:"received,omitempty"` // chunks stored so far; resume from this one
+	Complete      bool                   `protobuf:"varint,3,opt,name=complete,proto3" json:"complete,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadStatus) Reset() {
+	*x = UploadStatus{}
+	mi := &file_server_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadStatus) ProtoMessage() {}
+
+func (x *UploadStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadStatus.ProtoReflect.Descriptor instead.
+func (*UploadStatus) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *UploadStatus) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *UploadStatus) GetReceived() uint32 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *UploadStatus) GetComplete() bool {
+	if x != nil {
+		return x.Complete
+	}
+	return false
+}
+
+type GetUploadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUploadRequest) Reset() {
+	*x = GetUploadRequest{}
+	mi := &file_server_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUploadRequest) ProtoMessage() {}
+
+func (x *GetUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUploadRequest.ProtoReflect.Descriptor instead.
+func (*GetUploadRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetUploadRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+type DownloadBlobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	Offset        uint32                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"` // first chunk to send, to resume a download
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadBlobRequest) Reset() {
+	*x = DownloadBlobRequest{}
+	mi := &file_server_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadBlobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadBlobRequest) ProtoMessage() {}
+
+func (x *DownloadBlobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadBlobRequest.ProtoReflect.Descriptor instead.
+func (*DownloadBlobRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *DownloadBlobRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *DownloadBlobRequest) GetOffset() uint32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+var File_server_proto protoreflect.FileDescriptor
+
+const file_server_proto_rawDesc = "" +
+	"\n" +
+	"\fserver.proto\x12\x03api\x1a\x1bgoogle/protobuf/empty.proto\x1a google/protobuf/field_mask.proto\"C\n" +
+	"\x0fRegisterRequest\x12\x14\n" +
+	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"+\n" +
+	"\x10RegisterResponse\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\"\x88\x01\n" +
+	"\fLoginRequest\x12\x14\n" +
+	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x1f\n" +
+	"\vdevice_name\x18\x03 \x01(\tR\n" +
+	"deviceName\x12%\n" +
+	"\x0eclient_version\x18\x04 \x01(\tR\rclientVersion\"\xa9\x01\n" +
+	"\rLoginResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12#\n" +
+	"\rrefresh_token\x18\x02 \x01(\tR\frefreshToken\x12\x1d\n" +
+	"\n" +
+	"expires_in\x18\x03 \x01(\x03R\texpiresIn\x12!\n" +
+	"\fmfa_required\x18\x04 \x01(\bR\vmfaRequired\x12\x1b\n" +
+	"\tmfa_token\x18\x05 \x01(\tR\bmfaToken\":\n" +
+	"\x13RefreshTokenRequest\x12#\n" +
+	"\rrefresh_token\x18\x01 \x01(\tR\frefreshToken\"L\n" +
+	"\x11EnrollMFAResponse\x12\x16\n" +
+	"\x06secret\x18\x01 \x01(\tR\x06secret\x12\x1f\n" +
+	"\votpauth_uri\x18\x02 \x01(\tR\n" +
+	"otpauthUri\"'\n" +
+	"\x11ConfirmMFARequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\";\n" +
+	"\x12ConfirmMFAResponse\x12%\n" +
+	"\x0erecovery_codes\x18\x01 \x03(\tR\rrecoveryCodes\"\x8b\x01\n" +
+	"\x10VerifyMFARequest\x12\x1b\n" +
+	"\tmfa_token\x18\x01 \x01(\tR\bmfaToken\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x1f\n" +
+	"\vdevice_name\x18\x03 \x01(\tR\n" +
+	"deviceName\x12%\n" +
+	"\x0eclient_version\x18\x04 \x01(\tR\rclientVersion\"\xcc\x01\n" +
+	"\aSession\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1f\n" +
+	"\vdevice_name\x18\x02 \x01(\tR\n" +
+	"deviceName\x12%\n" +
+	"\x0eclient_version\x18\x03 \x01(\tR\rclientVersion\x12\x0e\n" +
+	"\x02ip\x18\x04 \x01(\tR\x02ip\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12 \n" +
+	"\flast_seen_at\x18\x06 \x01(\tR\n" +
+	"lastSeenAt\x12\x18\n" +
+	"\acurrent\x18\a \x01(\bR\acurrent\"@\n" +
+	"\x14ListSessionsResponse\x12(\n" +
+	"\bsessions\x18\x01 \x03(\v2\f.api.SessionR\bsessions\"5\n" +
+	"\x14RevokeSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\x04R\tsessionId\"W\n" +
+	"\x12CreateVaultRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12(\n" +
+	"\x06record\x18\x02 \x01(\v2\x10.api.VaultRecordR\x06record\",\n" +
+	"\x0fGetVaultRequest\x12\x19\n" +
+	"\bvault_id\x18\x01 \x01(\x04R\avaultId\"/\n" +
+	"\x12DeleteVaultRequest\x12\x19\n" +
+	"\bvault_id\x18\x01 \x01(\x04R\avaultId\"\xe1\x02\n" +
+	"\x11ListVaultsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12!\n" +
+	"\ftitle_prefix\x18\x05 \x01(\tR\vtitlePrefix\x12#\n" +
+	"\rupdated_since\x18\x06 \x01(\tR\fupdatedSince\x12#\n" +
+	"\rmetadata_keys\x18\a \x03(\tR\fmetadataKeys\x12\"\n" +
+	"\x04sort\x18\b \x01(\x0e2\x0e.api.VaultSortR\x04sort\x12\x1e\n" +
+	"\n" +
+	"descending\x18\t \x01(\bR\n" +
+	"descending\x122\n" +
+	"\x06fields\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.FieldMaskR\x06fields\"+\n" +
+	"\x11FindVaultsRequest\x12\x16\n" +
+	"\x06tokens\x18\x01 \x03(\tR\x06tokens\"f\n" +
+	"\x12ListVaultsResponse\x12(\n" +
+	"\x06vaults\x18\x01 \x03(\v2\x10.api.VaultRecordR\x06vaults\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xf3\x01\n" +
+	"\fVaultSummary\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x1a\n" +
+	"\bmetadata\x18\x04 \x01(\tR\bmetadata\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x1a\n" +
+	"\brevision\x18\x06 \x01(\x04R\brevision\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\tR\tupdatedAt\x12\x1f\n" +
+	"\vsealed_meta\x18\t \x01(\bR\n" +
+	"sealedMeta\"o\n" +
+	"\x1aListVaultSummariesResponse\x12)\n" +
+	"\x06vaults\x18\x01 \x03(\v2\x11.api.VaultSummaryR\x06vaults\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x8f\x03\n" +
+	"\vVaultRecord\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x04R\x06userId\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12\x1a\n" +
+	"\bmetadata\x18\x05 \x01(\tR\bmetadata\x12%\n" +
+	"\x0eencrypted_data\x18\x06 \x01(\fR\rencryptedData\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\tR\tupdatedAt\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\t \x01(\tR\tdeletedAt\x12\x1a\n" +
+	"\brevision\x18\n" +
+	" \x01(\x04R\brevision\x12\x19\n" +
+	"\bblob_ref\x18\v \x01(\tR\ablobRef\x12!\n" +
+	"\fblind_tokens\x18\f \x03(\tR\vblindTokens\x12\x12\n" +
+	"\x04uuid\x18\r \x01(\tR\x04uuid\x12\x1f\n" +
+	"\vsealed_meta\x18\x0e \x01(\fR\n" +
+	"sealedMeta\"\x85\x01\n" +
+	"\x10RevisionConflict\x12\x19\n" +
+	"\bvault_id\x18\x01 \x01(\x04R\avaultId\x12)\n" +
+	"\x10current_revision\x18\x02 \x01(\x04R\x0fcurrentRevision\x12+\n" +
+	"\x11expected_revision\x18\x03 \x01(\x04R\x10expectedRevision\"5\n" +
+	"\x18ListVaultVersionsRequest\x12\x19\n" +
+	"\bvault_id\x18\x01 \x01(\x04R\avaultId\"\xf3\x01\n" +
+	"\fVaultVersion\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x04R\aversion\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x1a\n" +
+	"\bmetadata\x18\x04 \x01(\tR\bmetadata\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x19\n" +
+	"\bsaved_at\x18\x06 \x01(\tR\asavedAt\x12\x1f\n" +
+	"\varchived_at\x18\a \x01(\tR\n" +
+	"archivedAt\x12\x12\n" +
+	"\x04uuid\x18\b \x01(\tR\x04uuid\x12\x1f\n" +
+	"\vsealed_meta\x18\t \x01(\fR\n" +
+	"sealedMeta\"J\n" +
+	"\x19ListVaultVersionsResponse\x12-\n" +
+	"\bversions\x18\x01 \x03(\v2\x11.api.VaultVersionR\bversions\"Q\n" +
+	"\x1aRestoreVaultVersionRequest\x12\x19\n" +
+	"\bvault_id\x18\x01 \x01(\x04R\avaultId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x04R\aversion\"0\n" +
+	"\x13RestoreVaultRequest\x12\x19\n" +
+	"\bvault_id\x18\x01 \x01(\x04R\avaultId\".\n" +
+	"\x11PurgeVaultRequest\x12\x19\n" +
+	"\bvault_id\x18\x01 \x01(\x04R\avaultId\"F\n" +
+	"\vSyncRequest\x12!\n" +
+	"\fsince_cursor\x18\x01 \x01(\x04R\vsinceCursor\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\xa8\x01\n" +
+	"\fSyncResponse\x12*\n" +
+	"\achanged\x18\x01 \x03(\v2\x10.api.VaultRecordR\achanged\x12\x18\n" +
+	"\adeleted\x18\x02 \x03(\x04R\adeleted\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\x04R\x06cursor\x12\x19\n" +
+	"\bhas_more\x18\x04 \x01(\bR\ahasMore\x12\x1f\n" +
+	"\vfull_resync\x18\x05 \x01(\bR\n" +
+	"fullResync\"\x0e\n" +
+	"\fWatchRequest\"S\n" +
+	"\n" +
+	"VaultEvent\x12\x19\n" +
+	"\bvault_id\x18\x01 \x01(\x04R\avaultId\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x04R\brevision\x12\x0e\n" +
+	"\x02op\x18\x03 \x01(\tR\x02op\"\x81\x01\n" +
+	"\n" +
+	"BlobHeader\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x16\n" +
+	"\x06header\x18\x02 \x01(\fR\x06header\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06chunks\x18\x04 \x01(\rR\x06chunks\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\rR\x06offset\"U\n" +
+	"\bBlobPart\x12)\n" +
+	"\x06header\x18\x01 \x01(\v2\x0f.api.BlobHeaderH\x00R\x06header\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04part\"_\n" +
+	"\fUploadStatus\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x1a\n" +
+	"\breceived\x18\x02 \x01(\rR\breceived\x12\x1a\n" +
+	"\bcomplete\x18\x03 \x01(\bR\bcomplete\"+\n" +
+	"\x10GetUploadRequest\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\"F\n" +
+	"\x13DownloadBlobRequest\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\rR\x06offset*j\n" +
+	"\tVaultSort\x12\x11\n" +
+	"\rVAULT_SORT_ID\x10\x00\x12\x14\n" +
+	"\x10VAULT_SORT_TITLE\x10\x01\x12\x19\n" +
+	"\x15VAULT_SORT_UPDATED_AT\x10\x02\x12\x19\n" +
+	"\x15VAULT_SORT_CREATED_AT\x10\x032\xb8\f\n" +
+	"\n" +
+	"GophKeeper\x127\n" +
+	"\bRegister\x12\x14.api.RegisterRequest\x1a\x15.api.RegisterResponse\x12.\n" +
+	"\x05Login\x12\x11.api.LoginRequest\x1a\x12.api.LoginResponse\x12<\n" +
+	"\fRefreshToken\x12\x18.api.RefreshTokenRequest\x1a\x12.api.LoginResponse\x128\n" +
+	"\x06Logout\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\x12;\n" +
+	"\tEnrollMFA\x12\x16.google.protobuf.Empty\x1a\x16.api.EnrollMFAResponse\x12=\n" +
+	"\n" +
+	"ConfirmMFA\x12\x16.api.ConfirmMFARequest\x1a\x17.api.ConfirmMFAResponse\x126\n" +
+	"\tVerifyMFA\x12\x15.api.VerifyMFARequest\x1a\x12.api.LoginResponse\x12A\n" +
+	"\fListSessions\x12\x16.google.protobuf.Empty\x1a\x19.api.ListSessionsResponse\x12B\n" +
+	"\rRevokeSession\x12\x19.api.RevokeSessionRequest\x1a\x16.google.protobuf.Empty\x128\n" +
+	"\vCreateVault\x12\x17.api.CreateVaultRequest\x1a\x10.api.VaultRecord\x122\n" +
+	"\bGetVault\x12\x14.api.GetVaultRequest\x1a\x10.api.VaultRecord\x127\n" +
+	"\vUpdateVault\x12\x10.api.VaultRecord\x1a\x16.google.protobuf.Empty\x12=\n" +
+	"\n" +
+	"ListVaults\x12\x16.api.ListVaultsRequest\x1a\x17.api.ListVaultsResponse\x12M\n" +
+	"\x12ListVaultSummaries\x12\x16.api.ListVaultsRequest\x1a\x1f.api.ListVaultSummariesResponse\x12>\n" +
+	"\vDeleteVault\x12\x17.api.DeleteVaultRequest\x1a\x16.google.protobuf.Empty\x12=\n" +
+	"\n" +
+	"FindVaults\x12\x16.api.FindVaultsRequest\x1a\x17.api.ListVaultsResponse\x12R\n" +
+	"\x11ListVaultVersions\x12\x1d.api.ListVaultVersionsRequest\x1a\x1e.api.ListVaultVersionsResponse\x12H\n" +
+	"\x13RestoreVaultVersion\x12\x1f.api.RestoreVaultVersionRequest\x1a\x10.api.VaultRecord\x12<\n" +
+	"\tListTrash\x12\x16.google.protobuf.Empty\x1a\x17.api.ListVaultsResponse\x12@\n" +
+	"\fRestoreVault\x12\x18.api.RestoreVaultRequest\x1a\x16.google.protobuf.Empty\x12<\n" +
+	"\n" +
+	"PurgeVault\x12\x16.api.PurgeVaultRequest\x1a\x16.google.protobuf.Empty\x12+\n" +
+	"\x04Sync\x12\x10.api.SyncRequest\x1a\x11.api.SyncResponse\x12-\n" +
+	"\x05Watch\x12\x11.api.WatchRequest\x1a\x0f.api.VaultEvent0\x01\x120\n" +
+	"\n" +
+	"UploadBlob\x12\r.api.BlobPart\x1a\x11.api.UploadStatus(\x01\x125\n" +
+	"\tGetUpload\x12\x15.api.GetUploadRequest\x1a\x11.api.UploadStatus\x129\n" +
+	"\fDownloadBlob\x12\x18.api.DownloadBlobRequest\x1a\r.api.BlobPart0\x01B\x10Z\x0e./internal/apib\x06proto3"
+
+var (
+	file_server_proto_rawDescOnce sync.Once
+	file_server_proto_rawDescData []byte
+)
+
+func file_server_proto_rawDescGZIP() []byte {
+	file_server_proto_rawDescOnce.Do(func() {
+		file_server_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_server_proto_rawDesc), len(file_server_proto_rawDesc)))
+	})
+	return file_server_proto_rawDescData
+}
+
+var file_server_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_server_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
+var file_server_proto_goTypes = []any{
+	(VaultSort)(0),                     // 0: api.VaultSort
+	(*RegisterRequest)(nil),            // 1: api.RegisterRequest
+	(*RegisterResponse)(nil),           // 2: api.RegisterResponse
+	(*LoginRequest)(nil),               // 3: api.LoginRequest
+	(*LoginResponse)(nil),              // 4: api.LoginResponse
+	(*RefreshTokenRequest)(nil),        // 5: api.RefreshTokenRequest
+	(*EnrollMFAResponse)(nil),          // 6: api.EnrollMFAResponse
+	(*ConfirmMFARequest)(nil),          // 7: api.ConfirmMFARequest
+	(*ConfirmMFAResponse)(nil),         // 8: api.ConfirmMFAResponse
+	(*VerifyMFARequest)(nil),           // 9: api.VerifyMFARequest
+	(*Session)(nil),                    // 10: api.Session
+	(*ListSessionsResponse)(nil),       // 11: api.ListSessionsResponse
+	(*RevokeSessionRequest)(nil),       // 12: api.RevokeSessionRequest
+	(*CreateVaultRequest)(nil),         // 13: api.CreateVaultRequest
+	(*GetVaultRequest)(nil),            // 14: api.GetVaultRequest
+	(*DeleteVaultRequest)(nil),         // 15: api.DeleteVaultRequest
+	(*ListVaultsRequest)(nil),          // 16: api.ListVaultsRequest
+	(*FindVaultsRequest)(nil),          // 17: api.FindVaultsRequest
+	(*ListVaultsResponse)(nil),         // 18: api.ListVaultsResponse
+	(*VaultSummary)(nil),               // 19: api.VaultSummary
+	(*ListVaultSummariesResponse)(nil), // 20: api.ListVaultSummariesResponse
+	(*VaultRecord)(nil),                // 21: api.VaultRecord
+	(*RevisionConflict)(nil),           // 22: api.RevisionConflict
+	(*ListVaultVersionsRequest)(nil),   // 23: api.ListVaultVersionsRequest
+	(*VaultVersion)(nil),               // 24: api.VaultVersion
+	(*ListVaultVersionsResponse)(nil),  // 25: api.ListVaultVersionsResponse
+	(*RestoreVaultVersionRequest)(nil), // 26: api.RestoreVaultVersionRequest
+	(*RestoreVaultRequest)(nil),        // 27: api.RestoreVaultRequest
+	(*PurgeVaultRequest)(nil),          // 28: api.PurgeVaultRequest
+	(*SyncRequest)(nil),                // 29: api.SyncRequest
+	(*SyncResponse)(nil),               // 30: api.SyncResponse
+	(*WatchRequest)(nil),               // 31: api.WatchRequest
+	(*VaultEvent)(nil),                 // 32: api.VaultEvent
+	(*BlobHeader)(nil),                 // 33: api.BlobHeader
+	(*BlobPart)(nil),                   // 34: api.BlobPart
+	(*UploadStatus)(nil),               // 35: api.UploadStatus
+	(*GetUploadRequest)(nil),           // 36: api.GetUploadRequest
+	(*DownloadBlobRequest)(nil),        // 37: api.DownloadBlobRequest
+	(*fieldmaskpb.FieldMask)(nil),      // 38: google.protobuf.FieldMask
+	(*emptypb.Empty)(nil),              // 39: google.protobuf.Empty
+}
+var file_server_proto_depIdxs = []int32{
+	10, // 0: api.ListSessionsResponse.sessions:type_name -> api.Session
+	21, // 1: api.CreateVaultRequest.record:type_name -> api.VaultRecord
+	0,  // 2: api.ListVaultsRequest.sort:type_name -> api.VaultSort
+	38, // 3: api.ListVaultsRequest.fields:type_name -> google.protobuf.FieldMask
+	21, // 4: api.ListVaultsResponse.vaults:type_name -> api.VaultRecord
+	19, // 5: api.ListVaultSummariesResponse.vaults:type_name -> api.VaultSummary
+	24, // 6: api.ListVaultVersionsResponse.versions:type_name -> api.VaultVersion
+	21, // 7: api.SyncResponse.changed:type_name -> api.VaultRecord
+	33, // 8: api.BlobPart.header:type_name -> api.BlobHeader
+	1,  // 9: api.GophKeeper.Register:input_type -> api.RegisterRequest
+	3,  // 10: api.GophKeeper.Login:input_type -> api.LoginRequest
+	5,  // 11: api.GophKeeper.RefreshToken:input_type -> api.RefreshTokenRequest
+	39, // 12: api.GophKeeper.Logout:input_type -> google.protobuf.Empty
+	39, // 13: api.GophKeeper.EnrollMFA:input_type -> google.protobuf.Empty
+	7,  // 14: api.GophKeeper.ConfirmMFA:input_type -> api.ConfirmMFARequest
+	9,  // 15: api.GophKeeper.VerifyMFA:input_type -> api.VerifyMFARequest
+	39, // 16: api.GophKeeper.ListSessions:input_type -> google.protobuf.Empty
+	12, // 17: api.GophKeeper.RevokeSession:input_type -> api.RevokeSessionRequest
+	13, // 18: api.GophKeeper.CreateVault:input_type -> api.CreateVaultRequest
+	14, // 19: api.GophKeeper.GetVault:input_type -> api.GetVaultRequest
+	21, // 20: api.GophKeeper.UpdateVault:input_type -> api.VaultRecord
+	16, // 21: api.GophKeeper.ListVaults:input_type -> api.ListVaultsRequest
+	16, // 22: api.GophKeeper.ListVaultSummaries:input_type -> api.ListVaultsRequest
+	15, // 23: api.GophKeeper.DeleteVault:input_type -> api.DeleteVaultRequest
+	17, // 24: api.GophKeeper.FindVaults:input_type -> api.FindVaultsRequest
+	23, // 25: api.GophKeeper.ListVaultVersions:input_type -> api.ListVaultVersionsRequest
+	26, // 26: api.GophKeeper.RestoreVaultVersion:input_type -> api.RestoreVaultVersionRequest
+	39, // 27: api.GophKeeper.ListTrash:input_type -> google.protobuf.Empty
+	27, // 28: api.GophKeeper.RestoreVault:input_type -> api.RestoreVaultRequest
+	28, // 29: api.GophKeeper.PurgeVault:input_type -> api.PurgeVaultRequest
+	29, // 30: api.GophKeeper.Sync:input_type -> api.SyncRequest
+	31, // 31: api.GophKeeper.Watch:input_type -> api.WatchRequest
+	34, // 32: api.GophKeeper.UploadBlob:input_type -> api.BlobPart
+	36, // 33: api.GophKeeper.GetUpload:input_type -> api.GetUploadRequest
+	37, // 34: api.GophKeeper.DownloadBlob:input_type -> api.DownloadBlobRequest
+	2,  // 35: api.GophKeeper.Register:output_type -> api.RegisterResponse
+	4,  // 36: api.GophKeeper.Login:output_type -> api.LoginResponse
+	4,  // 37: api.GophKeeper.RefreshToken:output_type -> api.LoginResponse
+	39, // 38: api.GophKeeper.Logout:output_type -> google.protobuf.Empty
+	6,  // 39: api.GophKeeper.EnrollMFA:output_type -> api.EnrollMFAResponse
+	8,  // 40: api.GophKeeper.ConfirmMFA:output_type -> api.ConfirmMFAResponse
+	4,  // 41: api.GophKeeper.VerifyMFA:output_type -> api.LoginResponse
+	11, // 42: api.GophKeeper.ListSessions:output_type -> api.ListSessionsResponse
+	39, // 43: api.GophKeeper.RevokeSession:output_type -> google.protobuf.Empty
+	21, // 44: api.GophKeeper.CreateVault:output_type -> api.VaultRecord
+	21, // 45: api.GophKeeper.GetVault:output_type -> api.VaultRecord
+	39, // 46: api.GophKeeper.UpdateVault:output_type -> google.protobuf.Empty
+	18, // 47: api.GophKeeper.ListVaults:output_type -> api.ListVaultsResponse
+	20, // 48: api.GophKeeper.ListVaultSummaries:output_type -> api.ListVaultSummariesResponse
+	39, // 49: api.GophKeeper.DeleteVault:output_type -> google.protobuf.Empty
+	18, // 50: api.GophKeeper.FindVaults:output_type -> api.ListVaultsResponse
+	25, // 51: api.GophKeeper.ListVaultVersions:output_type -> api.ListVaultVersionsResponse
+	21, // 52: api.GophKeeper.RestoreVaultVersion:output_type -> api.VaultRecord
+	18, // 53: api.GophKeeper.ListTrash:output_type -> api.ListVaultsResponse
+	39, // 54: api.GophKeeper.RestoreVault:output_type -> google.protobuf.Empty
+	39, // 55: api.GophKeeper.PurgeVault:output_type -> google.protobuf.Empty
+	30, // 56: api.GophKeeper.Sync:output_type -> api.SyncResponse
+	32, // 57: api.GophKeeper.Watch:output_type -> api.VaultEvent
+	35, // 58: api.GophKeeper.UploadBlob:output_type -> api.UploadStatus
+	35, // 59: api.GophKeeper.GetUpload:output_type -> api.UploadStatus
+	34, // 60: api.GophKeeper.DownloadBlob:output_type -> api.BlobPart
+	35, // [35:61] is the sub-list for method output_type
+	9,  // [9:35] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_server_proto_init() }
@@ -645,18 +2700,23 @@ func file_server_proto_init() {
 	if File_server_proto != nil {
 		return
 	}
+	file_server_proto_msgTypes[33].OneofWrappers = []any{
+		(*BlobPart_Header)(nil),
+		(*BlobPart_Chunk)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_server_proto_rawDesc), len(file_server_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   10,
+			NumEnums:      1,
+			NumMessages:   37,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_server_proto_goTypes,
 		DependencyIndexes: file_server_proto_depIdxs,
+		EnumInfos:         file_server_proto_enumTypes,
 		MessageInfos:      file_server_proto_msgTypes,
 	}.Build()
 	File_server_proto = out.File
@@ -20,13 +20,32 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	GophKeeper_Register_FullMethodName    = "/api.GophKeeper/Register"
-	GophKeeper_Login_FullMethodName       = "/api.GophKeeper/Login"
-	GophKeeper_CreateVault_FullMethodName = "/api.GophKeeper/CreateVault"
-	GophKeeper_GetVault_FullMethodName    = "/api.GophKeeper/GetVault"
-	GophKeeper_UpdateVault_FullMethodName = "/api.GophKeeper/UpdateVault"
-	GophKeeper_ListVaults_FullMethodName  = "/api.GophKeeper/ListVaults"
-	GophKeeper_DeleteVault_FullMethodName = "/api.GophKeeper/DeleteVault"
+	GophKeeper_Register_FullMethodName            = "/api.GophKeeper/Register"
+	GophKeeper_Login_FullMethodName               = "/api.GophKeeper/Login"
+	GophKeeper_RefreshToken_FullMethodName        = "/api.GophKeeper/RefreshToken"
+	GophKeeper_Logout_FullMethodName              = "/api.GophKeeper/Logout"
+	GophKeeper_EnrollMFA_FullMethodName           = "/api.GophKeeper/EnrollMFA"
+	GophKeeper_ConfirmMFA_FullMethodName          = "/api.GophKeeper/ConfirmMFA"
+	GophKeeper_VerifyMFA_FullMethodName           = "/api.GophKeeper/VerifyMFA"
+	GophKeeper_ListSessions_FullMethodName        = "/api.GophKeeper/ListSessions"
+	GophKeeper_RevokeSession_FullMethodName       = "/api.GophKeeper/RevokeSession"
+	GophKeeper_CreateVault_FullMethodName         = "/api.GophKeeper/CreateVault"
+	GophKeeper_GetVault_FullMethodName            = "/api.GophKeeper/GetVault"
+	GophKeeper_UpdateVault_FullMethodName         = "/api.GophKeeper/UpdateVault"
+	GophKeeper_ListVaults_FullMethodName          = "/api.GophKeeper/ListVaults"
+	GophKeeper_ListVaultSummaries_FullMethodName  = "/api.GophKeeper/ListVaultSummaries"
+	GophKeeper_DeleteVault_FullMethodName         = "/api.GophKeeper/DeleteVault"
+	GophKeeper_FindVaults_FullMethodName          = "/api.GophKeeper/FindVaults"
+	GophKeeper_ListVaultVersions_FullMethodName   = "/api.GophKeeper/ListVaultVersions"
+	GophKeeper_RestoreVaultVersion_FullMethodName = "/api.GophKeeper/RestoreVaultVersion"
+	GophKeeper_ListTrash_FullMethodName           = "/api.GophKeeper/ListTrash"
+	GophKeeper_RestoreVault_FullMethodName        = "/api.GophKeeper/RestoreVault"
+	GophKeeper_PurgeVault_FullMethodName          = "/api.GophKeeper/PurgeVault"
+	GophKeeper_Sync_FullMethodName                = "/api.GophKeeper/Sync"
+	GophKeeper_Watch_FullMethodName               = "/api.GophKeeper/Watch"
+	GophKeeper_UploadBlob_FullMethodName          = "/api.GophKeeper/UploadBlob"
+	GophKeeper_GetUpload_FullMethodName           = "/api.GophKeeper/GetUpload"
+	GophKeeper_DownloadBlob_FullMethodName        = "/api.GophKeeper/DownloadBlob"
 )
 
 // GophKeeperClient is the client API for GophKeeper service.
@@ -36,12 +55,38 @@ type GophKeeperClient interface {
 	// User-related methods
 	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	Logout(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Two-factor authentication methods
+	EnrollMFA(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*EnrollMFAResponse, error)
+	ConfirmMFA(ctx context.Context, in *ConfirmMFARequest, opts ...grpc.CallOption) (*ConfirmMFAResponse, error)
+	VerifyMFA(ctx context.Context, in *VerifyMFARequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	// Session-related methods
+	ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// Vault-related methods
-	CreateVault(ctx context.Context, in *CreateVaultRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CreateVault(ctx context.Context, in *CreateVaultRequest, opts ...grpc.CallOption) (*VaultRecord, error)
 	GetVault(ctx context.Context, in *GetVaultRequest, opts ...grpc.CallOption) (*VaultRecord, error)
 	UpdateVault(ctx context.Context, in *VaultRecord, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	ListVaults(ctx context.Context, in *ListVaultsRequest, opts ...grpc.CallOption) (*ListVaultsResponse, error)
+	ListVaultSummaries(ctx context.Context, in *ListVaultsRequest, opts ...grpc.CallOption) (*ListVaultSummariesResponse, error)
 	DeleteVault(ctx context.Context, in *DeleteVaultRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	FindVaults(ctx context.Context, in *FindVaultsRequest, opts ...grpc.CallOption) (*ListVaultsResponse, error)
+	// Vault history methods
+	ListVaultVersions(ctx context.Context, in *ListVaultVersionsRequest, opts ...grpc.CallOption) (*ListVaultVersionsResponse, error)
+	RestoreVaultVersion(ctx context.Context, in *RestoreVaultVersionRequest, opts ...grpc.CallOption) (*VaultRecord, error)
+	// Trash methods
+	ListTrash(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListVaultsResponse, error)
+	RestoreVault(ctx context.Context, in *RestoreVaultRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PurgeVault(ctx context.Context, in *PurgeVaultRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Incremental sync
+	Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*SyncResponse, error)
+	// Live updates: streams changes of the caller's records made by other sessions
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[VaultEvent], error)
+	// Large files: chunked, resumable transfers of encrypted blobs
+	UploadBlob(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BlobPart, UploadStatus], error)
+	GetUpload(ctx context.Context, in *GetUploadRequest, opts ...grpc.CallOption) (*UploadStatus, error)
+	DownloadBlob(ctx context.Context, in *DownloadBlobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BlobPart], error)
 }
 
 type gophKeeperClient struct {
@@ -72,9 +117,79 @@ func (c *gophKeeperClient) Login(ctx context.Context, in *LoginRequest, opts ...
 	return out, nil
 }
 
-func (c *gophKeeperClient) CreateVault(ctx context.Context, in *CreateVaultRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (c *gophKeeperClient) RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_RefreshToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) Logout(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, GophKeeper_Logout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) EnrollMFA(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*EnrollMFAResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnrollMFAResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_EnrollMFA_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ConfirmMFA(ctx context.Context, in *ConfirmMFARequest, opts ...grpc.CallOption) (*ConfirmMFAResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfirmMFAResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_ConfirmMFA_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) VerifyMFA(ctx context.Context, in *VerifyMFARequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_VerifyMFA_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, GophKeeper_RevokeSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) CreateVault(ctx context.Context, in *CreateVaultRequest, opts ...grpc.CallOption) (*VaultRecord, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VaultRecord)
 	err := c.cc.Invoke(ctx, GophKeeper_CreateVault_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -112,6 +227,16 @@ func (c *gophKeeperClient) ListVaults(ctx context.Context, in *ListVaultsRequest
 	return out, nil
 }
 
+func (c *gophKeeperClient) ListVaultSummaries(ctx context.Context, in *ListVaultsRequest, opts ...grpc.CallOption) (*ListVaultSummariesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVaultSummariesResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_ListVaultSummaries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gophKeeperClient) DeleteVault(ctx context.Context, in *DeleteVaultRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -122,6 +247,137 @@ func (c *gophKeeperClient) DeleteVault(ctx context.Context, in *DeleteVaultReque
 	return out, nil
 }
 
+func (c *gophKeeperClient) FindVaults(ctx context.Context, in *FindVaultsRequest, opts ...grpc.CallOption) (*ListVaultsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVaultsResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_FindVaults_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListVaultVersions(ctx context.Context, in *ListVaultVersionsRequest, opts ...grpc.CallOption) (*ListVaultVersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVaultVersionsResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_ListVaultVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RestoreVaultVersion(ctx context.Context, in *RestoreVaultVersionRequest, opts ...grpc.CallOption) (*VaultRecord, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VaultRecord)
+	err := c.cc.Invoke(ctx, GophKeeper_RestoreVaultVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListTrash(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListVaultsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVaultsResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_ListTrash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RestoreVault(ctx context.Context, in *RestoreVaultRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, GophKeeper_RestoreVault_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) PurgeVault(ctx context.Context, in *PurgeVaultRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, GophKeeper_PurgeVault_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*SyncResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_Sync_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[VaultEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GophKeeper_ServiceDesc.Streams[0], GophKeeper_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, VaultEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GophKeeper_WatchClient = grpc.ServerStreamingClient[VaultEvent]
+
+func (c *gophKeeperClient) UploadBlob(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BlobPart, UploadStatus], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GophKeeper_ServiceDesc.Streams[1], GophKeeper_UploadBlob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BlobPart, UploadStatus]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GophKeeper_UploadBlobClient = grpc.ClientStreamingClient[BlobPart, UploadStatus]
+
+func (c *gophKeeperClient) GetUpload(ctx context.Context, in *GetUploadRequest, opts ...grpc.CallOption) (*UploadStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadStatus)
+	err := c.cc.Invoke(ctx, GophKeeper_GetUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) DownloadBlob(ctx context.Context, in *DownloadBlobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BlobPart], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GophKeeper_ServiceDesc.Streams[2], GophKeeper_DownloadBlob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadBlobRequest, BlobPart]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GophKeeper_DownloadBlobClient = grpc.ServerStreamingClient[BlobPart]
+
 // GophKeeperServer is the server API for GophKeeper service.
 // All implementations must embed UnimplementedGophKeeperServer
 // for forward compatibility.
@@ -129,12 +385,38 @@ type GophKeeperServer interface {
 	// User-related methods
 	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	RefreshToken(context.Context, *RefreshTokenRequest) (*LoginResponse, error)
+	Logout(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// Two-factor authentication methods
+	EnrollMFA(context.Context, *emptypb.Empty) (*EnrollMFAResponse, error)
+	ConfirmMFA(context.Context, *ConfirmMFARequest) (*ConfirmMFAResponse, error)
+	VerifyMFA(context.Context, *VerifyMFARequest) (*LoginResponse, error)
+	// Session-related methods
+	ListSessions(context.Context, *emptypb.Empty) (*ListSessionsResponse, error)
+	RevokeSession(context.Context, *RevokeSessionRequest) (*emptypb.Empty, error)
 	// Vault-related methods
-	CreateVault(context.Context, *CreateVaultRequest) (*emptypb.Empty, error)
+	CreateVault(context.Context, *CreateVaultRequest) (*VaultRecord, error)
 	GetVault(context.Context, *GetVaultRequest) (*VaultRecord, error)
 	UpdateVault(context.Context, *VaultRecord) (*emptypb.Empty, error)
 	ListVaults(context.Context, *ListVaultsRequest) (*ListVaultsResponse, error)
+	ListVaultSummaries(context.Context, *ListVaultsRequest) (*ListVaultSummariesResponse, error)
 	DeleteVault(context.Context, *DeleteVaultRequest) (*emptypb.Empty, error)
+	FindVaults(context.Context, *FindVaultsRequest) (*ListVaultsResponse, error)
+	// Vault history methods
+	ListVaultVersions(context.Context, *ListVaultVersionsRequest) (*ListVaultVersionsResponse, error)
+	RestoreVaultVersion(context.Context, *RestoreVaultVersionRequest) (*VaultRecord, error)
+	// Trash methods
+	ListTrash(context.Context, *emptypb.Empty) (*ListVaultsResponse, error)
+	RestoreVault(context.Context, *RestoreVaultRequest) (*emptypb.Empty, error)
+	PurgeVault(context.Context, *PurgeVaultRequest) (*emptypb.Empty, error)
+	// Incremental sync
+	Sync(context.Context, *SyncRequest) (*SyncResponse, error)
+	// Live updates: streams changes of the caller's records made by other sessions
+	Watch(*WatchRequest, grpc.ServerStreamingServer[VaultEvent]) error
+	// Large files: chunked, resumable transfers of encrypted blobs
+	UploadBlob(grpc.ClientStreamingServer[BlobPart, UploadStatus]) error
+	GetUpload(context.Context, *GetUploadRequest) (*UploadStatus, error)
+	DownloadBlob(*DownloadBlobRequest, grpc.ServerStreamingServer[BlobPart]) error
 	mustEmbedUnimplementedGophKeeperServer()
 }
 
@@ -151,7 +433,28 @@ func (UnimplementedGophKeeperServer) Register(context.Context, *RegisterRequest)
 func (UnimplementedGophKeeperServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
 }
-func (UnimplementedGophKeeperServer) CreateVault(context.Context, *CreateVaultRequest) (*emptypb.Empty, error) {
+func (UnimplementedGophKeeperServer) RefreshToken(context.Context, *RefreshTokenRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshToken not implemented")
+}
+func (UnimplementedGophKeeperServer) Logout(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
+}
+func (UnimplementedGophKeeperServer) EnrollMFA(context.Context, *emptypb.Empty) (*EnrollMFAResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollMFA not implemented")
+}
+func (UnimplementedGophKeeperServer) ConfirmMFA(context.Context, *ConfirmMFARequest) (*ConfirmMFAResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmMFA not implemented")
+}
+func (UnimplementedGophKeeperServer) VerifyMFA(context.Context, *VerifyMFARequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyMFA not implemented")
+}
+func (UnimplementedGophKeeperServer) ListSessions(context.Context, *emptypb.Empty) (*ListSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedGophKeeperServer) RevokeSession(context.Context, *RevokeSessionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedGophKeeperServer) CreateVault(context.Context, *CreateVaultRequest) (*VaultRecord, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateVault not implemented")
 }
 func (UnimplementedGophKeeperServer) GetVault(context.Context, *GetVaultRequest) (*VaultRecord, error) {
@@ -163,9 +466,45 @@ func (UnimplementedGophKeeperServer) UpdateVault(context.Context, *VaultRecord)
 func (UnimplementedGophKeeperServer) ListVaults(context.Context, *ListVaultsRequest) (*ListVaultsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListVaults not implemented")
 }
+func (UnimplementedGophKeeperServer) ListVaultSummaries(context.Context, *ListVaultsRequest) (*ListVaultSummariesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVaultSummaries not implemented")
+}
 func (UnimplementedGophKeeperServer) DeleteVault(context.Context, *DeleteVaultRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteVault not implemented")
 }
+func (UnimplementedGophKeeperServer) FindVaults(context.Context, *FindVaultsRequest) (*ListVaultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindVaults not implemented")
+}
+func (UnimplementedGophKeeperServer) ListVaultVersions(context.Context, *ListVaultVersionsRequest) (*ListVaultVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVaultVersions not implemented")
+}
+func (UnimplementedGophKeeperServer) RestoreVaultVersion(context.Context, *RestoreVaultVersionRequest) (*VaultRecord, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreVaultVersion not implemented")
+}
+func (UnimplementedGophKeeperServer) ListTrash(context.Context, *emptypb.Empty) (*ListVaultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (UnimplementedGophKeeperServer) RestoreVault(context.Context, *RestoreVaultRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreVault not implemented")
+}
+func (UnimplementedGophKeeperServer) PurgeVault(context.Context, *PurgeVaultRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeVault not implemented")
+}
+func (UnimplementedGophKeeperServer) Sync(context.Context, *SyncRequest) (*SyncResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sync not implemented")
+}
+func (UnimplementedGophKeeperServer) Watch(*WatchRequest, grpc.ServerStreamingServer[VaultEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedGophKeeperServer) UploadBlob(grpc.ClientStreamingServer[BlobPart, UploadStatus]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadBlob not implemented")
+}
+func (UnimplementedGophKeeperServer) GetUpload(context.Context, *GetUploadRequest) (*UploadStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUpload not implemented")
+}
+func (UnimplementedGophKeeperServer) DownloadBlob(*DownloadBlobRequest, grpc.ServerStreamingServer[BlobPart]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadBlob not implemented")
+}
 func (UnimplementedGophKeeperServer) mustEmbedUnimplementedGophKeeperServer() {}
 func (UnimplementedGophKeeperServer) testEmbeddedByValue()                    {}
 
@@ -223,6 +562,132 @@ func _GophKeeper_Login_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GophKeeper_RefreshToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RefreshToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_RefreshToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RefreshToken(ctx, req.(*RefreshTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_Logout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).Logout(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_EnrollMFA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).EnrollMFA(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_EnrollMFA_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).EnrollMFA(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ConfirmMFA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmMFARequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ConfirmMFA(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_ConfirmMFA_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ConfirmMFA(ctx, req.(*ConfirmMFARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_VerifyMFA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyMFARequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).VerifyMFA(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_VerifyMFA_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).VerifyMFA(ctx, req.(*VerifyMFARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListSessions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_RevokeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RevokeSession(ctx, req.(*RevokeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GophKeeper_CreateVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateVaultRequest)
 	if err := dec(in); err != nil {
@@ -295,6 +760,24 @@ func _GophKeeper_ListVaults_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GophKeeper_ListVaultSummaries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVaultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListVaultSummaries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_ListVaultSummaries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListVaultSummaries(ctx, req.(*ListVaultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GophKeeper_DeleteVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteVaultRequest)
 	if err := dec(in); err != nil {
@@ -313,6 +796,179 @@ func _GophKeeper_DeleteVault_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GophKeeper_FindVaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindVaultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).FindVaults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_FindVaults_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).FindVaults(ctx, req.(*FindVaultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListVaultVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVaultVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListVaultVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_ListVaultVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListVaultVersions(ctx, req.(*ListVaultVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RestoreVaultVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreVaultVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RestoreVaultVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_RestoreVaultVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RestoreVaultVersion(ctx, req.(*RestoreVaultVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_ListTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListTrash(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RestoreVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreVaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RestoreVault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_RestoreVault_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RestoreVault(ctx, req.(*RestoreVaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_PurgeVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeVaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).PurgeVault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_PurgeVault_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).PurgeVault(ctx, req.(*PurgeVaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_Sync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).Sync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_Sync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).Sync(ctx, req.(*SyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GophKeeperServer).Watch(m, &grpc.GenericServerStream[WatchRequest, VaultEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GophKeeper_WatchServer = grpc.ServerStreamingServer[VaultEvent]
+
+func _GophKeeper_UploadBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GophKeeperServer).UploadBlob(&grpc.GenericServerStream[BlobPart, UploadStatus]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GophKeeper_UploadBlobServer = grpc.ClientStreamingServer[BlobPart, UploadStatus]
+
+func _GophKeeper_GetUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_GetUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetUpload(ctx, req.(*GetUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_DownloadBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadBlobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GophKeeperServer).DownloadBlob(m, &grpc.GenericServerStream[DownloadBlobRequest, BlobPart]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GophKeeper_DownloadBlobServer = grpc.ServerStreamingServer[BlobPart]
+
 // GophKeeper_ServiceDesc is the grpc.ServiceDesc for GophKeeper service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -328,6 +984,34 @@ var GophKeeper_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Login",
 			Handler:    _GophKeeper_Login_Handler,
 		},
+		{
+			MethodName: "RefreshToken",
+			Handler:    _GophKeeper_RefreshToken_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _GophKeeper_Logout_Handler,
+		},
+		{
+			MethodName: "EnrollMFA",
+			Handler:    _GophKeeper_EnrollMFA_Handler,
+		},
+		{
+			MethodName: "ConfirmMFA",
+			Handler:    _GophKeeper_ConfirmMFA_Handler,
+		},
+		{
+			MethodName: "VerifyMFA",
+			Handler:    _GophKeeper_VerifyMFA_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _GophKeeper_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _GophKeeper_RevokeSession_Handler,
+		},
 		{
 			MethodName: "CreateVault",
 			Handler:    _GophKeeper_CreateVault_Handler,
@@ -344,11 +1028,63 @@ var GophKeeper_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListVaults",
 			Handler:    _GophKeeper_ListVaults_Handler,
 		},
+		{
+			MethodName: "ListVaultSummaries",
+			Handler:    _GophKeeper_ListVaultSummaries_Handler,
+		},
 		{
 			MethodName: "DeleteVault",
 			Handler:    _GophKeeper_DeleteVault_Handler,
 		},
+		{
+			MethodName: "FindVaults",
+			Handler:    _GophKeeper_FindVaults_Handler,
+		},
+		{
+			MethodName: "ListVaultVersions",
+			Handler:    _GophKeeper_ListVaultVersions_Handler,
+		},
+		{
+			MethodName: "RestoreVaultVersion",
+			Handler:    _GophKeeper_RestoreVaultVersion_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _GophKeeper_ListTrash_Handler,
+		},
+		{
+			MethodName: "RestoreVault",
+			Handler:    _GophKeeper_RestoreVault_Handler,
+		},
+		{
+			MethodName: "PurgeVault",
+			Handler:    _GophKeeper_PurgeVault_Handler,
+		},
+		{
+			MethodName: "Sync",
+			Handler:    _GophKeeper_Sync_Handler,
+		},
+		{
+			MethodName: "GetUpload",
+			Handler:    _GophKeeper_GetUpload_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _GophKeeper_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadBlob",
+			Handler:       _GophKeeper_UploadBlob_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadBlob",
+			Handler:       _GophKeeper_DownloadBlob_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "server.proto",
 }
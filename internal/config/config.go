@@ -2,8 +2,10 @@
 package config
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/samber/do/v2"
@@ -12,9 +14,16 @@ import (
 
 // Config holds the full application configuration loaded from file.
 type Config struct {
-	Server       Server   `mapstructure:"server"`
-	Database     Database `mapstructure:"database"`
-	KV           KV       `mapstructure:"databaseKV"`
+	Server       Server     `mapstructure:"server"`
+	Database     Database   `mapstructure:"database"`
+	KV           KV         `mapstructure:"databaseKV"`
+	JWT          JWT        `mapstructure:"jwt"`
+	RateLimit    RateLimit  `mapstructure:"rateLimit"`
+	History      History    `mapstructure:"history"`
+	Trash        Trash      `mapstructure:"trash"`
+	Watch        Watch      `mapstructure:"watch"`
+	Blobs        Blobs      `mapstructure:"blobs"`
+	Encryption   Encryption `mapstructure:"encryption"`
 	Master       string
 	Envinronment string `mapstructure:"envinronment"`
 }
@@ -22,6 +31,23 @@ type Config struct {
 // Server contains server-related configuration such as port.
 type Server struct {
 	Port string `mapstructure:"port"`
+	Host string `mapstructure:"host"` // address dialed by the client, localhost by default
+	TLS  TLS    `mapstructure:"tls"`
+}
+
+// TLS contains transport security settings shared by the server and the client.
+//
+// On the server CertFile/KeyFile are its own certificate and CAFile verifies
+// client certificates when ClientAuth is set. On the client CAFile pins the CA
+// the server certificate must chain to, and CertFile/KeyFile are presented
+// to servers that require mutual TLS.
+type TLS struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	CertFile   string `mapstructure:"certFile"`
+	KeyFile    string `mapstructure:"keyFile"`
+	CAFile     string `mapstructure:"caFile"`
+	ClientAuth bool   `mapstructure:"clientAuth"` // server only
+	ServerName string `mapstructure:"serverName"` // client only, overrides the host name checked in the certificate
 }
 
 // Database contains database connection settings.
@@ -34,6 +60,118 @@ type KV struct {
 	DirPath string `mapstructure:"dirPath"`
 }
 
+// JWT contains access token settings.
+//
+// Keys lists every key accepted for verification; SigningKey names the one
+// used for new tokens. Rotation is done by adding a new key, switching
+// SigningKey to it and removing the old key once its tokens have expired.
+type JWT struct {
+	Issuer     string        `mapstructure:"issuer"`
+	Audience   string        `mapstructure:"audience"`
+	AccessTTL  time.Duration `mapstructure:"accessTTL"`
+	RefreshTTL time.Duration `mapstructure:"refreshTTL"`
+	SigningKey string        `mapstructure:"signingKey"`
+	Keys       []JWTKey      `mapstructure:"keys"`
+}
+
+// JWTKey describes a single key identified by the token "kid" header.
+type JWTKey struct {
+	ID         string `mapstructure:"id"`
+	Algorithm  string `mapstructure:"algorithm"`  // HS256 or EdDSA
+	Secret     string `mapstructure:"secret"`     // HS256 shared secret
+	PrivateKey string `mapstructure:"privateKey"` // EdDSA base64 seed, may be empty for verification-only keys
+	PublicKey  string `mapstructure:"publicKey"`  // EdDSA base64 public key
+}
+
+// RateLimit contains brute-force protection settings for login methods.
+//
+// Attempts are limited by token buckets per peer IP and per login; after
+// MaxFailures consecutive failures a key is locked out for LockoutBase,
+// doubled with every further failure up to LockoutMax. Zero values fall
+// back to defaults.
+type RateLimit struct {
+	Backend     string        `mapstructure:"backend"` // memory (default) or postgres
+	IPBurst     int           `mapstructure:"ipBurst"`
+	IPEvery     time.Duration `mapstructure:"ipEvery"`
+	LoginBurst  int           `mapstructure:"loginBurst"`
+	LoginEvery  time.Duration `mapstructure:"loginEvery"`
+	MaxFailures int           `mapstructure:"maxFailures"`
+	LockoutBase time.Duration `mapstructure:"lockoutBase"`
+	LockoutMax  time.Duration `mapstructure:"lockoutMax"`
+}
+
+// History contains retention settings for previous versions of vault records.
+//
+// A version is pruned once it is neither among the newest KeepVersions nor
+// younger than KeepFor. A zero value disables its condition; with both unset
+// every version is kept.
+type History struct {
+	KeepVersions int           `mapstructure:"keepVersions"`
+	KeepFor      time.Duration `mapstructure:"keepFor"`
+}
+
+// Trash contains settings of the trash bin. Deleted records stay restorable
+// for Retention and are permanently removed by a background purge that runs
+// every PurgeInterval.
+type Trash struct {
+	Retention     time.Duration `mapstructure:"retention"`
+	PurgeInterval time.Duration `mapstructure:"purgeInterval"`
+}
+
+// Watch selects how vault change events reach the clients watching them.
+// The memory backend serves a single server instance; with several instances
+// behind one database, postgres relays the events with LISTEN/NOTIFY.
+type Watch struct {
+	Backend string `mapstructure:"backend"` // memory (default) or postgres
+}
+
+// Blobs selects where the encrypted chunks of uploaded files are kept. The fs
+// backend stores them under Dir, s3 in a bucket of an S3-compatible service
+// and memory inside the process, for tests only.
+//
+// Blobs no vault record refers to and chunks of no blob are removed every
+// GCInterval once they are older than GCGrace, which leaves clients time to
+// save the record of a finished upload.
+type Blobs struct {
+	Backend    string        `mapstructure:"backend"` // fs (default), s3 or memory
+	Dir        string        `mapstructure:"dir"`
+	S3         S3            `mapstructure:"s3"`
+	GCInterval time.Duration `mapstructure:"gcInterval"`
+	GCGrace    time.Duration `mapstructure:"gcGrace"`
+}
+
+// Encryption contains client-side encryption settings. Cipher is the cipher
+// new record contents are sealed with: aes-256-gcm (default) or
+// xchacha20-poly1305. Contents sealed with either are always readable.
+// PrivateMetadata also seals the titles and metadata of the records written,
+// so the server only stores them encrypted.
+type Encryption struct {
+	Cipher          string `mapstructure:"cipher"`
+	PrivateMetadata bool   `mapstructure:"privateMetadata"`
+}
+
+// S3 contains the bucket of the s3 blob backend.
+type S3 struct {
+	Endpoint  string `mapstructure:"endpoint"` // e.g. https://s3.amazonaws.com or http://localhost:9000
+	Region    string `mapstructure:"region"`   // us-east-1 by default
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"accessKey"`
+	SecretKey string `mapstructure:"secretKey"`
+	Prefix    string `mapstructure:"prefix"` // objects are kept and collected under it, blobs/ by default, "/" for the root
+}
+
+// JWT environment variables override the file so secrets can stay out of it.
+const (
+	envJWTSecret = "GK_JWT_SECRET"
+	envJWTKeyID  = "GK_JWT_KID"
+)
+
+// S3 environment variables override the credentials of the s3 blob backend.
+const (
+	envS3AccessKey = "GK_S3_ACCESS_KEY"
+	envS3SecretKey = "GK_S3_SECRET_KEY"
+)
+
 // NewConfig loads configuration from a file using viper and sets defaults where needed.
 func NewConfig(i do.Injector) (*Config, error) {
 	configPath := do.MustInvokeNamed[string](i, "config.path")
@@ -64,5 +202,45 @@ func NewConfig(i do.Injector) (*Config, error) {
 	}
 
 	cfg.Master = viper.GetString("master")
+	applyJWTEnv(&cfg.JWT)
+	applyS3Env(&cfg.Blobs.S3)
+
 	return &cfg, nil
 }
+
+// applyJWTEnv adds an HS256 key from the environment and makes it the signing
+// key. Without GK_JWT_KID the secret is that of the configured signing key.
+func applyJWTEnv(cfg *JWT) {
+	secret := os.Getenv(envJWTSecret)
+	if secret == "" {
+		return
+	}
+
+	kid := os.Getenv(envJWTKeyID)
+	if kid == "" {
+		kid = cfg.SigningKey
+	}
+	if kid == "" {
+		kid = "env"
+	}
+
+	keys := []JWTKey{{ID: kid, Algorithm: "HS256", Secret: secret}}
+	for _, k := range cfg.Keys {
+		if k.ID != kid {
+			keys = append(keys, k)
+		}
+	}
+
+	cfg.Keys = keys
+	cfg.SigningKey = kid
+}
+
+// applyS3Env takes the S3 credentials from the environment when they are set there.
+func applyS3Env(cfg *S3) {
+	if v := os.Getenv(envS3AccessKey); v != "" {
+		cfg.AccessKey = v
+	}
+	if v := os.Getenv(envS3SecretKey); v != "" {
+		cfg.SecretKey = v
+	}
+}
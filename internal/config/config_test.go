@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/samber/do/v2"
 	"github.com/stretchr/testify/require"
@@ -77,4 +78,91 @@ server: "not a map"
 		_, err = NewConfig(i)
 		require.ErrorContains(t, err, "unable to decode")
 	})
+
+	t.Run("success: jwt keys from file and env", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.dev.yaml")
+
+		err := os.WriteFile(path, []byte(`
+jwt:
+  issuer: gophkeeper
+  accessTTL: 15m
+  signingKey: old
+  keys:
+    - id: old
+      algorithm: HS256
+      secret: file-secret
+`), 0644)
+		require.NoError(t, err)
+
+		t.Setenv("GK_JWT_SECRET", "env-secret")
+		t.Setenv("GK_JWT_KID", "new")
+
+		i := do.New()
+		do.ProvideNamedValue[string](i, "config.path", path)
+
+		cfg, err := NewConfig(i)
+		require.NoError(t, err)
+		require.Equal(t, "gophkeeper", cfg.JWT.Issuer)
+		require.Equal(t, 15*time.Minute, cfg.JWT.AccessTTL)
+		require.Equal(t, "new", cfg.JWT.SigningKey)
+		require.Len(t, cfg.JWT.Keys, 2)
+		require.Equal(t, "env-secret", cfg.JWT.Keys[0].Secret)
+		require.Equal(t, "old", cfg.JWT.Keys[1].ID)
+	})
+
+	t.Run("success: jwt secret from env for the signing key", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.dev.yaml")
+
+		err := os.WriteFile(path, []byte(`
+jwt:
+  signingKey: "2025-07"
+  keys:
+    - id: "2025-07"
+      algorithm: HS256
+      secret: ""
+`), 0644)
+		require.NoError(t, err)
+
+		t.Setenv("GK_JWT_SECRET", "env-secret")
+
+		i := do.New()
+		do.ProvideNamedValue[string](i, "config.path", path)
+
+		cfg, err := NewConfig(i)
+		require.NoError(t, err)
+		require.Equal(t, "2025-07", cfg.JWT.SigningKey)
+		require.Equal(t, []JWTKey{{ID: "2025-07", Algorithm: "HS256", Secret: "env-secret"}}, cfg.JWT.Keys)
+	})
+
+	t.Run("success: s3 blobs with credentials from env", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.dev.yaml")
+
+		err := os.WriteFile(path, []byte(`
+blobs:
+  backend: s3
+  gcGrace: 48h
+  s3:
+    endpoint: http://localhost:9000
+    bucket: gophkeeper
+    accessKey: file-key
+    secretKey: file-secret
+`), 0644)
+		require.NoError(t, err)
+
+		t.Setenv("GK_S3_SECRET_KEY", "env-secret")
+
+		i := do.New()
+		do.ProvideNamedValue[string](i, "config.path", path)
+
+		cfg, err := NewConfig(i)
+		require.NoError(t, err)
+		require.Equal(t, "s3", cfg.Blobs.Backend)
+		require.Equal(t, 48*time.Hour, cfg.Blobs.GCGrace)
+		require.Equal(t, "gophkeeper", cfg.Blobs.S3.Bucket)
+		require.Equal(t, "file-key", cfg.Blobs.S3.AccessKey)
+		require.Equal(t, "env-secret", cfg.Blobs.S3.SecretKey)
+	})
 }
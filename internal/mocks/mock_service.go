@@ -12,7 +12,9 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	pubsub "github.com/wickedv43/go-goph-keeper/internal/pubsub"
 	storage "github.com/wickedv43/go-goph-keeper/internal/storage"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +43,97 @@ func (m *MockGophKeeper) EXPECT() *MockGophKeeperMockRecorder {
 	return m.recorder
 }
 
+// Authenticate mocks base method.
+func (m *MockGophKeeper) Authenticate(ctx context.Context, login, password string) (storage.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, login, password)
+	ret0, _ := ret[0].(storage.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockGophKeeperMockRecorder) Authenticate(ctx, login, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockGophKeeper)(nil).Authenticate), ctx, login, password)
+}
+
+// Blob mocks base method.
+func (m *MockGophKeeper) Blob(ctx context.Context, uID uint64, ref string) (storage.Blob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Blob", ctx, uID, ref)
+	ret0, _ := ret[0].(storage.Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Blob indicates an expected call of Blob.
+func (mr *MockGophKeeperMockRecorder) Blob(ctx, uID, ref any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Blob", reflect.TypeOf((*MockGophKeeper)(nil).Blob), ctx, uID, ref)
+}
+
+// BlobChunk mocks base method.
+func (m *MockGophKeeper) BlobChunk(ctx context.Context, b storage.Blob, seq uint32) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlobChunk", ctx, b, seq)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlobChunk indicates an expected call of BlobChunk.
+func (mr *MockGophKeeperMockRecorder) BlobChunk(ctx, b, seq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlobChunk", reflect.TypeOf((*MockGophKeeper)(nil).BlobChunk), ctx, b, seq)
+}
+
+// CheckSession mocks base method.
+func (m *MockGophKeeper) CheckSession(ctx context.Context, uID, sID uint64) (storage.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckSession", ctx, uID, sID)
+	ret0, _ := ret[0].(storage.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckSession indicates an expected call of CheckSession.
+func (mr *MockGophKeeperMockRecorder) CheckSession(ctx, uID, sID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSession", reflect.TypeOf((*MockGophKeeper)(nil).CheckSession), ctx, uID, sID)
+}
+
+// CollectBlobs mocks base method.
+func (m *MockGophKeeper) CollectBlobs(ctx context.Context, before time.Time) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CollectBlobs", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CollectBlobs indicates an expected call of CollectBlobs.
+func (mr *MockGophKeeperMockRecorder) CollectBlobs(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectBlobs", reflect.TypeOf((*MockGophKeeper)(nil).CollectBlobs), ctx, before)
+}
+
+// ConfirmMFA mocks base method.
+func (m *MockGophKeeper) ConfirmMFA(ctx context.Context, uID uint64, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmMFA", ctx, uID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmMFA indicates an expected call of ConfirmMFA.
+func (mr *MockGophKeeperMockRecorder) ConfirmMFA(ctx, uID, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmMFA", reflect.TypeOf((*MockGophKeeper)(nil).ConfirmMFA), ctx, uID, code)
+}
+
 // CreateVault mocks base method.
 func (m *MockGophKeeper) CreateVault(ctx context.Context, v *storage.VaultRecord) error {
 	m.ctrl.T.Helper()
@@ -56,47 +149,155 @@ func (mr *MockGophKeeperMockRecorder) CreateVault(ctx, v any) *gomock.Call {
 }
 
 // DeleteVault mocks base method.
-func (m *MockGophKeeper) DeleteVault(ctx context.Context, vID uint64) error {
+func (m *MockGophKeeper) DeleteVault(ctx context.Context, uID, vID uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteVault", ctx, vID)
+	ret := m.ctrl.Call(m, "DeleteVault", ctx, uID, vID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteVault indicates an expected call of DeleteVault.
-func (mr *MockGophKeeperMockRecorder) DeleteVault(ctx, vID any) *gomock.Call {
+func (mr *MockGophKeeperMockRecorder) DeleteVault(ctx, uID, vID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVault", reflect.TypeOf((*MockGophKeeper)(nil).DeleteVault), ctx, uID, vID)
+}
+
+// EnrollMFA mocks base method.
+func (m *MockGophKeeper) EnrollMFA(ctx context.Context, uID uint64) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollMFA", ctx, uID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// EnrollMFA indicates an expected call of EnrollMFA.
+func (mr *MockGophKeeperMockRecorder) EnrollMFA(ctx, uID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVault", reflect.TypeOf((*MockGophKeeper)(nil).DeleteVault), ctx, vID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollMFA", reflect.TypeOf((*MockGophKeeper)(nil).EnrollMFA), ctx, uID)
+}
+
+// FindVaults mocks base method.
+func (m *MockGophKeeper) FindVaults(ctx context.Context, uID uint64, tokens []string) ([]storage.VaultRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVaults", ctx, uID, tokens)
+	ret0, _ := ret[0].([]storage.VaultRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVaults indicates an expected call of FindVaults.
+func (mr *MockGophKeeperMockRecorder) FindVaults(ctx, uID, tokens any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVaults", reflect.TypeOf((*MockGophKeeper)(nil).FindVaults), ctx, uID, tokens)
 }
 
 // GetVault mocks base method.
-func (m *MockGophKeeper) GetVault(ctx context.Context, vID uint64) (storage.VaultRecord, error) {
+func (m *MockGophKeeper) GetVault(ctx context.Context, uID, vID uint64) (storage.VaultRecord, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetVault", ctx, vID)
+	ret := m.ctrl.Call(m, "GetVault", ctx, uID, vID)
 	ret0, _ := ret[0].(storage.VaultRecord)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetVault indicates an expected call of GetVault.
-func (mr *MockGophKeeperMockRecorder) GetVault(ctx, vID any) *gomock.Call {
+func (mr *MockGophKeeperMockRecorder) GetVault(ctx, uID, vID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVault", reflect.TypeOf((*MockGophKeeper)(nil).GetVault), ctx, vID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVault", reflect.TypeOf((*MockGophKeeper)(nil).GetVault), ctx, uID, vID)
 }
 
-// ListVaults mocks base method.
-func (m *MockGophKeeper) ListVaults(ctx context.Context, uID uint64) ([]storage.VaultRecord, error) {
+// ListSessions mocks base method.
+func (m *MockGophKeeper) ListSessions(ctx context.Context, uID uint64) ([]storage.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx, uID)
+	ret0, _ := ret[0].([]storage.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockGophKeeperMockRecorder) ListSessions(ctx, uID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockGophKeeper)(nil).ListSessions), ctx, uID)
+}
+
+// ListTrash mocks base method.
+func (m *MockGophKeeper) ListTrash(ctx context.Context, uID uint64) ([]storage.VaultRecord, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListVaults", ctx, uID)
+	ret := m.ctrl.Call(m, "ListTrash", ctx, uID)
 	ret0, _ := ret[0].([]storage.VaultRecord)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockGophKeeperMockRecorder) ListTrash(ctx, uID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockGophKeeper)(nil).ListTrash), ctx, uID)
+}
+
+// ListVaultSummaries mocks base method.
+func (m *MockGophKeeper) ListVaultSummaries(ctx context.Context, uID uint64, f storage.VaultFilter, pageToken string) ([]storage.VaultSummary, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVaultSummaries", ctx, uID, f, pageToken)
+	ret0, _ := ret[0].([]storage.VaultSummary)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListVaultSummaries indicates an expected call of ListVaultSummaries.
+func (mr *MockGophKeeperMockRecorder) ListVaultSummaries(ctx, uID, f, pageToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaultSummaries", reflect.TypeOf((*MockGophKeeper)(nil).ListVaultSummaries), ctx, uID, f, pageToken)
+}
+
+// ListVaultVersions mocks base method.
+func (m *MockGophKeeper) ListVaultVersions(ctx context.Context, uID, vID uint64) ([]storage.VaultRecordVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVaultVersions", ctx, uID, vID)
+	ret0, _ := ret[0].([]storage.VaultRecordVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVaultVersions indicates an expected call of ListVaultVersions.
+func (mr *MockGophKeeperMockRecorder) ListVaultVersions(ctx, uID, vID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaultVersions", reflect.TypeOf((*MockGophKeeper)(nil).ListVaultVersions), ctx, uID, vID)
+}
+
+// ListVaults mocks base method.
+func (m *MockGophKeeper) ListVaults(ctx context.Context, uID uint64, f storage.VaultFilter, pageToken string) ([]storage.VaultRecord, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVaults", ctx, uID, f, pageToken)
+	ret0, _ := ret[0].([]storage.VaultRecord)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // ListVaults indicates an expected call of ListVaults.
-func (mr *MockGophKeeperMockRecorder) ListVaults(ctx, uID any) *gomock.Call {
+func (mr *MockGophKeeperMockRecorder) ListVaults(ctx, uID, f, pageToken any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaults", reflect.TypeOf((*MockGophKeeper)(nil).ListVaults), ctx, uID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaults", reflect.TypeOf((*MockGophKeeper)(nil).ListVaults), ctx, uID, f, pageToken)
+}
+
+// MFARequired mocks base method.
+func (m *MockGophKeeper) MFARequired(ctx context.Context, uID uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MFARequired", ctx, uID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MFARequired indicates an expected call of MFARequired.
+func (mr *MockGophKeeperMockRecorder) MFARequired(ctx, uID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MFARequired", reflect.TypeOf((*MockGophKeeper)(nil).MFARequired), ctx, uID)
 }
 
 // NewUser mocks base method.
@@ -114,6 +315,138 @@ func (mr *MockGophKeeperMockRecorder) NewUser(ctx, u any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewUser", reflect.TypeOf((*MockGophKeeper)(nil).NewUser), ctx, u)
 }
 
+// OpenUpload mocks base method.
+func (m *MockGophKeeper) OpenUpload(ctx context.Context, b *storage.Blob) (storage.Blob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenUpload", ctx, b)
+	ret0, _ := ret[0].(storage.Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenUpload indicates an expected call of OpenUpload.
+func (mr *MockGophKeeperMockRecorder) OpenUpload(ctx, b any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUpload", reflect.TypeOf((*MockGophKeeper)(nil).OpenUpload), ctx, b)
+}
+
+// PurgeTrash mocks base method.
+func (m *MockGophKeeper) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockGophKeeperMockRecorder) PurgeTrash(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockGophKeeper)(nil).PurgeTrash), ctx, before)
+}
+
+// PurgeVault mocks base method.
+func (m *MockGophKeeper) PurgeVault(ctx context.Context, uID, vID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeVault", ctx, uID, vID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeVault indicates an expected call of PurgeVault.
+func (mr *MockGophKeeperMockRecorder) PurgeVault(ctx, uID, vID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeVault", reflect.TypeOf((*MockGophKeeper)(nil).PurgeVault), ctx, uID, vID)
+}
+
+// PutBlobChunk mocks base method.
+func (m *MockGophKeeper) PutBlobChunk(ctx context.Context, b storage.Blob, seq uint32, data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutBlobChunk", ctx, b, seq, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutBlobChunk indicates an expected call of PutBlobChunk.
+func (mr *MockGophKeeperMockRecorder) PutBlobChunk(ctx, b, seq, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutBlobChunk", reflect.TypeOf((*MockGophKeeper)(nil).PutBlobChunk), ctx, b, seq, data)
+}
+
+// RefreshSession mocks base method.
+func (m *MockGophKeeper) RefreshSession(ctx context.Context, token string) (storage.Session, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSession", ctx, token)
+	ret0, _ := ret[0].(storage.Session)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RefreshSession indicates an expected call of RefreshSession.
+func (mr *MockGophKeeperMockRecorder) RefreshSession(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSession", reflect.TypeOf((*MockGophKeeper)(nil).RefreshSession), ctx, token)
+}
+
+// Register mocks base method.
+func (m *MockGophKeeper) Register(ctx context.Context, login, password string) (storage.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, login, password)
+	ret0, _ := ret[0].(storage.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockGophKeeperMockRecorder) Register(ctx, login, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockGophKeeper)(nil).Register), ctx, login, password)
+}
+
+// RestoreVault mocks base method.
+func (m *MockGophKeeper) RestoreVault(ctx context.Context, uID, vID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreVault", ctx, uID, vID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreVault indicates an expected call of RestoreVault.
+func (mr *MockGophKeeperMockRecorder) RestoreVault(ctx, uID, vID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVault", reflect.TypeOf((*MockGophKeeper)(nil).RestoreVault), ctx, uID, vID)
+}
+
+// RestoreVaultVersion mocks base method.
+func (m *MockGophKeeper) RestoreVaultVersion(ctx context.Context, uID, vID, version uint64) (storage.VaultRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreVaultVersion", ctx, uID, vID, version)
+	ret0, _ := ret[0].(storage.VaultRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreVaultVersion indicates an expected call of RestoreVaultVersion.
+func (mr *MockGophKeeperMockRecorder) RestoreVaultVersion(ctx, uID, vID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVaultVersion", reflect.TypeOf((*MockGophKeeper)(nil).RestoreVaultVersion), ctx, uID, vID, version)
+}
+
+// RevokeSession mocks base method.
+func (m *MockGophKeeper) RevokeSession(ctx context.Context, uID, sID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, uID, sID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockGophKeeperMockRecorder) RevokeSession(ctx, uID, sID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockGophKeeper)(nil).RevokeSession), ctx, uID, sID)
+}
+
 // Shutdown mocks base method.
 func (m *MockGophKeeper) Shutdown() error {
 	m.ctrl.T.Helper()
@@ -128,6 +461,51 @@ func (mr *MockGophKeeperMockRecorder) Shutdown() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockGophKeeper)(nil).Shutdown))
 }
 
+// StartSession mocks base method.
+func (m *MockGophKeeper) StartSession(ctx context.Context, uID uint64, device storage.Device) (storage.Session, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartSession", ctx, uID, device)
+	ret0, _ := ret[0].(storage.Session)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// StartSession indicates an expected call of StartSession.
+func (mr *MockGophKeeperMockRecorder) StartSession(ctx, uID, device any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartSession", reflect.TypeOf((*MockGophKeeper)(nil).StartSession), ctx, uID, device)
+}
+
+// Sync mocks base method.
+func (m *MockGophKeeper) Sync(ctx context.Context, uID, since uint64, limit int) (storage.VaultSync, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", ctx, uID, since, limit)
+	ret0, _ := ret[0].(storage.VaultSync)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockGophKeeperMockRecorder) Sync(ctx, uID, since, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockGophKeeper)(nil).Sync), ctx, uID, since, limit)
+}
+
+// TouchSession mocks base method.
+func (m *MockGophKeeper) TouchSession(ctx context.Context, sID uint64, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", ctx, sID, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockGophKeeperMockRecorder) TouchSession(ctx, sID, ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockGophKeeper)(nil).TouchSession), ctx, sID, ip)
+}
+
 // UpdateVault mocks base method.
 func (m *MockGophKeeper) UpdateVault(ctx context.Context, v *storage.VaultRecord) error {
 	m.ctrl.T.Helper()
@@ -143,18 +521,18 @@ func (mr *MockGophKeeperMockRecorder) UpdateVault(ctx, v any) *gomock.Call {
 }
 
 // User mocks base method.
-func (m *MockGophKeeper) User(ctx context.Context, uID uint64) (storage.User, error) {
+func (m *MockGophKeeper) User(ctx context.Context, id uint64) (storage.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "User", ctx, uID)
+	ret := m.ctrl.Call(m, "User", ctx, id)
 	ret0, _ := ret[0].(storage.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // User indicates an expected call of User.
-func (mr *MockGophKeeperMockRecorder) User(ctx, uID any) *gomock.Call {
+func (mr *MockGophKeeperMockRecorder) User(ctx, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "User", reflect.TypeOf((*MockGophKeeper)(nil).User), ctx, uID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "User", reflect.TypeOf((*MockGophKeeper)(nil).User), ctx, id)
 }
 
 // UserByLogin mocks base method.
@@ -171,3 +549,32 @@ func (mr *MockGophKeeperMockRecorder) UserByLogin(ctx, login any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserByLogin", reflect.TypeOf((*MockGophKeeper)(nil).UserByLogin), ctx, login)
 }
+
+// VerifyMFA mocks base method.
+func (m *MockGophKeeper) VerifyMFA(ctx context.Context, uID uint64, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyMFA", ctx, uID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyMFA indicates an expected call of VerifyMFA.
+func (mr *MockGophKeeperMockRecorder) VerifyMFA(ctx, uID, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyMFA", reflect.TypeOf((*MockGophKeeper)(nil).VerifyMFA), ctx, uID, code)
+}
+
+// Watch mocks base method.
+func (m *MockGophKeeper) Watch(ctx context.Context, uID uint64) (<-chan pubsub.Event, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", ctx, uID)
+	ret0, _ := ret[0].(<-chan pubsub.Event)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockGophKeeperMockRecorder) Watch(ctx, uID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockGophKeeper)(nil).Watch), ctx, uID)
+}
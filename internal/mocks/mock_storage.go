@@ -12,7 +12,9 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	ratelimit "github.com/wickedv43/go-goph-keeper/internal/ratelimit"
 	storage "github.com/wickedv43/go-goph-keeper/internal/storage"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +43,108 @@ func (m *MockDataKeeper) EXPECT() *MockDataKeeperMockRecorder {
 	return m.recorder
 }
 
+// AdvanceMFACounter mocks base method.
+func (m *MockDataKeeper) AdvanceMFACounter(ctx context.Context, uID uint64, counter int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceMFACounter", ctx, uID, counter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdvanceMFACounter indicates an expected call of AdvanceMFACounter.
+func (mr *MockDataKeeperMockRecorder) AdvanceMFACounter(ctx, uID, counter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceMFACounter", reflect.TypeOf((*MockDataKeeper)(nil).AdvanceMFACounter), ctx, uID, counter)
+}
+
+// Blob mocks base method.
+func (m *MockDataKeeper) Blob(ctx context.Context, uID uint64, ref string) (storage.Blob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Blob", ctx, uID, ref)
+	ret0, _ := ret[0].(storage.Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Blob indicates an expected call of Blob.
+func (mr *MockDataKeeperMockRecorder) Blob(ctx, uID, ref any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Blob", reflect.TypeOf((*MockDataKeeper)(nil).Blob), ctx, uID, ref)
+}
+
+// BlobChunk mocks base method.
+func (m *MockDataKeeper) BlobChunk(ctx context.Context, blobID uint64, seq uint32) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlobChunk", ctx, blobID, seq)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlobChunk indicates an expected call of BlobChunk.
+func (mr *MockDataKeeperMockRecorder) BlobChunk(ctx, blobID, seq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlobChunk", reflect.TypeOf((*MockDataKeeper)(nil).BlobChunk), ctx, blobID, seq)
+}
+
+// CollectBlobs mocks base method.
+func (m *MockDataKeeper) CollectBlobs(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CollectBlobs", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CollectBlobs indicates an expected call of CollectBlobs.
+func (mr *MockDataKeeperMockRecorder) CollectBlobs(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectBlobs", reflect.TypeOf((*MockDataKeeper)(nil).CollectBlobs), ctx, before)
+}
+
+// CollectObjects mocks base method.
+func (m *MockDataKeeper) CollectObjects(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CollectObjects", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CollectObjects indicates an expected call of CollectObjects.
+func (mr *MockDataKeeperMockRecorder) CollectObjects(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectObjects", reflect.TypeOf((*MockDataKeeper)(nil).CollectObjects), ctx, before)
+}
+
+// CreateBlob mocks base method.
+func (m *MockDataKeeper) CreateBlob(ctx context.Context, b *storage.Blob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBlob", ctx, b)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBlob indicates an expected call of CreateBlob.
+func (mr *MockDataKeeperMockRecorder) CreateBlob(ctx, b any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBlob", reflect.TypeOf((*MockDataKeeper)(nil).CreateBlob), ctx, b)
+}
+
+// CreateSession mocks base method.
+func (m *MockDataKeeper) CreateSession(ctx context.Context, sess *storage.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, sess)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockDataKeeperMockRecorder) CreateSession(ctx, sess any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockDataKeeper)(nil).CreateSession), ctx, sess)
+}
+
 // CreateVault mocks base method.
 func (m *MockDataKeeper) CreateVault(ctx context.Context, v *storage.VaultRecord) error {
 	m.ctrl.T.Helper()
@@ -69,6 +173,35 @@ func (mr *MockDataKeeperMockRecorder) DeleteVault(ctx, vID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVault", reflect.TypeOf((*MockDataKeeper)(nil).DeleteVault), ctx, vID)
 }
 
+// EnableMFA mocks base method.
+func (m *MockDataKeeper) EnableMFA(ctx context.Context, uID uint64, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableMFA", ctx, uID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableMFA indicates an expected call of EnableMFA.
+func (mr *MockDataKeeperMockRecorder) EnableMFA(ctx, uID, codeHashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableMFA", reflect.TypeOf((*MockDataKeeper)(nil).EnableMFA), ctx, uID, codeHashes)
+}
+
+// FindVaults mocks base method.
+func (m *MockDataKeeper) FindVaults(ctx context.Context, uID uint64, tokens []string, limit int) ([]storage.VaultRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVaults", ctx, uID, tokens, limit)
+	ret0, _ := ret[0].([]storage.VaultRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVaults indicates an expected call of FindVaults.
+func (mr *MockDataKeeperMockRecorder) FindVaults(ctx, uID, tokens, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVaults", reflect.TypeOf((*MockDataKeeper)(nil).FindVaults), ctx, uID, tokens, limit)
+}
+
 // GetVault mocks base method.
 func (m *MockDataKeeper) GetVault(ctx context.Context, vID uint64) (storage.VaultRecord, error) {
 	m.ctrl.T.Helper()
@@ -84,19 +217,123 @@ func (mr *MockDataKeeperMockRecorder) GetVault(ctx, vID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVault", reflect.TypeOf((*MockDataKeeper)(nil).GetVault), ctx, vID)
 }
 
+// LastVaultChange mocks base method.
+func (m *MockDataKeeper) LastVaultChange(ctx context.Context, uID uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastVaultChange", ctx, uID)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastVaultChange indicates an expected call of LastVaultChange.
+func (mr *MockDataKeeperMockRecorder) LastVaultChange(ctx, uID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastVaultChange", reflect.TypeOf((*MockDataKeeper)(nil).LastVaultChange), ctx, uID)
+}
+
+// ListSessions mocks base method.
+func (m *MockDataKeeper) ListSessions(ctx context.Context, uID uint64) ([]storage.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx, uID)
+	ret0, _ := ret[0].([]storage.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockDataKeeperMockRecorder) ListSessions(ctx, uID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockDataKeeper)(nil).ListSessions), ctx, uID)
+}
+
+// ListTrash mocks base method.
+func (m *MockDataKeeper) ListTrash(ctx context.Context, uID uint64) ([]storage.VaultRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", ctx, uID)
+	ret0, _ := ret[0].([]storage.VaultRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockDataKeeperMockRecorder) ListTrash(ctx, uID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockDataKeeper)(nil).ListTrash), ctx, uID)
+}
+
+// ListVaultSummaries mocks base method.
+func (m *MockDataKeeper) ListVaultSummaries(ctx context.Context, uID uint64, f storage.VaultFilter) ([]storage.VaultSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVaultSummaries", ctx, uID, f)
+	ret0, _ := ret[0].([]storage.VaultSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVaultSummaries indicates an expected call of ListVaultSummaries.
+func (mr *MockDataKeeperMockRecorder) ListVaultSummaries(ctx, uID, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaultSummaries", reflect.TypeOf((*MockDataKeeper)(nil).ListVaultSummaries), ctx, uID, f)
+}
+
+// ListVaultVersions mocks base method.
+func (m *MockDataKeeper) ListVaultVersions(ctx context.Context, vID uint64) ([]storage.VaultRecordVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVaultVersions", ctx, vID)
+	ret0, _ := ret[0].([]storage.VaultRecordVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVaultVersions indicates an expected call of ListVaultVersions.
+func (mr *MockDataKeeperMockRecorder) ListVaultVersions(ctx, vID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaultVersions", reflect.TypeOf((*MockDataKeeper)(nil).ListVaultVersions), ctx, vID)
+}
+
 // ListVaults mocks base method.
-func (m *MockDataKeeper) ListVaults(ctx context.Context, uID uint64) ([]storage.VaultRecord, error) {
+func (m *MockDataKeeper) ListVaults(ctx context.Context, uID uint64, f storage.VaultFilter) ([]storage.VaultRecord, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListVaults", ctx, uID)
+	ret := m.ctrl.Call(m, "ListVaults", ctx, uID, f)
 	ret0, _ := ret[0].([]storage.VaultRecord)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListVaults indicates an expected call of ListVaults.
-func (mr *MockDataKeeperMockRecorder) ListVaults(ctx, uID any) *gomock.Call {
+func (mr *MockDataKeeperMockRecorder) ListVaults(ctx, uID, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaults", reflect.TypeOf((*MockDataKeeper)(nil).ListVaults), ctx, uID, f)
+}
+
+// Listen mocks base method.
+func (m *MockDataKeeper) Listen(ctx context.Context, channel string, fn func(string)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listen", ctx, channel, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Listen indicates an expected call of Listen.
+func (mr *MockDataKeeperMockRecorder) Listen(ctx, channel, fn any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaults", reflect.TypeOf((*MockDataKeeper)(nil).ListVaults), ctx, uID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockDataKeeper)(nil).Listen), ctx, channel, fn)
+}
+
+// MFA mocks base method.
+func (m *MockDataKeeper) MFA(ctx context.Context, uID uint64) (storage.UserMFA, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MFA", ctx, uID)
+	ret0, _ := ret[0].(storage.UserMFA)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MFA indicates an expected call of MFA.
+func (mr *MockDataKeeperMockRecorder) MFA(ctx, uID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MFA", reflect.TypeOf((*MockDataKeeper)(nil).MFA), ctx, uID)
 }
 
 // NewUser mocks base method.
@@ -114,6 +351,148 @@ func (mr *MockDataKeeperMockRecorder) NewUser(ctx, u any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewUser", reflect.TypeOf((*MockDataKeeper)(nil).NewUser), ctx, u)
 }
 
+// Notify mocks base method.
+func (m *MockDataKeeper) Notify(ctx context.Context, channel, payload string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", ctx, channel, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockDataKeeperMockRecorder) Notify(ctx, channel, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockDataKeeper)(nil).Notify), ctx, channel, payload)
+}
+
+// PurgeTrash mocks base method.
+func (m *MockDataKeeper) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockDataKeeperMockRecorder) PurgeTrash(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockDataKeeper)(nil).PurgeTrash), ctx, before)
+}
+
+// PurgeVault mocks base method.
+func (m *MockDataKeeper) PurgeVault(ctx context.Context, vID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeVault", ctx, vID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeVault indicates an expected call of PurgeVault.
+func (mr *MockDataKeeperMockRecorder) PurgeVault(ctx, vID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeVault", reflect.TypeOf((*MockDataKeeper)(nil).PurgeVault), ctx, vID)
+}
+
+// PutBlobChunk mocks base method.
+func (m *MockDataKeeper) PutBlobChunk(ctx context.Context, blobID uint64, seq uint32, data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutBlobChunk", ctx, blobID, seq, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutBlobChunk indicates an expected call of PutBlobChunk.
+func (mr *MockDataKeeperMockRecorder) PutBlobChunk(ctx, blobID, seq, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutBlobChunk", reflect.TypeOf((*MockDataKeeper)(nil).PutBlobChunk), ctx, blobID, seq, data)
+}
+
+// ResetRateLimit mocks base method.
+func (m *MockDataKeeper) ResetRateLimit(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetRateLimit", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetRateLimit indicates an expected call of ResetRateLimit.
+func (mr *MockDataKeeperMockRecorder) ResetRateLimit(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetRateLimit", reflect.TypeOf((*MockDataKeeper)(nil).ResetRateLimit), ctx, key)
+}
+
+// RestoreVault mocks base method.
+func (m *MockDataKeeper) RestoreVault(ctx context.Context, vID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreVault", ctx, vID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreVault indicates an expected call of RestoreVault.
+func (mr *MockDataKeeperMockRecorder) RestoreVault(ctx, vID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVault", reflect.TypeOf((*MockDataKeeper)(nil).RestoreVault), ctx, vID)
+}
+
+// RevokeSession mocks base method.
+func (m *MockDataKeeper) RevokeSession(ctx context.Context, sID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, sID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockDataKeeperMockRecorder) RevokeSession(ctx, sID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockDataKeeper)(nil).RevokeSession), ctx, sID)
+}
+
+// RotateSession mocks base method.
+func (m *MockDataKeeper) RotateSession(ctx context.Context, sID, generation uint64, refreshHash string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSession", ctx, sID, generation, refreshHash, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateSession indicates an expected call of RotateSession.
+func (mr *MockDataKeeperMockRecorder) RotateSession(ctx, sID, generation, refreshHash, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSession", reflect.TypeOf((*MockDataKeeper)(nil).RotateSession), ctx, sID, generation, refreshHash, expiresAt)
+}
+
+// SaveMFA mocks base method.
+func (m_2 *MockDataKeeper) SaveMFA(ctx context.Context, m *storage.UserMFA) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SaveMFA", ctx, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMFA indicates an expected call of SaveMFA.
+func (mr *MockDataKeeperMockRecorder) SaveMFA(ctx, m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMFA", reflect.TypeOf((*MockDataKeeper)(nil).SaveMFA), ctx, m)
+}
+
+// Session mocks base method.
+func (m *MockDataKeeper) Session(ctx context.Context, sID uint64) (storage.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Session", ctx, sID)
+	ret0, _ := ret[0].(storage.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Session indicates an expected call of Session.
+func (mr *MockDataKeeperMockRecorder) Session(ctx, sID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Session", reflect.TypeOf((*MockDataKeeper)(nil).Session), ctx, sID)
+}
+
 // Shutdown mocks base method.
 func (m *MockDataKeeper) Shutdown() error {
 	m.ctrl.T.Helper()
@@ -128,6 +507,78 @@ func (mr *MockDataKeeperMockRecorder) Shutdown() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockDataKeeper)(nil).Shutdown))
 }
 
+// SweepRateLimits mocks base method.
+func (m *MockDataKeeper) SweepRateLimits(ctx context.Context, now, idleBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SweepRateLimits", ctx, now, idleBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SweepRateLimits indicates an expected call of SweepRateLimits.
+func (mr *MockDataKeeperMockRecorder) SweepRateLimits(ctx, now, idleBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SweepRateLimits", reflect.TypeOf((*MockDataKeeper)(nil).SweepRateLimits), ctx, now, idleBefore)
+}
+
+// TouchSession mocks base method.
+func (m *MockDataKeeper) TouchSession(ctx context.Context, sID uint64, ip string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", ctx, sID, ip, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockDataKeeperMockRecorder) TouchSession(ctx, sID, ip, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockDataKeeper)(nil).TouchSession), ctx, sID, ip, at)
+}
+
+// TrashedVault mocks base method.
+func (m *MockDataKeeper) TrashedVault(ctx context.Context, vID uint64) (storage.VaultRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrashedVault", ctx, vID)
+	ret0, _ := ret[0].(storage.VaultRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TrashedVault indicates an expected call of TrashedVault.
+func (mr *MockDataKeeperMockRecorder) TrashedVault(ctx, vID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrashedVault", reflect.TypeOf((*MockDataKeeper)(nil).TrashedVault), ctx, vID)
+}
+
+// UpdatePasswordHash mocks base method.
+func (m *MockDataKeeper) UpdatePasswordHash(ctx context.Context, uID uint64, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", ctx, uID, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockDataKeeperMockRecorder) UpdatePasswordHash(ctx, uID, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockDataKeeper)(nil).UpdatePasswordHash), ctx, uID, hash)
+}
+
+// UpdateRateLimit mocks base method.
+func (m *MockDataKeeper) UpdateRateLimit(ctx context.Context, key string, fn func(*ratelimit.State) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRateLimit", ctx, key, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRateLimit indicates an expected call of UpdateRateLimit.
+func (mr *MockDataKeeperMockRecorder) UpdateRateLimit(ctx, key, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRateLimit", reflect.TypeOf((*MockDataKeeper)(nil).UpdateRateLimit), ctx, key, fn)
+}
+
 // UpdateVault mocks base method.
 func (m *MockDataKeeper) UpdateVault(ctx context.Context, v *storage.VaultRecord) error {
 	m.ctrl.T.Helper()
@@ -142,6 +593,20 @@ func (mr *MockDataKeeperMockRecorder) UpdateVault(ctx, v any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVault", reflect.TypeOf((*MockDataKeeper)(nil).UpdateVault), ctx, v)
 }
 
+// UseRecoveryCode mocks base method.
+func (m *MockDataKeeper) UseRecoveryCode(ctx context.Context, uID uint64, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, uID, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockDataKeeperMockRecorder) UseRecoveryCode(ctx, uID, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockDataKeeper)(nil).UseRecoveryCode), ctx, uID, codeHash)
+}
+
 // User mocks base method.
 func (m *MockDataKeeper) User(ctx context.Context, uID uint64) (storage.User, error) {
 	m.ctrl.T.Helper()
@@ -171,3 +636,48 @@ func (mr *MockDataKeeperMockRecorder) UserByLogin(ctx, login any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserByLogin", reflect.TypeOf((*MockDataKeeper)(nil).UserByLogin), ctx, login)
 }
+
+// VaultChanges mocks base method.
+func (m *MockDataKeeper) VaultChanges(ctx context.Context, uID, since uint64, limit int) ([]storage.VaultChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VaultChanges", ctx, uID, since, limit)
+	ret0, _ := ret[0].([]storage.VaultChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VaultChanges indicates an expected call of VaultChanges.
+func (mr *MockDataKeeperMockRecorder) VaultChanges(ctx, uID, since, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VaultChanges", reflect.TypeOf((*MockDataKeeper)(nil).VaultChanges), ctx, uID, since, limit)
+}
+
+// VaultVersion mocks base method.
+func (m *MockDataKeeper) VaultVersion(ctx context.Context, vID, version uint64) (storage.VaultRecordVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VaultVersion", ctx, vID, version)
+	ret0, _ := ret[0].(storage.VaultRecordVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VaultVersion indicates an expected call of VaultVersion.
+func (mr *MockDataKeeperMockRecorder) VaultVersion(ctx, vID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VaultVersion", reflect.TypeOf((*MockDataKeeper)(nil).VaultVersion), ctx, vID, version)
+}
+
+// VaultsByID mocks base method.
+func (m *MockDataKeeper) VaultsByID(ctx context.Context, ids []uint64) ([]storage.VaultRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VaultsByID", ctx, ids)
+	ret0, _ := ret[0].([]storage.VaultRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VaultsByID indicates an expected call of VaultsByID.
+func (mr *MockDataKeeperMockRecorder) VaultsByID(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VaultsByID", reflect.TypeOf((*MockDataKeeper)(nil).VaultsByID), ctx, ids)
+}
@@ -0,0 +1,87 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// bufferSize is the number of events a subscriber may lag behind.
+const bufferSize = 16
+
+// Memory is an in-process Broker. Only subscribers of the same server
+// instance receive the events it publishes.
+type Memory struct {
+	mu     sync.Mutex
+	subs   map[uint64]map[chan Event]struct{}
+	closed bool
+}
+
+// NewMemory returns a broker without subscribers.
+func NewMemory() *Memory {
+	return &Memory{subs: make(map[uint64]map[chan Event]struct{})}
+}
+
+// Publish sends the event to the subscribers of its user without waiting for them.
+func (m *Memory) Publish(_ context.Context, ev Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for ch := range m.subs[ev.UserID] {
+		select {
+		case ch <- ev:
+		default: // the subscriber will catch up with Sync
+		}
+	}
+	return nil
+}
+
+// Subscribe returns the events of the user until cancel is called.
+func (m *Memory) Subscribe(uID uint64) (<-chan Event, func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch := make(chan Event, bufferSize)
+	if m.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if m.subs[uID] == nil {
+		m.subs[uID] = make(map[chan Event]struct{})
+	}
+	m.subs[uID][ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() { m.unsubscribe(uID, ch) })
+	}
+}
+
+// Close ends all subscriptions by closing their channels.
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, subs := range m.subs {
+		for ch := range subs {
+			close(ch)
+		}
+	}
+	m.subs = make(map[uint64]map[chan Event]struct{})
+	m.closed = true
+	return nil
+}
+
+func (m *Memory) unsubscribe(uID uint64, ch chan Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.subs[uID][ch]; !ok {
+		return // already closed
+	}
+	delete(m.subs[uID], ch)
+	if len(m.subs[uID]) == 0 {
+		delete(m.subs, uID)
+	}
+	close(ch)
+}
@@ -0,0 +1,106 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Channel is the Postgres notification channel that carries vault events.
+const Channel = "vault_events"
+
+// reconnectDelay is the pause before listening again after the connection failed.
+const reconnectDelay = 5 * time.Second
+
+// Store sends and receives Postgres notifications. Listen must call fn with
+// the payload of every notification on the channel and return only when ctx
+// ends or the connection fails.
+type Store interface {
+	Notify(ctx context.Context, channel, payload string) error
+	Listen(ctx context.Context, channel string, fn func(payload string)) error
+}
+
+// Postgres is a Broker for several server instances sharing one database.
+// Events are published with NOTIFY; every instance, the publishing one
+// included, LISTENs and hands them to its own subscribers. Events sent while
+// an instance reconnects are lost; its watchers catch up with Sync.
+type Postgres struct {
+	store Store
+	local *Memory
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	retry time.Duration
+	log   *zap.SugaredLogger
+}
+
+// NewPostgres returns a broker backed by the given store and starts listening.
+func NewPostgres(store Store, log *zap.SugaredLogger) *Postgres {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := &Postgres{
+		store:  store,
+		local:  NewMemory(),
+		cancel: cancel,
+		done:   make(chan struct{}),
+		retry:  reconnectDelay,
+		log:    log,
+	}
+	go p.listen(ctx)
+
+	return p
+}
+
+// Publish notifies all instances of the event.
+func (p *Postgres) Publish(ctx context.Context, ev Event) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return errors.Wrap(err, "encode event")
+	}
+	return p.store.Notify(ctx, Channel, string(payload))
+}
+
+// Subscribe returns the events of the user received by this instance until cancel is called.
+func (p *Postgres) Subscribe(uID uint64) (<-chan Event, func()) {
+	return p.local.Subscribe(uID)
+}
+
+// Close stops listening and ends all subscriptions.
+func (p *Postgres) Close() error {
+	p.cancel()
+	<-p.done
+	return p.local.Close()
+}
+
+// listen receives notifications until ctx ends, reconnecting after failures.
+func (p *Postgres) listen(ctx context.Context) {
+	defer close(p.done)
+
+	for {
+		err := p.store.Listen(ctx, Channel, p.deliver)
+		if ctx.Err() != nil {
+			return
+		}
+		p.log.Warnf("listen %s: %v, retrying in %s", Channel, err, p.retry)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.retry):
+		}
+	}
+}
+
+// deliver hands a notification to the local subscribers.
+func (p *Postgres) deliver(payload string) {
+	var ev Event
+	if err := json.Unmarshal([]byte(payload), &ev); err != nil {
+		p.log.Warnf("decode event %q: %v", payload, err)
+		return
+	}
+	_ = p.local.Publish(context.Background(), ev)
+}
@@ -0,0 +1,50 @@
+// Package pubsub delivers vault change events to the sessions watching them.
+package pubsub
+
+import (
+	"context"
+)
+
+// Vault change operations.
+const (
+	OpCreate  = "create"
+	OpUpdate  = "update"
+	OpDelete  = "delete"
+	OpRestore = "restore"
+)
+
+// Event tells that a vault record of a user has changed.
+type Event struct {
+	UserID   uint64 `json:"user_id"`
+	VaultID  uint64 `json:"vault_id"`
+	Revision uint64 `json:"revision"` // zero when the record left the vault
+	Op       string `json:"op"`
+	Origin   uint64 `json:"origin"` // the session that made the change, zero if unknown
+}
+
+// Broker fans events out to the subscribers of their user.
+type Broker interface {
+	// Publish sends the event to every subscriber of ev.UserID.
+	Publish(ctx context.Context, ev Event) error
+
+	// Subscribe returns the events of the user until cancel is called.
+	// A subscriber too slow to take an event misses it.
+	Subscribe(uID uint64) (events <-chan Event, cancel func())
+
+	// Close stops delivering events.
+	Close() error
+}
+
+// originKey is the context key of the session making a change.
+type originKey struct{}
+
+// WithOrigin returns a context telling that changes made with it come from the session sID.
+func WithOrigin(ctx context.Context, sID uint64) context.Context {
+	return context.WithValue(ctx, originKey{}, sID)
+}
+
+// Origin returns the session set by WithOrigin, zero if none.
+func Origin(ctx context.Context) uint64 {
+	sID, _ := ctx.Value(originKey{}).(uint64)
+	return sID
+}
@@ -0,0 +1,176 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// receive waits for the next event of the subscription.
+func receive(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		require.True(t, ok, "subscription closed")
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+		return Event{}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+
+	alice, cancelAlice := m.Subscribe(1)
+	bob, cancelBob := m.Subscribe(2)
+	defer cancelBob()
+
+	require.NoError(t, m.Publish(ctx, Event{UserID: 1, VaultID: 10, Revision: 2, Op: OpUpdate}))
+	require.Equal(t, Event{UserID: 1, VaultID: 10, Revision: 2, Op: OpUpdate}, receive(t, alice))
+	require.Empty(t, bob, "events go to their user only")
+
+	t.Run("slow subscriber misses events", func(t *testing.T) {
+		for i := 0; i < bufferSize+5; i++ {
+			require.NoError(t, m.Publish(ctx, Event{UserID: 2, VaultID: uint64(i)}))
+		}
+		require.Len(t, bob, bufferSize)
+		for range bufferSize {
+			<-bob
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		cancelAlice()
+		cancelAlice()
+		_, ok := <-alice
+		require.False(t, ok)
+		require.NoError(t, m.Publish(ctx, Event{UserID: 1}))
+	})
+
+	t.Run("close", func(t *testing.T) {
+		require.NoError(t, m.Close())
+		_, ok := <-bob
+		require.False(t, ok)
+
+		late, cancel := m.Subscribe(2)
+		defer cancel()
+		_, ok = <-late
+		require.False(t, ok)
+	})
+}
+
+func TestOrigin(t *testing.T) {
+	ctx := context.Background()
+	require.Zero(t, Origin(ctx))
+	require.Equal(t, uint64(7), Origin(WithOrigin(ctx, 7)))
+}
+
+// fakeStore is a Postgres notification channel inside the test process.
+type fakeStore struct {
+	mu        sync.Mutex
+	listeners map[chan string]struct{}
+	fail      int // number of Listen calls that fail right away
+	listens   int
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{listeners: make(map[chan string]struct{})}
+}
+
+func (f *fakeStore) Notify(_ context.Context, channel, payload string) error {
+	if channel != Channel {
+		return errors.New("unexpected channel")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.listeners {
+		ch <- payload
+	}
+	return nil
+}
+
+func (f *fakeStore) Listen(ctx context.Context, _ string, fn func(payload string)) error {
+	f.mu.Lock()
+	f.listens++
+	if f.fail > 0 {
+		f.fail--
+		f.mu.Unlock()
+		return errors.New("connection refused")
+	}
+	ch := make(chan string, 8)
+	f.listeners[ch] = struct{}{}
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		delete(f.listeners, ch)
+		f.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case payload := <-ch:
+			fn(payload)
+		}
+	}
+}
+
+// listening reports whether the broker is connected to the store.
+func (f *fakeStore) listening() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.listeners) > 0
+}
+
+func TestPostgres(t *testing.T) {
+	store := newFakeStore()
+	store.fail = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := &Postgres{
+		store:  store,
+		local:  NewMemory(),
+		cancel: cancel,
+		done:   make(chan struct{}),
+		retry:  time.Millisecond,
+		log:    zap.NewNop().Sugar(),
+	}
+	go p.listen(ctx)
+	require.Eventually(t, store.listening, time.Second, time.Millisecond, "reconnects after a failure")
+
+	// a second instance sharing the database
+	other := NewPostgres(store, zap.NewNop().Sugar())
+
+	events, unsubscribe := p.Subscribe(1)
+	defer unsubscribe()
+
+	require.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.listeners) == 2
+	}, time.Second, time.Millisecond)
+
+	ev := Event{UserID: 1, VaultID: 3, Revision: 4, Op: OpUpdate, Origin: 9}
+	require.NoError(t, other.Publish(context.Background(), ev))
+	require.Equal(t, ev, receive(t, events))
+
+	// malformed payloads are skipped
+	require.NoError(t, store.Notify(context.Background(), Channel, "{"))
+
+	require.NoError(t, other.Close())
+	require.NoError(t, p.Close())
+	_, ok := <-events
+	require.False(t, ok)
+	require.Equal(t, 3, store.listens)
+}
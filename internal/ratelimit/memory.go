@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// idleTTL is how long an unlocked key is kept after its last attempt. With the
+// default rules its bucket is full again long before, so forgetting the key
+// changes nothing.
+const idleTTL = time.Hour
+
+// sweepInterval is how often idle keys are dropped.
+const sweepInterval = 10 * time.Minute
+
+// Memory is an in-process Limiter. Each server instance counts attempts on its own.
+type Memory struct {
+	mu        sync.Mutex
+	states    map[string]*State
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+// NewMemory returns an empty in-memory limiter.
+func NewMemory() *Memory {
+	return &Memory{
+		states: make(map[string]*State),
+		now:    time.Now,
+	}
+}
+
+// Allow takes an attempt for the key.
+func (m *Memory) Allow(_ context.Context, key string, r Rule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	return m.state(key).Allow(r, now)
+}
+
+// Fail records a failed attempt of the key.
+func (m *Memory) Fail(_ context.Context, key string, r Rule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state(key).Fail(r, m.now())
+	return nil
+}
+
+// Reset forgets the failures of the key.
+func (m *Memory) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if st, ok := m.states[key]; ok {
+		st.Reset()
+	}
+	return nil
+}
+
+// state returns the state of the key, creating it on first use.
+func (m *Memory) state(key string) *State {
+	st, ok := m.states[key]
+	if !ok {
+		st = &State{}
+		m.states[key] = st
+	}
+	return st
+}
+
+// sweep drops keys that are neither locked nor used recently, so random
+// logins sent by an attacker do not grow the map forever.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, st := range m.states {
+		if now.After(st.LockedUntil) && now.Sub(st.RefilledAt) > idleTTL {
+			delete(m.states, key)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Store persists attempt states. UpdateRateLimit must load the state of the key,
+// pass it to fn and save the result atomically, even if fn returns an error.
+// ResetRateLimit clears the failures and the lockout of a stored key and does
+// nothing for an unknown one. SweepRateLimits deletes the keys that are not
+// locked at now and were last attempted before idleBefore.
+type Store interface {
+	UpdateRateLimit(ctx context.Context, key string, fn func(st *State) error) error
+	ResetRateLimit(ctx context.Context, key string) error
+	SweepRateLimits(ctx context.Context, now, idleBefore time.Time) (int64, error)
+}
+
+// Postgres is a Limiter that keeps states in the shared database, so that
+// limits hold across all server instances.
+type Postgres struct {
+	store Store
+
+	mu        sync.Mutex
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+// NewPostgres returns a limiter backed by the given store.
+func NewPostgres(store Store) *Postgres {
+	return &Postgres{store: store, now: time.Now}
+}
+
+// Allow takes an attempt for the key.
+func (p *Postgres) Allow(ctx context.Context, key string, r Rule) error {
+	now := p.now()
+	if err := p.sweep(ctx, now); err != nil {
+		return err
+	}
+	return p.store.UpdateRateLimit(ctx, key, func(st *State) error {
+		return st.Allow(r, now)
+	})
+}
+
+// Fail records a failed attempt of the key.
+func (p *Postgres) Fail(ctx context.Context, key string, r Rule) error {
+	now := p.now()
+	return p.store.UpdateRateLimit(ctx, key, func(st *State) error {
+		st.Fail(r, now)
+		return nil
+	})
+}
+
+// Reset forgets the failures of the key.
+func (p *Postgres) Reset(ctx context.Context, key string) error {
+	return p.store.ResetRateLimit(ctx, key)
+}
+
+// sweep deletes idle keys like Memory does, so random logins sent by an
+// attacker do not grow the table forever. Every instance sweeps on its own,
+// which is harmless as the delete is idempotent.
+func (p *Postgres) sweep(ctx context.Context, now time.Time) error {
+	p.mu.Lock()
+	if now.Sub(p.lastSweep) < sweepInterval {
+		p.mu.Unlock()
+		return nil
+	}
+	p.lastSweep = now
+	p.mu.Unlock()
+
+	_, err := p.store.SweepRateLimits(ctx, now, now.Add(-idleTTL))
+	return errors.Wrap(err, "sweep rate limits")
+}
@@ -0,0 +1,99 @@
+// Package ratelimit throttles repeated attempts with token buckets and locks keys out after failures.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Rule describes the limits applied to one key.
+type Rule struct {
+	// Burst is the number of attempts allowed at once.
+	Burst int
+	// Every is the time needed to regain one attempt.
+	Every time.Duration
+	// MaxFailures is the number of consecutive failures that triggers a lockout.
+	MaxFailures int
+	// LockoutBase is the first lockout; every further failure doubles it.
+	LockoutBase time.Duration
+	// LockoutMax caps the lockout.
+	LockoutMax time.Duration
+}
+
+// LimitedError is returned when a key is throttled or locked out.
+type LimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LimitedError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// Limiter keeps the attempt state of keys such as a peer IP or a login.
+type Limiter interface {
+	// Allow takes an attempt for the key or returns *LimitedError.
+	Allow(ctx context.Context, key string, r Rule) error
+
+	// Fail records a failed attempt and locks the key out once r.MaxFailures is reached.
+	Fail(ctx context.Context, key string, r Rule) error
+
+	// Reset forgets the failures of the key after a successful attempt.
+	Reset(ctx context.Context, key string) error
+}
+
+// State is the attempt state of a single key.
+type State struct {
+	Tokens      float64
+	RefilledAt  time.Time
+	Failures    int
+	LockedUntil time.Time
+}
+
+// Allow refills the bucket for the time passed since the last attempt and takes one token.
+func (st *State) Allow(r Rule, now time.Time) error {
+	if now.Before(st.LockedUntil) {
+		return &LimitedError{RetryAfter: st.LockedUntil.Sub(now)}
+	}
+
+	burst := float64(r.Burst)
+	if st.RefilledAt.IsZero() {
+		st.Tokens = burst
+	} else if r.Every > 0 {
+		st.Tokens += float64(now.Sub(st.RefilledAt)) / float64(r.Every)
+		if st.Tokens > burst {
+			st.Tokens = burst
+		}
+	}
+	st.RefilledAt = now
+
+	if st.Tokens < 1 {
+		return &LimitedError{RetryAfter: time.Duration((1 - st.Tokens) * float64(r.Every))}
+	}
+
+	st.Tokens--
+	return nil
+}
+
+// Fail counts a failed attempt. From r.MaxFailures on, the key is locked out
+// for r.LockoutBase, doubled with every further failure up to r.LockoutMax.
+func (st *State) Fail(r Rule, now time.Time) {
+	st.Failures++
+	if r.MaxFailures <= 0 || st.Failures < r.MaxFailures {
+		return
+	}
+
+	lockout := r.LockoutMax
+	if shift := st.Failures - r.MaxFailures; shift < 32 {
+		if d := r.LockoutBase << shift; d > 0 && d < r.LockoutMax {
+			lockout = d
+		}
+	}
+	st.LockedUntil = now.Add(lockout)
+}
+
+// Reset clears failures and any lockout.
+func (st *State) Reset() {
+	st.Failures = 0
+	st.LockedUntil = time.Time{}
+}
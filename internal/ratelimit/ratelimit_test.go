@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testRule = Rule{
+	Burst:       2,
+	Every:       time.Minute,
+	MaxFailures: 3,
+	LockoutBase: time.Minute,
+	LockoutMax:  5 * time.Minute,
+}
+
+func TestState_Allow(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	var st State
+
+	require.NoError(t, st.Allow(testRule, now))
+	require.NoError(t, st.Allow(testRule, now))
+
+	err := st.Allow(testRule, now)
+	var limited *LimitedError
+	require.ErrorAs(t, err, &limited)
+	require.Equal(t, time.Minute, limited.RetryAfter)
+
+	// one token is back after Every
+	require.NoError(t, st.Allow(testRule, now.Add(time.Minute)))
+	require.Error(t, st.Allow(testRule, now.Add(time.Minute)))
+
+	// the bucket never holds more than Burst
+	require.NoError(t, st.Allow(testRule, now.Add(time.Hour)))
+	require.NoError(t, st.Allow(testRule, now.Add(time.Hour)))
+	require.Error(t, st.Allow(testRule, now.Add(time.Hour)))
+}
+
+func TestState_Lockout(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	var st State
+
+	st.Fail(testRule, now)
+	st.Fail(testRule, now)
+	require.True(t, st.LockedUntil.IsZero(), "no lockout below MaxFailures")
+
+	st.Fail(testRule, now)
+	require.Equal(t, now.Add(time.Minute), st.LockedUntil)
+
+	var limited *LimitedError
+	require.ErrorAs(t, st.Allow(testRule, now.Add(30*time.Second)), &limited)
+	require.Equal(t, 30*time.Second, limited.RetryAfter)
+
+	// every further failure doubles the lockout up to LockoutMax
+	st.Fail(testRule, now)
+	require.Equal(t, now.Add(2*time.Minute), st.LockedUntil)
+	st.Fail(testRule, now)
+	require.Equal(t, now.Add(4*time.Minute), st.LockedUntil)
+	st.Fail(testRule, now)
+	require.Equal(t, now.Add(5*time.Minute), st.LockedUntil)
+
+	for range 100 {
+		st.Fail(testRule, now)
+	}
+	require.Equal(t, now.Add(5*time.Minute), st.LockedUntil, "no overflow")
+
+	st.Reset()
+	require.Zero(t, st.Failures)
+	require.NoError(t, st.Allow(testRule, now))
+}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	for range 3 {
+		require.NoError(t, m.Allow(ctx, "login:alice", Rule{Burst: 10, Every: time.Second, MaxFailures: 3, LockoutBase: time.Minute, LockoutMax: time.Hour}))
+		require.NoError(t, m.Fail(ctx, "login:alice", Rule{MaxFailures: 3, LockoutBase: time.Minute, LockoutMax: time.Hour}))
+	}
+
+	var limited *LimitedError
+	require.ErrorAs(t, m.Allow(ctx, "login:alice", testRule), &limited)
+	require.NoError(t, m.Allow(ctx, "login:bob", testRule), "keys are independent")
+
+	require.NoError(t, m.Reset(ctx, "login:alice"))
+	require.NoError(t, m.Allow(ctx, "login:alice", testRule))
+
+	t.Run("idle keys are swept", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		require.NoError(t, m.Allow(ctx, "login:carol", testRule))
+		require.NotContains(t, m.states, "login:bob")
+		require.Contains(t, m.states, "login:carol")
+	})
+}
+
+// fakeStore keeps states in a map like the database would.
+type fakeStore map[string]State
+
+func (f fakeStore) UpdateRateLimit(_ context.Context, key string, fn func(st *State) error) error {
+	st := f[key]
+	err := fn(&st)
+	f[key] = st
+	return err
+}
+
+func (f fakeStore) ResetRateLimit(_ context.Context, key string) error {
+	if st, ok := f[key]; ok {
+		st.Reset()
+		f[key] = st
+	}
+	return nil
+}
+
+func (f fakeStore) SweepRateLimits(_ context.Context, now, idleBefore time.Time) (int64, error) {
+	var n int64
+	for key, st := range f {
+		if now.After(st.LockedUntil) && st.RefilledAt.Before(idleBefore) {
+			delete(f, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestPostgres(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+
+	store := fakeStore{}
+	p := NewPostgres(store)
+	p.now = func() time.Time { return now }
+
+	require.NoError(t, p.Allow(ctx, "ip:10.0.0.1", testRule))
+	require.NoError(t, p.Allow(ctx, "ip:10.0.0.1", testRule))
+	require.Error(t, p.Allow(ctx, "ip:10.0.0.1", testRule))
+	require.Equal(t, now, store["ip:10.0.0.1"].RefilledAt, "state is saved even when limited")
+
+	for range 3 {
+		require.NoError(t, p.Fail(ctx, "login:alice", testRule))
+	}
+	require.Equal(t, now.Add(time.Minute), store["login:alice"].LockedUntil)
+
+	require.NoError(t, p.Reset(ctx, "login:alice"))
+	require.Zero(t, store["login:alice"].Failures)
+
+	require.NoError(t, p.Reset(ctx, "login:nobody"))
+	require.NotContains(t, store, "login:nobody", "reset does not create keys")
+
+	t.Run("idle keys are swept", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		require.NoError(t, p.Allow(ctx, "login:carol", testRule))
+		require.NotContains(t, store, "ip:10.0.0.1")
+		require.Contains(t, store, "login:carol")
+	})
+}
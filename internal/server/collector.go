@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/samber/do/v2"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+	"github.com/wickedv43/go-goph-keeper/internal/logger"
+	"github.com/wickedv43/go-goph-keeper/internal/service"
+	"go.uber.org/zap"
+)
+
+// Blob collection defaults used when blobs.gcInterval or blobs.gcGrace are not set.
+const (
+	defaultGCInterval = time.Hour
+	defaultGCGrace    = 24 * time.Hour
+)
+
+// BlobCollector removes uploaded files that no vault record refers to.
+type BlobCollector struct {
+	service  service.GophKeeper
+	grace    time.Duration
+	interval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	now func() time.Time
+	log *zap.SugaredLogger
+}
+
+// NewBlobCollector creates a collector configured by the blobs section.
+func NewBlobCollector(i do.Injector) (*BlobCollector, error) {
+	cfg := do.MustInvoke[*config.Config](i)
+
+	c := &BlobCollector{
+		service:  do.MustInvoke[service.GophKeeper](i),
+		grace:    cfg.Blobs.GCGrace,
+		interval: cfg.Blobs.GCInterval,
+		now:      time.Now,
+		log:      do.MustInvoke[*logger.Logger](i).Named("collector"),
+	}
+	if c.grace <= 0 {
+		c.grace = defaultGCGrace
+	}
+	if c.interval <= 0 {
+		c.interval = defaultGCInterval
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+
+	return c, nil
+}
+
+// Start collects right away and then every interval until Shutdown.
+func (c *BlobCollector) Start() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.collect(c.ctx)
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown stops the collector, interrupting a collection in progress.
+func (c *BlobCollector) Shutdown() {
+	c.cancel()
+	c.log.Debug("blob collector stopped")
+}
+
+// collect removes blobs left unreferenced for longer than the grace period.
+func (c *BlobCollector) collect(ctx context.Context) {
+	blobs, objects, err := c.service.CollectBlobs(ctx, c.now().Add(-c.grace))
+	if err != nil {
+		if ctx.Err() == nil {
+			c.log.Errorf("collect blobs: %v", err)
+		}
+		return
+	}
+
+	if blobs > 0 || objects > 0 {
+		c.log.Infof("collected %d blobs and %d stored chunks", blobs, objects)
+	}
+}
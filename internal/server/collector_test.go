@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/do/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+	"github.com/wickedv43/go-goph-keeper/internal/logger"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/service"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestNewBlobCollector(t *testing.T) {
+	newCollector := func(cfg config.Blobs) *BlobCollector {
+		i := do.New()
+		do.ProvideValue(i, &config.Config{Blobs: cfg})
+		do.ProvideValue[service.GophKeeper](i, &mocks.MockGophKeeper{})
+		do.ProvideValue(i, &logger.Logger{SugaredLogger: zap.NewNop().Sugar()})
+
+		c, err := NewBlobCollector(i)
+		require.NoError(t, err)
+		return c
+	}
+
+	c := newCollector(config.Blobs{})
+	require.Equal(t, defaultGCGrace, c.grace)
+	require.Equal(t, defaultGCInterval, c.interval)
+
+	c = newCollector(config.Blobs{GCGrace: time.Hour, GCInterval: time.Minute})
+	require.Equal(t, time.Hour, c.grace)
+	require.Equal(t, time.Minute, c.interval)
+}
+
+func TestBlobCollector_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &BlobCollector{
+		service:  mockService,
+		grace:    24 * time.Hour,
+		interval: 10 * time.Millisecond,
+		ctx:      ctx,
+		cancel:   cancel,
+		now:      func() time.Time { return now },
+		log:      zap.NewNop().Sugar(),
+	}
+
+	collected := make(chan struct{}, 10)
+	mockService.EXPECT().
+		CollectBlobs(gomock.Any(), now.Add(-24*time.Hour)).
+		DoAndReturn(func(context.Context, time.Time) (int64, int64, error) {
+			collected <- struct{}{}
+			return 1, 3, nil
+		}).
+		MinTimes(2)
+
+	done := make(chan struct{})
+	go func() {
+		c.Start()
+		close(done)
+	}()
+
+	// once on start and again on the next tick
+	<-collected
+	<-collected
+
+	c.Shutdown()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("collector did not stop")
+	}
+}
+
+func TestBlobCollector_CollectError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	c := &BlobCollector{
+		service: mockService,
+		grace:   time.Hour,
+		now:     time.Now,
+		log:     zap.NewNop().Sugar(),
+	}
+
+	mockService.EXPECT().CollectBlobs(gomock.Any(), gomock.Any()).Return(int64(0), int64(0), errors.New("db down"))
+
+	// errors are logged, the collector keeps running
+	c.collect(context.Background())
+}
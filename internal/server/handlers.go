@@ -2,23 +2,24 @@ package server
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/pkg/errors"
 	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/internal/service"
 	"github.com/wickedv43/go-goph-keeper/internal/storage"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// ErrInvalidCredentials is returned by Login for both unknown logins and wrong passwords.
+var ErrInvalidCredentials = status.Error(codes.Unauthenticated, "неверный логин или пароль")
+
 // Register registers a new user with the provided login and password.
 func (s *Server) Register(ctx context.Context, in *pb.RegisterRequest) (*pb.RegisterResponse, error) {
-	u := &storage.User{
-		Login:        in.Login,
-		PasswordHash: in.Password,
-	}
-
-	user, err := s.service.NewUser(ctx, u)
+	user, err := s.service.Register(ctx, in.Login, in.Password)
 	if err != nil {
 		if errors.Is(storage.ErrLoginUsed, err) {
 			return nil, err
@@ -33,63 +34,224 @@ func (s *Server) Register(ctx context.Context, in *pb.RegisterRequest) (*pb.Regi
 
 // Login authenticates a user and returns a JWT token on success.
 func (s *Server) Login(ctx context.Context, in *pb.LoginRequest) (*pb.LoginResponse, error) {
-	user, err := s.service.UserByLogin(ctx, in.Login)
+	user, err := s.service.Authenticate(ctx, in.Login, in.Password)
 	if err != nil {
-		return nil, status.Error(codes.NotFound, "пользователь не найден")
+		// один ответ для неизвестного логина и неверного пароля, чтобы нельзя было перебирать логины
+		if !errors.Is(err, service.ErrInvalidPassword) {
+			s.log.Debugf("login %q: %v", in.Login, err)
+		}
+		return nil, ErrInvalidCredentials
 	}
 
-	if user.PasswordHash != in.Password {
-		return nil, status.Error(codes.Unauthenticated, "неверный пароль")
+	required, err := s.service.MFARequired(ctx, user.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "не удалось проверить 2FA: %v", err)
 	}
 
-	token, err := generateJWT(user.ID)
+	if required {
+		mfaToken, err := s.tokens.generateMFA(user.ID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "ошибка генерации токена: %v", err)
+		}
+		return &pb.LoginResponse{MfaRequired: true, MfaToken: mfaToken}, nil
+	}
+
+	return s.startSession(ctx, user.ID, in.DeviceName, in.ClientVersion)
+}
+
+// VerifyMFA completes a login that requires a second factor and issues the token pair.
+func (s *Server) VerifyMFA(ctx context.Context, in *pb.VerifyMFARequest) (*pb.LoginResponse, error) {
+	userID, err := s.tokens.parseMFA(in.MfaToken)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "ошибка генерации токена: %v", err)
+		return nil, status.Error(codes.Unauthenticated, "сессия входа истекла, войдите заново")
 	}
 
-	s.log.Debugf("userID: ", int64(user.ID))
-	return &pb.LoginResponse{
-		Token: token,
-	}, nil
+	if err = s.service.VerifyMFA(ctx, userID, in.Code); err != nil {
+		if errors.Is(err, service.ErrInvalidMFACode) || errors.Is(err, service.ErrMFANotEnrolled) {
+			return nil, status.Error(codes.Unauthenticated, "неверный код")
+		}
+		return nil, status.Errorf(codes.Internal, "не удалось проверить код: %v", err)
+	}
+
+	return s.startSession(ctx, userID, in.DeviceName, in.ClientVersion)
 }
 
-// CreateVault stores a new vault record for the authenticated user.
-func (s *Server) CreateVault(ctx context.Context, in *pb.CreateVaultRequest) (*emptypb.Empty, error) {
+// EnrollMFA starts TOTP enrollment for the authenticated user.
+func (s *Server) EnrollMFA(ctx context.Context, _ *emptypb.Empty) (*pb.EnrollMFAResponse, error) {
 	userID, err := UserIDFromContext(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
 	}
 
+	secret, uri, err := s.service.EnrollMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrMFAAlreadyEnabled) {
+			return nil, status.Error(codes.AlreadyExists, "2FA уже включена")
+		}
+		return nil, status.Errorf(codes.Internal, "не удалось начать подключение 2FA: %v", err)
+	}
+
+	return &pb.EnrollMFAResponse{Secret: secret, OtpauthUri: uri}, nil
+}
+
+// ConfirmMFA enables TOTP for the authenticated user and returns recovery codes.
+func (s *Server) ConfirmMFA(ctx context.Context, in *pb.ConfirmMFARequest) (*pb.ConfirmMFAResponse, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	codesList, err := s.service.ConfirmMFA(ctx, userID, in.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMFACode):
+			return nil, status.Error(codes.InvalidArgument, "неверный код")
+		case errors.Is(err, service.ErrMFANotEnrolled):
+			return nil, status.Error(codes.FailedPrecondition, "сначала начните подключение 2FA")
+		case errors.Is(err, service.ErrMFAAlreadyEnabled):
+			return nil, status.Error(codes.AlreadyExists, "2FA уже включена")
+		}
+		return nil, status.Errorf(codes.Internal, "не удалось подключить 2FA: %v", err)
+	}
+
+	return &pb.ConfirmMFAResponse{RecoveryCodes: codesList}, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access and refresh token pair.
+func (s *Server) RefreshToken(ctx context.Context, in *pb.RefreshTokenRequest) (*pb.LoginResponse, error) {
+	sess, refresh, err := s.service.RefreshSession(ctx, in.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			s.log.Warnf("refresh token reuse detected, session revoked")
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "не удалось обновить токен: %v", err)
+	}
+
+	return s.tokenResponse(sess, refresh)
+}
+
+// Logout revokes the session of the access token used for the call.
+func (s *Server) Logout(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	sessionID, err := SessionIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди сессии")
+	}
+
+	if err = s.service.RevokeSession(ctx, userID, sessionID); err != nil {
+		return nil, status.Errorf(codes.Internal, "не удалось завершить сессию: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ListSessions returns active sessions of the authenticated user.
+func (s *Server) ListSessions(ctx context.Context, _ *emptypb.Empty) (*pb.ListSessionsResponse, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	// текущая сессия помечается в ответе
+	sessionID, _ := SessionIDFromContext(ctx)
+
+	sessions, err := s.service.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "не удалось получить список сессий: %v", err)
+	}
+
+	var out []*pb.Session
+	for _, sess := range sessions {
+		out = append(out, mapSessionToProto(&sess, sessionID))
+	}
+
+	return &pb.ListSessionsResponse{Sessions: out}, nil
+}
+
+// RevokeSession ends one of the sessions of the authenticated user.
+func (s *Server) RevokeSession(ctx context.Context, in *pb.RevokeSessionRequest) (*emptypb.Empty, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	if err = s.service.RevokeSession(ctx, userID, in.SessionId); err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			return nil, status.Errorf(codes.NotFound, "сессия не найдена: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "не удалось завершить сессию: %v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// CreateVault stores a new vault record for the authenticated user and returns it with its ID.
+// A record with the UUID of an existing one fails with AlreadyExists.
+func (s *Server) CreateVault(ctx context.Context, in *pb.CreateVaultRequest) (*pb.VaultRecord, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	tokens, err := blindTokens(in.Record.GetBlindTokens())
+	if err != nil {
+		return nil, err
+	}
+	if err = checkUUID(in.Record.GetUuid()); err != nil {
+		return nil, err
+	}
+
 	v := &storage.VaultRecord{
 		UserID:        userID,
 		Type:          storage.RecordType(in.Record.Type),
 		Title:         in.Record.Title,
 		Metadata:      in.Record.Metadata,
 		EncryptedData: in.Record.EncryptedData,
+		BlobRef:       in.Record.BlobRef,
+		UUID:          in.Record.Uuid,
+		SealedMeta:    in.Record.SealedMeta,
+		Tokens:        tokens,
 	}
 	if err = s.service.CreateVault(ctx, v); err != nil {
-		return nil, status.Errorf(codes.Internal, "не удалось создать запись: %v", err)
+		return nil, vaultStatus(err, "не удалось создать запись")
 	}
-	return &emptypb.Empty{}, nil
+	return mapVaultToProto(v), nil
 }
 
-// GetVault retrieves a vault record by its ID.
+// GetVault retrieves a vault record of the authenticated user by its ID.
 func (s *Server) GetVault(ctx context.Context, in *pb.GetVaultRequest) (*pb.VaultRecord, error) {
-	v, err := s.service.GetVault(ctx, in.VaultId)
+	userID, err := UserIDFromContext(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "запись не найдена: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	v, err := s.service.GetVault(ctx, userID, in.VaultId)
+	if err != nil {
+		return nil, vaultStatus(err, "не удалось получить запись")
 	}
 	return mapVaultToProto(&v), nil
 }
 
-// TODO: delete
 // UpdateVault updates an existing vault record belonging to the authenticated user.
+// If in.Revision is stale the call fails with Aborted and a RevisionConflict detail,
+// if it is missing with InvalidArgument.
 func (s *Server) UpdateVault(ctx context.Context, in *pb.VaultRecord) (*emptypb.Empty, error) {
 	userID, err := UserIDFromContext(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
 	}
 
+	tokens, err := blindTokens(in.BlindTokens)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkUUID(in.Uuid); err != nil {
+		return nil, err
+	}
+
 	v := &storage.VaultRecord{
 		ID:            in.Id,
 		UserID:        userID,
@@ -97,41 +259,390 @@ func (s *Server) UpdateVault(ctx context.Context, in *pb.VaultRecord) (*emptypb.
 		Title:         in.Title,
 		Metadata:      in.Metadata,
 		EncryptedData: in.EncryptedData,
+		BlobRef:       in.BlobRef,
+		Revision:      in.Revision,
+		UUID:          in.Uuid,
+		SealedMeta:    in.SealedMeta,
+		Tokens:        tokens,
 	}
 	if err = s.service.UpdateVault(ctx, v); err != nil {
-		return nil, status.Errorf(codes.Internal, "не удалось обновить запись: %v", err)
+		return nil, vaultStatus(err, "не удалось обновить запись")
 	}
 	return &emptypb.Empty{}, nil
 }
 
-// TODO: delete
-// DeleteVault removes a vault record by its ID.
+// DeleteVault moves a vault record of the authenticated user to the trash.
 func (s *Server) DeleteVault(ctx context.Context, in *pb.DeleteVaultRequest) (*emptypb.Empty, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
 
-	if err := s.service.DeleteVault(ctx, in.VaultId); err != nil {
-		return nil, status.Errorf(codes.Internal, "не удалось удалить запись: %v", err)
+	if err = s.service.DeleteVault(ctx, userID, in.VaultId); err != nil {
+		return nil, vaultStatus(err, "не удалось удалить запись")
 	}
 	return &emptypb.Empty{}, nil
 }
 
-// ListVaults returns all vault records associated with the authenticated user.
-func (s *Server) ListVaults(ctx context.Context, _ *pb.ListVaultsRequest) (*pb.ListVaultsResponse, error) {
+// ListVaults returns a page of the vault records of the authenticated user
+// that match the filters of the request, in the requested order.
+func (s *Server) ListVaults(ctx context.Context, in *pb.ListVaultsRequest) (*pb.ListVaultsResponse, error) {
 	userID, err := UserIDFromContext(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
 	}
 
-	records, err := s.service.ListVaults(ctx, userID)
+	filter, err := vaultFilter(in)
+	if err != nil {
+		return nil, err
+	}
+
+	records, next, err := s.service.ListVaults(ctx, userID, filter, in.PageToken)
+	if errors.Is(err, service.ErrInvalidPageToken) {
+		return nil, status.Errorf(codes.InvalidArgument, "неверный page_token: %v", err)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "не удалось получить список: %v", err)
 	}
 
-	var result []*pb.VaultRecord
+	result := make([]*pb.VaultRecord, 0, len(records))
 	for _, r := range records {
-		result = append(result, mapVaultToProto(&r))
+		pv := mapVaultToProto(&r)
+		maskVault(pv, filter.Fields)
+		result = append(result, pv)
 	}
 
 	return &pb.ListVaultsResponse{
-		Vaults: result,
+		Vaults:        result,
+		NextPageToken: next,
+	}, nil
+}
+
+// ListVaultSummaries returns a page of the vault records of the authenticated user without their contents.
+func (s *Server) ListVaultSummaries(ctx context.Context, in *pb.ListVaultsRequest) (*pb.ListVaultSummariesResponse, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	filter, err := vaultFilter(in)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, next, err := s.service.ListVaultSummaries(ctx, userID, filter, in.PageToken)
+	if errors.Is(err, service.ErrInvalidPageToken) {
+		return nil, status.Errorf(codes.InvalidArgument, "неверный page_token: %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "не удалось получить список: %v", err)
+	}
+
+	result := make([]*pb.VaultSummary, 0, len(summaries))
+	for _, v := range summaries {
+		result = append(result, mapSummaryToProto(&v))
+	}
+
+	return &pb.ListVaultSummariesResponse{
+		Vaults:        result,
+		NextPageToken: next,
+	}, nil
+}
+
+// FindVaults returns the vault records of the authenticated user that have
+// all the blind index tokens of the request.
+func (s *Server) FindVaults(ctx context.Context, in *pb.FindVaultsRequest) (*pb.ListVaultsResponse, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	tokens, err := blindTokens(in.Tokens)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "нужен хотя бы один токен")
+	}
+
+	vaults, err := s.service.FindVaults(ctx, userID, tokens)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "не удалось найти записи: %v", err)
+	}
+
+	result := make([]*pb.VaultRecord, 0, len(vaults))
+	for _, v := range vaults {
+		result = append(result, mapVaultToProto(&v))
+	}
+	return &pb.ListVaultsResponse{Vaults: result}, nil
+}
+
+// ListVaultVersions returns previous versions of a vault record of the authenticated user.
+func (s *Server) ListVaultVersions(ctx context.Context, in *pb.ListVaultVersionsRequest) (*pb.ListVaultVersionsResponse, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	versions, err := s.service.ListVaultVersions(ctx, userID, in.VaultId)
+	if err != nil {
+		return nil, vaultStatus(err, "не удалось получить историю")
+	}
+
+	result := make([]*pb.VaultVersion, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, mapVersionToProto(&v))
+	}
+
+	return &pb.ListVaultVersionsResponse{Versions: result}, nil
+}
+
+// RestoreVaultVersion makes a previous version of a vault record current again.
+func (s *Server) RestoreVaultVersion(ctx context.Context, in *pb.RestoreVaultVersionRequest) (*pb.VaultRecord, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	v, err := s.service.RestoreVaultVersion(ctx, userID, in.VaultId, in.Version)
+	if err != nil {
+		return nil, vaultStatus(err, "не удалось восстановить версию")
+	}
+
+	return mapVaultToProto(&v), nil
+}
+
+// ListTrash returns trashed vault records of the authenticated user.
+func (s *Server) ListTrash(ctx context.Context, _ *emptypb.Empty) (*pb.ListVaultsResponse, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	records, err := s.service.ListTrash(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "не удалось получить корзину: %v", err)
+	}
+
+	result := make([]*pb.VaultRecord, 0, len(records))
+	for _, r := range records {
+		result = append(result, mapVaultToProto(&r))
+	}
+
+	return &pb.ListVaultsResponse{Vaults: result}, nil
+}
+
+// RestoreVault moves a vault record of the authenticated user out of the trash.
+func (s *Server) RestoreVault(ctx context.Context, in *pb.RestoreVaultRequest) (*emptypb.Empty, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	if err = s.service.RestoreVault(ctx, userID, in.VaultId); err != nil {
+		return nil, vaultStatus(err, "не удалось восстановить запись")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// PurgeVault permanently removes a trashed vault record of the authenticated user.
+func (s *Server) PurgeVault(ctx context.Context, in *pb.PurgeVaultRequest) (*emptypb.Empty, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	if err = s.service.PurgeVault(ctx, userID, in.VaultId); err != nil {
+		return nil, vaultStatus(err, "не удалось удалить запись")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// Sync returns the changes of the authenticated user's records since the cursor.
+func (s *Server) Sync(ctx context.Context, in *pb.SyncRequest) (*pb.SyncResponse, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	res, err := s.service.Sync(ctx, userID, in.SinceCursor, int(in.Limit))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "не удалось получить изменения: %v", err)
+	}
+
+	changed := make([]*pb.VaultRecord, 0, len(res.Changed))
+	for _, r := range res.Changed {
+		changed = append(changed, mapVaultToProto(&r))
+	}
+
+	return &pb.SyncResponse{
+		Changed:    changed,
+		Deleted:    res.Deleted,
+		Cursor:     res.Cursor,
+		HasMore:    res.HasMore,
+		FullResync: res.Reset,
 	}, nil
 }
+
+// watchCheckInterval is how often Watch checks that the session is still active.
+const watchCheckInterval = time.Minute
+
+// Watch streams changes of the caller's records made by other sessions until
+// the client disconnects. The session is checked again every
+// watchCheckInterval, so a revoked or logged out session stops receiving them.
+func (s *Server) Watch(_ *pb.WatchRequest, stream pb.GophKeeper_WatchServer) error {
+	ctx := stream.Context()
+
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+	sessionID, err := SessionIDFromContext(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "нет айди сессии")
+	}
+
+	events, cancel := s.service.Watch(ctx, userID)
+	defer cancel()
+
+	every := s.watchCheck
+	if every == 0 {
+		every = watchCheckInterval
+	}
+	check := time.NewTicker(every)
+	defer check.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-check.C:
+			if _, err = s.service.CheckSession(ctx, userID, sessionID); err != nil {
+				return ErrUnauthenticated
+			}
+		case ev, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "сервер останавливается")
+			}
+			if ev.Origin == sessionID {
+				continue
+			}
+			err = stream.Send(&pb.VaultEvent{VaultId: ev.VaultID, Revision: ev.Revision, Op: ev.Op})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// UploadBlob stores the encrypted chunks of a file. The first part is the
+// header describing the upload; the chunks that follow start at its offset.
+// Chunks the server already has are skipped, so an interrupted upload is
+// resumed by sending it again from the position reported by GetUpload.
+func (s *Server) UploadBlob(stream pb.GophKeeper_UploadBlobServer) error {
+	ctx := stream.Context()
+
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	hdr := first.GetHeader()
+	if hdr == nil {
+		return status.Error(codes.InvalidArgument, "загрузка должна начинаться с заголовка")
+	}
+
+	b, err := s.service.OpenUpload(ctx, &storage.Blob{
+		UserID: userID,
+		Ref:    hdr.BlobId,
+		Header: hdr.Header,
+		Size:   hdr.Size,
+		Chunks: hdr.Chunks,
+	})
+	if err != nil {
+		return blobStatus(err, "не удалось начать загрузку")
+	}
+	if hdr.Offset > b.Received {
+		return status.Errorf(codes.FailedPrecondition, "загрузка продолжается с куска %d", b.Received)
+	}
+
+	for seq := hdr.Offset; ; seq++ {
+		part, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if seq < b.Received {
+			continue // уже сохранён при прошлой попытке
+		}
+		if err = s.service.PutBlobChunk(ctx, b, seq, part.GetChunk()); err != nil {
+			return blobStatus(err, "не удалось сохранить кусок")
+		}
+		b.Received++
+	}
+
+	return stream.SendAndClose(&pb.UploadStatus{BlobId: b.Ref, Received: b.Received, Complete: b.Complete()})
+}
+
+// GetUpload reports how much of an upload the server has, to resume it.
+func (s *Server) GetUpload(ctx context.Context, in *pb.GetUploadRequest) (*pb.UploadStatus, error) {
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	b, err := s.service.Blob(ctx, userID, in.BlobId)
+	if err != nil {
+		return nil, blobStatus(err, "не удалось получить загрузку")
+	}
+
+	return &pb.UploadStatus{BlobId: b.Ref, Received: b.Received, Complete: b.Complete()}, nil
+}
+
+// DownloadBlob streams the header of a blob and its chunks from the requested offset.
+func (s *Server) DownloadBlob(in *pb.DownloadBlobRequest, stream pb.GophKeeper_DownloadBlobServer) error {
+	ctx := stream.Context()
+
+	userID, err := UserIDFromContext(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "нет айди юзера")
+	}
+
+	b, err := s.service.Blob(ctx, userID, in.BlobId)
+	if err != nil {
+		return blobStatus(err, "не удалось получить файл")
+	}
+	if !b.Complete() {
+		return blobStatus(service.ErrBlobIncomplete, "")
+	}
+
+	err = stream.Send(&pb.BlobPart{Part: &pb.BlobPart_Header{Header: &pb.BlobHeader{
+		BlobId: b.Ref,
+		Header: b.Header,
+		Size:   b.Size,
+		Chunks: b.Chunks,
+		Offset: in.Offset,
+	}}})
+	if err != nil {
+		return err
+	}
+
+	for seq := in.Offset; seq < b.Chunks; seq++ {
+		data, err := s.service.BlobChunk(ctx, b, seq)
+		if err != nil {
+			return blobStatus(err, "не удалось прочитать файл")
+		}
+		if err = stream.Send(&pb.BlobPart{Part: &pb.BlobPart_Chunk{Chunk: data}}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,7 +2,11 @@ package server
 
 import (
 	"context"
+	"io"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/samber/do/v2"
@@ -11,11 +15,18 @@ import (
 	pb "github.com/wickedv43/go-goph-keeper/internal/api"
 	"github.com/wickedv43/go-goph-keeper/internal/logger"
 	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/pubsub"
+	"github.com/wickedv43/go-goph-keeper/internal/service"
 	"github.com/wickedv43/go-goph-keeper/internal/storage"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"gorm.io/gorm"
 )
 
 func TestServer_Register(t *testing.T) {
@@ -35,7 +46,7 @@ func TestServer_Register(t *testing.T) {
 	t.Run("success: user created", func(t *testing.T) {
 		mockService.
 			EXPECT().
-			NewUser(gomock.Any(), gomock.Any()).
+			Register(gomock.Any(), "test", "pass123").
 			Return(storage.User{ID: 42, Login: "test"}, nil)
 
 		req := &api.RegisterRequest{
@@ -51,7 +62,7 @@ func TestServer_Register(t *testing.T) {
 	t.Run("error: login already used", func(t *testing.T) {
 		mockService.
 			EXPECT().
-			NewUser(gomock.Any(), gomock.Any()).
+			Register(gomock.Any(), "test", "pass123").
 			Return(storage.User{}, storage.ErrLoginUsed)
 
 		req := &api.RegisterRequest{
@@ -67,7 +78,7 @@ func TestServer_Register(t *testing.T) {
 	t.Run("error: internal error", func(t *testing.T) {
 		mockService.
 			EXPECT().
-			NewUser(gomock.Any(), gomock.Any()).
+			Register(gomock.Any(), "test", "pass123").
 			Return(storage.User{}, errors.New("db down"))
 
 		req := &api.RegisterRequest{
@@ -91,29 +102,70 @@ func TestServer_Login(t *testing.T) {
 
 	s := &Server{
 		service: mockService,
+		tokens:  newTestTokenManager(t),
 		log:     log,
 	}
 
 	t.Run("success: valid login and password", func(t *testing.T) {
 		mockService.
 			EXPECT().
-			UserByLogin(gomock.Any(), "tester").
-			Return(storage.User{ID: 101, Login: "tester", PasswordHash: "qwerty"}, nil)
+			Authenticate(gomock.Any(), "tester", "qwerty").
+			Return(storage.User{ID: 101, Login: "tester"}, nil)
+		mockService.
+			EXPECT().
+			MFARequired(gomock.Any(), uint64(101)).
+			Return(false, nil)
+		mockService.
+			EXPECT().
+			StartSession(gomock.Any(), uint64(101), storage.Device{Name: "laptop", ClientVersion: "v1.2.0"}).
+			Return(storage.Session{ID: 5, UserID: 101}, "5.0.f.s", nil)
 
 		req := &pb.LoginRequest{
-			Login:    "tester",
-			Password: "qwerty",
+			Login:         "tester",
+			Password:      "qwerty",
+			DeviceName:    "laptop",
+			ClientVersion: "v1.2.0",
 		}
 
 		resp, err := s.Login(context.Background(), req)
 		require.NoError(t, err)
 		require.NotEmpty(t, resp.Token)
+		require.Equal(t, "5.0.f.s", resp.RefreshToken)
+		require.Equal(t, int64(defaultAccessTTL.Seconds()), resp.ExpiresIn)
+
+		claims, err := s.tokens.parseClaims(resp.Token)
+		require.NoError(t, err)
+		require.Equal(t, accessClaims{UserID: 101, SessionID: 5}, claims)
+	})
+
+	t.Run("mfa: second factor required", func(t *testing.T) {
+		mockService.
+			EXPECT().
+			Authenticate(gomock.Any(), "secure", "qwerty").
+			Return(storage.User{ID: 102, Login: "secure"}, nil)
+		mockService.
+			EXPECT().
+			MFARequired(gomock.Any(), uint64(102)).
+			Return(true, nil)
+
+		resp, err := s.Login(context.Background(), &pb.LoginRequest{Login: "secure", Password: "qwerty"})
+		require.NoError(t, err)
+		require.True(t, resp.MfaRequired)
+		require.Empty(t, resp.Token)
+		require.Empty(t, resp.RefreshToken)
+
+		uid, err := s.tokens.parseMFA(resp.MfaToken)
+		require.NoError(t, err)
+		require.Equal(t, uint64(102), uid)
+
+		_, err = s.tokens.parseClaims(resp.MfaToken)
+		require.Error(t, err, "challenge token must not work as access token")
 	})
 
 	t.Run("error: user not found", func(t *testing.T) {
 		mockService.
 			EXPECT().
-			UserByLogin(gomock.Any(), "unknown").
+			Authenticate(gomock.Any(), "unknown", "pass").
 			Return(storage.User{}, errors.New("not found"))
 
 		req := &pb.LoginRequest{
@@ -124,15 +176,14 @@ func TestServer_Login(t *testing.T) {
 		resp, err := s.Login(context.Background(), req)
 		require.Error(t, err)
 		require.Nil(t, resp)
-		st, _ := status.FromError(err)
-		require.Equal(t, codes.NotFound, st.Code())
+		require.Equal(t, ErrInvalidCredentials, err, "unknown login looks like a wrong password")
 	})
 
 	t.Run("error: wrong password", func(t *testing.T) {
 		mockService.
 			EXPECT().
-			UserByLogin(gomock.Any(), "tester").
-			Return(storage.User{ID: 101, Login: "tester", PasswordHash: "correct"}, nil)
+			Authenticate(gomock.Any(), "tester", "wrong").
+			Return(storage.User{}, service.ErrInvalidPassword)
 
 		req := &pb.LoginRequest{
 			Login:    "tester",
@@ -144,6 +195,253 @@ func TestServer_Login(t *testing.T) {
 		require.Nil(t, resp)
 		st, _ := status.FromError(err)
 		require.Equal(t, codes.Unauthenticated, st.Code())
+		require.Equal(t, ErrInvalidCredentials, err)
+	})
+}
+
+func TestServer_VerifyMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+
+	s := &Server{
+		service: mockService,
+		tokens:  newTestTokenManager(t),
+		log:     zap.NewNop().Sugar(),
+	}
+
+	mfaToken, err := s.tokens.generateMFA(102)
+	require.NoError(t, err)
+
+	t.Run("success: issues tokens", func(t *testing.T) {
+		mockService.EXPECT().VerifyMFA(gomock.Any(), uint64(102), "123456").Return(nil)
+		mockService.
+			EXPECT().
+			StartSession(gomock.Any(), uint64(102), storage.Device{Name: "laptop"}).
+			Return(storage.Session{ID: 8, UserID: 102}, "8.0.f.s", nil)
+
+		resp, err := s.VerifyMFA(context.Background(), &pb.VerifyMFARequest{
+			MfaToken: mfaToken, Code: "123456", DeviceName: "laptop",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "8.0.f.s", resp.RefreshToken)
+
+		claims, err := s.tokens.parseClaims(resp.Token)
+		require.NoError(t, err)
+		require.Equal(t, accessClaims{UserID: 102, SessionID: 8}, claims)
+	})
+
+	t.Run("error: wrong code", func(t *testing.T) {
+		mockService.EXPECT().VerifyMFA(gomock.Any(), uint64(102), "000000").Return(service.ErrInvalidMFACode)
+
+		_, err := s.VerifyMFA(context.Background(), &pb.VerifyMFARequest{MfaToken: mfaToken, Code: "000000"})
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.Unauthenticated, st.Code())
+	})
+
+	t.Run("error: access token instead of challenge", func(t *testing.T) {
+		access, err := s.tokens.generate(102, 1)
+		require.NoError(t, err)
+
+		_, err = s.VerifyMFA(context.Background(), &pb.VerifyMFARequest{MfaToken: access, Code: "123456"})
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.Unauthenticated, st.Code())
+	})
+}
+
+func TestServer_EnrollAndConfirmMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+
+	s := &Server{
+		service: mockService,
+		log:     zap.NewNop().Sugar(),
+	}
+
+	ctx := ContextWithUserID(context.Background(), 42)
+
+	t.Run("enroll", func(t *testing.T) {
+		mockService.EXPECT().EnrollMFA(gomock.Any(), uint64(42)).Return("SECRET", "otpauth://totp/x", nil)
+
+		resp, err := s.EnrollMFA(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+		require.Equal(t, "SECRET", resp.Secret)
+		require.Equal(t, "otpauth://totp/x", resp.OtpauthUri)
+	})
+
+	t.Run("enroll: already enabled", func(t *testing.T) {
+		mockService.EXPECT().EnrollMFA(gomock.Any(), uint64(42)).Return("", "", service.ErrMFAAlreadyEnabled)
+
+		_, err := s.EnrollMFA(ctx, &emptypb.Empty{})
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.AlreadyExists, st.Code())
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		mockService.EXPECT().ConfirmMFA(gomock.Any(), uint64(42), "123456").Return([]string{"aaaaa-bbbbb"}, nil)
+
+		resp, err := s.ConfirmMFA(ctx, &pb.ConfirmMFARequest{Code: "123456"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"aaaaa-bbbbb"}, resp.RecoveryCodes)
+	})
+
+	t.Run("confirm: wrong code", func(t *testing.T) {
+		mockService.EXPECT().ConfirmMFA(gomock.Any(), uint64(42), "000000").Return(nil, service.ErrInvalidMFACode)
+
+		_, err := s.ConfirmMFA(ctx, &pb.ConfirmMFARequest{Code: "000000"})
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+	})
+}
+
+func TestServer_RefreshToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+
+	s := &Server{
+		service: mockService,
+		tokens:  newTestTokenManager(t),
+		log:     zap.NewNop().Sugar(),
+	}
+
+	t.Run("success: rotates tokens", func(t *testing.T) {
+		mockService.
+			EXPECT().
+			RefreshSession(gomock.Any(), "5.0.f.s").
+			Return(storage.Session{ID: 5, UserID: 101, Generation: 1}, "5.1.f.n", nil)
+
+		resp, err := s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: "5.0.f.s"})
+		require.NoError(t, err)
+		require.Equal(t, "5.1.f.n", resp.RefreshToken)
+
+		claims, err := s.tokens.parseClaims(resp.Token)
+		require.NoError(t, err)
+		require.Equal(t, accessClaims{UserID: 101, SessionID: 5}, claims)
+	})
+
+	for _, svcErr := range []error{
+		service.ErrInvalidRefreshToken,
+		service.ErrRefreshTokenReused,
+		service.ErrSessionRevoked,
+	} {
+		t.Run("error: "+svcErr.Error(), func(t *testing.T) {
+			mockService.
+				EXPECT().
+				RefreshSession(gomock.Any(), "bad").
+				Return(storage.Session{}, "", svcErr)
+
+			resp, err := s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: "bad"})
+			require.Nil(t, resp)
+			st, _ := status.FromError(err)
+			require.Equal(t, codes.Unauthenticated, st.Code())
+		})
+	}
+}
+
+func TestServer_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+
+	s := &Server{
+		service: mockService,
+		log:     zap.NewNop().Sugar(),
+	}
+
+	t.Run("success: revokes current session", func(t *testing.T) {
+		mockService.
+			EXPECT().
+			RevokeSession(gomock.Any(), uint64(42), uint64(5)).
+			Return(nil)
+
+		ctx := ContextWithSessionID(ContextWithUserID(context.Background(), 42), 5)
+		_, err := s.Logout(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+	})
+
+	t.Run("error: no session in context", func(t *testing.T) {
+		ctx := ContextWithUserID(context.Background(), 42)
+		_, err := s.Logout(ctx, &emptypb.Empty{})
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.Unauthenticated, st.Code())
+	})
+}
+
+func TestServer_ListSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+
+	s := &Server{
+		service: mockService,
+		log:     zap.NewNop().Sugar(),
+	}
+
+	t.Run("success: marks current session", func(t *testing.T) {
+		mockService.
+			EXPECT().
+			ListSessions(gomock.Any(), uint64(42)).
+			Return([]storage.Session{
+				{ID: 5, UserID: 42, Device: storage.Device{Name: "laptop", ClientVersion: "v1", IP: "10.0.0.1"}},
+				{ID: 6, UserID: 42, Device: storage.Device{Name: "phone"}},
+			}, nil)
+
+		ctx := ContextWithSessionID(ContextWithUserID(context.Background(), 42), 6)
+		resp, err := s.ListSessions(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+		require.Len(t, resp.Sessions, 2)
+		require.Equal(t, "laptop", resp.Sessions[0].DeviceName)
+		require.Equal(t, "10.0.0.1", resp.Sessions[0].Ip)
+		require.False(t, resp.Sessions[0].Current)
+		require.True(t, resp.Sessions[1].Current)
+	})
+
+	t.Run("error: unauthenticated", func(t *testing.T) {
+		_, err := s.ListSessions(context.Background(), &emptypb.Empty{})
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.Unauthenticated, st.Code())
+	})
+}
+
+func TestServer_RevokeSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+
+	s := &Server{
+		service: mockService,
+		log:     zap.NewNop().Sugar(),
+	}
+
+	ctx := ContextWithUserID(context.Background(), 42)
+
+	t.Run("success", func(t *testing.T) {
+		mockService.
+			EXPECT().
+			RevokeSession(gomock.Any(), uint64(42), uint64(5)).
+			Return(nil)
+
+		_, err := s.RevokeSession(ctx, &pb.RevokeSessionRequest{SessionId: 5})
+		require.NoError(t, err)
+	})
+
+	t.Run("error: foreign or unknown session", func(t *testing.T) {
+		mockService.
+			EXPECT().
+			RevokeSession(gomock.Any(), uint64(42), uint64(9)).
+			Return(errors.Wrap(storage.ErrSessionNotFound, "id=9"))
+
+		_, err := s.RevokeSession(ctx, &pb.RevokeSessionRequest{SessionId: 9})
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.NotFound, st.Code())
 	})
 }
 
@@ -171,7 +469,10 @@ func TestServer_CreateVault(t *testing.T) {
 				Metadata:      "meta",
 				EncryptedData: []byte("secret"),
 			}).
-			Return(nil)
+			DoAndReturn(func(_ context.Context, v *storage.VaultRecord) error {
+				v.ID, v.Revision = 7, 1
+				return nil
+			})
 
 		req := &pb.CreateVaultRequest{
 			Record: &pb.VaultRecord{
@@ -184,7 +485,99 @@ func TestServer_CreateVault(t *testing.T) {
 
 		resp, err := s.CreateVault(ctx, req)
 		require.NoError(t, err)
-		require.NotNil(t, resp)
+		require.Equal(t, uint64(7), resp.Id, "the client learns the ID of the new record")
+		require.Equal(t, uint64(1), resp.Revision)
+	})
+
+	t.Run("success: blind tokens", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+		token := strings.Repeat("0f", 32)
+
+		mockService.
+			EXPECT().
+			CreateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *storage.VaultRecord) error {
+				require.Equal(t, []string{token}, v.Tokens)
+				return nil
+			})
+
+		resp, err := s.CreateVault(ctx, &pb.CreateVaultRequest{
+			Record: &pb.VaultRecord{Type: "login", Title: "GitHub", BlindTokens: []string{token, token}},
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.BlindTokens, "tokens are never returned")
+	})
+
+	t.Run("error: repeated create", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+		mockService.
+			EXPECT().
+			CreateVault(gomock.Any(), gomock.Any()).
+			Return(errors.Wrap(storage.ErrVaultExists, "uuid"))
+
+		_, err := s.CreateVault(ctx, &pb.CreateVaultRequest{
+			Record: &pb.VaultRecord{Type: "note", Uuid: "0b5e5e9c-7a47-4a8e-9e3c-1d1f2a3b4c5d"},
+		})
+		require.Equal(t, codes.AlreadyExists, status.Code(err))
+	})
+
+	t.Run("error: invalid blind token", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+		_, err := s.CreateVault(ctx, &pb.CreateVaultRequest{
+			Record: &pb.VaultRecord{Type: "login", BlindTokens: []string{"github.com"}},
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("success: uuid", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+		const uuid = "0b5e5e9c-7a47-4a8e-9e3c-1d1f2a3b4c5d"
+
+		mockService.
+			EXPECT().
+			CreateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *storage.VaultRecord) error {
+				require.Equal(t, uuid, v.UUID)
+				return nil
+			})
+
+		resp, err := s.CreateVault(ctx, &pb.CreateVaultRequest{
+			Record: &pb.VaultRecord{Type: "note", Title: "todo", Uuid: uuid},
+		})
+		require.NoError(t, err)
+		require.Equal(t, uuid, resp.Uuid)
+	})
+
+	t.Run("success: sealed metadata", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+		mockService.
+			EXPECT().
+			CreateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *storage.VaultRecord) error {
+				require.Empty(t, v.Title)
+				require.Equal(t, []byte("sealed"), v.SealedMeta)
+				return nil
+			})
+
+		resp, err := s.CreateVault(ctx, &pb.CreateVaultRequest{
+			Record: &pb.VaultRecord{Type: "note", Metadata: "{}", SealedMeta: []byte("sealed")},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []byte("sealed"), resp.SealedMeta)
+	})
+
+	t.Run("error: invalid uuid", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+		for _, uuid := range []string{"42", "0B5E5E9C-7A47-4A8E-9E3C-1D1F2A3B4C5D", "0b5e5e9c07a4704a8e09e3c01d1f2a3b4c5d"} {
+			_, err := s.CreateVault(ctx, &pb.CreateVaultRequest{
+				Record: &pb.VaultRecord{Type: "note", Uuid: uuid},
+			})
+			require.Equal(t, codes.InvalidArgument, status.Code(err), uuid)
+		}
 	})
 
 	t.Run("error: unauthenticated", func(t *testing.T) {
@@ -226,6 +619,18 @@ func TestServer_CreateVault(t *testing.T) {
 		st, _ := status.FromError(err)
 		require.Equal(t, codes.Internal, st.Code())
 	})
+
+	t.Run("error: file not uploaded", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+		mockService.
+			EXPECT().
+			CreateVault(gomock.Any(), &storage.VaultRecord{UserID: 42, Type: "binary", BlobRef: "f00d"}).
+			Return(errors.Wrap(service.ErrBlobIncomplete, "ref=f00d"))
+
+		_, err := s.CreateVault(ctx, &pb.CreateVaultRequest{Record: &pb.VaultRecord{Type: "binary", BlobRef: "f00d"}})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
 }
 
 func TestServer_GetVault(t *testing.T) {
@@ -240,10 +645,12 @@ func TestServer_GetVault(t *testing.T) {
 		log:     log,
 	}
 
+	ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
 	t.Run("success: vault found", func(t *testing.T) {
 		mockService.
 			EXPECT().
-			GetVault(gomock.Any(), uint64(1)).
+			GetVault(gomock.Any(), uint64(42), uint64(1)).
 			Return(storage.VaultRecord{
 				ID:            1,
 				UserID:        42,
@@ -254,7 +661,7 @@ func TestServer_GetVault(t *testing.T) {
 			}, nil)
 
 		req := &pb.GetVaultRequest{VaultId: 1}
-		resp, err := s.GetVault(context.Background(), req)
+		resp, err := s.GetVault(ctx, req)
 		require.NoError(t, err)
 		require.Equal(t, uint64(1), resp.Id)
 		require.Equal(t, "note", resp.Type)
@@ -266,11 +673,11 @@ func TestServer_GetVault(t *testing.T) {
 	t.Run("error: vault not found", func(t *testing.T) {
 		mockService.
 			EXPECT().
-			GetVault(gomock.Any(), uint64(999)).
-			Return(storage.VaultRecord{}, errors.New("not found"))
+			GetVault(gomock.Any(), uint64(42), uint64(999)).
+			Return(storage.VaultRecord{}, storage.ErrVaultNotFound)
 
 		req := &pb.GetVaultRequest{VaultId: 999}
-		resp, err := s.GetVault(context.Background(), req)
+		resp, err := s.GetVault(ctx, req)
 		require.Error(t, err)
 		require.Nil(t, resp)
 
@@ -278,6 +685,29 @@ func TestServer_GetVault(t *testing.T) {
 		require.Equal(t, codes.NotFound, st.Code())
 		require.Contains(t, st.Message(), "запись не найдена")
 	})
+
+	t.Run("error: unauthenticated", func(t *testing.T) {
+		resp, err := s.GetVault(context.Background(), &pb.GetVaultRequest{VaultId: 1})
+		require.Error(t, err)
+		require.Nil(t, resp)
+
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.Unauthenticated, st.Code())
+	})
+
+	t.Run("error: service failure", func(t *testing.T) {
+		mockService.
+			EXPECT().
+			GetVault(gomock.Any(), uint64(42), uint64(5)).
+			Return(storage.VaultRecord{}, errors.New("db error"))
+
+		resp, err := s.GetVault(ctx, &pb.GetVaultRequest{VaultId: 5})
+		require.Error(t, err)
+		require.Nil(t, resp)
+
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.Internal, st.Code())
+	})
 }
 
 func TestServer_UpdateVault(t *testing.T) {
@@ -355,6 +785,44 @@ func TestServer_UpdateVault(t *testing.T) {
 		require.Equal(t, codes.Internal, st.Code())
 		require.Contains(t, st.Message(), "не удалось обновить запись")
 	})
+
+	t.Run("error: revision conflict", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, uint64(1))
+
+		mockService.
+			EXPECT().
+			UpdateVault(gomock.Any(), &storage.VaultRecord{ID: 2, UserID: 1, Revision: 3}).
+			Return(errors.Wrap(&storage.RevisionConflictError{VaultID: 2, Current: 5, Expected: 3}, "update"))
+
+		resp, err := s.UpdateVault(ctx, &pb.VaultRecord{Id: 2, Revision: 3})
+		require.Error(t, err)
+		require.Nil(t, resp)
+
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.Aborted, st.Code())
+		require.Len(t, st.Details(), 1)
+
+		conflict, ok := st.Details()[0].(*pb.RevisionConflict)
+		require.True(t, ok)
+		require.Equal(t, uint64(5), conflict.CurrentRevision)
+		require.Equal(t, uint64(3), conflict.ExpectedRevision)
+	})
+
+	t.Run("error: missing revision", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, uint64(1))
+
+		mockService.
+			EXPECT().
+			UpdateVault(gomock.Any(), &storage.VaultRecord{ID: 2, UserID: 1}).
+			Return(errors.Wrap(storage.ErrRevisionRequired, "id=2"))
+
+		resp, err := s.UpdateVault(ctx, &pb.VaultRecord{Id: 2})
+		require.Error(t, err)
+		require.Nil(t, resp)
+
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+	})
 }
 
 func TestServer_DeleteVault(t *testing.T) {
@@ -369,15 +837,17 @@ func TestServer_DeleteVault(t *testing.T) {
 		log:     log,
 	}
 
+	ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
 	t.Run("success: deleted", func(t *testing.T) {
 		mockService.
 			EXPECT().
-			DeleteVault(gomock.Any(), uint64(10)).
+			DeleteVault(gomock.Any(), uint64(42), uint64(10)).
 			Return(nil)
 
 		req := &pb.DeleteVaultRequest{VaultId: 10}
 
-		resp, err := s.DeleteVault(context.Background(), req)
+		resp, err := s.DeleteVault(ctx, req)
 		require.NoError(t, err)
 		require.NotNil(t, resp)
 	})
@@ -385,12 +855,12 @@ func TestServer_DeleteVault(t *testing.T) {
 	t.Run("error: failed to delete", func(t *testing.T) {
 		mockService.
 			EXPECT().
-			DeleteVault(gomock.Any(), uint64(999)).
+			DeleteVault(gomock.Any(), uint64(42), uint64(999)).
 			Return(errors.New("db error"))
 
 		req := &pb.DeleteVaultRequest{VaultId: 999}
 
-		resp, err := s.DeleteVault(context.Background(), req)
+		resp, err := s.DeleteVault(ctx, req)
 		require.Error(t, err)
 		require.Nil(t, resp)
 
@@ -398,6 +868,75 @@ func TestServer_DeleteVault(t *testing.T) {
 		require.Equal(t, codes.Internal, st.Code())
 		require.Contains(t, st.Message(), "не удалось удалить запись")
 	})
+
+	t.Run("error: unauthenticated", func(t *testing.T) {
+		resp, err := s.DeleteVault(context.Background(), &pb.DeleteVaultRequest{VaultId: 10})
+		require.Error(t, err)
+		require.Nil(t, resp)
+
+		st, _ := status.FromError(err)
+		require.Equal(t, codes.Unauthenticated, st.Code())
+	})
+}
+
+func TestServer_VaultCrossUserAccess(t *testing.T) {
+	const (
+		stranger = uint64(2)
+		vaultID  = uint64(10)
+	)
+
+	tests := []struct {
+		name string
+		mock func(m *mocks.MockGophKeeper)
+		call func(s *Server, ctx context.Context) error
+	}{
+		{
+			name: "GetVault",
+			mock: func(m *mocks.MockGophKeeper) {
+				m.EXPECT().GetVault(gomock.Any(), stranger, vaultID).Return(storage.VaultRecord{}, storage.ErrVaultNotFound)
+			},
+			call: func(s *Server, ctx context.Context) error {
+				_, err := s.GetVault(ctx, &pb.GetVaultRequest{VaultId: vaultID})
+				return err
+			},
+		},
+		{
+			name: "UpdateVault",
+			mock: func(m *mocks.MockGophKeeper) {
+				m.EXPECT().UpdateVault(gomock.Any(), &storage.VaultRecord{ID: vaultID, UserID: stranger, Revision: 1}).Return(storage.ErrVaultNotFound)
+			},
+			call: func(s *Server, ctx context.Context) error {
+				_, err := s.UpdateVault(ctx, &pb.VaultRecord{Id: vaultID, UserId: 1, Revision: 1})
+				return err
+			},
+		},
+		{
+			name: "DeleteVault",
+			mock: func(m *mocks.MockGophKeeper) {
+				m.EXPECT().DeleteVault(gomock.Any(), stranger, vaultID).Return(storage.ErrVaultNotFound)
+			},
+			call: func(s *Server, ctx context.Context) error {
+				_, err := s.DeleteVault(ctx, &pb.DeleteVaultRequest{VaultId: vaultID})
+				return err
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockGophKeeper(ctrl)
+			s := &Server{service: mockService, log: zap.NewNop().Sugar()}
+
+			tc.mock(mockService)
+
+			err := tc.call(s, ContextWithUserID(context.Background(), stranger))
+			require.Error(t, err)
+
+			st, _ := status.FromError(err)
+			require.Equal(t, codes.NotFound, st.Code())
+		})
+	}
 }
 
 func TestServer_ListVaults(t *testing.T) {
@@ -417,17 +956,78 @@ func TestServer_ListVaults(t *testing.T) {
 
 		mockService.
 			EXPECT().
-			ListVaults(gomock.Any(), uint64(42)).
+			ListVaults(gomock.Any(), uint64(42), storage.VaultFilter{Sort: storage.SortByID}, "").
 			Return([]storage.VaultRecord{
 				{ID: 1, UserID: 42, Type: "note", Title: "Note1", Metadata: "", EncryptedData: []byte("123")},
 				{ID: 2, UserID: 42, Type: "login", Title: "Login1", Metadata: "m", EncryptedData: []byte("456")},
-			}, nil)
+			}, "next", nil)
 
 		resp, err := s.ListVaults(ctx, &pb.ListVaultsRequest{})
 		require.NoError(t, err)
 		require.Len(t, resp.Vaults, 2)
 		require.Equal(t, "note", resp.Vaults[0].Type)
 		require.Equal(t, "Login1", resp.Vaults[1].Title)
+		require.Equal(t, []byte("456"), resp.Vaults[1].EncryptedData)
+		require.Equal(t, "next", resp.NextPageToken)
+	})
+
+	t.Run("success: filters and field mask", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+		since := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		mockService.
+			EXPECT().
+			ListVaults(gomock.Any(), uint64(42), storage.VaultFilter{
+				Type:         "login",
+				TitlePrefix:  "Ba",
+				UpdatedSince: since,
+				MetadataKeys: []string{"url"},
+				Sort:         storage.SortByUpdated,
+				Desc:         true,
+				Limit:        10,
+				Fields:       []string{"title", "type"},
+			}, "token").
+			Return([]storage.VaultRecord{
+				{ID: 3, UserID: 42, Type: "login", Title: "Bank", UpdatedAt: since},
+			}, "", nil)
+
+		resp, err := s.ListVaults(ctx, &pb.ListVaultsRequest{
+			PageSize:     10,
+			PageToken:    "token",
+			Type:         "login",
+			TitlePrefix:  "Ba",
+			UpdatedSince: since.Format(time.RFC3339),
+			MetadataKeys: []string{"url"},
+			Sort:         pb.VaultSort_VAULT_SORT_UPDATED_AT,
+			Descending:   true,
+			Fields:       &fieldmaskpb.FieldMask{Paths: []string{"title", "type"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Vaults, 1)
+		require.True(t, proto.Equal(&pb.VaultRecord{Id: 3, Type: "login", Title: "Bank"}, resp.Vaults[0]),
+			"only the requested fields and the id are set")
+	})
+
+	t.Run("error: invalid request", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+		for name, in := range map[string]*pb.ListVaultsRequest{
+			"negative page size": {PageSize: -1},
+			"unknown sort":       {Sort: pb.VaultSort(42)},
+			"bad time":           {UpdatedSince: "yesterday"},
+			"unknown field":      {Fields: &fieldmaskpb.FieldMask{Paths: []string{"password"}}},
+		} {
+			_, err := s.ListVaults(ctx, in)
+			require.Equal(t, codes.InvalidArgument, status.Code(err), name)
+		}
+
+		mockService.
+			EXPECT().
+			ListVaults(gomock.Any(), uint64(42), gomock.Any(), "stale").
+			Return(nil, "", errors.Wrap(service.ErrInvalidPageToken, "malformed"))
+
+		_, err := s.ListVaults(ctx, &pb.ListVaultsRequest{PageToken: "stale"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
 	})
 
 	t.Run("error: unauthenticated", func(t *testing.T) {
@@ -444,8 +1044,8 @@ func TestServer_ListVaults(t *testing.T) {
 
 		mockService.
 			EXPECT().
-			ListVaults(gomock.Any(), uint64(99)).
-			Return(nil, errors.New("db error"))
+			ListVaults(gomock.Any(), uint64(99), gomock.Any(), "").
+			Return(nil, "", errors.New("db error"))
 
 		resp, err := s.ListVaults(ctx, &pb.ListVaultsRequest{})
 		require.Error(t, err)
@@ -456,3 +1056,539 @@ func TestServer_ListVaults(t *testing.T) {
 		require.Contains(t, st.Message(), "не удалось получить список")
 	})
 }
+
+func TestServer_ListVaultSummaries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	s := &Server{service: mockService, log: zap.NewNop().Sugar()}
+
+	ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+	t.Run("success", func(t *testing.T) {
+		updated := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+		mockService.EXPECT().
+			ListVaultSummaries(gomock.Any(), uint64(42), storage.VaultFilter{Type: "binary", Sort: storage.SortByTitle, Limit: 5}, "").
+			Return([]storage.VaultSummary{
+				{ID: 3, Type: "binary", Title: "photo.jpg", Metadata: "m", Size: 1 << 20, Revision: 2, CreatedAt: updated, UpdatedAt: updated},
+				{ID: 4, Type: "binary", Metadata: "{}", SealedMeta: true},
+			}, "next", nil)
+
+		resp, err := s.ListVaultSummaries(ctx, &pb.ListVaultsRequest{Type: "binary", Sort: pb.VaultSort_VAULT_SORT_TITLE, PageSize: 5})
+		require.NoError(t, err)
+		require.Equal(t, "next", resp.NextPageToken)
+		require.Len(t, resp.Vaults, 2)
+		require.True(t, resp.Vaults[1].SealedMeta)
+		require.True(t, proto.Equal(&pb.VaultSummary{
+			Id:        3,
+			Type:      "binary",
+			Title:     "photo.jpg",
+			Metadata:  "m",
+			Size:      1 << 20,
+			Revision:  2,
+			CreatedAt: updated.Format(time.RFC3339),
+			UpdatedAt: updated.Format(time.RFC3339),
+		}, resp.Vaults[0]))
+	})
+
+	t.Run("error: invalid request", func(t *testing.T) {
+		_, err := s.ListVaultSummaries(ctx, &pb.ListVaultsRequest{PageSize: -1})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		mockService.EXPECT().
+			ListVaultSummaries(gomock.Any(), uint64(42), gomock.Any(), "stale").
+			Return(nil, "", errors.Wrap(service.ErrInvalidPageToken, "malformed"))
+
+		_, err = s.ListVaultSummaries(ctx, &pb.ListVaultsRequest{PageToken: "stale"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("error: unauthenticated", func(t *testing.T) {
+		_, err := s.ListVaultSummaries(context.Background(), &pb.ListVaultsRequest{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("error: service failure", func(t *testing.T) {
+		mockService.EXPECT().
+			ListVaultSummaries(gomock.Any(), uint64(42), gomock.Any(), "").
+			Return(nil, "", errors.New("db error"))
+
+		_, err := s.ListVaultSummaries(ctx, &pb.ListVaultsRequest{})
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestServer_FindVaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	s := &Server{service: mockService, log: zap.NewNop().Sugar()}
+
+	ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+	tokenA, tokenB := strings.Repeat("a", 64), strings.Repeat("b", 64)
+
+	t.Run("success", func(t *testing.T) {
+		mockService.EXPECT().
+			FindVaults(gomock.Any(), uint64(42), []string{tokenA, tokenB}).
+			Return([]storage.VaultRecord{{ID: 3, UserID: 42, Type: "login", Title: "GitHub"}}, nil)
+
+		resp, err := s.FindVaults(ctx, &pb.FindVaultsRequest{Tokens: []string{tokenA, tokenB, tokenA}})
+		require.NoError(t, err)
+		require.Len(t, resp.Vaults, 1)
+		require.Equal(t, uint64(3), resp.Vaults[0].Id)
+		require.Empty(t, resp.NextPageToken)
+	})
+
+	t.Run("error: invalid tokens", func(t *testing.T) {
+		for _, tokens := range [][]string{
+			nil,
+			{"abc"},
+			{strings.Repeat("A", 64)},
+			{strings.Repeat("z", 64)},
+			slices.Repeat([]string{tokenA}, maxBlindTokens+1),
+		} {
+			_, err := s.FindVaults(ctx, &pb.FindVaultsRequest{Tokens: tokens})
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		}
+	})
+
+	t.Run("error: unauthenticated", func(t *testing.T) {
+		_, err := s.FindVaults(context.Background(), &pb.FindVaultsRequest{Tokens: []string{tokenA}})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("error: service failure", func(t *testing.T) {
+		mockService.EXPECT().FindVaults(gomock.Any(), uint64(42), gomock.Any()).Return(nil, errors.New("db error"))
+
+		_, err := s.FindVaults(ctx, &pb.FindVaultsRequest{Tokens: []string{tokenA}})
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestServer_ListVaultVersions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	s := &Server{service: mockService, log: zap.NewNop().Sugar()}
+
+	ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+	t.Run("success", func(t *testing.T) {
+		archived := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+		mockService.EXPECT().
+			ListVaultVersions(gomock.Any(), uint64(42), uint64(1)).
+			Return([]storage.VaultRecordVersion{
+				{VaultID: 1, Version: 2, Type: "note", Title: "v2", EncryptedData: []byte("12345"), ArchivedAt: archived},
+				{VaultID: 1, Version: 1, Type: "note", Title: "v1"},
+			}, nil)
+
+		resp, err := s.ListVaultVersions(ctx, &pb.ListVaultVersionsRequest{VaultId: 1})
+		require.NoError(t, err)
+		require.Len(t, resp.Versions, 2)
+		require.Equal(t, uint64(2), resp.Versions[0].Version)
+		require.Equal(t, "v2", resp.Versions[0].Title)
+		require.Equal(t, int64(5), resp.Versions[0].Size)
+		require.Equal(t, archived.Format(time.RFC3339), resp.Versions[0].ArchivedAt)
+	})
+
+	t.Run("error: vault not found", func(t *testing.T) {
+		mockService.EXPECT().
+			ListVaultVersions(gomock.Any(), uint64(42), uint64(9)).
+			Return(nil, storage.ErrVaultNotFound)
+
+		_, err := s.ListVaultVersions(ctx, &pb.ListVaultVersionsRequest{VaultId: 9})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("error: unauthenticated", func(t *testing.T) {
+		_, err := s.ListVaultVersions(context.Background(), &pb.ListVaultVersionsRequest{VaultId: 1})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestServer_RestoreVaultVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	s := &Server{service: mockService, log: zap.NewNop().Sugar()}
+
+	ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+	t.Run("success", func(t *testing.T) {
+		mockService.EXPECT().
+			RestoreVaultVersion(gomock.Any(), uint64(42), uint64(1), uint64(3)).
+			Return(storage.VaultRecord{ID: 1, UserID: 42, Title: "restored"}, nil)
+
+		resp, err := s.RestoreVaultVersion(ctx, &pb.RestoreVaultVersionRequest{VaultId: 1, Version: 3})
+		require.NoError(t, err)
+		require.Equal(t, "restored", resp.Title)
+	})
+
+	t.Run("error: version not found", func(t *testing.T) {
+		mockService.EXPECT().
+			RestoreVaultVersion(gomock.Any(), uint64(42), uint64(1), uint64(99)).
+			Return(storage.VaultRecord{}, storage.ErrVersionNotFound)
+
+		_, err := s.RestoreVaultVersion(ctx, &pb.RestoreVaultVersionRequest{VaultId: 1, Version: 99})
+		require.Equal(t, codes.NotFound, status.Code(err))
+		require.Contains(t, status.Convert(err).Message(), "версия не найдена")
+	})
+
+	t.Run("error: version without uuid", func(t *testing.T) {
+		mockService.EXPECT().
+			RestoreVaultVersion(gomock.Any(), uint64(42), uint64(1), uint64(2)).
+			Return(storage.VaultRecord{}, service.ErrVersionWithoutUUID)
+
+		_, err := s.RestoreVaultVersion(ctx, &pb.RestoreVaultVersionRequest{VaultId: 1, Version: 2})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestServer_Trash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	s := &Server{service: mockService, log: zap.NewNop().Sugar()}
+
+	ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+	t.Run("list", func(t *testing.T) {
+		deleted := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+		mockService.EXPECT().ListTrash(gomock.Any(), uint64(42)).Return([]storage.VaultRecord{
+			{ID: 3, UserID: 42, Title: "old", DeletedAt: gorm.DeletedAt{Time: deleted, Valid: true}},
+		}, nil)
+
+		resp, err := s.ListTrash(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+		require.Len(t, resp.Vaults, 1)
+		require.Equal(t, deleted.Format(time.RFC3339), resp.Vaults[0].DeletedAt)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		mockService.EXPECT().RestoreVault(gomock.Any(), uint64(42), uint64(3)).Return(nil)
+
+		_, err := s.RestoreVault(ctx, &pb.RestoreVaultRequest{VaultId: 3})
+		require.NoError(t, err)
+	})
+
+	t.Run("restore: not in trash", func(t *testing.T) {
+		mockService.EXPECT().RestoreVault(gomock.Any(), uint64(42), uint64(4)).Return(storage.ErrVaultNotFound)
+
+		_, err := s.RestoreVault(ctx, &pb.RestoreVaultRequest{VaultId: 4})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		mockService.EXPECT().PurgeVault(gomock.Any(), uint64(42), uint64(3)).Return(nil)
+
+		_, err := s.PurgeVault(ctx, &pb.PurgeVaultRequest{VaultId: 3})
+		require.NoError(t, err)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, err := s.ListTrash(context.Background(), &emptypb.Empty{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = s.PurgeVault(context.Background(), &pb.PurgeVaultRequest{VaultId: 3})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestServer_Sync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	s := &Server{service: mockService, log: zap.NewNop().Sugar()}
+
+	ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+	t.Run("success", func(t *testing.T) {
+		mockService.EXPECT().Sync(gomock.Any(), uint64(42), uint64(7), 50).Return(storage.VaultSync{
+			Changed: []storage.VaultRecord{{ID: 3, UserID: 42, Title: "note", Revision: 2}},
+			Deleted: []uint64{4},
+			Cursor:  9,
+			HasMore: true,
+		}, nil)
+
+		resp, err := s.Sync(ctx, &pb.SyncRequest{SinceCursor: 7, Limit: 50})
+		require.NoError(t, err)
+		require.Len(t, resp.Changed, 1)
+		require.Equal(t, uint64(2), resp.Changed[0].Revision)
+		require.Equal(t, []uint64{4}, resp.Deleted)
+		require.Equal(t, uint64(9), resp.Cursor)
+		require.True(t, resp.HasMore)
+		require.False(t, resp.FullResync)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockService.EXPECT().Sync(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(storage.VaultSync{}, errors.New("db down"))
+
+		_, err := s.Sync(ctx, &pb.SyncRequest{})
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, err := s.Sync(context.Background(), &pb.SyncRequest{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+// watchStream is a Watch stream recording the events sent to the client.
+type watchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan *pb.VaultEvent
+}
+
+func (w *watchStream) Context() context.Context {
+	return w.ctx
+}
+
+func (w *watchStream) Send(ev *pb.VaultEvent) error {
+	w.sent <- ev
+	return nil
+}
+
+func TestServer_Watch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	s := &Server{service: mockService, log: zap.NewNop().Sugar()}
+
+	authed := ContextWithSessionID(ContextWithUserID(context.Background(), 42), 7)
+
+	t.Run("sends events of other sessions", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(authed)
+		defer cancel()
+
+		events := make(chan pubsub.Event, 2)
+		events <- pubsub.Event{UserID: 42, VaultID: 3, Revision: 2, Op: pubsub.OpUpdate, Origin: 7}
+		events <- pubsub.Event{UserID: 42, VaultID: 4, Revision: 1, Op: pubsub.OpCreate, Origin: 8}
+
+		unsubscribed := false
+		mockService.EXPECT().Watch(gomock.Any(), uint64(42)).
+			Return((<-chan pubsub.Event)(events), func() { unsubscribed = true })
+
+		stream := &watchStream{ctx: ctx, sent: make(chan *pb.VaultEvent, 2)}
+		done := make(chan error)
+		go func() { done <- s.Watch(&pb.WatchRequest{}, stream) }()
+
+		ev := <-stream.sent
+		require.Equal(t, uint64(4), ev.VaultId)
+		require.Equal(t, uint64(1), ev.Revision)
+		require.Equal(t, pubsub.OpCreate, ev.Op)
+
+		cancel()
+		require.NoError(t, <-done)
+		require.Empty(t, stream.sent, "own changes are not echoed")
+		require.True(t, unsubscribed)
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		s := &Server{service: mockService, log: zap.NewNop().Sugar(), watchCheck: time.Millisecond}
+
+		mockService.EXPECT().Watch(gomock.Any(), uint64(42)).
+			Return(make(<-chan pubsub.Event), func() {})
+		mockService.EXPECT().CheckSession(gomock.Any(), uint64(42), uint64(7)).
+			Return(storage.Session{}, service.ErrSessionRevoked)
+
+		err := s.Watch(&pb.WatchRequest{}, &watchStream{ctx: authed})
+		require.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("server shutdown", func(t *testing.T) {
+		events := make(chan pubsub.Event)
+		close(events)
+		mockService.EXPECT().Watch(gomock.Any(), uint64(42)).
+			Return((<-chan pubsub.Event)(events), func() {})
+
+		err := s.Watch(&pb.WatchRequest{}, &watchStream{ctx: authed})
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		err := s.Watch(&pb.WatchRequest{}, &watchStream{ctx: context.Background()})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+// uploadStream is an UploadBlob stream replaying the parts sent by the client.
+type uploadStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	parts  []*pb.BlobPart
+	status *pb.UploadStatus
+}
+
+func (u *uploadStream) Context() context.Context {
+	return u.ctx
+}
+
+func (u *uploadStream) Recv() (*pb.BlobPart, error) {
+	if len(u.parts) == 0 {
+		return nil, io.EOF
+	}
+	p := u.parts[0]
+	u.parts = u.parts[1:]
+	return p, nil
+}
+
+func (u *uploadStream) SendAndClose(st *pb.UploadStatus) error {
+	u.status = st
+	return nil
+}
+
+// downloadStream is a DownloadBlob stream recording the parts sent to the client.
+type downloadStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	parts []*pb.BlobPart
+}
+
+func (d *downloadStream) Context() context.Context {
+	return d.ctx
+}
+
+func (d *downloadStream) Send(p *pb.BlobPart) error {
+	d.parts = append(d.parts, p)
+	return nil
+}
+
+func blobHeaderPart(h *pb.BlobHeader) *pb.BlobPart {
+	return &pb.BlobPart{Part: &pb.BlobPart_Header{Header: h}}
+}
+
+func blobChunkPart(data string) *pb.BlobPart {
+	return &pb.BlobPart{Part: &pb.BlobPart_Chunk{Chunk: []byte(data)}}
+}
+
+func TestServer_UploadBlob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	s := &Server{service: mockService, log: zap.NewNop().Sugar()}
+
+	ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+	header := &pb.BlobHeader{BlobId: "f00d", Header: []byte("hdr"), Size: 10, Chunks: 3}
+
+	t.Run("resumes after the stored chunks", func(t *testing.T) {
+		stored := storage.Blob{ID: 7, UserID: 42, Ref: "f00d", Chunks: 3, Received: 1}
+		mockService.EXPECT().OpenUpload(gomock.Any(), &storage.Blob{
+			UserID: 42, Ref: "f00d", Header: []byte("hdr"), Size: 10, Chunks: 3,
+		}).Return(stored, nil)
+
+		gomock.InOrder(
+			mockService.EXPECT().PutBlobChunk(gomock.Any(), stored, uint32(1), []byte("b")).Return(nil),
+			mockService.EXPECT().PutBlobChunk(gomock.Any(), gomock.Any(), uint32(2), []byte("c")).Return(nil),
+		)
+
+		stream := &uploadStream{ctx: ctx, parts: []*pb.BlobPart{
+			blobHeaderPart(header), blobChunkPart("a"), blobChunkPart("b"), blobChunkPart("c"),
+		}}
+		require.NoError(t, s.UploadBlob(stream))
+		require.Equal(t, uint32(3), stream.status.Received)
+		require.True(t, stream.status.Complete)
+	})
+
+	t.Run("offset past the stored chunks", func(t *testing.T) {
+		mockService.EXPECT().OpenUpload(gomock.Any(), gomock.Any()).Return(storage.Blob{Received: 1, Chunks: 3}, nil)
+
+		h := &pb.BlobHeader{BlobId: "f00d", Header: []byte("hdr"), Size: 10, Chunks: 3, Offset: 2}
+		err := s.UploadBlob(&uploadStream{ctx: ctx, parts: []*pb.BlobPart{blobHeaderPart(h)}})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("invalid chunk", func(t *testing.T) {
+		mockService.EXPECT().OpenUpload(gomock.Any(), gomock.Any()).Return(storage.Blob{Chunks: 3}, nil)
+		mockService.EXPECT().PutBlobChunk(gomock.Any(), gomock.Any(), uint32(0), gomock.Any()).Return(service.ErrInvalidChunk)
+
+		err := s.UploadBlob(&uploadStream{ctx: ctx, parts: []*pb.BlobPart{blobHeaderPart(header), blobChunkPart("a")}})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("missing header", func(t *testing.T) {
+		err := s.UploadBlob(&uploadStream{ctx: ctx, parts: []*pb.BlobPart{blobChunkPart("a")}})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("another file under the same id", func(t *testing.T) {
+		mockService.EXPECT().OpenUpload(gomock.Any(), gomock.Any()).Return(storage.Blob{}, service.ErrUploadMismatch)
+
+		err := s.UploadBlob(&uploadStream{ctx: ctx, parts: []*pb.BlobPart{blobHeaderPart(header)}})
+		require.Equal(t, codes.AlreadyExists, status.Code(err))
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		err := s.UploadBlob(&uploadStream{ctx: context.Background()})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestServer_GetUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	s := &Server{service: mockService, log: zap.NewNop().Sugar()}
+
+	ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+
+	mockService.EXPECT().Blob(gomock.Any(), uint64(42), "f00d").Return(storage.Blob{Ref: "f00d", Chunks: 3, Received: 2}, nil)
+	st, err := s.GetUpload(ctx, &pb.GetUploadRequest{BlobId: "f00d"})
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), st.Received)
+	require.False(t, st.Complete)
+
+	mockService.EXPECT().Blob(gomock.Any(), uint64(42), "beef").Return(storage.Blob{}, storage.ErrBlobNotFound)
+	_, err = s.GetUpload(ctx, &pb.GetUploadRequest{BlobId: "beef"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_DownloadBlob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	s := &Server{service: mockService, log: zap.NewNop().Sugar()}
+
+	ctx := context.WithValue(context.Background(), userIDKey, uint64(42))
+	blob := storage.Blob{ID: 7, UserID: 42, Ref: "f00d", Header: []byte("hdr"), Size: 10, Chunks: 3, Received: 3}
+
+	t.Run("from offset", func(t *testing.T) {
+		mockService.EXPECT().Blob(gomock.Any(), uint64(42), "f00d").Return(blob, nil)
+		mockService.EXPECT().BlobChunk(gomock.Any(), blob, uint32(1)).Return([]byte("b"), nil)
+		mockService.EXPECT().BlobChunk(gomock.Any(), blob, uint32(2)).Return([]byte("c"), nil)
+
+		stream := &downloadStream{ctx: ctx}
+		require.NoError(t, s.DownloadBlob(&pb.DownloadBlobRequest{BlobId: "f00d", Offset: 1}, stream))
+		require.Len(t, stream.parts, 3)
+		require.Equal(t, uint32(3), stream.parts[0].GetHeader().Chunks)
+		require.Equal(t, uint32(1), stream.parts[0].GetHeader().Offset)
+		require.Equal(t, []byte("c"), stream.parts[2].GetChunk())
+	})
+
+	t.Run("incomplete upload", func(t *testing.T) {
+		partial := blob
+		partial.Received = 1
+		mockService.EXPECT().Blob(gomock.Any(), uint64(42), "f00d").Return(partial, nil)
+
+		err := s.DownloadBlob(&pb.DownloadBlobRequest{BlobId: "f00d"}, &downloadStream{ctx: ctx})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService.EXPECT().Blob(gomock.Any(), uint64(42), "beef").Return(storage.Blob{}, storage.ErrBlobNotFound)
+
+		err := s.DownloadBlob(&pb.DownloadBlobRequest{BlobId: "beef"}, &downloadStream{ctx: ctx})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
@@ -5,13 +5,16 @@ import (
 	"strings"
 	"time"
 
-	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/pubsub"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // ErrUnauthenticated is returned when authentication fails or a token is missing.
-var ErrUnauthenticated = errors.New("unauthenticated")
+// It carries codes.Unauthenticated so that clients know to refresh their token.
+var ErrUnauthenticated = status.Error(codes.Unauthenticated, "unauthenticated")
 
 // contextKey is a custom type used to avoid key collisions in context values.
 type contextKey string
@@ -19,9 +22,15 @@ type contextKey string
 // userIDKey is the context key used to store the authenticated user's ID.
 const userIDKey contextKey = "user_id"
 
+// sessionIDKey is the context key used to store the ID of the authenticated session.
+const sessionIDKey contextKey = "session_id"
+
 // bearerPrefix is the prefix used in the Authorization header for Bearer tokens.
 const bearerPrefix = "Bearer "
 
+// lastSeenInterval limits how often a session's last-seen time is written to the database.
+const lastSeenInterval = time.Minute
+
 // ChainUnaryInterceptors chains multiple gRPC unary interceptors into a single interceptor.
 func ChainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
 	return func(
@@ -80,26 +89,77 @@ func (s *Server) AuthInterceptor(excludedMethods map[string]bool) grpc.UnaryServ
 			return handler(ctx, req)
 		}
 
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, ErrUnauthenticated
-		}
-
-		authHeader := md.Get("authorization")
-		if len(authHeader) == 0 {
-			return nil, ErrUnauthenticated
+		ctx, err := s.authenticate(ctx)
+		if err != nil {
+			return nil, err
 		}
+		return handler(ctx, req)
+	}
+}
 
-		token := strings.TrimPrefix(authHeader[0], bearerPrefix)
-		userID, err := parseJWT(token)
+// AuthStreamInterceptor validates JWT tokens of streaming calls the same way as AuthInterceptor.
+func (s *Server) AuthStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		_ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, err := s.authenticate(ss.Context())
 		if err != nil {
-			return nil, ErrUnauthenticated
+			return err
 		}
+		return handler(srv, &authedStream{ServerStream: ss, ctx: ctx})
+	}
+}
 
-		// передаём user_id дальше
-		ctx = ContextWithUserID(ctx, userID)
-		return handler(ctx, req)
+// authedStream is a server stream carrying the context of the authenticated caller.
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a *authedStream) Context() context.Context {
+	return a.ctx
+}
+
+// authenticate checks the bearer token of the call and returns a context
+// with the IDs of the user and the session.
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
 	}
+
+	authHeader := md.Get("authorization")
+	if len(authHeader) == 0 {
+		return nil, ErrUnauthenticated
+	}
+
+	token := strings.TrimPrefix(authHeader[0], bearerPrefix)
+	claims, err := s.tokens.parseClaims(token)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	// токен жив, пока жива его сессия
+	sess, err := s.service.CheckSession(ctx, claims.UserID, claims.SessionID)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	if time.Since(sess.LastSeenAt) > lastSeenInterval {
+		if err = s.service.TouchSession(ctx, sess.ID, peerIP(ctx)); err != nil {
+			s.log.Warnf("touch session %d: %v", sess.ID, err)
+		}
+	}
+
+	// передаём user_id дальше
+	ctx = ContextWithUserID(ctx, claims.UserID)
+	ctx = ContextWithSessionID(ctx, claims.SessionID)
+	// изменения помечаются сессией, чтобы она не получала уведомления о своих же правках
+	ctx = pubsub.WithOrigin(ctx, claims.SessionID)
+	return ctx, nil
 }
 
 // ContextWithUserID returns a new context with the given user ID.
@@ -115,3 +175,17 @@ func UserIDFromContext(ctx context.Context) (uint64, error) {
 	}
 	return uid, nil
 }
+
+// ContextWithSessionID returns a new context with the given session ID.
+func ContextWithSessionID(ctx context.Context, sid uint64) context.Context {
+	return context.WithValue(ctx, sessionIDKey, sid)
+}
+
+// SessionIDFromContext extracts the session ID from the given context.
+func SessionIDFromContext(ctx context.Context) (uint64, error) {
+	sid, ok := ctx.Value(sessionIDKey).(uint64)
+	if !ok {
+		return 0, ErrUnauthenticated
+	}
+	return sid, nil
+}
@@ -2,13 +2,21 @@ package server
 
 import (
 	"context"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/pubsub"
+	"github.com/wickedv43/go-goph-keeper/internal/service"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
 func TestChainUnaryInterceptors(t *testing.T) {
@@ -119,8 +127,15 @@ func TestServer_LogUnaryInterceptor(t *testing.T) {
 }
 
 func TestAuthInterceptor_WithJWT(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+
 	s := &Server{
-		log: zap.NewNop().Sugar(),
+		log:     zap.NewNop().Sugar(),
+		service: mockService,
+		tokens:  newTestTokenManager(t),
 	}
 
 	t.Run("excluded method bypasses auth", func(t *testing.T) {
@@ -192,10 +207,39 @@ func TestAuthInterceptor_WithJWT(t *testing.T) {
 		require.Nil(t, resp)
 	})
 
+	t.Run("revoked session returns unauthenticated", func(t *testing.T) {
+		token, err := s.tokens.generate(123, 8)
+		require.NoError(t, err)
+
+		mockService.EXPECT().
+			CheckSession(gomock.Any(), uint64(123), uint64(8)).
+			Return(storage.Session{}, service.ErrSessionRevoked)
+
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{
+			"authorization": {"Bearer " + token},
+		})
+
+		interceptor := s.AuthInterceptor(nil)
+
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/gk/Secured",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			t.Fatal("handler should not be called")
+			return nil, nil
+		})
+
+		require.ErrorIs(t, err, ErrUnauthenticated)
+		require.Nil(t, resp)
+	})
+
 	t.Run("valid JWT sets userID in context", func(t *testing.T) {
-		token, err := generateJWT(123)
+		token, err := s.tokens.generate(123, 7)
 		require.NoError(t, err)
 
+		mockService.EXPECT().
+			CheckSession(gomock.Any(), uint64(123), uint64(7)).
+			Return(storage.Session{ID: 7, UserID: 123, LastSeenAt: time.Now()}, nil)
+
 		ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{
 			"authorization": {"Bearer " + token},
 		})
@@ -211,6 +255,9 @@ func TestAuthInterceptor_WithJWT(t *testing.T) {
 			uid, err := UserIDFromContext(ctx)
 			require.NoError(t, err)
 			require.Equal(t, uint64(123), uid)
+			sid, err := SessionIDFromContext(ctx)
+			require.NoError(t, err)
+			require.Equal(t, uint64(7), sid)
 			return "ok", nil
 		})
 
@@ -218,4 +265,90 @@ func TestAuthInterceptor_WithJWT(t *testing.T) {
 		require.Equal(t, "ok", resp)
 		require.True(t, called)
 	})
+
+	t.Run("stale last-seen is refreshed", func(t *testing.T) {
+		token, err := s.tokens.generate(123, 9)
+		require.NoError(t, err)
+
+		mockService.EXPECT().
+			CheckSession(gomock.Any(), uint64(123), uint64(9)).
+			Return(storage.Session{ID: 9, UserID: 123, LastSeenAt: time.Now().Add(-time.Hour)}, nil)
+		mockService.EXPECT().
+			TouchSession(gomock.Any(), uint64(9), "10.0.0.7").
+			Return(nil)
+
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{
+			"authorization": {"Bearer " + token},
+		})
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 5555}})
+
+		interceptor := s.AuthInterceptor(nil)
+
+		_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/gk/Secured",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+		require.NoError(t, err)
+	})
+}
+
+// stubStream is a server stream with a fixed context.
+type stubStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *stubStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestAuthStreamInterceptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+
+	s := &Server{
+		log:     zap.NewNop().Sugar(),
+		service: mockService,
+		tokens:  newTestTokenManager(t),
+	}
+	interceptor := s.AuthStreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/gk/Watch", IsServerStream: true}
+
+	t.Run("missing token returns unauthenticated", func(t *testing.T) {
+		err := interceptor(nil, &stubStream{ctx: context.Background()}, info,
+			func(srv any, stream grpc.ServerStream) error {
+				t.Fatal("handler should not be called")
+				return nil
+			})
+		require.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("valid JWT sets IDs in stream context", func(t *testing.T) {
+		token, err := s.tokens.generate(123, 7)
+		require.NoError(t, err)
+
+		mockService.EXPECT().
+			CheckSession(gomock.Any(), uint64(123), uint64(7)).
+			Return(storage.Session{ID: 7, UserID: 123, LastSeenAt: time.Now()}, nil)
+
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{
+			"authorization": {"Bearer " + token},
+		})
+
+		called := false
+		err = interceptor(nil, &stubStream{ctx: ctx}, info,
+			func(srv any, stream grpc.ServerStream) error {
+				called = true
+				uid, err := UserIDFromContext(stream.Context())
+				require.NoError(t, err)
+				require.Equal(t, uint64(123), uid)
+				require.Equal(t, uint64(7), pubsub.Origin(stream.Context()))
+				return nil
+			})
+		require.NoError(t, err)
+		require.True(t, called)
+	})
 }
@@ -1,39 +1,239 @@
 package server
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
 )
 
-// jwtSecret is the secret key used to sign JWT tokens.
-var jwtSecret = []byte("my-very-secret-key")
+// defaultAccessTTL is used when the config does not set jwt.accessTTL.
+// Access tokens are short-lived, clients renew them with refresh tokens.
+const defaultAccessTTL = 15 * time.Minute
 
-// generateJWT generates a JWT token with the given user ID and 24-hour expiration.
-func generateJWT(userID uint64) (string, error) {
-	claims := jwt.MapClaims{
+// mfaTokenTTL limits how long a password-verified login waits for the second factor.
+const mfaTokenTTL = 5 * time.Minute
+
+// mfaTokenType marks challenge tokens, so they are never accepted as access tokens.
+const mfaTokenType = "mfa"
+
+// accessClaims are the values carried by an access token.
+type accessClaims struct {
+	UserID    uint64
+	SessionID uint64
+}
+
+// jwtKey is a single signing or verification key.
+type jwtKey struct {
+	id     string
+	method jwt.SigningMethod
+	sign   any // []byte or ed25519.PrivateKey, nil for verification-only keys
+	verify any // []byte or ed25519.PublicKey
+}
+
+// tokenManager issues and validates access tokens.
+type tokenManager struct {
+	issuer   string
+	audience string
+	ttl      time.Duration
+
+	signing *jwtKey
+	keys    map[string]*jwtKey
+	methods []string
+}
+
+// newTokenManager builds a token manager from the config.
+// Without configured keys it falls back to a random HS256 key, so tokens do not survive a restart.
+func newTokenManager(cfg config.JWT) (*tokenManager, error) {
+	m := &tokenManager{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		ttl:      cfg.AccessTTL,
+		keys:     make(map[string]*jwtKey),
+	}
+	if m.ttl <= 0 {
+		m.ttl = defaultAccessTTL
+	}
+
+	keys := cfg.Keys
+	signingID := cfg.SigningKey
+	if len(keys) == 0 {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, errors.Wrap(err, "generate jwt secret")
+		}
+		keys = []config.JWTKey{{ID: "ephemeral", Algorithm: jwt.SigningMethodHS256.Alg(), Secret: string(secret)}}
+		signingID = "ephemeral"
+	}
+
+	seen := make(map[string]bool)
+	for _, kc := range keys {
+		k, err := parseJWTKey(kc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "jwt key %q", kc.ID)
+		}
+		if _, ok := m.keys[k.id]; ok {
+			return nil, errors.Errorf("duplicate jwt key %q", k.id)
+		}
+		m.keys[k.id] = k
+
+		if !seen[k.method.Alg()] {
+			seen[k.method.Alg()] = true
+			m.methods = append(m.methods, k.method.Alg())
+		}
+	}
+
+	if signingID == "" && len(keys) == 1 {
+		signingID = keys[0].ID
+	}
+
+	signing, ok := m.keys[signingID]
+	if !ok {
+		return nil, errors.Errorf("signing key %q is not configured", signingID)
+	}
+	if signing.sign == nil {
+		return nil, errors.Errorf("signing key %q has no private part", signingID)
+	}
+	m.signing = signing
+
+	return m, nil
+}
+
+// sampleJWTSecret is the secret the sample config used to ship with. Anyone can
+// sign tokens with it, so a server configured with it refuses to start.
+const sampleJWTSecret = "change-me-to-a-long-random-string"
+
+// parseJWTKey decodes key material according to its algorithm.
+func parseJWTKey(kc config.JWTKey) (*jwtKey, error) {
+	if kc.ID == "" {
+		return nil, errors.New("empty key id")
+	}
+
+	switch kc.Algorithm {
+	case "", jwt.SigningMethodHS256.Alg():
+		switch {
+		case kc.Secret == "":
+			return nil, errors.New("HS256 secret is empty, set it in the config or GK_JWT_SECRET")
+		case kc.Secret == sampleJWTSecret:
+			return nil, errors.New("HS256 secret is the published sample one, set your own")
+		case len(kc.Secret) < 16:
+			return nil, errors.New("HS256 secret must be at least 16 bytes")
+		}
+		secret := []byte(kc.Secret)
+		return &jwtKey{id: kc.ID, method: jwt.SigningMethodHS256, sign: secret, verify: secret}, nil
+
+	case jwt.SigningMethodEdDSA.Alg():
+		k := &jwtKey{id: kc.ID, method: jwt.SigningMethodEdDSA}
+
+		if kc.PrivateKey != "" {
+			raw, err := base64.StdEncoding.DecodeString(kc.PrivateKey)
+			if err != nil {
+				return nil, errors.Wrap(err, "decode private key")
+			}
+
+			var priv ed25519.PrivateKey
+			switch len(raw) {
+			case ed25519.SeedSize:
+				priv = ed25519.NewKeyFromSeed(raw)
+			case ed25519.PrivateKeySize:
+				priv = ed25519.PrivateKey(raw)
+			default:
+				return nil, errors.Errorf("bad Ed25519 private key size %d", len(raw))
+			}
+			k.sign = priv
+			k.verify = priv.Public()
+		}
+
+		if kc.PublicKey != "" {
+			raw, err := base64.StdEncoding.DecodeString(kc.PublicKey)
+			if err != nil {
+				return nil, errors.Wrap(err, "decode public key")
+			}
+			if len(raw) != ed25519.PublicKeySize {
+				return nil, errors.Errorf("bad Ed25519 public key size %d", len(raw))
+			}
+			k.verify = ed25519.PublicKey(raw)
+		}
+
+		if k.verify == nil {
+			return nil, errors.New("EdDSA key needs privateKey or publicKey")
+		}
+		return k, nil
+
+	default:
+		return nil, errors.Errorf("unsupported algorithm %q", kc.Algorithm)
+	}
+}
+
+// generate issues an access token for the user session signed with the current signing key.
+func (m *tokenManager) generate(userID, sessionID uint64) (string, error) {
+	return m.sign(jwt.MapClaims{
+		"user_id": userID,
+		"sid":     sessionID,
+	}, m.ttl)
+}
+
+// generateMFA issues a short-lived challenge token for a user who passed the password check.
+func (m *tokenManager) generateMFA(userID uint64) (string, error) {
+	return m.sign(jwt.MapClaims{
 		"user_id": userID,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
-		"iat":     time.Now().Unix(),
+		"typ":     mfaTokenType,
+	}, mfaTokenTTL)
+}
+
+// sign adds the standard claims and signs the token with the current signing key.
+func (m *tokenManager) sign(claims jwt.MapClaims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims["exp"] = now.Add(ttl).Unix()
+	claims["iat"] = now.Unix()
+	claims["nbf"] = now.Unix()
+	if m.issuer != "" {
+		claims["iss"] = m.issuer
+	}
+	if m.audience != "" {
+		claims["aud"] = m.audience
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	token := jwt.NewWithClaims(m.signing.method, claims)
+	token.Header["kid"] = m.signing.id
+
+	return token.SignedString(m.signing.sign)
 }
 
-// parseJWT validates the token and extracts the user ID from its claims.
-func parseJWT(tokenStr string) (uint64, error) {
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
-	if err != nil || !token.Valid {
-		return 0, errors.New("невалидный токен")
+// parseClaims validates an access token and extracts its claims.
+func (m *tokenManager) parseClaims(tokenStr string) (accessClaims, error) {
+	claims, err := m.verify(tokenStr)
+	if err != nil {
+		return accessClaims{}, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
+	if typ, _ := claims["typ"].(string); typ != "" {
+		return accessClaims{}, errors.New("невалидный токен")
+	}
+
+	uidFloat, ok := claims["user_id"].(float64)
 	if !ok {
-		return 0, errors.New("невалидный payload")
+		return accessClaims{}, errors.New("user_id отсутствует или некорректен")
+	}
+
+	sidFloat, _ := claims["sid"].(float64)
+
+	return accessClaims{UserID: uint64(uidFloat), SessionID: uint64(sidFloat)}, nil
+}
+
+// parseMFA validates a challenge token and returns the user ID it was issued for.
+func (m *tokenManager) parseMFA(tokenStr string) (uint64, error) {
+	claims, err := m.verify(tokenStr)
+	if err != nil {
+		return 0, err
+	}
+
+	if typ, _ := claims["typ"].(string); typ != mfaTokenType {
+		return 0, errors.New("невалидный токен")
 	}
 
 	uidFloat, ok := claims["user_id"].(float64)
@@ -43,3 +243,47 @@ func parseJWT(tokenStr string) (uint64, error) {
 
 	return uint64(uidFloat), nil
 }
+
+// verify checks the signature and the standard claims of the token.
+// The key is looked up by "kid" and the token algorithm must match that key.
+func (m *tokenManager) verify(tokenStr string) (jwt.MapClaims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(m.methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if m.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(m.issuer))
+	}
+	if m.audience != "" {
+		opts = append(opts, jwt.WithAudience(m.audience))
+	}
+
+	token, err := jwt.Parse(tokenStr, m.keyFunc, opts...)
+	if err != nil || !token.Valid {
+		return nil, errors.New("невалидный токен")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("невалидный payload")
+	}
+
+	return claims, nil
+}
+
+// keyFunc resolves the verification key for a token.
+func (m *tokenManager) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, errors.Errorf("unknown key id %q", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+
+	return key.verify, nil
+}
@@ -1,110 +1,358 @@
 package server
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
 )
 
+// testJWTSecret signs tokens of newTestTokenManager.
+const testJWTSecret = "test-secret-test-secret"
+
+// newTestTokenManager returns a manager with a single HS256 key "test".
+func newTestTokenManager(t *testing.T) *tokenManager {
+	t.Helper()
+
+	m, err := newTokenManager(config.JWT{
+		Keys: []config.JWTKey{{ID: "test", Algorithm: "HS256", Secret: testJWTSecret}},
+	})
+	require.NoError(t, err)
+
+	return m
+}
+
+// signTestToken signs arbitrary claims with the "test" key.
+func signTestToken(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = "test"
+
+	tokenStr, err := token.SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+
+	return tokenStr
+}
+
 func TestGenerateJWT(t *testing.T) {
 	t.Run("generates valid JWT with user_id", func(t *testing.T) {
-		tokenStr, err := generateJWT(123)
+		m := newTestTokenManager(t)
+
+		tokenStr, err := m.generate(123, 1)
 		require.NoError(t, err)
 		require.NotEmpty(t, tokenStr)
 
-		uid, err := parseJWT(tokenStr)
+		claims, err := m.parseClaims(tokenStr)
 		require.NoError(t, err)
-		require.Equal(t, uint64(123), uid)
+		require.Equal(t, uint64(123), claims.UserID)
+	})
+
+	t.Run("carries session id", func(t *testing.T) {
+		m := newTestTokenManager(t)
+
+		tokenStr, err := m.generate(123, 456)
+		require.NoError(t, err)
+
+		claims, err := m.parseClaims(tokenStr)
+		require.NoError(t, err)
+		require.Equal(t, accessClaims{UserID: 123, SessionID: 456}, claims)
+	})
+
+	t.Run("sets kid, issuer, audience and ttl", func(t *testing.T) {
+		m, err := newTokenManager(config.JWT{
+			Issuer:    "gk",
+			Audience:  "cli",
+			AccessTTL: 5 * time.Minute,
+			Keys:      []config.JWTKey{{ID: "k1", Algorithm: "HS256", Secret: testJWTSecret}},
+		})
+		require.NoError(t, err)
+
+		tokenStr, err := m.generate(1, 1)
+		require.NoError(t, err)
+
+		token, _, err := jwt.NewParser().ParseUnverified(tokenStr, jwt.MapClaims{})
+		require.NoError(t, err)
+		require.Equal(t, "k1", token.Header["kid"])
+
+		claims := token.Claims.(jwt.MapClaims)
+		require.Equal(t, "gk", claims["iss"])
+		require.Equal(t, "cli", claims["aud"])
+
+		exp, err := claims.GetExpirationTime()
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().Add(5*time.Minute), exp.Time, 5*time.Second)
+	})
+
+	t.Run("ephemeral key when nothing is configured", func(t *testing.T) {
+		m, err := newTokenManager(config.JWT{})
+		require.NoError(t, err)
+
+		tokenStr, err := m.generate(9, 1)
+		require.NoError(t, err)
+
+		claims, err := m.parseClaims(tokenStr)
+		require.NoError(t, err)
+		require.Equal(t, uint64(9), claims.UserID)
+	})
+}
+
+func TestNewTokenManager_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.JWT
+		err  string
+	}{
+		{
+			name: "unknown signing key",
+			cfg:  config.JWT{SigningKey: "nope", Keys: []config.JWTKey{{ID: "a", Secret: testJWTSecret}}},
+			err:  "signing key",
+		},
+		{
+			name: "short secret",
+			cfg:  config.JWT{Keys: []config.JWTKey{{ID: "a", Secret: "short"}}},
+			err:  "at least 16 bytes",
+		},
+		{
+			name: "empty secret",
+			cfg:  config.JWT{Keys: []config.JWTKey{{ID: "a"}}},
+			err:  "secret is empty",
+		},
+		{
+			name: "sample secret",
+			cfg:  config.JWT{Keys: []config.JWTKey{{ID: "a", Secret: "change-me-to-a-long-random-string"}}},
+			err:  "sample",
+		},
+		{
+			name: "duplicate kid",
+			cfg: config.JWT{SigningKey: "a", Keys: []config.JWTKey{
+				{ID: "a", Secret: testJWTSecret},
+				{ID: "a", Secret: testJWTSecret},
+			}},
+			err: "duplicate",
+		},
+		{
+			name: "unsupported algorithm",
+			cfg:  config.JWT{Keys: []config.JWTKey{{ID: "a", Algorithm: "RS256"}}},
+			err:  "unsupported algorithm",
+		},
+		{
+			name: "verification-only signing key",
+			cfg: config.JWT{Keys: []config.JWTKey{{
+				ID: "a", Algorithm: "EdDSA", PublicKey: base64.StdEncoding.EncodeToString(make([]byte, ed25519.PublicKeySize)),
+			}}},
+			err: "no private part",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newTokenManager(tc.cfg)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestTokenManager_Rotation(t *testing.T) {
+	oldKey := config.JWTKey{ID: "old", Algorithm: "HS256", Secret: "old-secret-old-secret"}
+	newKey := config.JWTKey{ID: "new", Algorithm: "HS256", Secret: "new-secret-new-secret"}
+
+	before, err := newTokenManager(config.JWT{SigningKey: "old", Keys: []config.JWTKey{oldKey}})
+	require.NoError(t, err)
+	oldToken, err := before.generate(1, 1)
+	require.NoError(t, err)
+
+	during, err := newTokenManager(config.JWT{SigningKey: "new", Keys: []config.JWTKey{newKey, oldKey}})
+	require.NoError(t, err)
+
+	claims, err := during.parseClaims(oldToken)
+	require.NoError(t, err, "tokens of the previous key stay valid during rotation")
+	require.Equal(t, uint64(1), claims.UserID)
+
+	newToken, err := during.generate(2, 1)
+	require.NoError(t, err)
+
+	after, err := newTokenManager(config.JWT{SigningKey: "new", Keys: []config.JWTKey{newKey}})
+	require.NoError(t, err)
+
+	_, err = after.parseClaims(oldToken)
+	require.Error(t, err, "retired key is no longer accepted")
+
+	claims, err = after.parseClaims(newToken)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), claims.UserID)
+}
+
+func TestTokenManager_EdDSA(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := newTokenManager(config.JWT{Keys: []config.JWTKey{{
+		ID:         "ed",
+		Algorithm:  "EdDSA",
+		PrivateKey: base64.StdEncoding.EncodeToString(priv.Seed()),
+	}}})
+	require.NoError(t, err)
+
+	tokenStr, err := signer.generate(77, 1)
+	require.NoError(t, err)
+
+	// A verifier only needs the public key.
+	verifier, err := newTokenManager(config.JWT{
+		SigningKey: "hs",
+		Keys: []config.JWTKey{
+			{ID: "hs", Algorithm: "HS256", Secret: testJWTSecret},
+			{ID: "ed", Algorithm: "EdDSA", PublicKey: base64.StdEncoding.EncodeToString(pub)},
+		},
 	})
+	require.NoError(t, err)
+
+	claims, err := verifier.parseClaims(tokenStr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(77), claims.UserID)
 }
 
 func TestParseJWT(t *testing.T) {
+	m := newTestTokenManager(t)
+
 	t.Run("returns user_id from valid token", func(t *testing.T) {
-		tokenStr, _ := generateJWT(555)
+		tokenStr, _ := m.generate(555, 1)
 
-		uid, err := parseJWT(tokenStr)
+		claims, err := m.parseClaims(tokenStr)
 		require.NoError(t, err)
-		require.Equal(t, uint64(555), uid)
+		require.Equal(t, uint64(555), claims.UserID)
 	})
 
 	t.Run("fails on tampered token", func(t *testing.T) {
-		tokenStr, _ := generateJWT(42)
+		tokenStr, _ := m.generate(42, 1)
 
 		// Подделываем подпись (например, меняем символ)
 		tampered := tokenStr[:len(tokenStr)-1] + "x"
 
-		uid, err := parseJWT(tampered)
+		claims, err := m.parseClaims(tampered)
 		require.Error(t, err)
-		require.Equal(t, uint64(0), uid)
+		require.Equal(t, uint64(0), claims.UserID)
 		require.Contains(t, err.Error(), "невалидный токен")
 	})
 
 	t.Run("fails on invalid structure", func(t *testing.T) {
-		uid, err := parseJWT("not-a-token")
+		claims, err := m.parseClaims("not-a-token")
 		require.Error(t, err)
-		require.Equal(t, uint64(0), uid)
+		require.Equal(t, uint64(0), claims.UserID)
 		require.Contains(t, err.Error(), "невалидный токен")
 	})
 
+	t.Run("fails on unknown kid", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": 1,
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "other"
+		tokenStr, _ := token.SignedString([]byte(testJWTSecret))
+
+		_, err := m.parseClaims(tokenStr)
+		require.ErrorContains(t, err, "невалидный токен")
+	})
+
+	t.Run("fails on unexpected algorithm", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.MapClaims{
+			"user_id": 1,
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "test"
+		tokenStr, _ := token.SignedString([]byte(testJWTSecret))
+
+		_, err := m.parseClaims(tokenStr)
+		require.ErrorContains(t, err, "невалидный токен")
+	})
+
+	t.Run("fails on alg none", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+			"user_id": 1,
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "test"
+		tokenStr, _ := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+		_, err := m.parseClaims(tokenStr)
+		require.ErrorContains(t, err, "невалидный токен")
+	})
+
+	t.Run("fails on wrong issuer", func(t *testing.T) {
+		strict, err := newTokenManager(config.JWT{
+			Issuer: "gk",
+			Keys:   []config.JWTKey{{ID: "test", Algorithm: "HS256", Secret: testJWTSecret}},
+		})
+		require.NoError(t, err)
+
+		tokenStr := signTestToken(t, jwt.MapClaims{
+			"user_id": 1,
+			"iss":     "someone-else",
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		})
+
+		_, err = strict.parseClaims(tokenStr)
+		require.ErrorContains(t, err, "невалидный токен")
+	})
+
 	t.Run("fails when claims are not map", func(t *testing.T) {
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		tokenStr := signTestToken(t, jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
 		})
 
-		tokenStr, _ := token.SignedString(jwtSecret)
-
-		uid, err := parseJWT(tokenStr)
+		claims, err := m.parseClaims(tokenStr)
 		require.Error(t, err)
-		require.Equal(t, uint64(0), uid)
+		require.Equal(t, uint64(0), claims.UserID)
 		require.Contains(t, err.Error(), "user_id отсутствует")
 	})
 
 	t.Run("fails when user_id missing", func(t *testing.T) {
-		claims := jwt.MapClaims{
+		tokenStr := signTestToken(t, jwt.MapClaims{
 			"exp": time.Now().Add(time.Hour).Unix(),
 			"iat": time.Now().Unix(),
-		}
-
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenStr, _ := token.SignedString(jwtSecret)
+		})
 
-		uid, err := parseJWT(tokenStr)
+		claims, err := m.parseClaims(tokenStr)
 		require.Error(t, err)
-		require.Equal(t, uint64(0), uid)
+		require.Equal(t, uint64(0), claims.UserID)
 		require.Contains(t, err.Error(), "user_id отсутствует")
 	})
 
 	t.Run("fails when user_id wrong type", func(t *testing.T) {
-		claims := jwt.MapClaims{
+		tokenStr := signTestToken(t, jwt.MapClaims{
 			"user_id": "not-a-number",
 			"exp":     time.Now().Add(time.Hour).Unix(),
 			"iat":     time.Now().Unix(),
-		}
-
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenStr, _ := token.SignedString(jwtSecret)
+		})
 
-		uid, err := parseJWT(tokenStr)
+		claims, err := m.parseClaims(tokenStr)
 		require.Error(t, err)
-		require.Equal(t, uint64(0), uid)
+		require.Equal(t, uint64(0), claims.UserID)
 		require.Contains(t, err.Error(), "user_id отсутствует")
 	})
 
 	t.Run("fails on expired token", func(t *testing.T) {
-		claims := jwt.MapClaims{
+		tokenStr := signTestToken(t, jwt.MapClaims{
 			"user_id": float64(777),
 			"exp":     time.Now().Add(-time.Minute).Unix(), // уже истёк
 			"iat":     time.Now().Add(-2 * time.Minute).Unix(),
-		}
-
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenStr, _ := token.SignedString(jwtSecret)
+		})
 
-		uid, err := parseJWT(tokenStr)
+		claims, err := m.parseClaims(tokenStr)
 		require.Error(t, err)
-		require.Equal(t, uint64(0), uid)
+		require.Equal(t, uint64(0), claims.UserID)
 		require.Contains(t, err.Error(), "невалидный токен")
 	})
+
+	t.Run("fails without exp", func(t *testing.T) {
+		tokenStr := signTestToken(t, jwt.MapClaims{"user_id": float64(1)})
+
+		_, err := m.parseClaims(tokenStr)
+		require.ErrorContains(t, err, "невалидный токен")
+	})
 }
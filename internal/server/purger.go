@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/samber/do/v2"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+	"github.com/wickedv43/go-goph-keeper/internal/logger"
+	"github.com/wickedv43/go-goph-keeper/internal/service"
+	"go.uber.org/zap"
+)
+
+// Trash defaults used when trash.retention or trash.purgeInterval are not set.
+const (
+	defaultTrashRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval  = time.Hour
+)
+
+// TrashPurger permanently removes records that stayed in the trash longer than the retention.
+type TrashPurger struct {
+	service   service.GophKeeper
+	retention time.Duration
+	interval  time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	now func() time.Time
+	log *zap.SugaredLogger
+}
+
+// NewTrashPurger creates a purger configured by the trash section.
+func NewTrashPurger(i do.Injector) (*TrashPurger, error) {
+	cfg := do.MustInvoke[*config.Config](i)
+
+	p := &TrashPurger{
+		service:   do.MustInvoke[service.GophKeeper](i),
+		retention: cfg.Trash.Retention,
+		interval:  cfg.Trash.PurgeInterval,
+		now:       time.Now,
+		log:       do.MustInvoke[*logger.Logger](i).Named("purger"),
+	}
+	if p.retention <= 0 {
+		p.retention = defaultTrashRetention
+	}
+	if p.interval <= 0 {
+		p.interval = defaultPurgeInterval
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+
+	return p, nil
+}
+
+// Start purges the trash right away and then every interval until Shutdown.
+func (p *TrashPurger) Start() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(p.ctx)
+
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown stops the purger, interrupting a purge in progress.
+func (p *TrashPurger) Shutdown() {
+	p.cancel()
+	p.log.Debug("trash purger stopped")
+}
+
+// purge removes records trashed before the retention period.
+func (p *TrashPurger) purge(ctx context.Context) {
+	n, err := p.service.PurgeTrash(ctx, p.now().Add(-p.retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			p.log.Errorf("purge trash: %v", err)
+		}
+		return
+	}
+
+	if n > 0 {
+		p.log.Infof("purged %d records from trash", n)
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/do/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+	"github.com/wickedv43/go-goph-keeper/internal/logger"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/service"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestNewTrashPurger(t *testing.T) {
+	newPurger := func(cfg config.Trash) *TrashPurger {
+		i := do.New()
+		do.ProvideValue(i, &config.Config{Trash: cfg})
+		do.ProvideValue[service.GophKeeper](i, &mocks.MockGophKeeper{})
+		do.ProvideValue(i, &logger.Logger{SugaredLogger: zap.NewNop().Sugar()})
+
+		p, err := NewTrashPurger(i)
+		require.NoError(t, err)
+		return p
+	}
+
+	p := newPurger(config.Trash{})
+	require.Equal(t, defaultTrashRetention, p.retention)
+	require.Equal(t, defaultPurgeInterval, p.interval)
+
+	p = newPurger(config.Trash{Retention: time.Hour, PurgeInterval: time.Minute})
+	require.Equal(t, time.Hour, p.retention)
+	require.Equal(t, time.Minute, p.interval)
+}
+
+func TestTrashPurger_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &TrashPurger{
+		service:   mockService,
+		retention: 24 * time.Hour,
+		interval:  10 * time.Millisecond,
+		ctx:       ctx,
+		cancel:    cancel,
+		now:       func() time.Time { return now },
+		log:       zap.NewNop().Sugar(),
+	}
+
+	purged := make(chan struct{}, 10)
+	mockService.EXPECT().
+		PurgeTrash(gomock.Any(), now.Add(-24*time.Hour)).
+		DoAndReturn(func(context.Context, time.Time) (int64, error) {
+			purged <- struct{}{}
+			return 1, nil
+		}).
+		MinTimes(2)
+
+	done := make(chan struct{})
+	go func() {
+		p.Start()
+		close(done)
+	}()
+
+	// once on start and again on the next tick
+	<-purged
+	<-purged
+
+	p.Shutdown()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("purger did not stop")
+	}
+}
+
+func TestTrashPurger_PurgeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockGophKeeper(ctrl)
+	p := &TrashPurger{
+		service:   mockService,
+		retention: time.Hour,
+		now:       time.Now,
+		log:       zap.NewNop().Sugar(),
+	}
+
+	mockService.EXPECT().PurgeTrash(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db down"))
+
+	// errors are logged, the purger keeps running
+	p.purge(context.Background())
+}
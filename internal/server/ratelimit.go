@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/do/v2"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+	"github.com/wickedv43/go-goph-keeper/internal/ratelimit"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrTooManyAttempts is returned while a peer or a login is throttled or locked out.
+// The message is the same in every case, so it tells nothing about the login.
+var ErrTooManyAttempts = status.Error(codes.ResourceExhausted, "слишком много попыток входа, попробуйте позже")
+
+// limitRules holds the rules applied to peers and to logins.
+type limitRules struct {
+	ip    ratelimit.Rule
+	login ratelimit.Rule
+}
+
+// newLimitRules fills the rules from the config, using defaults for unset values.
+func newLimitRules(cfg config.RateLimit) limitRules {
+	def := func(v, d time.Duration) time.Duration {
+		if v > 0 {
+			return v
+		}
+		return d
+	}
+	defInt := func(v, d int) int {
+		if v > 0 {
+			return v
+		}
+		return d
+	}
+
+	maxFailures := defInt(cfg.MaxFailures, 5)
+	lockoutBase := def(cfg.LockoutBase, time.Minute)
+	lockoutMax := def(cfg.LockoutMax, time.Hour)
+
+	return limitRules{
+		ip: ratelimit.Rule{
+			Burst:       defInt(cfg.IPBurst, 20),
+			Every:       def(cfg.IPEvery, 3*time.Second),
+			MaxFailures: maxFailures * 4, // one address may serve several users
+			LockoutBase: lockoutBase,
+			LockoutMax:  lockoutMax,
+		},
+		login: ratelimit.Rule{
+			Burst:       defInt(cfg.LoginBurst, 5),
+			Every:       def(cfg.LoginEvery, time.Minute),
+			MaxFailures: maxFailures,
+			LockoutBase: lockoutBase,
+			LockoutMax:  lockoutMax,
+		},
+	}
+}
+
+// newLimiter returns the limiter selected by rateLimit.backend.
+func newLimiter(i do.Injector, cfg config.RateLimit) (ratelimit.Limiter, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return ratelimit.NewMemory(), nil
+	case "postgres":
+		return ratelimit.NewPostgres(do.MustInvoke[storage.DataKeeper](i)), nil
+	default:
+		return nil, errors.Errorf("unknown rate limit backend %q", cfg.Backend)
+	}
+}
+
+// limitKey is a rate-limited key with its rule.
+type limitKey struct {
+	key  string
+	rule ratelimit.Rule
+}
+
+// RateLimitInterceptor limits calls of the given methods per peer IP and per login.
+// Calls that fail with Unauthenticated count as failures and lead to lockouts;
+// a successful call clears the failures of the login.
+func (s *Server) RateLimitInterceptor(methods map[string]bool) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if !methods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		keys := s.limitKeys(ctx, req)
+		for _, k := range keys {
+			if err := s.limiter.Allow(ctx, k.key, k.rule); err != nil {
+				var limited *ratelimit.LimitedError
+				if errors.As(err, &limited) {
+					s.log.Warnf("rate limited %s on %s, retry after %s", k.key, info.FullMethod, limited.RetryAfter)
+					return nil, ErrTooManyAttempts
+				}
+				s.log.Errorf("rate limiter: %v", err)
+				return nil, status.Error(codes.Unavailable, "сервис временно недоступен")
+			}
+		}
+
+		resp, err := handler(ctx, req)
+
+		switch status.Code(err) {
+		case codes.OK:
+			// успешный вход не сбрасывает IP, иначе перебор чужих логинов
+			// можно прикрыть входами в свой аккаунт
+			for _, k := range keys[1:] {
+				if rerr := s.limiter.Reset(ctx, k.key); rerr != nil {
+					s.log.Errorf("rate limiter reset: %v", rerr)
+				}
+			}
+		case codes.Unauthenticated:
+			for _, k := range keys {
+				if ferr := s.limiter.Fail(ctx, k.key, k.rule); ferr != nil {
+					s.log.Errorf("rate limiter fail: %v", ferr)
+				}
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// limitKeys returns the peer key first, followed by the account key if the request names one.
+func (s *Server) limitKeys(ctx context.Context, req interface{}) []limitKey {
+	keys := []limitKey{{key: "ip:" + peerIP(ctx), rule: s.limits.ip}}
+
+	switch r := req.(type) {
+	case *pb.LoginRequest:
+		if login := strings.ToLower(strings.TrimSpace(r.Login)); login != "" {
+			keys = append(keys, limitKey{key: "login:" + login, rule: s.limits.login})
+		}
+	case *pb.VerifyMFARequest:
+		// коды 2FA перебираются по пользователю, а не по токену входа
+		if uid, err := s.tokens.parseMFA(r.MfaToken); err == nil {
+			keys = append(keys, limitKey{key: "mfa:" + strconv.FormatUint(uid, 10), rule: s.limits.login})
+		}
+	}
+
+	return keys
+}
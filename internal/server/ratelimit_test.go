@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+	"github.com/wickedv43/go-goph-keeper/internal/ratelimit"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_RateLimitInterceptor(t *testing.T) {
+	const loginMethod = "/api.GophKeeper/Login"
+
+	newServer := func() *Server {
+		return &Server{
+			log:     zap.NewNop().Sugar(),
+			tokens:  newTestTokenManager(t),
+			limiter: ratelimit.NewMemory(),
+			limits: limitRules{
+				ip:    ratelimit.Rule{Burst: 100, Every: time.Second, MaxFailures: 100, LockoutBase: time.Minute, LockoutMax: time.Hour},
+				login: ratelimit.Rule{Burst: 3, Every: time.Minute, MaxFailures: 2, LockoutBase: time.Minute, LockoutMax: time.Hour},
+			},
+		}
+	}
+
+	ctxFrom := func(ip string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 5555}})
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: loginMethod}
+	okHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.LoginResponse{}, nil
+	}
+	badHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, ErrInvalidCredentials
+	}
+
+	t.Run("login burst", func(t *testing.T) {
+		s := newServer()
+		interceptor := s.RateLimitInterceptor(map[string]bool{loginMethod: true})
+		req := &pb.LoginRequest{Login: "alice"}
+
+		for range 3 {
+			_, err := interceptor(ctxFrom("10.0.0.1"), req, info, okHandler)
+			require.NoError(t, err)
+		}
+
+		_, err := interceptor(ctxFrom("10.0.0.2"), &pb.LoginRequest{Login: " Alice "}, info, okHandler)
+		require.ErrorIs(t, err, ErrTooManyAttempts, "login key does not depend on the address or case")
+
+		_, err = interceptor(ctxFrom("10.0.0.1"), &pb.LoginRequest{Login: "bob"}, info, okHandler)
+		require.NoError(t, err)
+	})
+
+	t.Run("lockout after failures", func(t *testing.T) {
+		s := newServer()
+		interceptor := s.RateLimitInterceptor(map[string]bool{loginMethod: true})
+		req := &pb.LoginRequest{Login: "alice"}
+
+		for range 2 {
+			_, err := interceptor(ctxFrom("10.0.0.1"), req, info, badHandler)
+			require.ErrorIs(t, err, ErrInvalidCredentials)
+		}
+
+		handlerCalled := false
+		_, err := interceptor(ctxFrom("10.0.0.1"), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			handlerCalled = true
+			return &pb.LoginResponse{}, nil
+		})
+		require.ErrorIs(t, err, ErrTooManyAttempts)
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.False(t, handlerCalled, "locked out logins are not checked")
+	})
+
+	t.Run("success resets failures", func(t *testing.T) {
+		s := newServer()
+		interceptor := s.RateLimitInterceptor(map[string]bool{loginMethod: true})
+		req := &pb.LoginRequest{Login: "alice"}
+
+		_, err := interceptor(ctxFrom("10.0.0.1"), req, info, badHandler)
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+		_, err = interceptor(ctxFrom("10.0.0.1"), req, info, okHandler)
+		require.NoError(t, err)
+		_, err = interceptor(ctxFrom("10.0.0.1"), req, info, badHandler)
+		require.ErrorIs(t, err, ErrInvalidCredentials, "one failure after reset does not lock out")
+	})
+
+	t.Run("ip burst", func(t *testing.T) {
+		s := newServer()
+		s.limits.ip.Burst = 2
+		interceptor := s.RateLimitInterceptor(map[string]bool{loginMethod: true})
+
+		for _, login := range []string{"a", "b"} {
+			_, err := interceptor(ctxFrom("10.0.0.1"), &pb.LoginRequest{Login: login}, info, okHandler)
+			require.NoError(t, err)
+		}
+		_, err := interceptor(ctxFrom("10.0.0.1"), &pb.LoginRequest{Login: "c"}, info, okHandler)
+		require.ErrorIs(t, err, ErrTooManyAttempts)
+	})
+
+	t.Run("not limited method", func(t *testing.T) {
+		s := newServer()
+		s.limits.ip.Burst = 0
+		interceptor := s.RateLimitInterceptor(map[string]bool{loginMethod: true})
+
+		_, err := interceptor(ctxFrom("10.0.0.1"), &pb.GetVaultRequest{}, &grpc.UnaryServerInfo{FullMethod: "/api.GophKeeper/GetVault"}, okHandler)
+		require.NoError(t, err)
+	})
+}
+
+func TestNewLimitRules(t *testing.T) {
+	rules := newLimitRules(config.RateLimit{LoginBurst: 10, MaxFailures: 3})
+
+	require.Equal(t, 10, rules.login.Burst)
+	require.Equal(t, time.Minute, rules.login.Every)
+	require.Equal(t, 3, rules.login.MaxFailures)
+	require.Equal(t, 12, rules.ip.MaxFailures)
+	require.Equal(t, time.Hour, rules.ip.LockoutMax)
+}
+
+func TestNewLimiter(t *testing.T) {
+	l, err := newLimiter(nil, config.RateLimit{})
+	require.NoError(t, err)
+	require.IsType(t, &ratelimit.Memory{}, l)
+
+	_, err = newLimiter(nil, config.RateLimit{Backend: "redis"})
+	require.Error(t, err)
+}
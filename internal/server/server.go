@@ -3,14 +3,19 @@ package server
 
 import (
 	"net"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/samber/do/v2"
 	pb "github.com/wickedv43/go-goph-keeper/internal/api"
 	"github.com/wickedv43/go-goph-keeper/internal/config"
 	"github.com/wickedv43/go-goph-keeper/internal/logger"
+	"github.com/wickedv43/go-goph-keeper/internal/ratelimit"
 	"github.com/wickedv43/go-goph-keeper/internal/service"
+	"github.com/wickedv43/go-goph-keeper/internal/tlsutil"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Server represents a gRPC server for the GophKeeper service.
@@ -23,6 +28,16 @@ type Server struct {
 	// service provides the business logic layer.
 	service service.GophKeeper
 
+	// tokens issues and validates access tokens.
+	tokens *tokenManager
+
+	// limiter throttles login attempts according to limits.
+	limiter ratelimit.Limiter
+	limits  limitRules
+
+	// watchCheck overrides watchCheckInterval.
+	watchCheck time.Duration
+
 	cfg *config.Config
 	log *zap.SugaredLogger
 }
@@ -35,19 +50,49 @@ func NewServer(i do.Injector) (*Server, error) {
 		log:     do.MustInvoke[*logger.Logger](i).Named("server"),
 	}
 
+	tokens, err := newTokenManager(s.cfg.JWT)
+	if err != nil {
+		return nil, errors.Wrap(err, "init jwt")
+	}
+	if len(s.cfg.JWT.Keys) == 0 {
+		s.log.Warn("jwt keys are not configured, tokens are signed with an ephemeral key")
+	}
+	s.tokens = tokens
+
+	s.limiter, err = newLimiter(i, s.cfg.RateLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "init rate limiter")
+	}
+	s.limits = newLimitRules(s.cfg.RateLimit)
+
+	limited := map[string]bool{
+		"/api.GophKeeper/Register":  true,
+		"/api.GophKeeper/Login":     true,
+		"/api.GophKeeper/VerifyMFA": true,
+	}
+
 	excluded := map[string]bool{
-		"/api.GophKeeper/Register": true,
-		"/api.GophKeeper/Login":    true,
+		"/api.GophKeeper/Register":     true,
+		"/api.GophKeeper/Login":        true,
+		"/api.GophKeeper/RefreshToken": true,
+		"/api.GophKeeper/VerifyMFA":    true,
+	}
+
+	opts, err := s.transportOptions()
+	if err != nil {
+		return nil, errors.Wrap(err, "init tls")
 	}
 
-	grpcServer := grpc.NewServer(
+	grpcServer := grpc.NewServer(append(opts,
 		grpc.UnaryInterceptor(
 			ChainUnaryInterceptors(
 				s.LogUnaryInterceptor(),
+				s.RateLimitInterceptor(limited),
 				s.AuthInterceptor(excluded),
 			),
 		),
-	)
+		grpc.StreamInterceptor(s.AuthStreamInterceptor()),
+	)...)
 
 	s.GRPC = grpcServer
 
@@ -56,6 +101,21 @@ func NewServer(i do.Injector) (*Server, error) {
 	return s, nil
 }
 
+// transportOptions returns the transport credentials configured in server.tls.
+func (s *Server) transportOptions() ([]grpc.ServerOption, error) {
+	if !s.cfg.Server.TLS.Enabled {
+		s.log.Warn("tls is disabled, connections are not encrypted")
+		return nil, nil
+	}
+
+	tlsCfg, err := tlsutil.ServerConfig(s.cfg.Server.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsCfg))}, nil
+}
+
 // Start launches the gRPC server and begins listening for client connections.
 func (s *Server) Start() {
 	s.log.Debugf("grpc server port: " + s.cfg.Server.Port)
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/wickedv43/go-goph-keeper/internal/logger"
 	"github.com/wickedv43/go-goph-keeper/internal/mocks"
 	"github.com/wickedv43/go-goph-keeper/internal/service"
+	"github.com/wickedv43/go-goph-keeper/internal/tlsutil"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -87,3 +89,37 @@ func TestServer_StartAndShutdown(t *testing.T) {
 	_, err := grpc.Dial("localhost:"+cfg.Server.Port, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
 	assert.Error(t, err, "ожидалась ошибка подключения после Shutdown()")
 }
+
+func TestNewServer_TLS(t *testing.T) {
+	newInjector := func(cfg *config.Config) do.Injector {
+		i := do.New()
+		do.ProvideValue(i, cfg)
+		do.ProvideValue[service.GophKeeper](i, &mocks.MockGophKeeper{})
+		do.ProvideValue[*logger.Logger](i, &logger.Logger{SugaredLogger: zap.NewNop().Sugar()})
+		return i
+	}
+
+	t.Run("valid certificates", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, tlsutil.GenerateDevCerts(dir, []string{"localhost"}))
+
+		cfg := &config.Config{Server: config.Server{Port: "9090", TLS: config.TLS{
+			Enabled:    true,
+			CertFile:   filepath.Join(dir, tlsutil.ServerFile),
+			KeyFile:    filepath.Join(dir, tlsutil.ServerKeyFile),
+			CAFile:     filepath.Join(dir, tlsutil.CAFile),
+			ClientAuth: true,
+		}}}
+
+		srv, err := NewServer(newInjector(cfg))
+		require.NoError(t, err)
+		require.NotNil(t, srv.GRPC)
+	})
+
+	t.Run("missing certificate", func(t *testing.T) {
+		cfg := &config.Config{Server: config.Server{Port: "9090", TLS: config.TLS{Enabled: true}}}
+
+		_, err := NewServer(newInjector(cfg))
+		require.Error(t, err)
+	})
+}
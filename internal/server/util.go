@@ -1,22 +1,295 @@
 package server
 
 import (
+	"context"
+	"encoding/hex"
+	"net"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	pb "github.com/wickedv43/go-goph-keeper/internal/api"
+	"github.com/wickedv43/go-goph-keeper/internal/service"
 	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 // mapVaultToProto converts a VaultRecord from the storage layer to its protobuf representation.
 func mapVaultToProto(v *storage.VaultRecord) *pb.VaultRecord {
-	return &pb.VaultRecord{
+	pv := &pb.VaultRecord{
 		Id:            v.ID,
 		UserId:        v.UserID,
 		Type:          string(v.Type),
 		Title:         v.Title,
 		Metadata:      v.Metadata,
 		EncryptedData: v.EncryptedData,
+		BlobRef:       v.BlobRef,
+		Uuid:          v.UUID,
+		SealedMeta:    v.SealedMeta,
 		CreatedAt:     v.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:     v.UpdatedAt.Format(time.RFC3339),
+		Revision:      v.Revision,
 	}
+	if v.DeletedAt.Valid {
+		pv.DeletedAt = v.DeletedAt.Time.Format(time.RFC3339)
+	}
+	return pv
+}
+
+func mapSummaryToProto(v *storage.VaultSummary) *pb.VaultSummary {
+	return &pb.VaultSummary{
+		Id:         v.ID,
+		Type:       string(v.Type),
+		Title:      v.Title,
+		Metadata:   v.Metadata,
+		Size:       v.Size,
+		Revision:   v.Revision,
+		CreatedAt:  v.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  v.UpdatedAt.Format(time.RFC3339),
+		SealedMeta: v.SealedMeta,
+	}
+}
+
+// vaultSorts maps the orders of ListVaults to the storage ones.
+var vaultSorts = map[pb.VaultSort]storage.VaultSort{
+	pb.VaultSort_VAULT_SORT_ID:         storage.SortByID,
+	pb.VaultSort_VAULT_SORT_TITLE:      storage.SortByTitle,
+	pb.VaultSort_VAULT_SORT_UPDATED_AT: storage.SortByUpdated,
+	pb.VaultSort_VAULT_SORT_CREATED_AT: storage.SortByCreated,
+}
+
+// vaultFilter converts a ListVaults request to a storage filter. The fields of
+// VaultRecord are named like the columns, so the mask selects them directly.
+func vaultFilter(in *pb.ListVaultsRequest) (storage.VaultFilter, error) {
+	f := storage.VaultFilter{
+		Type:         storage.RecordType(in.Type),
+		TitlePrefix:  in.TitlePrefix,
+		MetadataKeys: in.MetadataKeys,
+		Desc:         in.Descending,
+		Limit:        int(in.PageSize),
+	}
+
+	if in.PageSize < 0 {
+		return f, status.Error(codes.InvalidArgument, "page_size не может быть отрицательным")
+	}
+
+	var ok bool
+	if f.Sort, ok = vaultSorts[in.Sort]; !ok {
+		return f, status.Errorf(codes.InvalidArgument, "неизвестный порядок сортировки: %v", in.Sort)
+	}
+
+	if in.UpdatedSince != "" {
+		t, err := time.Parse(time.RFC3339, in.UpdatedSince)
+		if err != nil {
+			return f, status.Errorf(codes.InvalidArgument, "updated_since не в формате RFC 3339: %v", err)
+		}
+		f.UpdatedSince = t
+	}
+
+	if len(in.Fields.GetPaths()) > 0 {
+		if !in.Fields.IsValid(&pb.VaultRecord{}) || slices.Contains(in.Fields.GetPaths(), "blind_tokens") {
+			return f, status.Errorf(codes.InvalidArgument, "неизвестные поля: %v", in.Fields.GetPaths())
+		}
+		f.Fields = in.Fields.GetPaths()
+	}
+
+	return f, nil
+}
+
+// maxBlindTokens limits the blind index tokens of a record and of a lookup.
+const maxBlindTokens = 32
+
+// blindTokens checks blind index tokens sent by the client and drops repeats.
+// A token is the hex of an HMAC-SHA256.
+func blindTokens(tokens []string) ([]string, error) {
+	if len(tokens) > maxBlindTokens {
+		return nil, status.Errorf(codes.InvalidArgument, "не больше %d токенов", maxBlindTokens)
+	}
+
+	var list []string
+	for _, t := range tokens {
+		if _, err := hex.DecodeString(t); err != nil || len(t) != crypto.BlindTokenSize || strings.ToLower(t) != t {
+			return nil, status.Errorf(codes.InvalidArgument, "неверный токен: %q", t)
+		}
+		if !slices.Contains(list, t) {
+			list = append(list, t)
+		}
+	}
+	return list, nil
+}
+
+// checkUUID accepts an empty UUID, left by clients that do not bind the
+// encrypted data to one, and the canonical lower-case form.
+func checkUUID(s string) error {
+	if s == "" {
+		return nil
+	}
+	if len(s) != 36 {
+		return status.Errorf(codes.InvalidArgument, "неверный uuid: %q", s)
+	}
+	for i, r := range s {
+		dash := i == 8 || i == 13 || i == 18 || i == 23
+		if dash != (r == '-') || !dash && !strings.ContainsRune("0123456789abcdef", r) {
+			return status.Errorf(codes.InvalidArgument, "неверный uuid: %q", s)
+		}
+	}
+	return nil
+}
+
+// maskVault clears the fields of v that are not listed, except the ID. An
+// empty list keeps all of them.
+func maskVault(v *pb.VaultRecord, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+
+	keep := map[protoreflect.Name]bool{"id": true}
+	for _, f := range fields {
+		keep[protoreflect.Name(f)] = true
+	}
+
+	m := v.ProtoReflect()
+	all := m.Descriptor().Fields()
+	for i := 0; i < all.Len(); i++ {
+		if fd := all.Get(i); !keep[fd.Name()] {
+			m.Clear(fd)
+		}
+	}
+}
+
+// mapVersionToProto converts a VaultRecordVersion to its protobuf representation without the data.
+func mapVersionToProto(v *storage.VaultRecordVersion) *pb.VaultVersion {
+	return &pb.VaultVersion{
+		Version:    v.Version,
+		Type:       string(v.Type),
+		Title:      v.Title,
+		Metadata:   v.Metadata,
+		Size:       int64(len(v.EncryptedData)),
+		SavedAt:    v.SavedAt.Format(time.RFC3339),
+		ArchivedAt: v.ArchivedAt.Format(time.RFC3339),
+		Uuid:       v.UUID,
+		SealedMeta: v.SealedMeta,
+	}
+}
+
+// mapSessionToProto converts a Session from the storage layer to its protobuf representation.
+func mapSessionToProto(sess *storage.Session, currentID uint64) *pb.Session {
+	return &pb.Session{
+		Id:            sess.ID,
+		DeviceName:    sess.Device.Name,
+		ClientVersion: sess.Device.ClientVersion,
+		Ip:            sess.Device.IP,
+		CreatedAt:     sess.CreatedAt.Format(time.RFC3339),
+		LastSeenAt:    sess.LastSeenAt.Format(time.RFC3339),
+		Current:       sess.ID == currentID,
+	}
+}
+
+// peerIP returns the address of the calling client without the port.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// vaultStatus converts a service error into a gRPC status. Missing records and
+// records of other users are both reported as NotFound. A revision conflict is
+// reported as Aborted with the current revision attached as a detail, a record
+// with a UUID already taken as AlreadyExists, an update without a revision as
+// InvalidArgument, a file that is missing or not fully
+// uploaded as FailedPrecondition.
+func vaultStatus(err error, msg string) error {
+	var conflict *storage.RevisionConflictError
+	if errors.As(err, &conflict) {
+		st := status.Newf(codes.Aborted, "запись изменена другим клиентом: текущая ревизия %d", conflict.Current)
+		withDetails, dErr := st.WithDetails(&pb.RevisionConflict{
+			VaultId:          conflict.VaultID,
+			CurrentRevision:  conflict.Current,
+			ExpectedRevision: conflict.Expected,
+		})
+		if dErr != nil {
+			return st.Err()
+		}
+		return withDetails.Err()
+	}
+	if errors.Is(err, storage.ErrVaultExists) {
+		return status.Error(codes.AlreadyExists, "запись с этим UUID уже есть")
+	}
+	if errors.Is(err, storage.ErrRevisionRequired) {
+		return status.Error(codes.InvalidArgument, "не указана ревизия записи")
+	}
+	if errors.Is(err, storage.ErrVaultNotFound) {
+		return status.Errorf(codes.NotFound, "запись не найдена: %v", err)
+	}
+	if errors.Is(err, storage.ErrVersionNotFound) {
+		return status.Errorf(codes.NotFound, "версия не найдена: %v", err)
+	}
+	if errors.Is(err, service.ErrVersionWithoutUUID) {
+		return status.Error(codes.FailedPrecondition, "версия создана до перешифрования записи и не может быть восстановлена")
+	}
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return status.Error(codes.FailedPrecondition, "файл записи не найден")
+	}
+	if errors.Is(err, service.ErrBlobIncomplete) {
+		return status.Error(codes.FailedPrecondition, "файл записи загружен не полностью")
+	}
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
+}
+
+// blobStatus converts blob errors of the service layer to gRPC statuses.
+func blobStatus(err error, msg string) error {
+	switch {
+	case errors.Is(err, storage.ErrBlobNotFound):
+		return status.Error(codes.NotFound, "файл не найден")
+	case errors.Is(err, service.ErrInvalidUpload), errors.Is(err, service.ErrInvalidChunk):
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	case errors.Is(err, service.ErrUploadMismatch):
+		return status.Error(codes.AlreadyExists, "загрузка с этим идентификатором начата для другого файла")
+	case errors.Is(err, storage.ErrChunkOutOfOrder):
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	case errors.Is(err, service.ErrBlobIncomplete):
+		return status.Error(codes.FailedPrecondition, "файл загружен не полностью")
+	}
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
+}
+
+// startSession opens a session on the calling device and issues its token pair.
+func (s *Server) startSession(ctx context.Context, userID uint64, deviceName, clientVersion string) (*pb.LoginResponse, error) {
+	sess, refresh, err := s.service.StartSession(ctx, userID, storage.Device{
+		Name:          deviceName,
+		ClientVersion: clientVersion,
+		IP:            peerIP(ctx),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "не удалось создать сессию: %v", err)
+	}
+
+	s.log.Debugf("userID: %d, sessionID: %d", userID, sess.ID)
+	return s.tokenResponse(sess, refresh)
+}
+
+// tokenResponse issues an access token for the session and packs it with the refresh token.
+func (s *Server) tokenResponse(sess storage.Session, refresh string) (*pb.LoginResponse, error) {
+	token, err := s.tokens.generate(sess.UserID, sess.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ошибка генерации токена: %v", err)
+	}
+
+	return &pb.LoginResponse{
+		Token:        token,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(s.tokens.ttl.Seconds()),
+	}, nil
 }
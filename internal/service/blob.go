@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+)
+
+// maxBlobRef is the longest blob reference a client may choose.
+const maxBlobRef = 64
+
+var (
+	// ErrInvalidUpload indicates an upload description that cannot belong to an encrypted stream.
+	ErrInvalidUpload = errors.New("invalid upload")
+
+	// ErrUploadMismatch indicates an upload resumed with a different description than it was started with.
+	ErrUploadMismatch = errors.New("upload does not match")
+
+	// ErrInvalidChunk indicates a chunk of the wrong size.
+	ErrInvalidChunk = errors.New("invalid blob chunk")
+
+	// ErrBlobIncomplete indicates a download of a blob whose upload has not finished.
+	ErrBlobIncomplete = errors.New("blob upload incomplete")
+)
+
+// OpenUpload starts the upload b of b.UserID. An upload with the same
+// reference is returned instead, so that it can be resumed, if it describes
+// the same stream.
+func (s *Service) OpenUpload(ctx context.Context, b *storage.Blob) (storage.Blob, error) {
+	if b.Ref == "" || len(b.Ref) > maxBlobRef ||
+		len(b.Header) != crypto.StreamHeaderSize || b.Size < 0 ||
+		b.Chunks != crypto.StreamChunks(b.Size) {
+		return storage.Blob{}, errors.Wrapf(ErrInvalidUpload, "ref=%q size=%d chunks=%d", b.Ref, b.Size, b.Chunks)
+	}
+
+	current, err := s.storage.Blob(ctx, b.UserID, b.Ref)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		err = s.storage.CreateBlob(ctx, b)
+		return *b, err
+	}
+	if err != nil {
+		return storage.Blob{}, err
+	}
+
+	if string(current.Header) != string(b.Header) || current.Size != b.Size {
+		return storage.Blob{}, errors.Wrapf(ErrUploadMismatch, "ref=%q", b.Ref)
+	}
+	return current, nil
+}
+
+// PutBlobChunk stores chunk seq of the upload b. Every chunk but the last
+// must be full, and the last one must hold at least the authentication tag.
+func (s *Service) PutBlobChunk(ctx context.Context, b storage.Blob, seq uint32, data []byte) error {
+	last := seq+1 == b.Chunks
+	if len(data) > crypto.SealedChunkSize || len(data) < crypto.SealedChunkSize-crypto.StreamChunkSize ||
+		!last && len(data) != crypto.SealedChunkSize {
+		return errors.Wrapf(ErrInvalidChunk, "seq=%d size=%d", seq, len(data))
+	}
+
+	return s.storage.PutBlobChunk(ctx, b.ID, seq, data)
+}
+
+// Blob returns the blob of the user uID with the given reference.
+func (s *Service) Blob(ctx context.Context, uID uint64, ref string) (storage.Blob, error) {
+	return s.storage.Blob(ctx, uID, ref)
+}
+
+// BlobChunk returns chunk seq of the completely uploaded blob b.
+func (s *Service) BlobChunk(ctx context.Context, b storage.Blob, seq uint32) ([]byte, error) {
+	if !b.Complete() {
+		return nil, errors.Wrapf(ErrBlobIncomplete, "ref=%q", b.Ref)
+	}
+	return s.storage.BlobChunk(ctx, b.ID, seq)
+}
+
+// checkBlobRef makes sure the blob a record refers to is a complete upload of
+// the record owner.
+func (s *Service) checkBlobRef(ctx context.Context, v *storage.VaultRecord) error {
+	if v.BlobRef == "" {
+		return nil
+	}
+
+	b, err := s.storage.Blob(ctx, v.UserID, v.BlobRef)
+	if err != nil {
+		return err
+	}
+	if !b.Complete() {
+		return errors.Wrapf(ErrBlobIncomplete, "ref=%q", b.Ref)
+	}
+	return nil
+}
+
+// CollectBlobs removes the blobs no record refers to and then the stored
+// chunks no blob refers to, both when untouched since before.
+func (s *Service) CollectBlobs(ctx context.Context, before time.Time) (blobs, objects int64, err error) {
+	if blobs, err = s.storage.CollectBlobs(ctx, before); err != nil {
+		return 0, 0, errors.Wrap(err, "collect blobs")
+	}
+	if objects, err = s.storage.CollectObjects(ctx, before); err != nil {
+		return blobs, 0, errors.Wrap(err, "collect objects")
+	}
+	return blobs, objects, nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"github.com/wickedv43/go-goph-keeper/pkg/crypto"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Blobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+	ctx := context.Background()
+
+	header := bytes.Repeat([]byte{1}, crypto.StreamHeaderSize)
+	header[0] = 1
+	upload := func() *storage.Blob {
+		return &storage.Blob{UserID: 1, Ref: "f00d", Header: header, Size: crypto.StreamChunkSize + 10, Chunks: 2}
+	}
+
+	t.Run("new upload", func(t *testing.T) {
+		mockStorage.EXPECT().Blob(gomock.Any(), uint64(1), "f00d").Return(storage.Blob{}, storage.ErrBlobNotFound)
+		mockStorage.EXPECT().CreateBlob(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, b *storage.Blob) error {
+			b.ID = 7
+			return nil
+		})
+
+		b, err := s.OpenUpload(ctx, upload())
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), b.ID)
+	})
+
+	t.Run("resume upload", func(t *testing.T) {
+		stored := *upload()
+		stored.ID, stored.Received = 7, 1
+		mockStorage.EXPECT().Blob(gomock.Any(), uint64(1), "f00d").Return(stored, nil)
+
+		b, err := s.OpenUpload(ctx, upload())
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), b.Received)
+	})
+
+	t.Run("resume a different file", func(t *testing.T) {
+		stored := *upload()
+		stored.Size++
+		mockStorage.EXPECT().Blob(gomock.Any(), uint64(1), "f00d").Return(stored, nil)
+
+		_, err := s.OpenUpload(ctx, upload())
+		require.ErrorIs(t, err, ErrUploadMismatch)
+	})
+
+	t.Run("invalid upload", func(t *testing.T) {
+		b := upload()
+		b.Chunks = 1
+		_, err := s.OpenUpload(ctx, b)
+		require.ErrorIs(t, err, ErrInvalidUpload)
+
+		b = upload()
+		b.Header = []byte("short")
+		_, err = s.OpenUpload(ctx, b)
+		require.ErrorIs(t, err, ErrInvalidUpload)
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		b := *upload()
+		b.ID = 7
+
+		full := make([]byte, crypto.SealedChunkSize)
+		mockStorage.EXPECT().PutBlobChunk(gomock.Any(), uint64(7), uint32(0), full).Return(nil)
+		require.NoError(t, s.PutBlobChunk(ctx, b, 0, full))
+
+		tail := make([]byte, 26)
+		mockStorage.EXPECT().PutBlobChunk(gomock.Any(), uint64(7), uint32(1), tail).Return(nil)
+		require.NoError(t, s.PutBlobChunk(ctx, b, 1, tail))
+
+		require.ErrorIs(t, s.PutBlobChunk(ctx, b, 0, tail), ErrInvalidChunk, "only the last chunk may be short")
+		require.ErrorIs(t, s.PutBlobChunk(ctx, b, 1, make([]byte, 10)), ErrInvalidChunk)
+		require.ErrorIs(t, s.PutBlobChunk(ctx, b, 1, make([]byte, crypto.SealedChunkSize+1)), ErrInvalidChunk)
+	})
+
+	t.Run("download", func(t *testing.T) {
+		b := *upload()
+		b.ID = 7
+
+		_, err := s.BlobChunk(ctx, b, 0)
+		require.ErrorIs(t, err, ErrBlobIncomplete)
+
+		b.Received = 2
+		mockStorage.EXPECT().BlobChunk(gomock.Any(), uint64(7), uint32(1)).Return([]byte("sealed"), nil)
+		data, err := s.BlobChunk(ctx, b, 1)
+		require.NoError(t, err)
+		require.Equal(t, []byte("sealed"), data)
+	})
+}
+
+func TestService_VaultBlobRef(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+	ctx := context.Background()
+
+	t.Run("complete blob", func(t *testing.T) {
+		v := &storage.VaultRecord{UserID: 1, Type: storage.RecordTypeBinary, BlobRef: "f00d"}
+		mockStorage.EXPECT().Blob(gomock.Any(), uint64(1), "f00d").Return(storage.Blob{Ref: "f00d", Chunks: 2, Received: 2}, nil)
+		mockStorage.EXPECT().CreateVault(gomock.Any(), v).Return(nil)
+
+		require.NoError(t, s.CreateVault(ctx, v))
+	})
+
+	t.Run("incomplete blob", func(t *testing.T) {
+		v := &storage.VaultRecord{UserID: 1, BlobRef: "f00d"}
+		mockStorage.EXPECT().Blob(gomock.Any(), uint64(1), "f00d").Return(storage.Blob{Ref: "f00d", Chunks: 2, Received: 1}, nil)
+
+		require.ErrorIs(t, s.CreateVault(ctx, v), ErrBlobIncomplete)
+	})
+
+	t.Run("blob of nobody", func(t *testing.T) {
+		v := &storage.VaultRecord{ID: 3, UserID: 1, BlobRef: "f00d", Revision: 1}
+		mockStorage.EXPECT().GetVault(gomock.Any(), uint64(3)).Return(storage.VaultRecord{ID: 3, UserID: 1, Revision: 1}, nil)
+		mockStorage.EXPECT().Blob(gomock.Any(), uint64(1), "f00d").Return(storage.Blob{}, storage.ErrBlobNotFound)
+
+		require.ErrorIs(t, s.UpdateVault(ctx, v), storage.ErrBlobNotFound)
+	})
+}
+
+func TestService_CollectBlobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+	ctx := context.Background()
+	before := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		gomock.InOrder(
+			mockStorage.EXPECT().CollectBlobs(gomock.Any(), before).Return(int64(2), nil),
+			mockStorage.EXPECT().CollectObjects(gomock.Any(), before).Return(int64(5), nil),
+		)
+
+		blobs, objects, err := s.CollectBlobs(ctx, before)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), blobs)
+		require.Equal(t, int64(5), objects)
+	})
+
+	t.Run("blobs fail", func(t *testing.T) {
+		mockStorage.EXPECT().CollectBlobs(gomock.Any(), before).Return(int64(0), errors.New("db down"))
+
+		_, _, err := s.CollectBlobs(ctx, before)
+		require.ErrorContains(t, err, "collect blobs")
+	})
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/pubsub"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+)
+
+// ErrVersionWithoutUUID is returned when restoring a version made before UUIDs
+// over a record that has one: clients refuse a record that lost its UUID.
+var ErrVersionWithoutUUID = errors.New("vault version has no uuid")
+
+// ListVaultVersions returns previous versions of the record if it belongs to the user.
+func (s *Service) ListVaultVersions(ctx context.Context, uID, vID uint64) ([]storage.VaultRecordVersion, error) {
+	if _, err := s.ownedVault(ctx, uID, vID); err != nil {
+		return nil, err
+	}
+
+	return s.storage.ListVaultVersions(ctx, vID)
+}
+
+// RestoreVaultVersion writes the contents of a previous version over the record.
+// Restoring is an ordinary update, so the replaced contents become a version too
+// and a restore can itself be undone.
+func (s *Service) RestoreVaultVersion(ctx context.Context, uID, vID, version uint64) (storage.VaultRecord, error) {
+	v, err := s.ownedVault(ctx, uID, vID)
+	if err != nil {
+		return storage.VaultRecord{}, err
+	}
+
+	old, err := s.storage.VaultVersion(ctx, vID, version)
+	if err != nil {
+		return storage.VaultRecord{}, err
+	}
+
+	if old.UUID == "" && v.UUID != "" {
+		return storage.VaultRecord{}, ErrVersionWithoutUUID
+	}
+
+	v.Type = old.Type
+	v.Title = old.Title
+	v.Metadata = old.Metadata
+	v.EncryptedData = old.EncryptedData
+	v.BlobRef = old.BlobRef
+	v.UUID = old.UUID // the contents only open bound to the UUID they were sealed with
+	v.SealedMeta = old.SealedMeta
+	v.Tokens = old.BlindTokens()
+
+	if err = s.storage.UpdateVault(ctx, &v); err != nil {
+		return storage.VaultRecord{}, errors.Wrap(err, "restore version")
+	}
+
+	s.publish(ctx, uID, vID, v.Revision, pubsub.OpUpdate)
+	return v, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_ListVaultVersions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+
+	t.Run("success", func(t *testing.T) {
+		versions := []storage.VaultRecordVersion{{VaultID: 42, Version: 2}, {VaultID: 42, Version: 1}}
+
+		mockStorage.EXPECT().GetVault(gomock.Any(), uint64(42)).Return(storage.VaultRecord{ID: 42, UserID: 1}, nil)
+		mockStorage.EXPECT().ListVaultVersions(gomock.Any(), uint64(42)).Return(versions, nil)
+
+		got, err := s.ListVaultVersions(context.Background(), 1, 42)
+		require.NoError(t, err)
+		require.Equal(t, versions, got)
+	})
+
+	t.Run("record of another user", func(t *testing.T) {
+		mockStorage.EXPECT().GetVault(gomock.Any(), uint64(42)).Return(storage.VaultRecord{ID: 42, UserID: 2}, nil)
+
+		_, err := s.ListVaultVersions(context.Background(), 1, 42)
+		require.ErrorIs(t, err, storage.ErrVaultNotFound)
+	})
+}
+
+func TestService_RestoreVaultVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+
+	current := storage.VaultRecord{
+		ID:            42,
+		UserID:        1,
+		Type:          storage.RecordTypeNote,
+		Title:         "broken",
+		EncryptedData: []byte("bad"),
+	}
+	old := storage.VaultRecordVersion{
+		VaultID:       42,
+		Version:       3,
+		Type:          storage.RecordTypeNote,
+		Title:         "good",
+		Metadata:      `{"tag":"x"}`,
+		EncryptedData: []byte("good"),
+		UUID:          "0b5e5e9c-7a47-4a8e-9e3c-1d1f2a3b4c5d",
+		SealedMeta:    []byte("sealed"),
+		Tokens:        "aa bb",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockStorage.EXPECT().GetVault(gomock.Any(), uint64(42)).Return(current, nil)
+		mockStorage.EXPECT().VaultVersion(gomock.Any(), uint64(42), uint64(3)).Return(old, nil)
+		mockStorage.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *storage.VaultRecord) error {
+				require.Equal(t, uint64(42), v.ID)
+				require.Equal(t, uint64(1), v.UserID)
+				require.Equal(t, "good", v.Title)
+				require.Equal(t, []string{"aa", "bb"}, v.Tokens)
+				require.Equal(t, old.Metadata, v.Metadata)
+				require.Equal(t, old.EncryptedData, v.EncryptedData)
+				require.Equal(t, old.UUID, v.UUID)
+				require.Equal(t, old.SealedMeta, v.SealedMeta)
+				return nil
+			})
+
+		v, err := s.RestoreVaultVersion(context.Background(), 1, 42, 3)
+		require.NoError(t, err)
+		require.Equal(t, "good", v.Title)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		mockStorage.EXPECT().GetVault(gomock.Any(), uint64(42)).Return(current, nil)
+		mockStorage.EXPECT().VaultVersion(gomock.Any(), uint64(42), uint64(9)).Return(storage.VaultRecordVersion{}, storage.ErrVersionNotFound)
+
+		_, err := s.RestoreVaultVersion(context.Background(), 1, 42, 9)
+		require.ErrorIs(t, err, storage.ErrVersionNotFound)
+	})
+
+	t.Run("version made before UUIDs", func(t *testing.T) {
+		resealed := current
+		resealed.UUID = old.UUID
+		legacy := old
+		legacy.UUID = ""
+		mockStorage.EXPECT().GetVault(gomock.Any(), uint64(42)).Return(resealed, nil)
+		mockStorage.EXPECT().VaultVersion(gomock.Any(), uint64(42), uint64(2)).Return(legacy, nil)
+
+		_, err := s.RestoreVaultVersion(context.Background(), 1, 42, 2)
+		require.ErrorIs(t, err, ErrVersionWithoutUUID)
+	})
+
+	t.Run("update fails", func(t *testing.T) {
+		mockStorage.EXPECT().GetVault(gomock.Any(), uint64(42)).Return(current, nil)
+		mockStorage.EXPECT().VaultVersion(gomock.Any(), uint64(42), uint64(3)).Return(old, nil)
+		mockStorage.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(errors.New("db down"))
+
+		_, err := s.RestoreVaultVersion(context.Background(), 1, 42, 3)
+		require.Error(t, err)
+	})
+}
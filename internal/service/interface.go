@@ -2,13 +2,128 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/pubsub"
 	"github.com/wickedv43/go-goph-keeper/internal/storage"
 )
 
 // GophKeeper defines the service layer interface for user and vault operations.
+//
+// Every vault method that addresses a record by ID takes the ID of the calling
+// user and refuses to touch records owned by someone else.
 type GophKeeper interface {
-	storage.DataKeeper
+	// NewUser creates a new user.
+	NewUser(ctx context.Context, u *storage.User) (storage.User, error)
+
+	// User retrieves a user by their unique ID.
+	User(ctx context.Context, id uint64) (storage.User, error)
+
+	// UserByLogin retrieves a user by their login.
+	UserByLogin(ctx context.Context, login string) (storage.User, error)
+
+	// Register creates a user and stores only a slow salted hash of the password.
+	Register(ctx context.Context, login, password string) (storage.User, error)
+
+	// Authenticate checks the password of the user with the given login.
+	Authenticate(ctx context.Context, login, password string) (storage.User, error)
+
+	// EnrollMFA generates a TOTP secret for the user and returns it with its otpauth URI.
+	EnrollMFA(ctx context.Context, uID uint64) (string, string, error)
+
+	// ConfirmMFA enables the second factor after a valid code and returns recovery codes.
+	ConfirmMFA(ctx context.Context, uID uint64, code string) ([]string, error)
+
+	// MFARequired reports whether logins of the user need a second factor.
+	MFARequired(ctx context.Context, uID uint64) (bool, error)
+
+	// VerifyMFA checks a TOTP code or a recovery code of the user.
+	VerifyMFA(ctx context.Context, uID uint64, code string) error
+
+	// StartSession opens a login session on the device and returns its first refresh token.
+	StartSession(ctx context.Context, uID uint64, device storage.Device) (storage.Session, string, error)
+
+	// RefreshSession rotates a refresh token and returns the session with the new token.
+	RefreshSession(ctx context.Context, token string) (storage.Session, string, error)
+
+	// CheckSession verifies that the session of the user is still active and returns it.
+	CheckSession(ctx context.Context, uID, sID uint64) (storage.Session, error)
+
+	// TouchSession records that the session was just used from the given address.
+	TouchSession(ctx context.Context, sID uint64, ip string) error
+
+	// ListSessions returns active sessions of the user.
+	ListSessions(ctx context.Context, uID uint64) ([]storage.Session, error)
+
+	// RevokeSession ends the session sID of the user uID.
+	RevokeSession(ctx context.Context, uID, sID uint64) error
+
+	// CreateVault stores a new vault record owned by v.UserID.
+	CreateVault(ctx context.Context, v *storage.VaultRecord) error
+
+	// GetVault returns the record vID if it belongs to the user uID.
+	GetVault(ctx context.Context, uID, vID uint64) (storage.VaultRecord, error)
+
+	// UpdateVault overwrites the record v.ID if it belongs to v.UserID and is
+	// still at v.Revision. The revision is required: a zero one is rejected
+	// with storage.ErrRevisionRequired.
+	UpdateVault(ctx context.Context, v *storage.VaultRecord) error
+
+	// ListVaults returns a page of the records of the user uID matching the
+	// filter, starting after the page token, and the token of the next page.
+	ListVaults(ctx context.Context, uID uint64, f storage.VaultFilter, pageToken string) ([]storage.VaultRecord, string, error)
+
+	// ListVaultSummaries is ListVaults returning the records without their contents.
+	ListVaultSummaries(ctx context.Context, uID uint64, f storage.VaultFilter, pageToken string) ([]storage.VaultSummary, string, error)
+
+	// FindVaults returns the records of the user uID having all the blind index tokens.
+	FindVaults(ctx context.Context, uID uint64, tokens []string) ([]storage.VaultRecord, error)
+
+	// DeleteVault moves the record vID to the trash if it belongs to the user uID.
+	DeleteVault(ctx context.Context, uID, vID uint64) error
+
+	// Sync returns up to limit changes of the records of the user uID after the cursor.
+	Sync(ctx context.Context, uID, since uint64, limit int) (storage.VaultSync, error)
+
+	// Watch returns changes of the records of the user uID made from now on, until cancel is called.
+	Watch(ctx context.Context, uID uint64) (events <-chan pubsub.Event, cancel func())
+
+	// ListTrash lists trashed records of the user uID.
+	ListTrash(ctx context.Context, uID uint64) ([]storage.VaultRecord, error)
+
+	// RestoreVault moves the record vID of the user uID out of the trash.
+	RestoreVault(ctx context.Context, uID, vID uint64) error
+
+	// PurgeVault permanently removes the trashed record vID of the user uID.
+	PurgeVault(ctx context.Context, uID, vID uint64) error
+
+	// PurgeTrash permanently removes records of all users trashed before the given time.
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+
+	// ListVaultVersions lists previous versions of the record vID of the user uID, newest first.
+	ListVaultVersions(ctx context.Context, uID, vID uint64) ([]storage.VaultRecordVersion, error)
+
+	// RestoreVaultVersion makes a previous version current again and returns the restored record.
+	RestoreVaultVersion(ctx context.Context, uID, vID, version uint64) (storage.VaultRecord, error)
+
+	// OpenUpload starts a chunked upload of b.UserID, or returns the earlier one with the same reference to resume it.
+	OpenUpload(ctx context.Context, b *storage.Blob) (storage.Blob, error)
+
+	// PutBlobChunk stores the next chunk seq of the upload b.
+	PutBlobChunk(ctx context.Context, b storage.Blob, seq uint32, data []byte) error
+
+	// Blob returns the blob of the user uID with the given reference.
+	Blob(ctx context.Context, uID uint64, ref string) (storage.Blob, error)
+
+	// BlobChunk returns chunk seq of the uploaded blob b.
+	BlobChunk(ctx context.Context, b storage.Blob, seq uint32) ([]byte, error)
+
+	// CollectBlobs removes blobs and their stored chunks that nothing has referred to since the given time.
+	CollectBlobs(ctx context.Context, before time.Time) (blobs, objects int64, err error)
+
+	// Shutdown releases service resources.
+	Shutdown() error
 }
 
 func (s *Service) NewUser(ctx context.Context, u *storage.User) (storage.User, error) {
@@ -23,26 +138,144 @@ func (s *Service) UserByLogin(ctx context.Context, login string) (storage.User,
 	return s.storage.UserByLogin(ctx, login)
 }
 
+func (s *Service) Register(ctx context.Context, login, password string) (storage.User, error) {
+	hash, err := hashPassword(password, s.passwordParams())
+	if err != nil {
+		return storage.User{}, err
+	}
+
+	return s.storage.NewUser(ctx, &storage.User{Login: login, PasswordHash: hash})
+}
+
+func (s *Service) Authenticate(ctx context.Context, login, password string) (storage.User, error) {
+	user, err := s.storage.UserByLogin(ctx, login)
+	if err != nil {
+		// Spend as much time as for an existing user, so the response time does not reveal the login.
+		_, _ = verifyPassword(password, s.unknownUserHash(), s.passwordParams())
+		return storage.User{}, err
+	}
+
+	rehash, err := verifyPassword(password, user.PasswordHash, s.passwordParams())
+	if err != nil {
+		return storage.User{}, err
+	}
+
+	if rehash {
+		// Upgrade legacy or outdated hashes while the plain password is at hand.
+		hash, err := hashPassword(password, s.passwordParams())
+		if err != nil {
+			return storage.User{}, err
+		}
+		if err = s.storage.UpdatePasswordHash(ctx, user.ID, hash); err != nil {
+			return storage.User{}, errors.Wrap(err, "rehash password")
+		}
+		user.PasswordHash = hash
+	}
+
+	return user, nil
+}
+
 func (s *Service) CreateVault(ctx context.Context, v *storage.VaultRecord) error {
-	return s.storage.CreateVault(ctx, v)
+	if err := s.checkBlobRef(ctx, v); err != nil {
+		return err
+	}
+
+	if err := s.storage.CreateVault(ctx, v); err != nil {
+		return err
+	}
+
+	s.publish(ctx, v.UserID, v.ID, v.Revision, pubsub.OpCreate)
+	return nil
 }
 
-func (s *Service) GetVault(ctx context.Context, vID uint64) (storage.VaultRecord, error) {
-	return s.storage.GetVault(ctx, vID)
+func (s *Service) GetVault(ctx context.Context, uID, vID uint64) (storage.VaultRecord, error) {
+	return s.ownedVault(ctx, uID, vID)
 }
 
 func (s *Service) UpdateVault(ctx context.Context, v *storage.VaultRecord) error {
-	return s.storage.UpdateVault(ctx, v)
-}
+	if v.Revision == 0 {
+		return errors.Wrapf(storage.ErrRevisionRequired, "id=%d", v.ID)
+	}
+
+	current, err := s.ownedVault(ctx, v.UserID, v.ID)
+	if err != nil {
+		return err
+	}
+
+	// A stale revision fails here already; storage checks it again under the row lock.
+	if v.Revision != current.Revision {
+		return &storage.RevisionConflictError{VaultID: v.ID, Current: current.Revision, Expected: v.Revision}
+	}
 
-func (s *Service) ListVaults(ctx context.Context, uID uint64) ([]storage.VaultRecord, error) {
-	return s.storage.ListVaults(ctx, uID)
+	if err = s.checkBlobRef(ctx, v); err != nil {
+		return err
+	}
+
+	// Save writes every column, keep the original creation time and the UUID
+	// once the record has one.
+	v.CreatedAt = current.CreatedAt
+	if current.UUID != "" {
+		v.UUID = current.UUID
+	}
+
+	if err = s.storage.UpdateVault(ctx, v); err != nil {
+		return err
+	}
+
+	s.publish(ctx, v.UserID, v.ID, v.Revision, pubsub.OpUpdate)
+	return nil
 }
 
-func (s *Service) DeleteVault(ctx context.Context, vID uint64) error {
-	return s.storage.DeleteVault(ctx, vID)
+func (s *Service) DeleteVault(ctx context.Context, uID, vID uint64) error {
+	if _, err := s.ownedVault(ctx, uID, vID); err != nil {
+		return err
+	}
+
+	if err := s.storage.DeleteVault(ctx, vID); err != nil {
+		return err
+	}
+
+	s.publish(ctx, uID, vID, 0, pubsub.OpDelete)
+	return nil
 }
 
 func (s *Service) Shutdown() error {
-	return nil
+	if s.events == nil {
+		return nil
+	}
+	return s.events.Close()
+}
+
+// passwordParams returns the configured hashing parameters or the defaults.
+func (s *Service) passwordParams() passwordParams {
+	if s.password.Time == 0 {
+		return defaultPasswordParams
+	}
+	return s.password
+}
+
+// unknownUserHash returns a hash of a random password with the current parameters.
+func (s *Service) unknownUserHash() string {
+	s.dummyOnce.Do(func() {
+		secret, err := randomSecret()
+		if err == nil {
+			s.dummyHash, _ = hashPassword(secret, s.passwordParams())
+		}
+	})
+	return s.dummyHash
+}
+
+// ownedVault loads a record and reports records of other users as missing,
+// so that a caller cannot probe which IDs exist.
+func (s *Service) ownedVault(ctx context.Context, uID, vID uint64) (storage.VaultRecord, error) {
+	v, err := s.storage.GetVault(ctx, vID)
+	if err != nil {
+		return storage.VaultRecord{}, err
+	}
+
+	if v.UserID != uID {
+		return storage.VaultRecord{}, errors.Wrapf(storage.ErrVaultNotFound, "id=%d", vID)
+	}
+
+	return v, nil
 }
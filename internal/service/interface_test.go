@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
@@ -142,7 +143,7 @@ func TestService_GetVault(t *testing.T) {
 			GetVault(gomock.Any(), expected.ID).
 			Return(expected, nil)
 
-		got, err := s.GetVault(context.Background(), expected.ID)
+		got, err := s.GetVault(context.Background(), expected.UserID, expected.ID)
 		require.NoError(t, err)
 		require.Equal(t, expected, got)
 	})
@@ -155,7 +156,7 @@ func TestService_GetVault(t *testing.T) {
 			GetVault(gomock.Any(), expected.ID).
 			Return(storage.VaultRecord{}, expectedErr)
 
-		_, err := s.GetVault(context.Background(), expected.ID)
+		_, err := s.GetVault(context.Background(), expected.UserID, expected.ID)
 		require.Error(t, err)
 		require.Equal(t, expectedErr, err)
 	})
@@ -175,9 +176,16 @@ func TestService_UpdateVault(t *testing.T) {
 		Title:         "Updated Title",
 		Metadata:      "updated-meta",
 		EncryptedData: []byte("new data"),
+		Revision:      3,
 	}
+	existing := storage.VaultRecord{ID: 42, UserID: 1, Revision: 3, CreatedAt: time.Unix(1700000000, 0)}
 
 	t.Run("successfully updates vault record", func(t *testing.T) {
+		mockStorage.
+			EXPECT().
+			GetVault(gomock.Any(), vault.ID).
+			Return(existing, nil)
+
 		mockStorage.
 			EXPECT().
 			UpdateVault(gomock.Any(), vault).
@@ -185,11 +193,17 @@ func TestService_UpdateVault(t *testing.T) {
 
 		err := s.UpdateVault(context.Background(), vault)
 		require.NoError(t, err)
+		require.Equal(t, existing.CreatedAt, vault.CreatedAt)
 	})
 
 	t.Run("fails to update vault record", func(t *testing.T) {
 		expectedErr := errors.New("update failed")
 
+		mockStorage.
+			EXPECT().
+			GetVault(gomock.Any(), vault.ID).
+			Return(existing, nil)
+
 		mockStorage.
 			EXPECT().
 			UpdateVault(gomock.Any(), vault).
@@ -199,45 +213,50 @@ func TestService_UpdateVault(t *testing.T) {
 		require.Error(t, err)
 		require.Equal(t, expectedErr, err)
 	})
-}
-
-func TestService_ListVaults(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mockStorage := mocks.NewMockDataKeeper(ctrl)
-	s := &Service{storage: mockStorage}
+	t.Run("stale revision is rejected before writing", func(t *testing.T) {
+		mockStorage.
+			EXPECT().
+			GetVault(gomock.Any(), vault.ID).
+			Return(storage.VaultRecord{ID: 42, UserID: 1, Revision: 7}, nil)
 
-	userID := uint64(1)
+		err := s.UpdateVault(context.Background(), &storage.VaultRecord{ID: 42, UserID: 1, Revision: 6})
+		require.ErrorIs(t, err, storage.ErrRevisionConflict)
+	})
 
-	expectedRecords := []storage.VaultRecord{
-		{ID: 1, UserID: userID, Type: "login", Title: "Email", Metadata: "gmail", EncryptedData: []byte("data1")},
-		{ID: 2, UserID: userID, Type: "note", Title: "Secrets", Metadata: "notes", EncryptedData: []byte("data2")},
-	}
+	t.Run("update without revision is rejected", func(t *testing.T) {
+		err := s.UpdateVault(context.Background(), &storage.VaultRecord{ID: 42, UserID: 1})
+		require.ErrorIs(t, err, storage.ErrRevisionRequired)
+	})
 
-	t.Run("successfully returns list of vaults", func(t *testing.T) {
+	t.Run("uuid never changes", func(t *testing.T) {
+		const uuid = "0b5e5e9c-7a47-4a8e-9e3c-1d1f2a3b4c5d"
+		mockStorage.
+			EXPECT().
+			GetVault(gomock.Any(), vault.ID).
+			Return(storage.VaultRecord{ID: 42, UserID: 1, Revision: 3, UUID: uuid}, nil)
 		mockStorage.
 			EXPECT().
-			ListVaults(gomock.Any(), userID).
-			Return(expectedRecords, nil)
+			UpdateVault(gomock.Any(), gomock.Any()).
+			Return(nil)
 
-		got, err := s.ListVaults(context.Background(), userID)
-		require.NoError(t, err)
-		require.Equal(t, expectedRecords, got)
+		v := &storage.VaultRecord{ID: 42, UserID: 1, Revision: 3, UUID: "5d4c3b2a-1f1d-4c3e-8e9a-7a47c9e5e5b0"}
+		require.NoError(t, s.UpdateVault(context.Background(), v))
+		require.Equal(t, uuid, v.UUID)
 	})
 
-	t.Run("fails to return vaults", func(t *testing.T) {
-		expectedErr := errors.New("storage error")
-
+	t.Run("record made before uuids gets one", func(t *testing.T) {
+		mockStorage.
+			EXPECT().
+			GetVault(gomock.Any(), vault.ID).
+			Return(storage.VaultRecord{ID: 42, UserID: 1, Revision: 3}, nil)
 		mockStorage.
 			EXPECT().
-			ListVaults(gomock.Any(), userID).
-			Return(nil, expectedErr)
+			UpdateVault(gomock.Any(), gomock.Any()).
+			Return(nil)
 
-		got, err := s.ListVaults(context.Background(), userID)
-		require.Error(t, err)
-		require.Nil(t, got)
-		require.Equal(t, expectedErr, err)
+		v := &storage.VaultRecord{ID: 42, UserID: 1, Revision: 3, UUID: "5d4c3b2a-1f1d-4c3e-8e9a-7a47c9e5e5b0"}
+		require.NoError(t, s.UpdateVault(context.Background(), v))
+		require.Equal(t, "5d4c3b2a-1f1d-4c3e-8e9a-7a47c9e5e5b0", v.UUID)
 	})
 }
 
@@ -248,32 +267,149 @@ func TestService_DeleteVault(t *testing.T) {
 	mockStorage := mocks.NewMockDataKeeper(ctrl)
 	s := &Service{storage: mockStorage}
 
+	userID := uint64(7)
 	vaultID := uint64(1)
 
 	t.Run("successfully deletes vault", func(t *testing.T) {
+		mockStorage.
+			EXPECT().
+			GetVault(gomock.Any(), vaultID).
+			Return(storage.VaultRecord{ID: vaultID, UserID: userID}, nil)
+
 		mockStorage.
 			EXPECT().
 			DeleteVault(gomock.Any(), vaultID).
 			Return(nil)
 
-		err := s.DeleteVault(context.Background(), vaultID)
+		err := s.DeleteVault(context.Background(), userID, vaultID)
 		require.NoError(t, err)
 	})
 
 	t.Run("fails to delete vault", func(t *testing.T) {
 		expectedErr := errors.New("delete failed")
 
+		mockStorage.
+			EXPECT().
+			GetVault(gomock.Any(), vaultID).
+			Return(storage.VaultRecord{ID: vaultID, UserID: userID}, nil)
+
 		mockStorage.
 			EXPECT().
 			DeleteVault(gomock.Any(), vaultID).
 			Return(expectedErr)
 
-		err := s.DeleteVault(context.Background(), vaultID)
+		err := s.DeleteVault(context.Background(), userID, vaultID)
 		require.Error(t, err)
 		require.Equal(t, expectedErr, err)
 	})
 }
 
+func TestService_VaultOwnership(t *testing.T) {
+	const (
+		owner    = uint64(1)
+		stranger = uint64(2)
+		vaultID  = uint64(10)
+	)
+
+	record := storage.VaultRecord{ID: vaultID, UserID: owner, Type: "note", Title: "secret", Revision: 1}
+
+	tests := []struct {
+		name    string
+		caller  uint64
+		stored  storage.VaultRecord
+		getErr  error
+		call    func(s *Service, caller uint64) error
+		wantErr error
+		mutates bool
+	}{
+		{
+			name:   "owner reads own record",
+			caller: owner,
+			stored: record,
+			call: func(s *Service, caller uint64) error {
+				_, err := s.GetVault(context.Background(), caller, vaultID)
+				return err
+			},
+		},
+		{
+			name:   "stranger cannot read record",
+			caller: stranger,
+			stored: record,
+			call: func(s *Service, caller uint64) error {
+				_, err := s.GetVault(context.Background(), caller, vaultID)
+				return err
+			},
+			wantErr: storage.ErrVaultNotFound,
+		},
+		{
+			name:   "owner updates own record",
+			caller: owner,
+			stored: record,
+			call: func(s *Service, caller uint64) error {
+				return s.UpdateVault(context.Background(), &storage.VaultRecord{ID: vaultID, UserID: caller, Revision: 1})
+			},
+			mutates: true,
+		},
+		{
+			name:   "stranger cannot overwrite record",
+			caller: stranger,
+			stored: record,
+			call: func(s *Service, caller uint64) error {
+				return s.UpdateVault(context.Background(), &storage.VaultRecord{ID: vaultID, UserID: caller, Revision: 1})
+			},
+			wantErr: storage.ErrVaultNotFound,
+		},
+		{
+			name:   "owner deletes own record",
+			caller: owner,
+			stored: record,
+			call: func(s *Service, caller uint64) error {
+				return s.DeleteVault(context.Background(), caller, vaultID)
+			},
+			mutates: true,
+		},
+		{
+			name:   "stranger cannot delete record",
+			caller: stranger,
+			stored: record,
+			call: func(s *Service, caller uint64) error {
+				return s.DeleteVault(context.Background(), caller, vaultID)
+			},
+			wantErr: storage.ErrVaultNotFound,
+		},
+		{
+			name:   "missing record looks the same as a foreign one",
+			caller: owner,
+			getErr: storage.ErrVaultNotFound,
+			call: func(s *Service, caller uint64) error {
+				return s.DeleteVault(context.Background(), caller, vaultID)
+			},
+			wantErr: storage.ErrVaultNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStorage := mocks.NewMockDataKeeper(ctrl)
+			s := &Service{storage: mockStorage}
+
+			mockStorage.EXPECT().GetVault(gomock.Any(), vaultID).Return(tc.stored, tc.getErr)
+			if tc.mutates {
+				mockStorage.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).Return(nil).MaxTimes(1)
+				mockStorage.EXPECT().DeleteVault(gomock.Any(), vaultID).Return(nil).MaxTimes(1)
+			}
+
+			err := tc.call(s, tc.caller)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
 func TestService_NewUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
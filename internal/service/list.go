@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+)
+
+// List page sizes.
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// ErrInvalidPageToken indicates a page token that was not issued for a listing in this order.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// pageToken is the position a listing continues from: the sort order and the
+// sort key of the last record of the previous page. Clients get it as an
+// opaque string.
+type pageToken struct {
+	Sort  storage.VaultSort `json:"s"`
+	Desc  bool              `json:"d,omitempty"`
+	ID    uint64            `json:"id"`
+	Title string            `json:"t,omitempty"`
+	Time  time.Time         `json:"at"`
+}
+
+// ListVaults returns up to f.Limit records of the user matching the filter,
+// starting after the page token, and the token of the next page, empty on the
+// last one. Pages are positioned by the sort key rather than an offset, so
+// records added or removed meanwhile do not shift the following pages.
+func (s *Service) ListVaults(ctx context.Context, uID uint64, f storage.VaultFilter, token string) ([]storage.VaultRecord, string, error) {
+	return listPage(f, token, func(f storage.VaultFilter) ([]storage.VaultRecord, error) {
+		return s.storage.ListVaults(ctx, uID, f)
+	}, func(v *storage.VaultRecord) storage.VaultRecord { return *v })
+}
+
+// ListVaultSummaries pages the summaries of the records of the user the way
+// ListVaults pages the records.
+func (s *Service) ListVaultSummaries(ctx context.Context, uID uint64, f storage.VaultFilter, token string) ([]storage.VaultSummary, string, error) {
+	return listPage(f, token, func(f storage.VaultFilter) ([]storage.VaultSummary, error) {
+		return s.storage.ListVaultSummaries(ctx, uID, f)
+	}, func(v *storage.VaultSummary) storage.VaultRecord {
+		return storage.VaultRecord{ID: v.ID, Title: v.Title, CreatedAt: v.CreatedAt, UpdatedAt: v.UpdatedAt}
+	})
+}
+
+// FindVaults returns the records of the user having all the tokens, at most
+// one page of the largest size.
+func (s *Service) FindVaults(ctx context.Context, uID uint64, tokens []string) ([]storage.VaultRecord, error) {
+	return s.storage.FindVaults(ctx, uID, tokens, maxListLimit)
+}
+
+// listPage fetches the page of the listing f following the token. pos returns
+// the sort key of an item as a record.
+func listPage[T any](f storage.VaultFilter, token string, fetch func(storage.VaultFilter) ([]T, error), pos func(*T) storage.VaultRecord) ([]T, string, error) {
+	if f.Limit <= 0 {
+		f.Limit = defaultListLimit
+	}
+	f.Limit = min(f.Limit, maxListLimit)
+	if f.Sort == "" {
+		f.Sort = storage.SortByID
+	}
+
+	if token != "" {
+		after, err := decodePageToken(token, f)
+		if err != nil {
+			return nil, "", err
+		}
+		f.After = &after
+	}
+
+	// one more item tells whether there is a next page
+	limit := f.Limit
+	f.Limit++
+	list, err := fetch(f)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(list) <= limit {
+		return list, "", nil
+	}
+
+	list = list[:limit]
+	last := pos(&list[limit-1])
+	return list, encodePageToken(f, &last), nil
+}
+
+// encodePageToken returns the token of the page following the record last.
+func encodePageToken(f storage.VaultFilter, last *storage.VaultRecord) string {
+	t := pageToken{Sort: f.Sort, Desc: f.Desc, ID: last.ID}
+	switch f.Sort {
+	case storage.SortByTitle:
+		t.Title = last.Title
+	case storage.SortByUpdated:
+		t.Time = last.UpdatedAt
+	case storage.SortByCreated:
+		t.Time = last.CreatedAt
+	}
+
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageToken returns the last record of the previous page of the listing f.
+func decodePageToken(token string, f storage.VaultFilter) (storage.VaultRecord, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return storage.VaultRecord{}, errors.Wrap(ErrInvalidPageToken, "not base64")
+	}
+
+	var t pageToken
+	if err = json.Unmarshal(data, &t); err != nil {
+		return storage.VaultRecord{}, errors.Wrap(ErrInvalidPageToken, "malformed")
+	}
+	if t.Sort != f.Sort || t.Desc != f.Desc {
+		return storage.VaultRecord{}, errors.Wrapf(ErrInvalidPageToken, "issued for sort %s", t.Sort)
+	}
+
+	return storage.VaultRecord{ID: t.ID, Title: t.Title, UpdatedAt: t.Time, CreatedAt: t.Time}, nil
+}
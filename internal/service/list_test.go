@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_ListVaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+	ctx := context.Background()
+
+	updated := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	records := []storage.VaultRecord{
+		{ID: 1, UserID: 1, Title: "Email", UpdatedAt: updated.Add(2 * time.Hour)},
+		{ID: 2, UserID: 1, Title: "Secrets", UpdatedAt: updated},
+		{ID: 3, UserID: 1, Title: "Bank", UpdatedAt: updated.Add(-time.Hour)},
+	}
+
+	t.Run("single page with defaults", func(t *testing.T) {
+		mockStorage.EXPECT().
+			ListVaults(gomock.Any(), uint64(1), storage.VaultFilter{Sort: storage.SortByID, Limit: defaultListLimit + 1}).
+			Return(records, nil)
+
+		got, next, err := s.ListVaults(ctx, 1, storage.VaultFilter{}, "")
+		require.NoError(t, err)
+		require.Equal(t, records, got)
+		require.Empty(t, next)
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		f := storage.VaultFilter{Sort: storage.SortByUpdated, Desc: true, Limit: 2}
+
+		mockStorage.EXPECT().
+			ListVaults(gomock.Any(), uint64(1), storage.VaultFilter{Sort: storage.SortByUpdated, Desc: true, Limit: 3}).
+			Return(records, nil)
+
+		got, next, err := s.ListVaults(ctx, 1, f, "")
+		require.NoError(t, err)
+		require.Equal(t, records[:2], got)
+		require.NotEmpty(t, next)
+
+		mockStorage.EXPECT().
+			ListVaults(gomock.Any(), uint64(1), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint64, f storage.VaultFilter) ([]storage.VaultRecord, error) {
+				require.Equal(t, uint64(2), f.After.ID)
+				require.True(t, updated.Equal(f.After.UpdatedAt))
+				return records[2:], nil
+			})
+
+		got, next, err = s.ListVaults(ctx, 1, f, next)
+		require.NoError(t, err)
+		require.Equal(t, records[2:], got)
+		require.Empty(t, next)
+	})
+
+	t.Run("page size is capped", func(t *testing.T) {
+		mockStorage.EXPECT().
+			ListVaults(gomock.Any(), uint64(1), storage.VaultFilter{Sort: storage.SortByID, Limit: maxListLimit + 1}).
+			Return(nil, nil)
+
+		_, _, err := s.ListVaults(ctx, 1, storage.VaultFilter{Limit: 1 << 20}, "")
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid page token", func(t *testing.T) {
+		_, _, err := s.ListVaults(ctx, 1, storage.VaultFilter{}, "%%%")
+		require.ErrorIs(t, err, ErrInvalidPageToken)
+
+		token := encodePageToken(storage.VaultFilter{Sort: storage.SortByTitle}, &records[0])
+		_, _, err = s.ListVaults(ctx, 1, storage.VaultFilter{Sort: storage.SortByUpdated}, token)
+		require.ErrorIs(t, err, ErrInvalidPageToken, "token of another order")
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockStorage.EXPECT().ListVaults(gomock.Any(), uint64(1), gomock.Any()).Return(nil, errors.New("storage error"))
+
+		got, _, err := s.ListVaults(ctx, 1, storage.VaultFilter{}, "")
+		require.Error(t, err)
+		require.Nil(t, got)
+	})
+}
+
+func TestService_ListVaultSummaries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+	ctx := context.Background()
+
+	summaries := []storage.VaultSummary{
+		{ID: 1, Title: "Bank", Size: 10},
+		{ID: 4, Title: "Email", Size: 20},
+		{ID: 2, Title: "Secrets", Size: 30},
+	}
+	f := storage.VaultFilter{Sort: storage.SortByTitle, Limit: 2}
+
+	mockStorage.EXPECT().
+		ListVaultSummaries(gomock.Any(), uint64(1), storage.VaultFilter{Sort: storage.SortByTitle, Limit: 3}).
+		Return(summaries, nil)
+
+	got, next, err := s.ListVaultSummaries(ctx, 1, f, "")
+	require.NoError(t, err)
+	require.Equal(t, summaries[:2], got)
+	require.NotEmpty(t, next)
+
+	mockStorage.EXPECT().
+		ListVaultSummaries(gomock.Any(), uint64(1), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uint64, f storage.VaultFilter) ([]storage.VaultSummary, error) {
+			require.Equal(t, &storage.VaultRecord{ID: 4, Title: "Email"}, f.After)
+			return summaries[2:], nil
+		})
+
+	got, next, err = s.ListVaultSummaries(ctx, 1, f, next)
+	require.NoError(t, err)
+	require.Equal(t, summaries[2:], got)
+	require.Empty(t, next)
+
+	_, _, err = s.ListVaultSummaries(ctx, 1, storage.VaultFilter{}, "%%%")
+	require.ErrorIs(t, err, ErrInvalidPageToken)
+}
+
+func TestService_FindVaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+
+	tokens := []string{"aa", "bb"}
+	vaults := []storage.VaultRecord{{ID: 3, UserID: 1, Title: "GitHub"}}
+	mockStorage.EXPECT().FindVaults(gomock.Any(), uint64(1), tokens, maxListLimit).Return(vaults, nil)
+
+	got, err := s.FindVaults(context.Background(), 1, tokens)
+	require.NoError(t, err)
+	require.Equal(t, vaults, got)
+}
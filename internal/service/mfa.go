@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+)
+
+// recoveryCodeCount is the number of recovery codes issued on enrollment.
+const recoveryCodeCount = 10
+
+// defaultMFAIssuer names the account in authenticator apps when jwt.issuer is not set.
+const defaultMFAIssuer = "GophKeeper"
+
+var (
+	// ErrMFAAlreadyEnabled indicates that the user already confirmed a second factor.
+	ErrMFAAlreadyEnabled = errors.New("mfa already enabled")
+
+	// ErrMFANotEnrolled indicates that the user has no second factor to verify.
+	ErrMFANotEnrolled = errors.New("mfa not enrolled")
+
+	// ErrInvalidMFACode indicates a wrong, expired or replayed code.
+	ErrInvalidMFACode = errors.New("invalid mfa code")
+)
+
+// EnrollMFA generates a new TOTP secret for the user and returns it with its otpauth URI.
+// The secret is not used for logins until ConfirmMFA succeeds.
+func (s *Service) EnrollMFA(ctx context.Context, uID uint64) (string, string, error) {
+	m, err := s.storage.MFA(ctx, uID)
+	if err != nil && !errors.Is(err, storage.ErrMFANotFound) {
+		return "", "", err
+	}
+	if m.Enabled {
+		return "", "", ErrMFAAlreadyEnabled
+	}
+
+	user, err := s.storage.User(ctx, uID)
+	if err != nil {
+		return "", "", errors.Wrap(err, "get user")
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	if err = s.storage.SaveMFA(ctx, &storage.UserMFA{UserID: uID, Secret: secret}); err != nil {
+		return "", "", errors.Wrap(err, "save mfa")
+	}
+
+	return secret, totpURI(s.mfaIssuer(), user.Login, secret), nil
+}
+
+// ConfirmMFA enables the enrolled second factor once the user proves it with a code,
+// and returns fresh recovery codes. Only their hashes are stored.
+func (s *Service) ConfirmMFA(ctx context.Context, uID uint64, code string) ([]string, error) {
+	m, err := s.storage.MFA(ctx, uID)
+	if err != nil {
+		if errors.Is(err, storage.ErrMFANotFound) {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	if m.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if err = s.checkTOTP(ctx, m, code); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = newRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = hashSecret(normalizeRecoveryCode(codes[i]))
+	}
+
+	if err = s.storage.EnableMFA(ctx, uID, hashes); err != nil {
+		return nil, errors.Wrap(err, "enable mfa")
+	}
+
+	return codes, nil
+}
+
+// MFARequired reports whether logins of the user need a second factor.
+func (s *Service) MFARequired(ctx context.Context, uID uint64) (bool, error) {
+	m, err := s.storage.MFA(ctx, uID)
+	if err != nil {
+		if errors.Is(err, storage.ErrMFANotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return m.Enabled, nil
+}
+
+// VerifyMFA checks the second factor of the user: a TOTP code or an unused recovery code.
+func (s *Service) VerifyMFA(ctx context.Context, uID uint64, code string) error {
+	m, err := s.storage.MFA(ctx, uID)
+	if err != nil {
+		if errors.Is(err, storage.ErrMFANotFound) {
+			return ErrMFANotEnrolled
+		}
+		return err
+	}
+	if !m.Enabled {
+		return ErrMFANotEnrolled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totpDigits {
+		return s.checkTOTP(ctx, m, code)
+	}
+
+	err = s.storage.UseRecoveryCode(ctx, uID, hashSecret(normalizeRecoveryCode(code)))
+	if errors.Is(err, storage.ErrRecoveryCodeNotFound) {
+		return ErrInvalidMFACode
+	}
+	return err
+}
+
+// checkTOTP verifies the code and remembers its step so that it cannot be replayed.
+func (s *Service) checkTOTP(ctx context.Context, m storage.UserMFA, code string) error {
+	counter, ok := matchTOTP(m.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	err := s.storage.AdvanceMFACounter(ctx, m.UserID, counter)
+	if errors.Is(err, storage.ErrMFACodeUsed) {
+		return ErrInvalidMFACode
+	}
+	return err
+}
+
+// mfaIssuer returns the issuer shown in authenticator apps.
+func (s *Service) mfaIssuer() string {
+	if s.cfg != nil && s.cfg.JWT.Issuer != "" {
+		return s.cfg.JWT.Issuer
+	}
+	return defaultMFAIssuer
+}
+
+// newRecoveryCode returns a random code formatted as "xxxxx-xxxxx".
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "generate recovery code")
+	}
+
+	code := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode makes codes typed with dashes, spaces or in upper case comparable.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
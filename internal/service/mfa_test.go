@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_EnrollMFA(t *testing.T) {
+	t.Run("stores a pending secret", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		mockStorage.EXPECT().MFA(gomock.Any(), uint64(42)).Return(storage.UserMFA{}, storage.ErrMFANotFound)
+		mockStorage.EXPECT().User(gomock.Any(), uint64(42)).Return(storage.User{ID: 42, Login: "alice"}, nil)
+		mockStorage.EXPECT().
+			SaveMFA(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, m *storage.UserMFA) error {
+				require.Equal(t, uint64(42), m.UserID)
+				require.False(t, m.Enabled)
+				require.NotEmpty(t, m.Secret)
+				return nil
+			})
+
+		secret, uri, err := s.EnrollMFA(context.Background(), 42)
+		require.NoError(t, err)
+		require.NotEmpty(t, secret)
+		require.Contains(t, uri, "otpauth://totp/GophKeeper:alice")
+		require.Contains(t, uri, "secret="+secret)
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		mockStorage.EXPECT().MFA(gomock.Any(), uint64(42)).Return(storage.UserMFA{UserID: 42, Enabled: true}, nil)
+
+		_, _, err := s.EnrollMFA(context.Background(), 42)
+		require.ErrorIs(t, err, ErrMFAAlreadyEnabled)
+	})
+}
+
+func TestService_ConfirmMFA(t *testing.T) {
+	pending := storage.UserMFA{UserID: 42, Secret: rfcSecret}
+
+	t.Run("valid code enables mfa", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		code, err := totpCode(rfcSecret, time.Now().Unix()/30)
+		require.NoError(t, err)
+
+		mockStorage.EXPECT().MFA(gomock.Any(), uint64(42)).Return(pending, nil)
+		mockStorage.EXPECT().AdvanceMFACounter(gomock.Any(), uint64(42), gomock.Any()).Return(nil)
+
+		var stored []string
+		mockStorage.EXPECT().
+			EnableMFA(gomock.Any(), uint64(42), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint64, hashes []string) error {
+				stored = hashes
+				return nil
+			})
+
+		codes, err := s.ConfirmMFA(context.Background(), 42, code)
+		require.NoError(t, err)
+		require.Len(t, codes, recoveryCodeCount)
+		require.Len(t, stored, recoveryCodeCount)
+
+		for i, c := range codes {
+			require.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, c)
+			require.Equal(t, hashSecret(normalizeRecoveryCode(c)), stored[i], "only hashes are stored")
+		}
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		mockStorage.EXPECT().MFA(gomock.Any(), uint64(42)).Return(pending, nil)
+
+		_, err := s.ConfirmMFA(context.Background(), 42, "000000x")
+		require.ErrorIs(t, err, ErrInvalidMFACode)
+	})
+
+	t.Run("not enrolled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		mockStorage.EXPECT().MFA(gomock.Any(), uint64(42)).Return(storage.UserMFA{}, errors.Wrap(storage.ErrMFANotFound, "x"))
+
+		_, err := s.ConfirmMFA(context.Background(), 42, "123456")
+		require.ErrorIs(t, err, ErrMFANotEnrolled)
+	})
+}
+
+func TestService_VerifyMFA(t *testing.T) {
+	enabled := storage.UserMFA{UserID: 42, Secret: rfcSecret, Enabled: true}
+
+	t.Run("totp code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		counter := time.Now().Unix() / 30
+		code, err := totpCode(rfcSecret, counter)
+		require.NoError(t, err)
+
+		mockStorage.EXPECT().MFA(gomock.Any(), uint64(42)).Return(enabled, nil)
+		mockStorage.EXPECT().AdvanceMFACounter(gomock.Any(), uint64(42), counter).Return(nil)
+
+		require.NoError(t, s.VerifyMFA(context.Background(), 42, code))
+	})
+
+	t.Run("replayed totp code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		code, err := totpCode(rfcSecret, time.Now().Unix()/30)
+		require.NoError(t, err)
+
+		mockStorage.EXPECT().MFA(gomock.Any(), uint64(42)).Return(enabled, nil)
+		mockStorage.EXPECT().
+			AdvanceMFACounter(gomock.Any(), uint64(42), gomock.Any()).
+			Return(errors.Wrap(storage.ErrMFACodeUsed, "x"))
+
+		require.ErrorIs(t, s.VerifyMFA(context.Background(), 42, code), ErrInvalidMFACode)
+	})
+
+	t.Run("recovery code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		mockStorage.EXPECT().MFA(gomock.Any(), uint64(42)).Return(enabled, nil)
+		mockStorage.EXPECT().UseRecoveryCode(gomock.Any(), uint64(42), hashSecret("abcdefghij")).Return(nil)
+
+		require.NoError(t, s.VerifyMFA(context.Background(), 42, "ABCDE-FGHIJ"))
+	})
+
+	t.Run("spent recovery code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		mockStorage.EXPECT().MFA(gomock.Any(), uint64(42)).Return(enabled, nil)
+		mockStorage.EXPECT().
+			UseRecoveryCode(gomock.Any(), uint64(42), gomock.Any()).
+			Return(errors.Wrap(storage.ErrRecoveryCodeNotFound, "x"))
+
+		require.ErrorIs(t, s.VerifyMFA(context.Background(), 42, "abcde-fghij"), ErrInvalidMFACode)
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		mockStorage.EXPECT().MFA(gomock.Any(), uint64(42)).Return(storage.UserMFA{UserID: 42, Secret: rfcSecret}, nil)
+
+		require.ErrorIs(t, s.VerifyMFA(context.Background(), 42, "123456"), ErrMFANotEnrolled)
+	})
+}
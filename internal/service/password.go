@@ -0,0 +1,116 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2idPrefix marks password hashes produced by hashPassword.
+const argon2idPrefix = "$argon2id$"
+
+// ErrInvalidPassword indicates that the supplied password does not match the stored hash.
+var ErrInvalidPassword = errors.New("invalid password")
+
+// passwordParams holds the argon2id cost parameters.
+type passwordParams struct {
+	Time    uint32
+	Memory  uint32 // KiB
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
+
+// defaultPasswordParams follows the OWASP recommendation for argon2id.
+var defaultPasswordParams = passwordParams{
+	Time:    2,
+	Memory:  19 * 1024,
+	Threads: 1,
+	SaltLen: 16,
+	KeyLen:  32,
+}
+
+// hashPassword derives an argon2id hash with a random salt and encodes it in PHC format:
+// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>.
+func hashPassword(password string, p passwordParams) (string, error) {
+	salt := make([]byte, p.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(err, "generate salt")
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version, p.Memory, p.Time, p.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// verifyPassword compares the password with the stored hash in constant time.
+// It reports whether the hash should be replaced: either it is a legacy value
+// stored before server-side hashing, or its parameters are weaker than p.
+func verifyPassword(password, encoded string, p passwordParams) (bool, error) {
+	if !strings.HasPrefix(encoded, argon2idPrefix) {
+		// Legacy rows keep the client HMAC as is.
+		if subtle.ConstantTimeCompare([]byte(password), []byte(encoded)) != 1 {
+			return false, ErrInvalidPassword
+		}
+		return true, nil
+	}
+
+	stored, salt, key, err := decodePasswordHash(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, stored.Time, stored.Memory, stored.Threads, stored.KeyLen)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, ErrInvalidPassword
+	}
+
+	rehash := stored.Time < p.Time || stored.Memory < p.Memory || stored.Threads < p.Threads || stored.KeyLen < p.KeyLen
+	return rehash, nil
+}
+
+// decodePasswordHash parses a PHC-formatted argon2id hash.
+func decodePasswordHash(encoded string) (passwordParams, []byte, []byte, error) {
+	var p passwordParams
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, errors.New("malformed password hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, errors.Wrap(err, "parse hash version")
+	}
+	if version != argon2.Version {
+		return p, nil, nil, errors.Errorf("unsupported argon2 version %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return p, nil, nil, errors.Wrap(err, "parse hash params")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, errors.Wrap(err, "decode salt")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, errors.Wrap(err, "decode hash")
+	}
+
+	p.SaltLen = uint32(len(salt))
+	p.KeyLen = uint32(len(key))
+
+	return p, salt, key, nil
+}
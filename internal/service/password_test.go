@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+// cheapParams keeps argon2id fast in tests.
+var cheapParams = passwordParams{Time: 1, Memory: 64, Threads: 1, SaltLen: 16, KeyLen: 32}
+
+func TestHashPassword(t *testing.T) {
+	t.Run("hash is salted and verifiable", func(t *testing.T) {
+		h1, err := hashPassword("secret", cheapParams)
+		require.NoError(t, err)
+		h2, err := hashPassword("secret", cheapParams)
+		require.NoError(t, err)
+
+		require.True(t, strings.HasPrefix(h1, argon2idPrefix))
+		require.NotEqual(t, h1, h2)
+		require.NotContains(t, h1, "secret")
+
+		rehash, err := verifyPassword("secret", h1, cheapParams)
+		require.NoError(t, err)
+		require.False(t, rehash)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		h, err := hashPassword("secret", cheapParams)
+		require.NoError(t, err)
+
+		_, err = verifyPassword("other", h, cheapParams)
+		require.ErrorIs(t, err, ErrInvalidPassword)
+	})
+
+	t.Run("weaker params ask for rehash", func(t *testing.T) {
+		h, err := hashPassword("secret", cheapParams)
+		require.NoError(t, err)
+
+		stronger := cheapParams
+		stronger.Time = 2
+
+		rehash, err := verifyPassword("secret", h, stronger)
+		require.NoError(t, err)
+		require.True(t, rehash)
+	})
+
+	t.Run("legacy plain value", func(t *testing.T) {
+		rehash, err := verifyPassword("hmac-hex", "hmac-hex", cheapParams)
+		require.NoError(t, err)
+		require.True(t, rehash)
+
+		_, err = verifyPassword("wrong", "hmac-hex", cheapParams)
+		require.ErrorIs(t, err, ErrInvalidPassword)
+	})
+
+	t.Run("malformed hash", func(t *testing.T) {
+		_, err := verifyPassword("secret", "$argon2id$broken", cheapParams)
+		require.Error(t, err)
+	})
+}
+
+func TestService_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage, password: cheapParams}
+
+	mockStorage.
+		EXPECT().
+		NewUser(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, u *storage.User) (storage.User, error) {
+			require.Equal(t, "alice", u.Login)
+			require.True(t, strings.HasPrefix(u.PasswordHash, argon2idPrefix))
+			u.ID = 1
+			return *u, nil
+		})
+
+	user, err := s.Register(context.Background(), "alice", "client-hmac")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), user.ID)
+}
+
+func TestService_Authenticate(t *testing.T) {
+	hash, err := hashPassword("client-hmac", cheapParams)
+	require.NoError(t, err)
+
+	t.Run("valid password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage, password: cheapParams}
+
+		mockStorage.EXPECT().UserByLogin(gomock.Any(), "alice").
+			Return(storage.User{ID: 1, Login: "alice", PasswordHash: hash}, nil)
+
+		user, err := s.Authenticate(context.Background(), "alice", "client-hmac")
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), user.ID)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage, password: cheapParams}
+
+		mockStorage.EXPECT().UserByLogin(gomock.Any(), "alice").
+			Return(storage.User{ID: 1, Login: "alice", PasswordHash: hash}, nil)
+
+		_, err := s.Authenticate(context.Background(), "alice", "guess")
+		require.ErrorIs(t, err, ErrInvalidPassword)
+	})
+
+	t.Run("legacy row is rehashed on login", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage, password: cheapParams}
+
+		mockStorage.EXPECT().UserByLogin(gomock.Any(), "bob").
+			Return(storage.User{ID: 2, Login: "bob", PasswordHash: "client-hmac"}, nil)
+		mockStorage.EXPECT().UpdatePasswordHash(gomock.Any(), uint64(2), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint64, h string) error {
+				rehash, err := verifyPassword("client-hmac", h, cheapParams)
+				require.NoError(t, err)
+				require.False(t, rehash)
+				return nil
+			})
+
+		user, err := s.Authenticate(context.Background(), "bob", "client-hmac")
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(user.PasswordHash, argon2idPrefix))
+	})
+
+	t.Run("legacy row with wrong password is left untouched", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage, password: cheapParams}
+
+		mockStorage.EXPECT().UserByLogin(gomock.Any(), "bob").
+			Return(storage.User{ID: 2, Login: "bob", PasswordHash: "client-hmac"}, nil)
+
+		_, err := s.Authenticate(context.Background(), "bob", "guess")
+		require.ErrorIs(t, err, ErrInvalidPassword)
+	})
+}
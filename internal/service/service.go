@@ -2,11 +2,14 @@
 package service
 
 import (
+	"sync"
+
 	"github.com/pkg/errors"
 	"github.com/samber/do/v2"
 
 	"github.com/wickedv43/go-goph-keeper/internal/config"
 	"github.com/wickedv43/go-goph-keeper/internal/logger"
+	"github.com/wickedv43/go-goph-keeper/internal/pubsub"
 	"github.com/wickedv43/go-goph-keeper/internal/storage"
 	"go.uber.org/zap"
 )
@@ -16,6 +19,12 @@ type Service struct {
 	cfg     *config.Config     // Configuration settings.
 	logger  *zap.SugaredLogger // Structured logger.
 	storage storage.DataKeeper // Interface to storage layer.
+	events  pubsub.Broker      // Delivers vault changes to watching sessions.
+
+	password passwordParams // Cost parameters for password hashing.
+
+	dummyOnce sync.Once // Guards dummyHash.
+	dummyHash string    // Hash checked for unknown logins to keep timing uniform.
 }
 
 // NewService constructs a new Service instance using dependency injection.
@@ -28,6 +37,12 @@ func NewService(i do.Injector) (*Service, error) {
 	u.cfg = do.MustInvoke[*config.Config](i)
 	u.logger = do.MustInvoke[*logger.Logger](i).Named("service")
 	u.storage = do.MustInvoke[storage.DataKeeper](i)
+	u.password = defaultPasswordParams
+
+	u.events, err = newBroker(i, u.cfg.Watch)
+	if err != nil {
+		return nil, errors.Wrap(err, "init event broker")
+	}
 
 	return u, nil
 }
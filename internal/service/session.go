@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+)
+
+// defaultRefreshTTL is used when the config does not set jwt.refreshTTL.
+const defaultRefreshTTL = 30 * 24 * time.Hour
+
+var (
+	// ErrInvalidRefreshToken indicates a malformed, unknown or expired refresh token.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+	// ErrRefreshTokenReused indicates that an already rotated refresh token was presented again.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+
+	// ErrSessionRevoked indicates that the session was revoked or has expired.
+	ErrSessionRevoked = errors.New("session revoked")
+)
+
+// refreshToken is the decoded form of "<session id>.<generation>.<family secret>.<secret>".
+//
+// The family secret is the same for every token of a session and proves that
+// the token was really issued for it; the secret changes on every rotation.
+type refreshToken struct {
+	sessionID  uint64
+	generation uint64
+	family     string
+	secret     string
+}
+
+func (t refreshToken) String() string {
+	return fmt.Sprintf("%d.%d.%s.%s", t.sessionID, t.generation, t.family, t.secret)
+}
+
+// parseRefreshToken splits a refresh token into its parts.
+func parseRefreshToken(s string) (refreshToken, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 4 {
+		return refreshToken{}, ErrInvalidRefreshToken
+	}
+
+	sID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return refreshToken{}, ErrInvalidRefreshToken
+	}
+
+	gen, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return refreshToken{}, ErrInvalidRefreshToken
+	}
+
+	return refreshToken{sessionID: sID, generation: gen, family: parts[2], secret: parts[3]}, nil
+}
+
+// StartSession opens a session for the user on the device and returns it with its first refresh token.
+func (s *Service) StartSession(ctx context.Context, uID uint64, device storage.Device) (storage.Session, string, error) {
+	family, err := randomSecret()
+	if err != nil {
+		return storage.Session{}, "", err
+	}
+
+	secret, err := randomSecret()
+	if err != nil {
+		return storage.Session{}, "", err
+	}
+
+	now := time.Now()
+	sess := &storage.Session{
+		UserID:      uID,
+		FamilyHash:  hashSecret(family),
+		RefreshHash: hashSecret(secret),
+		ExpiresAt:   now.Add(s.refreshTTL()),
+		Device:      device,
+		LastSeenAt:  now,
+	}
+	if err = s.storage.CreateSession(ctx, sess); err != nil {
+		return storage.Session{}, "", errors.Wrap(err, "create session")
+	}
+
+	token := refreshToken{sessionID: sess.ID, family: family, secret: secret}
+	return *sess, token.String(), nil
+}
+
+// RefreshSession rotates the refresh token of a session.
+// Presenting a token that was already rotated revokes the whole session,
+// since it means that the token has leaked.
+func (s *Service) RefreshSession(ctx context.Context, token string) (storage.Session, string, error) {
+	rt, err := parseRefreshToken(token)
+	if err != nil {
+		return storage.Session{}, "", err
+	}
+
+	sess, err := s.storage.Session(ctx, rt.sessionID)
+	if err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			return storage.Session{}, "", ErrInvalidRefreshToken
+		}
+		return storage.Session{}, "", err
+	}
+
+	if !equalHash(sess.FamilyHash, rt.family) {
+		return storage.Session{}, "", ErrInvalidRefreshToken
+	}
+
+	if !sess.Active(time.Now()) {
+		return storage.Session{}, "", ErrSessionRevoked
+	}
+
+	if rt.generation < sess.Generation {
+		if err = s.storage.RevokeSession(ctx, sess.ID); err != nil {
+			return storage.Session{}, "", errors.Wrap(err, "revoke session")
+		}
+		return storage.Session{}, "", ErrRefreshTokenReused
+	}
+
+	if rt.generation != sess.Generation || !equalHash(sess.RefreshHash, rt.secret) {
+		return storage.Session{}, "", ErrInvalidRefreshToken
+	}
+
+	secret, err := randomSecret()
+	if err != nil {
+		return storage.Session{}, "", err
+	}
+
+	expiresAt := time.Now().Add(s.refreshTTL())
+	if err = s.storage.RotateSession(ctx, sess.ID, sess.Generation, hashSecret(secret), expiresAt); err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			// Someone rotated or revoked the session in between.
+			return storage.Session{}, "", ErrRefreshTokenReused
+		}
+		return storage.Session{}, "", errors.Wrap(err, "rotate session")
+	}
+
+	sess.Generation++
+	sess.RefreshHash = hashSecret(secret)
+	sess.ExpiresAt = expiresAt
+
+	next := refreshToken{sessionID: sess.ID, generation: sess.Generation, family: rt.family, secret: secret}
+	return sess, next.String(), nil
+}
+
+// CheckSession verifies that the session belongs to the user and is still active.
+func (s *Service) CheckSession(ctx context.Context, uID, sID uint64) (storage.Session, error) {
+	sess, err := s.storage.Session(ctx, sID)
+	if err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			return storage.Session{}, ErrSessionRevoked
+		}
+		return storage.Session{}, err
+	}
+
+	if sess.UserID != uID || !sess.Active(time.Now()) {
+		return storage.Session{}, ErrSessionRevoked
+	}
+
+	return sess, nil
+}
+
+// TouchSession records that the session sID was used just now from the address ip.
+func (s *Service) TouchSession(ctx context.Context, sID uint64, ip string) error {
+	return s.storage.TouchSession(ctx, sID, ip, time.Now())
+}
+
+// ListSessions returns active sessions of the user.
+func (s *Service) ListSessions(ctx context.Context, uID uint64) ([]storage.Session, error) {
+	return s.storage.ListSessions(ctx, uID)
+}
+
+// RevokeSession ends the session sID of the user uID.
+func (s *Service) RevokeSession(ctx context.Context, uID, sID uint64) error {
+	sess, err := s.storage.Session(ctx, sID)
+	if err != nil {
+		return err
+	}
+
+	if sess.UserID != uID {
+		return errors.Wrapf(storage.ErrSessionNotFound, "id=%d", sID)
+	}
+
+	return s.storage.RevokeSession(ctx, sID)
+}
+
+// refreshTTL returns the configured refresh token lifetime or the default.
+func (s *Service) refreshTTL() time.Duration {
+	if s.cfg != nil && s.cfg.JWT.RefreshTTL > 0 {
+		return s.cfg.JWT.RefreshTTL
+	}
+	return defaultRefreshTTL
+}
+
+// randomSecret returns 32 random bytes encoded as URL-safe base64.
+func randomSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "generate secret")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashSecret returns the hex SHA-256 of the secret.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// equalHash compares the stored hash with the hash of the secret in constant time.
+func equalHash(stored, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(hashSecret(secret))) == 1
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_StartSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+
+	mockStorage.
+		EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, sess *storage.Session) error {
+			sess.ID = 5
+			return nil
+		})
+
+	device := storage.Device{Name: "laptop", ClientVersion: "v1.0.0", IP: "10.0.0.1"}
+
+	sess, token, err := s.StartSession(context.Background(), 42, device)
+	require.NoError(t, err)
+	require.Equal(t, device, sess.Device)
+	require.WithinDuration(t, time.Now(), sess.LastSeenAt, time.Minute)
+	require.Equal(t, uint64(5), sess.ID)
+	require.Equal(t, uint64(42), sess.UserID)
+	require.WithinDuration(t, time.Now().Add(defaultRefreshTTL), sess.ExpiresAt, time.Minute)
+
+	rt, err := parseRefreshToken(token)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), rt.sessionID)
+	require.Equal(t, uint64(0), rt.generation)
+	require.True(t, equalHash(sess.FamilyHash, rt.family))
+	require.True(t, equalHash(sess.RefreshHash, rt.secret))
+}
+
+func TestService_RefreshSession(t *testing.T) {
+	current := refreshToken{sessionID: 5, generation: 2, family: "family", secret: "secret"}
+	active := storage.Session{
+		ID:          5,
+		UserID:      42,
+		FamilyHash:  hashSecret("family"),
+		RefreshHash: hashSecret("secret"),
+		Generation:  2,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	t.Run("rotates the token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		mockStorage.EXPECT().Session(gomock.Any(), uint64(5)).Return(active, nil)
+		mockStorage.EXPECT().RotateSession(gomock.Any(), uint64(5), uint64(2), gomock.Any(), gomock.Any()).Return(nil)
+
+		sess, token, err := s.RefreshSession(context.Background(), current.String())
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), sess.Generation)
+
+		next, err := parseRefreshToken(token)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), next.generation)
+		require.Equal(t, "family", next.family)
+		require.NotEqual(t, "secret", next.secret)
+	})
+
+	t.Run("reused token revokes the session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		old := current
+		old.generation = 1
+
+		mockStorage.EXPECT().Session(gomock.Any(), uint64(5)).Return(active, nil)
+		mockStorage.EXPECT().RevokeSession(gomock.Any(), uint64(5)).Return(nil)
+
+		_, _, err := s.RefreshSession(context.Background(), old.String())
+		require.ErrorIs(t, err, ErrRefreshTokenReused)
+	})
+
+	t.Run("concurrent rotation counts as reuse", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		mockStorage.EXPECT().Session(gomock.Any(), uint64(5)).Return(active, nil)
+		mockStorage.EXPECT().
+			RotateSession(gomock.Any(), uint64(5), uint64(2), gomock.Any(), gomock.Any()).
+			Return(storage.ErrSessionNotFound)
+
+		_, _, err := s.RefreshSession(context.Background(), current.String())
+		require.ErrorIs(t, err, ErrRefreshTokenReused)
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		revoked := active
+		now := time.Now()
+		revoked.RevokedAt = &now
+
+		mockStorage.EXPECT().Session(gomock.Any(), uint64(5)).Return(revoked, nil)
+
+		_, _, err := s.RefreshSession(context.Background(), current.String())
+		require.ErrorIs(t, err, ErrSessionRevoked)
+	})
+
+	t.Run("wrong family", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := mocks.NewMockDataKeeper(ctrl)
+		s := &Service{storage: mockStorage}
+
+		forged := current
+		forged.family = "other"
+
+		mockStorage.EXPECT().Session(gomock.Any(), uint64(5)).Return(active, nil)
+
+		_, _, err := s.RefreshSession(context.Background(), forged.String())
+		require.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+
+	t.Run("malformed token", func(t *testing.T) {
+		s := &Service{}
+
+		_, _, err := s.RefreshSession(context.Background(), "garbage")
+		require.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+}
+
+func TestService_CheckSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+
+	active := storage.Session{ID: 5, UserID: 42, ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("active session", func(t *testing.T) {
+		mockStorage.EXPECT().Session(gomock.Any(), uint64(5)).Return(active, nil)
+		sess, err := s.CheckSession(context.Background(), 42, 5)
+		require.NoError(t, err)
+		require.Equal(t, active, sess)
+	})
+
+	t.Run("foreign session", func(t *testing.T) {
+		mockStorage.EXPECT().Session(gomock.Any(), uint64(5)).Return(active, nil)
+		_, err := s.CheckSession(context.Background(), 7, 5)
+		require.ErrorIs(t, err, ErrSessionRevoked)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		mockStorage.EXPECT().Session(gomock.Any(), uint64(6)).Return(storage.Session{}, storage.ErrSessionNotFound)
+		_, err := s.CheckSession(context.Background(), 42, 6)
+		require.ErrorIs(t, err, ErrSessionRevoked)
+	})
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+)
+
+// Sync page sizes.
+const (
+	defaultSyncLimit = 100
+	maxSyncLimit     = 500
+)
+
+// Sync returns the changes of the user's records after the cursor, at most limit of them.
+// A zero cursor, or one the server has never issued, starts a full sync from scratch.
+func (s *Service) Sync(ctx context.Context, uID, since uint64, limit int) (storage.VaultSync, error) {
+	if limit <= 0 {
+		limit = defaultSyncLimit
+	}
+	limit = min(limit, maxSyncLimit)
+
+	res := storage.VaultSync{Reset: since == 0}
+	if since > 0 {
+		last, err := s.storage.LastVaultChange(ctx, uID)
+		if err != nil {
+			return storage.VaultSync{}, errors.Wrap(err, "last change")
+		}
+		// the cursor comes from another server or from before a database restore
+		if since > last {
+			since, res.Reset = 0, true
+		}
+	}
+	res.Cursor = since
+
+	changes, err := s.storage.VaultChanges(ctx, uID, since, limit+1)
+	if err != nil {
+		return storage.VaultSync{}, errors.Wrap(err, "list changes")
+	}
+	if len(changes) > limit {
+		changes, res.HasMore = changes[:limit], true
+	}
+
+	var ids []uint64
+	for _, c := range changes {
+		if !c.Deleted {
+			ids = append(ids, c.VaultID)
+		}
+	}
+
+	records, err := s.storage.VaultsByID(ctx, ids)
+	if err != nil {
+		return storage.VaultSync{}, errors.Wrap(err, "load changed records")
+	}
+
+	byID := make(map[uint64]storage.VaultRecord, len(records))
+	for _, v := range records {
+		if v.UserID == uID {
+			byID[v.ID] = v
+		}
+	}
+
+	for _, c := range changes {
+		res.Cursor = c.Seq
+
+		v, ok := byID[c.VaultID]
+		if c.Deleted || !ok {
+			// a record trashed after its change was read gets a tombstone of its own later
+			res.Deleted = append(res.Deleted, c.VaultID)
+			continue
+		}
+		res.Changed = append(res.Changed, v)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Sync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+	ctx := context.Background()
+
+	t.Run("full sync", func(t *testing.T) {
+		mockStorage.EXPECT().VaultChanges(gomock.Any(), uint64(1), uint64(0), defaultSyncLimit+1).
+			Return([]storage.VaultChange{
+				{Seq: 3, UserID: 1, VaultID: 10},
+				{Seq: 5, UserID: 1, VaultID: 11, Deleted: true},
+				{Seq: 8, UserID: 1, VaultID: 12},
+			}, nil)
+		mockStorage.EXPECT().VaultsByID(gomock.Any(), []uint64{10, 12}).
+			Return([]storage.VaultRecord{{ID: 12, UserID: 1}, {ID: 10, UserID: 1}}, nil)
+
+		res, err := s.Sync(ctx, 1, 0, 0)
+		require.NoError(t, err)
+		require.True(t, res.Reset)
+		require.False(t, res.HasMore)
+		require.Equal(t, uint64(8), res.Cursor)
+		require.Equal(t, []uint64{11}, res.Deleted)
+		require.Len(t, res.Changed, 2)
+		require.Equal(t, uint64(10), res.Changed[0].ID, "records follow the feed order")
+	})
+
+	t.Run("incremental page", func(t *testing.T) {
+		mockStorage.EXPECT().LastVaultChange(gomock.Any(), uint64(1)).Return(uint64(20), nil)
+		mockStorage.EXPECT().VaultChanges(gomock.Any(), uint64(1), uint64(8), 3).
+			Return([]storage.VaultChange{
+				{Seq: 9, UserID: 1, VaultID: 10},
+				{Seq: 12, UserID: 1, VaultID: 13},
+				{Seq: 20, UserID: 1, VaultID: 14},
+			}, nil)
+		mockStorage.EXPECT().VaultsByID(gomock.Any(), []uint64{10, 13}).
+			Return([]storage.VaultRecord{{ID: 10, UserID: 1}}, nil)
+
+		res, err := s.Sync(ctx, 1, 8, 2)
+		require.NoError(t, err)
+		require.False(t, res.Reset)
+		require.True(t, res.HasMore)
+		require.Equal(t, uint64(12), res.Cursor)
+		require.Len(t, res.Changed, 1)
+		require.Equal(t, []uint64{13}, res.Deleted, "a record gone since its change is a tombstone")
+	})
+
+	t.Run("unknown cursor resets", func(t *testing.T) {
+		mockStorage.EXPECT().LastVaultChange(gomock.Any(), uint64(1)).Return(uint64(4), nil)
+		mockStorage.EXPECT().VaultChanges(gomock.Any(), uint64(1), uint64(0), maxSyncLimit+1).Return(nil, nil)
+		mockStorage.EXPECT().VaultsByID(gomock.Any(), nil).Return(nil, nil)
+
+		res, err := s.Sync(ctx, 1, 99, 10000)
+		require.NoError(t, err)
+		require.True(t, res.Reset)
+		require.Zero(t, res.Cursor)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockStorage.EXPECT().VaultChanges(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("db down"))
+
+		_, err := s.Sync(ctx, 1, 0, 10)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the RFC 6238 SHA-1 test key "12345678901234567890" in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_RFC6238(t *testing.T) {
+	// Test vectors of RFC 6238, appendix B, truncated to 6 digits.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tc := range tests {
+		code, err := totpCode(rfcSecret, tc.unix/30)
+		require.NoError(t, err)
+		require.Equal(t, tc.code, code, "T=%d", tc.unix)
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	t.Run("current step", func(t *testing.T) {
+		counter, ok := matchTOTP(rfcSecret, "081804", now)
+		require.True(t, ok)
+		require.Equal(t, int64(1111111109/30), counter)
+	})
+
+	t.Run("clock skew of one step", func(t *testing.T) {
+		_, ok := matchTOTP(rfcSecret, "081804", now.Add(30*time.Second))
+		require.True(t, ok)
+	})
+
+	t.Run("too old", func(t *testing.T) {
+		_, ok := matchTOTP(rfcSecret, "081804", now.Add(2*time.Minute))
+		require.False(t, ok)
+	})
+
+	t.Run("wrong length", func(t *testing.T) {
+		_, ok := matchTOTP(rfcSecret, "81804", now)
+		require.False(t, ok)
+	})
+}
+
+func TestTOTPURI(t *testing.T) {
+	u, err := url.Parse(totpURI("GophKeeper", "alice", "SECRET"))
+	require.NoError(t, err)
+
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/GophKeeper:alice", u.Path)
+	require.Equal(t, "SECRET", u.Query().Get("secret"))
+	require.Equal(t, "GophKeeper", u.Query().Get("issuer"))
+}
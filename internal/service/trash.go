@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/pubsub"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+)
+
+// ListTrash returns trashed records of the user.
+func (s *Service) ListTrash(ctx context.Context, uID uint64) ([]storage.VaultRecord, error) {
+	return s.storage.ListTrash(ctx, uID)
+}
+
+// RestoreVault moves a trashed record of the user back to the vault.
+func (s *Service) RestoreVault(ctx context.Context, uID, vID uint64) error {
+	v, err := s.trashedVault(ctx, uID, vID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.storage.RestoreVault(ctx, vID); err != nil {
+		return err
+	}
+
+	s.publish(ctx, uID, vID, v.Revision, pubsub.OpRestore)
+	return nil
+}
+
+// PurgeVault permanently removes a trashed record of the user.
+func (s *Service) PurgeVault(ctx context.Context, uID, vID uint64) error {
+	if _, err := s.trashedVault(ctx, uID, vID); err != nil {
+		return err
+	}
+
+	return s.storage.PurgeVault(ctx, vID)
+}
+
+// PurgeTrash permanently removes records trashed before the given time.
+func (s *Service) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	return s.storage.PurgeTrash(ctx, before)
+}
+
+// trashedVault loads a trashed record and reports records of other users as missing.
+func (s *Service) trashedVault(ctx context.Context, uID, vID uint64) (storage.VaultRecord, error) {
+	v, err := s.storage.TrashedVault(ctx, vID)
+	if err != nil {
+		return storage.VaultRecord{}, err
+	}
+
+	if v.UserID != uID {
+		return storage.VaultRecord{}, errors.Wrapf(storage.ErrVaultNotFound, "id=%d", vID)
+	}
+
+	return v, nil
+}
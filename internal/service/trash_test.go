@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Trash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage}
+	ctx := context.Background()
+
+	trashed := storage.VaultRecord{ID: 5, UserID: 1}
+
+	t.Run("list", func(t *testing.T) {
+		mockStorage.EXPECT().ListTrash(gomock.Any(), uint64(1)).Return([]storage.VaultRecord{trashed}, nil)
+
+		list, err := s.ListTrash(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		mockStorage.EXPECT().TrashedVault(gomock.Any(), uint64(5)).Return(trashed, nil)
+		mockStorage.EXPECT().RestoreVault(gomock.Any(), uint64(5)).Return(nil)
+
+		require.NoError(t, s.RestoreVault(ctx, 1, 5))
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		mockStorage.EXPECT().TrashedVault(gomock.Any(), uint64(5)).Return(trashed, nil)
+		mockStorage.EXPECT().PurgeVault(gomock.Any(), uint64(5)).Return(nil)
+
+		require.NoError(t, s.PurgeVault(ctx, 1, 5))
+	})
+
+	t.Run("record of another user", func(t *testing.T) {
+		mockStorage.EXPECT().TrashedVault(gomock.Any(), uint64(5)).Return(trashed, nil).Times(2)
+
+		require.ErrorIs(t, s.RestoreVault(ctx, 2, 5), storage.ErrVaultNotFound)
+		require.ErrorIs(t, s.PurgeVault(ctx, 2, 5), storage.ErrVaultNotFound)
+	})
+
+	t.Run("not in trash", func(t *testing.T) {
+		mockStorage.EXPECT().TrashedVault(gomock.Any(), uint64(6)).Return(storage.VaultRecord{}, storage.ErrVaultNotFound)
+
+		require.ErrorIs(t, s.PurgeVault(ctx, 1, 6), storage.ErrVaultNotFound)
+	})
+
+	t.Run("purge expired", func(t *testing.T) {
+		before := time.Now()
+		mockStorage.EXPECT().PurgeTrash(gomock.Any(), before).Return(int64(3), nil)
+
+		n, err := s.PurgeTrash(ctx, before)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), n)
+	})
+}
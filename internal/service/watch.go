@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/samber/do/v2"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+	"github.com/wickedv43/go-goph-keeper/internal/logger"
+	"github.com/wickedv43/go-goph-keeper/internal/pubsub"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+)
+
+// newBroker returns the event broker selected by watch.backend.
+func newBroker(i do.Injector, cfg config.Watch) (pubsub.Broker, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return pubsub.NewMemory(), nil
+	case "postgres":
+		log := do.MustInvoke[*logger.Logger](i).Named("pubsub")
+		return pubsub.NewPostgres(do.MustInvoke[storage.DataKeeper](i), log), nil
+	default:
+		return nil, errors.Errorf("unknown watch backend %q", cfg.Backend)
+	}
+}
+
+// Watch returns the changes of the user's records until cancel is called.
+func (s *Service) Watch(_ context.Context, uID uint64) (<-chan pubsub.Event, func()) {
+	return s.events.Subscribe(uID)
+}
+
+// publish tells the watchers of the user about a change of the record. A lost
+// event only delays their update until the next Sync, so a failure to publish
+// does not fail the change itself.
+func (s *Service) publish(ctx context.Context, uID, vID, revision uint64, op string) {
+	if s.events == nil {
+		return
+	}
+
+	ev := pubsub.Event{UserID: uID, VaultID: vID, Revision: revision, Op: op, Origin: pubsub.Origin(ctx)}
+	if err := s.events.Publish(ctx, ev); err != nil {
+		s.logger.Warnf("publish %s of vault %d: %v", op, vID, err)
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/do/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+	"github.com/wickedv43/go-goph-keeper/internal/mocks"
+	"github.com/wickedv43/go-goph-keeper/internal/pubsub"
+	"github.com/wickedv43/go-goph-keeper/internal/storage"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestService_Watch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockDataKeeper(ctrl)
+	s := &Service{storage: mockStorage, events: pubsub.NewMemory(), logger: zap.NewNop().Sugar()}
+	ctx := pubsub.WithOrigin(context.Background(), 9)
+
+	events, cancel := s.Watch(ctx, 1)
+	defer cancel()
+
+	t.Run("create", func(t *testing.T) {
+		mockStorage.EXPECT().CreateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *storage.VaultRecord) error {
+				v.ID, v.Revision = 5, 1
+				return nil
+			})
+
+		require.NoError(t, s.CreateVault(ctx, &storage.VaultRecord{UserID: 1}))
+		require.Equal(t, pubsub.Event{UserID: 1, VaultID: 5, Revision: 1, Op: pubsub.OpCreate, Origin: 9}, <-events)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		mockStorage.EXPECT().GetVault(gomock.Any(), uint64(5)).Return(storage.VaultRecord{ID: 5, UserID: 1, Revision: 1}, nil)
+		mockStorage.EXPECT().UpdateVault(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *storage.VaultRecord) error {
+				v.Revision = 2
+				return nil
+			})
+
+		require.NoError(t, s.UpdateVault(ctx, &storage.VaultRecord{ID: 5, UserID: 1, Revision: 1}))
+		require.Equal(t, pubsub.Event{UserID: 1, VaultID: 5, Revision: 2, Op: pubsub.OpUpdate, Origin: 9}, <-events)
+	})
+
+	t.Run("delete and restore", func(t *testing.T) {
+		mockStorage.EXPECT().GetVault(gomock.Any(), uint64(5)).Return(storage.VaultRecord{ID: 5, UserID: 1}, nil)
+		mockStorage.EXPECT().DeleteVault(gomock.Any(), uint64(5)).Return(nil)
+		mockStorage.EXPECT().TrashedVault(gomock.Any(), uint64(5)).Return(storage.VaultRecord{ID: 5, UserID: 1, Revision: 2}, nil)
+		mockStorage.EXPECT().RestoreVault(gomock.Any(), uint64(5)).Return(nil)
+
+		require.NoError(t, s.DeleteVault(ctx, 1, 5))
+		require.NoError(t, s.RestoreVault(ctx, 1, 5))
+		require.Equal(t, pubsub.OpDelete, (<-events).Op)
+		require.Equal(t, pubsub.Event{UserID: 1, VaultID: 5, Revision: 2, Op: pubsub.OpRestore, Origin: 9}, <-events)
+	})
+
+	t.Run("failed change is not published", func(t *testing.T) {
+		mockStorage.EXPECT().GetVault(gomock.Any(), uint64(5)).Return(storage.VaultRecord{ID: 5, UserID: 1, Revision: 3}, nil)
+
+		require.ErrorIs(t, s.UpdateVault(ctx, &storage.VaultRecord{ID: 5, UserID: 1, Revision: 2}), storage.ErrRevisionConflict)
+		require.Empty(t, events)
+	})
+
+	t.Run("shutdown ends watching", func(t *testing.T) {
+		require.NoError(t, s.Shutdown())
+		_, ok := <-events
+		require.False(t, ok)
+	})
+}
+
+func TestNewBroker(t *testing.T) {
+	i := do.New()
+
+	b, err := newBroker(i, config.Watch{})
+	require.NoError(t, err)
+	require.IsType(t, &pubsub.Memory{}, b)
+
+	_, err = newBroker(i, config.Watch{Backend: "redis"})
+	require.Error(t, err)
+}
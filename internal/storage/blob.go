@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Blob is an encrypted file uploaded in chunks. It is kept out of the vault
+// record, which refers to it by Ref in BlobRef. A blob no record or version
+// refers to any more is removed by CollectBlobs.
+type Blob struct {
+	ID        uint64    `gorm:"primaryKey"`
+	UserID    uint64    `gorm:"not null;uniqueIndex:idx_blob_ref"`
+	Ref       string    `gorm:"size:64;not null;uniqueIndex:idx_blob_ref"` // chosen by the client
+	Header    []byte    `gorm:"not null"`                                  // header of the encrypted stream
+	Size      int64     `gorm:"not null"`                                  // plaintext size in bytes
+	Chunks    uint32    `gorm:"not null"`                                  // number of chunks when complete
+	Received  uint32    `gorm:"not null;default:0"`                        // chunks stored so far
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// Complete reports whether all chunks of the blob have been uploaded.
+func (b Blob) Complete() bool {
+	return b.Received == b.Chunks
+}
+
+// BlobChunk is one encrypted chunk of a blob. Its bytes are kept in the
+// object store under Hash, the key derived from them by objectKey.
+type BlobChunk struct {
+	BlobID uint64 `gorm:"primaryKey;autoIncrement:false"`
+	Blob   Blob   `gorm:"constraint:OnDelete:CASCADE"`
+	Seq    uint32 `gorm:"primaryKey;autoIncrement:false"`
+	Hash   string `gorm:"size:64;index"`
+}
+
+// chunkBatch is the number of rows handled at once when chunks are moved or collected.
+const chunkBatch = 500
+
+// CreateBlob stores the description of a new upload.
+func (s *Storage) CreateBlob(ctx context.Context, b *Blob) error {
+	b.Received = 0
+	return s.db.WithContext(ctx).Create(b).Error
+}
+
+// Blob retrieves a blob of the user by its reference.
+func (s *Storage) Blob(ctx context.Context, uID uint64, ref string) (Blob, error) {
+	var b Blob
+	err := s.db.WithContext(ctx).First(&b, "user_id = ? AND ref = ?", uID, ref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return b, errors.Wrapf(ErrBlobNotFound, "ref=%s", ref)
+	}
+	return b, err
+}
+
+// PutBlobChunk stores the next chunk of an upload. Chunks must arrive in
+// order: seq has to be the number of chunks received so far. The bytes are
+// written to the object store first; if the chunk is then rejected they stay
+// there unreferenced until CollectObjects removes them.
+func (s *Storage) PutBlobChunk(ctx context.Context, blobID uint64, seq uint32, data []byte) error {
+	key := objectKey(data)
+	if err := s.objects.Put(ctx, key, data); err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Blob{}).
+			Where("id = ? AND received = ? AND received < chunks", blobID, seq).
+			Updates(map[string]any{
+				"received":   gorm.Expr("received + 1"),
+				"updated_at": time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.Wrapf(ErrChunkOutOfOrder, "blob=%d seq=%d", blobID, seq)
+		}
+
+		return tx.Omit("Blob").Create(&BlobChunk{BlobID: blobID, Seq: seq, Hash: key}).Error
+	})
+}
+
+// BlobChunk retrieves one chunk of a blob.
+func (s *Storage) BlobChunk(ctx context.Context, blobID uint64, seq uint32) ([]byte, error) {
+	var c BlobChunk
+	err := s.db.WithContext(ctx).
+		Select("hash").
+		First(&c, "blob_id = ? AND seq = ?", blobID, seq).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(ErrBlobNotFound, "blob=%d seq=%d", blobID, seq)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.objects.Get(ctx, c.Hash)
+}
+
+// CollectBlobs removes the blobs last written before the given time that no
+// vault record, trashed ones included, and no version of a record refers to.
+// Their chunks go with them; the objects are left to CollectObjects.
+// It returns the number of blobs removed.
+func (s *Storage) CollectBlobs(ctx context.Context, before time.Time) (int64, error) {
+	res := s.db.WithContext(ctx).
+		Where("updated_at < ?", before).
+		Where(`NOT EXISTS (SELECT 1 FROM vault_records v WHERE v.user_id = blobs.user_id AND v.blob_ref = blobs.ref)`).
+		Where(`NOT EXISTS (SELECT 1 FROM vault_record_versions rv JOIN vault_records v ON v.id = rv.vault_id
+WHERE v.user_id = blobs.user_id AND rv.blob_ref = blobs.ref)`).
+		Delete(&Blob{})
+	return res.RowsAffected, res.Error
+}
+
+// CollectObjects removes the objects written before the given time that no
+// chunk refers to and returns how many were removed. The grace period keeps
+// the objects of chunks being stored right now. Keys that are not object keys
+// belong to someone else and are left alone.
+func (s *Storage) CollectObjects(ctx context.Context, before time.Time) (int64, error) {
+	var (
+		removed int64
+		batch   []string
+	)
+
+	flush := func() error {
+		var used []string
+		err := s.db.WithContext(ctx).Model(&BlobChunk{}).
+			Distinct("hash").
+			Where("hash IN ?", batch).
+			Pluck("hash", &used).Error
+		if err != nil {
+			return errors.Wrap(err, "find used objects")
+		}
+
+		inUse := make(map[string]bool, len(used))
+		for _, h := range used {
+			inUse[h] = true
+		}
+		for _, key := range batch {
+			if inUse[key] {
+				continue
+			}
+			if err = s.objects.Delete(ctx, key); err != nil {
+				return err
+			}
+			removed++
+		}
+
+		batch = batch[:0]
+		return nil
+	}
+
+	err := s.objects.List(ctx, func(o Object) error {
+		if !o.ModTime.Before(before) || !validKey(o.Key) {
+			return nil
+		}
+		batch = append(batch, o.Key)
+		if len(batch) < chunkBatch {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil && len(batch) > 0 {
+		err = flush()
+	}
+	return removed, err
+}
+
+// moveChunkData moves the chunks stored in the blob_chunks table before the
+// object store existed into the store and drops their column.
+func (s *Storage) moveChunkData() error {
+	m := s.db.Migrator()
+	if !m.HasColumn(&BlobChunk{}, "data") {
+		return nil
+	}
+
+	type legacyChunk struct {
+		BlobID uint64
+		Seq    uint32
+		Data   []byte
+	}
+
+	ctx := context.Background()
+	for {
+		var chunks []legacyChunk
+		err := s.db.Table("blob_chunks").
+			Select("blob_id", "seq", "data").
+			Where("hash IS NULL").
+			Limit(chunkBatch).
+			Find(&chunks).Error
+		if err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			break
+		}
+
+		for _, c := range chunks {
+			key := objectKey(c.Data)
+			if err = s.objects.Put(ctx, key, c.Data); err != nil {
+				return err
+			}
+			err = s.db.Model(&BlobChunk{}).
+				Where("blob_id = ? AND seq = ?", c.BlobID, c.Seq).
+				Update("hash", key).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	s.log.Info("blob chunks moved to the object store")
+	return m.DropColumn(&BlobChunk{}, "data")
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestStorage_CreateBlob(t *testing.T) {
+	store, mock := setupVaultDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "blobs" \("user_id","ref","header","size","chunks","received","created_at","updated_at"\) VALUES`).
+		WithArgs(uint64(42), "f00d", []byte("hdr"), int64(70000), uint32(2), uint32(0), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectCommit()
+
+	b := &Blob{UserID: 42, Ref: "f00d", Header: []byte("hdr"), Size: 70000, Chunks: 2, Received: 5}
+	require.NoError(t, store.CreateBlob(context.Background(), b))
+	require.Equal(t, uint64(7), b.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_Blob(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectQuery(`SELECT \* FROM "blobs" WHERE user_id = \$1 AND ref = \$2`).
+			WithArgs(uint64(42), "f00d", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "ref", "chunks", "received"}).AddRow(7, 42, "f00d", 2, 1))
+
+		b, err := store.Blob(context.Background(), 42, "f00d")
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), b.ID)
+		require.False(t, b.Complete())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectQuery(`SELECT \* FROM "blobs"`).WillReturnError(gorm.ErrRecordNotFound)
+
+		_, err := store.Blob(context.Background(), 42, "f00d")
+		require.ErrorIs(t, err, ErrBlobNotFound)
+	})
+}
+
+func TestStorage_PutBlobChunk(t *testing.T) {
+	t.Run("next chunk", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "blobs" SET "received"=received \+ 1,"updated_at"=\$1 WHERE id = \$2 AND received = \$3 AND received < chunks`).
+			WithArgs(sqlmock.AnyArg(), uint64(7), uint32(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO "blob_chunks" \("blob_id","seq","hash"\) VALUES \(\$1,\$2,\$3\)`).
+			WithArgs(uint64(7), uint32(1), objectKey([]byte("sealed"))).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.PutBlobChunk(context.Background(), 7, 1, []byte("sealed")))
+		require.NoError(t, mock.ExpectationsWereMet())
+
+		data, err := store.objects.Get(context.Background(), objectKey([]byte("sealed")))
+		require.NoError(t, err)
+		require.Equal(t, []byte("sealed"), data)
+	})
+
+	t.Run("out of order", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "blobs"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := store.PutBlobChunk(context.Background(), 7, 3, []byte("sealed"))
+		require.ErrorIs(t, err, ErrChunkOutOfOrder)
+	})
+}
+
+func TestStorage_BlobChunk(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		key := objectKey([]byte("sealed"))
+		require.NoError(t, store.objects.Put(context.Background(), key, []byte("sealed")))
+
+		mock.ExpectQuery(`SELECT "hash" FROM "blob_chunks" WHERE blob_id = \$1 AND seq = \$2`).
+			WithArgs(uint64(7), uint32(1), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(key))
+
+		data, err := store.BlobChunk(context.Background(), 7, 1)
+		require.NoError(t, err)
+		require.Equal(t, []byte("sealed"), data)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectQuery(`SELECT "hash" FROM "blob_chunks"`).WillReturnError(gorm.ErrRecordNotFound)
+
+		_, err := store.BlobChunk(context.Background(), 7, 1)
+		require.ErrorIs(t, err, ErrBlobNotFound)
+	})
+
+	t.Run("object lost", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectQuery(`SELECT "hash" FROM "blob_chunks"`).
+			WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(objectKey([]byte("sealed"))))
+
+		_, err := store.BlobChunk(context.Background(), 7, 1)
+		require.ErrorIs(t, err, ErrObjectNotFound)
+	})
+}
+
+func TestStorage_CollectBlobs(t *testing.T) {
+	store, mock := setupVaultDB(t)
+	before := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "blobs" WHERE updated_at < \$1 AND \(NOT EXISTS \(SELECT 1 FROM vault_records v WHERE v.user_id = blobs.user_id AND v.blob_ref = blobs.ref\)\) AND \(NOT EXISTS \(SELECT 1 FROM vault_record_versions rv`).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	n, err := store.CollectBlobs(context.Background(), before)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_CollectObjects(t *testing.T) {
+	store, mock := setupVaultDB(t)
+	ctx := context.Background()
+	objects := store.objects.(*MemoryObjects)
+
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	put := func(data string, at time.Time) string {
+		objects.now = func() time.Time { return at }
+		key := objectKey([]byte(data))
+		require.NoError(t, objects.Put(ctx, key, []byte(data)))
+		return key
+	}
+	used := put("used", now.Add(-2*time.Hour))
+	unused := put("unused", now.Add(-2*time.Hour))
+	fresh := put("fresh", now)
+	objects.now = func() time.Time { return now.Add(-2 * time.Hour) }
+	require.NoError(t, objects.Put(ctx, "readme.txt", []byte("not an object")))
+
+	mock.ExpectQuery(`SELECT DISTINCT "hash" FROM "blob_chunks" WHERE hash IN \(\$1,\$2\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(used))
+
+	n, err := store.CollectObjects(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = objects.Get(ctx, unused)
+	require.ErrorIs(t, err, ErrObjectNotFound)
+	for _, key := range []string{used, fresh, "readme.txt"} {
+		_, err = objects.Get(ctx, key)
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// VaultChange is an entry of the change feed used for incremental sync.
+// Every write of a record appends an entry with the next sequence number and
+// drops the older entries of the same record, so the feed holds at most one
+// entry per record: the latest state a client has to apply.
+type VaultChange struct {
+	Seq       uint64    `gorm:"primaryKey;autoIncrement;index:idx_vault_changes_user_seq,priority:2"` // the sync cursor
+	UserID    uint64    `gorm:"not null;index:idx_vault_changes_user_seq,priority:1"`
+	VaultID   uint64    `gorm:"not null;index"`
+	Deleted   bool      `gorm:"not null"` // a tombstone: the record left the vault
+	ChangedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// VaultSync is a page of the change feed with the current state of the changed records.
+type VaultSync struct {
+	Changed []VaultRecord // records created, updated or restored since the cursor
+	Deleted []uint64      // tombstones: IDs of records that left the vault
+	Cursor  uint64        // the cursor to pass to the next sync
+	HasMore bool          // more changes follow the cursor
+	Reset   bool          // the client must drop its mirror before applying the page
+}
+
+// VaultChanges returns up to limit changes of the user's records after the cursor, oldest first.
+func (s *Storage) VaultChanges(ctx context.Context, uID, since uint64, limit int) ([]VaultChange, error) {
+	var list []VaultChange
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND seq > ?", uID, since).
+		Order("seq").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+// LastVaultChange returns the cursor of the newest change of the user's records, zero if there are none.
+func (s *Storage) LastVaultChange(ctx context.Context, uID uint64) (uint64, error) {
+	var last uint64
+	err := s.db.WithContext(ctx).Model(&VaultChange{}).
+		Where("user_id = ?", uID).
+		Select("COALESCE(MAX(seq), 0)").
+		Scan(&last).Error
+	return last, err
+}
+
+// VaultsByID returns the vault records with the given IDs that are not in the trash.
+func (s *Storage) VaultsByID(ctx context.Context, ids []uint64) ([]VaultRecord, error) {
+	var list []VaultRecord
+	if len(ids) == 0 {
+		return list, nil
+	}
+	err := s.db.WithContext(ctx).Where("id IN ?", ids).Find(&list).Error
+	return list, err
+}
+
+// logChange appends a change of the record to the feed inside tx.
+// The per-user advisory lock, held until tx ends, makes the sequence numbers
+// of one user's changes commit in order, so a client never skips a change
+// that was still in flight when it read the feed.
+func logChange(tx *gorm.DB, uID, vID uint64, deleted bool) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", int64(uID)).Error; err != nil {
+		return errors.Wrap(err, "lock change feed")
+	}
+
+	change := VaultChange{UserID: uID, VaultID: vID, Deleted: deleted}
+	if err := tx.Create(&change).Error; err != nil {
+		return errors.Wrap(err, "log change")
+	}
+
+	err := tx.Where("vault_id = ? AND seq < ?", vID, change.Seq).Delete(&VaultChange{}).Error
+	return errors.Wrap(err, "compact change feed")
+}
+
+// backfillChanges adds feed entries for records written before the feed existed.
+func (s *Storage) backfillChanges() error {
+	return s.db.Exec(`INSERT INTO vault_changes (user_id, vault_id, deleted, changed_at)
+SELECT user_id, id, false, updated_at FROM vault_records v
+WHERE v.deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM vault_changes c WHERE c.vault_id = v.id)
+ORDER BY v.id`).Error
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+// expectLogChange expects a write of the record to be appended to the change feed as seq.
+func expectLogChange(mock sqlmock.Sqlmock, uID, vID uint64, deleted bool, seq uint64) {
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1\)`).
+		WithArgs(int64(uID)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO "vault_changes" \("user_id","vault_id","deleted","changed_at"\) VALUES \(\$1,\$2,\$3,\$4\) RETURNING "seq"`).
+		WithArgs(uID, vID, deleted, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(seq))
+	mock.ExpectExec(`DELETE FROM "vault_changes" WHERE vault_id = \$1 AND seq < \$2`).
+		WithArgs(vID, seq).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestStorage_VaultChanges(t *testing.T) {
+	store, mock := setupVaultDB(t)
+
+	rows := sqlmock.NewRows([]string{"seq", "user_id", "vault_id", "deleted", "changed_at"}).
+		AddRow(11, 42, 1, false, time.Now()).
+		AddRow(12, 42, 2, true, time.Now())
+	mock.ExpectQuery(`SELECT \* FROM "vault_changes" WHERE user_id = \$1 AND seq > \$2 ORDER BY seq LIMIT \$3`).
+		WithArgs(uint64(42), uint64(10), 2).
+		WillReturnRows(rows)
+
+	list, err := store.VaultChanges(context.Background(), 42, 10, 2)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, uint64(12), list[1].Seq)
+	require.True(t, list[1].Deleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_LastVaultChange(t *testing.T) {
+	store, mock := setupVaultDB(t)
+
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(seq\), 0\) FROM "vault_changes" WHERE user_id = \$1`).
+		WithArgs(uint64(42)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(17))
+
+	last, err := store.LastVaultChange(context.Background(), 42)
+	require.NoError(t, err)
+	require.Equal(t, uint64(17), last)
+}
+
+func TestStorage_VaultsByID(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE id IN \(\$1,\$2\) AND "vault_records"\."deleted_at" IS NULL`).
+			WithArgs(uint64(1), uint64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(1, 42))
+
+		list, err := store.VaultsByID(context.Background(), []uint64{1, 2})
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+	})
+
+	t.Run("no ids", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		list, err := store.VaultsByID(context.Background(), nil)
+		require.NoError(t, err)
+		require.Empty(t, list)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -2,15 +2,67 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/ratelimit"
 )
 
-// ErrLoginUsed indicates that the login is already taken by another user.
 var (
+	// ErrLoginUsed indicates that the login is already taken by another user.
 	ErrLoginUsed = errors.New("login already used")
+
+	// ErrVaultNotFound indicates that the vault record does not exist.
+	ErrVaultNotFound = errors.New("vault not found")
+
+	// ErrVaultExists indicates that the user already has a vault record with the UUID.
+	ErrVaultExists = errors.New("vault already exists")
+
+	// ErrVersionNotFound indicates that the vault record has no such previous version.
+	ErrVersionNotFound = errors.New("vault version not found")
+
+	// ErrRevisionConflict indicates that the vault record was changed after the caller read it.
+	ErrRevisionConflict = errors.New("vault revision conflict")
+
+	// ErrRevisionRequired indicates an update that does not name the revision it replaces.
+	ErrRevisionRequired = errors.New("vault revision required")
+
+	// ErrSessionNotFound indicates that the session does not exist or has changed concurrently.
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrMFANotFound indicates that the user has not enrolled a second factor.
+	ErrMFANotFound = errors.New("mfa not found")
+
+	// ErrMFACodeUsed indicates that the TOTP code was already accepted once.
+	ErrMFACodeUsed = errors.New("mfa code already used")
+
+	// ErrRecoveryCodeNotFound indicates an unknown or already spent recovery code.
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+
+	// ErrBlobNotFound indicates that the user has no such blob or chunk.
+	ErrBlobNotFound = errors.New("blob not found")
+
+	// ErrChunkOutOfOrder indicates a chunk that is not the next one of its upload.
+	ErrChunkOutOfOrder = errors.New("blob chunk out of order")
 )
 
+// RevisionConflictError is returned by UpdateVault when the expected revision
+// is not the current one. It matches ErrRevisionConflict with errors.Is.
+type RevisionConflictError struct {
+	VaultID  uint64
+	Current  uint64
+	Expected uint64
+}
+
+func (e *RevisionConflictError) Error() string {
+	return fmt.Sprintf("%v: id=%d expected=%d current=%d", ErrRevisionConflict, e.VaultID, e.Expected, e.Current)
+}
+
+func (e *RevisionConflictError) Unwrap() error {
+	return ErrRevisionConflict
+}
+
 // DataKeeper defines the storage interface for users and their encrypted vault records.
 type DataKeeper interface {
 	// NewUser creates a new user in the storage.
@@ -22,21 +74,129 @@ type DataKeeper interface {
 	// UserByLogin retrieves a user by their login.
 	UserByLogin(ctx context.Context, login string) (User, error)
 
+	// UpdatePasswordHash replaces the stored password hash of the user.
+	UpdatePasswordHash(ctx context.Context, uID uint64, hash string) error
+
+	// MFA retrieves the second factor settings of the user.
+	MFA(ctx context.Context, uID uint64) (UserMFA, error)
+
+	// SaveMFA creates or replaces the second factor settings of the user.
+	SaveMFA(ctx context.Context, m *UserMFA) error
+
+	// AdvanceMFACounter stores the TOTP step of an accepted code, rejecting replays.
+	AdvanceMFACounter(ctx context.Context, uID uint64, counter int64) error
+
+	// EnableMFA turns the second factor on and replaces the hashed recovery codes.
+	EnableMFA(ctx context.Context, uID uint64, codeHashes []string) error
+
+	// UseRecoveryCode spends an unused recovery code.
+	UseRecoveryCode(ctx context.Context, uID uint64, codeHash string) error
+
+	// UpdateRateLimit atomically applies fn to the attempt state of the key.
+	UpdateRateLimit(ctx context.Context, key string, fn func(st *ratelimit.State) error) error
+
+	// ResetRateLimit clears the failures of a stored key; unknown keys are left alone.
+	ResetRateLimit(ctx context.Context, key string) error
+
+	// SweepRateLimits deletes unlocked keys idle since the given time.
+	SweepRateLimits(ctx context.Context, now, idleBefore time.Time) (int64, error)
+
 	// CreateVault stores a new encrypted vault record.
 	CreateVault(ctx context.Context, v *VaultRecord) error
 
 	// GetVault retrieves a vault record by its ID.
 	GetVault(ctx context.Context, vID uint64) (VaultRecord, error)
 
-	// UpdateVault updates an existing vault record.
+	// UpdateVault updates an existing vault record, keeping its previous contents as a version,
+	// and replaces its blind index tokens with v.Tokens.
+	// A non-zero v.Revision must match the stored revision, otherwise a *RevisionConflictError
+	// is returned. On success v.Revision holds the new revision.
 	UpdateVault(ctx context.Context, v *VaultRecord) error
 
-	// ListVaults lists all vault records for the specified user.
-	ListVaults(ctx context.Context, uID uint64) ([]VaultRecord, error)
+	// ListVaultVersions lists previous versions of a vault record, newest first.
+	ListVaultVersions(ctx context.Context, vID uint64) ([]VaultRecordVersion, error)
 
-	// DeleteVault removes a vault record by its ID.
+	// VaultVersion retrieves a previous version of a vault record.
+	VaultVersion(ctx context.Context, vID, version uint64) (VaultRecordVersion, error)
+
+	// ListVaults lists the vault records of the user matching the filter.
+	ListVaults(ctx context.Context, uID uint64, f VaultFilter) ([]VaultRecord, error)
+
+	// ListVaultSummaries lists the vault records of the user matching the filter without their contents.
+	ListVaultSummaries(ctx context.Context, uID uint64, f VaultFilter) ([]VaultSummary, error)
+
+	// FindVaults lists up to limit vault records of the user having all the blind index tokens.
+	FindVaults(ctx context.Context, uID uint64, tokens []string, limit int) ([]VaultRecord, error)
+
+	// DeleteVault moves a vault record to the trash by its ID.
 	DeleteVault(ctx context.Context, vID uint64) error
 
+	// VaultChanges lists up to limit changes of the user's records after the cursor, oldest first.
+	VaultChanges(ctx context.Context, uID, since uint64, limit int) ([]VaultChange, error)
+
+	// LastVaultChange returns the cursor of the newest change of the user's records.
+	LastVaultChange(ctx context.Context, uID uint64) (uint64, error)
+
+	// VaultsByID retrieves the vault records with the given IDs that are not in the trash.
+	VaultsByID(ctx context.Context, ids []uint64) ([]VaultRecord, error)
+
+	// ListTrash lists trashed vault records of the user, most recently deleted first.
+	ListTrash(ctx context.Context, uID uint64) ([]VaultRecord, error)
+
+	// TrashedVault retrieves a trashed vault record by its ID.
+	TrashedVault(ctx context.Context, vID uint64) (VaultRecord, error)
+
+	// RestoreVault moves a vault record out of the trash.
+	RestoreVault(ctx context.Context, vID uint64) error
+
+	// PurgeVault permanently removes a trashed vault record with its versions.
+	PurgeVault(ctx context.Context, vID uint64) error
+
+	// PurgeTrash permanently removes records trashed before the given time.
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+
+	// CreateBlob stores the description of a new chunked upload.
+	CreateBlob(ctx context.Context, b *Blob) error
+
+	// Blob retrieves a blob of the user by its reference.
+	Blob(ctx context.Context, uID uint64, ref string) (Blob, error)
+
+	// PutBlobChunk stores the next chunk of an upload.
+	PutBlobChunk(ctx context.Context, blobID uint64, seq uint32, data []byte) error
+
+	// BlobChunk retrieves one chunk of a blob.
+	BlobChunk(ctx context.Context, blobID uint64, seq uint32) ([]byte, error)
+
+	// CollectBlobs removes blobs written before the given time that no record or version refers to.
+	CollectBlobs(ctx context.Context, before time.Time) (int64, error)
+
+	// CollectObjects removes objects written before the given time that no chunk refers to.
+	CollectObjects(ctx context.Context, before time.Time) (int64, error)
+
+	// Notify sends a notification to the listeners of a Postgres channel.
+	Notify(ctx context.Context, channel, payload string) error
+
+	// Listen calls fn for every notification of a Postgres channel until ctx ends or the connection fails.
+	Listen(ctx context.Context, channel string, fn func(payload string)) error
+
+	// CreateSession stores a new login session.
+	CreateSession(ctx context.Context, sess *Session) error
+
+	// Session retrieves a session by its ID.
+	Session(ctx context.Context, sID uint64) (Session, error)
+
+	// ListSessions returns active sessions of the user.
+	ListSessions(ctx context.Context, uID uint64) ([]Session, error)
+
+	// TouchSession updates the last-seen time and address of the session.
+	TouchSession(ctx context.Context, sID uint64, ip string, at time.Time) error
+
+	// RotateSession swaps the refresh token hash if the session is still at the given generation.
+	RotateSession(ctx context.Context, sID, generation uint64, refreshHash string, expiresAt time.Time) error
+
+	// RevokeSession marks the session as revoked.
+	RevokeSession(ctx context.Context, sID uint64) error
+
 	// Shutdown gracefully closes the storage and releases resources.
 	Shutdown() error
 }
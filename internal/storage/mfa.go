@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// UserMFA holds the TOTP second factor of a user.
+// The secret is stored on enrollment and only used for logins once Enabled is set.
+type UserMFA struct {
+	UserID      uint64    `gorm:"primaryKey;autoIncrement:false"`
+	Secret      string    `gorm:"size:64;not null"` // base32 TOTP secret
+	Enabled     bool      `gorm:"not null;default:false"`
+	LastCounter int64     `gorm:"not null;default:0"` // Last accepted TOTP step, guards against code replay
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName keeps the table name readable.
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the device is lost.
+type RecoveryCode struct {
+	ID        uint64     `gorm:"primaryKey"`
+	UserID    uint64     `gorm:"index;not null"`
+	CodeHash  string     `gorm:"size:64;not null"` // SHA-256 of the normalized code
+	UsedAt    *time.Time // Set when the code is spent
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// MFA retrieves the second factor settings of the user.
+func (s *Storage) MFA(ctx context.Context, uID uint64) (UserMFA, error) {
+	var m UserMFA
+	err := s.db.WithContext(ctx).First(&m, "user_id = ?", uID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return m, errors.Wrapf(ErrMFANotFound, "user_id=%d", uID)
+	}
+	return m, err
+}
+
+// SaveMFA creates or replaces the second factor settings of the user.
+func (s *Storage) SaveMFA(ctx context.Context, m *UserMFA) error {
+	return s.db.WithContext(ctx).Save(m).Error
+}
+
+// AdvanceMFACounter stores the TOTP step of an accepted code.
+// A step that is not newer than the stored one fails with ErrMFACodeUsed.
+func (s *Storage) AdvanceMFACounter(ctx context.Context, uID uint64, counter int64) error {
+	res := s.db.WithContext(ctx).
+		Model(&UserMFA{}).
+		Where("user_id = ? AND last_counter < ?", uID, counter).
+		Update("last_counter", counter)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrapf(ErrMFACodeUsed, "user_id=%d", uID)
+	}
+	return nil
+}
+
+// EnableMFA turns the second factor on and replaces the recovery codes of the user.
+func (s *Storage) EnableMFA(ctx context.Context, uID uint64, codeHashes []string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&UserMFA{}).Where("user_id = ?", uID).Update("enabled", true)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.Wrapf(ErrMFANotFound, "user_id=%d", uID)
+		}
+
+		if err := tx.Where("user_id = ?", uID).Delete(&RecoveryCode{}).Error; err != nil {
+			return errors.Wrap(err, "delete recovery codes")
+		}
+
+		codes := make([]RecoveryCode, 0, len(codeHashes))
+		for _, h := range codeHashes {
+			codes = append(codes, RecoveryCode{UserID: uID, CodeHash: h})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode spends an unused recovery code of the user.
+func (s *Storage) UseRecoveryCode(ctx context.Context, uID uint64, codeHash string) error {
+	res := s.db.WithContext(ctx).
+		Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", uID, codeHash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrapf(ErrRecoveryCodeNotFound, "user_id=%d", uID)
+	}
+	return nil
+}
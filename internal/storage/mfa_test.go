@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestStorage_MFA(t *testing.T) {
+	t.Run("not_found", func(t *testing.T) {
+		s, mock := newTestStorage(t)
+
+		mock.ExpectQuery(`SELECT \* FROM "user_mfa" WHERE user_id = \$1`).
+			WithArgs(42, 1).
+			WillReturnError(gorm.ErrRecordNotFound)
+
+		_, err := s.MFA(context.Background(), 42)
+		require.ErrorIs(t, err, ErrMFANotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStorage_AdvanceMFACounter(t *testing.T) {
+	tests := []struct {
+		name          string
+		rows          int64
+		expectedError error
+	}{
+		{name: "success", rows: 1},
+		{name: "replayed step", rows: 0, expectedError: ErrMFACodeUsed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, mock := newTestStorage(t)
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "user_mfa" SET "last_counter"=\$1,"updated_at"=\$2 WHERE user_id = \$3 AND last_counter < \$4`).
+				WithArgs(100, sqlmock.AnyArg(), 42, 100).
+				WillReturnResult(sqlmock.NewResult(0, tc.rows))
+			mock.ExpectCommit()
+
+			err := s.AdvanceMFACounter(context.Background(), 42, 100)
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorage_EnableMFA(t *testing.T) {
+	s, mock := newTestStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "user_mfa" SET "enabled"=\$1,"updated_at"=\$2 WHERE user_id = \$3`).
+		WithArgs(true, sqlmock.AnyArg(), 42).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM "recovery_codes" WHERE user_id = \$1`).
+		WithArgs(42).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery(`INSERT INTO "recovery_codes"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectCommit()
+
+	require.NoError(t, s.EnableMFA(context.Background(), 42, []string{"h1", "h2"}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_UseRecoveryCode(t *testing.T) {
+	tests := []struct {
+		name          string
+		rows          int64
+		expectedError error
+	}{
+		{name: "success", rows: 1},
+		{name: "spent or unknown", rows: 0, expectedError: ErrRecoveryCodeNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, mock := newTestStorage(t)
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "recovery_codes" SET "used_at"=\$1 WHERE user_id = \$2 AND code_hash = \$3 AND used_at IS NULL`).
+				WithArgs(sqlmock.AnyArg(), 42, "hash").
+				WillReturnResult(sqlmock.NewResult(0, tc.rows))
+			mock.ExpectCommit()
+
+			err := s.UseRecoveryCode(context.Background(), 42, "hash")
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+// Notify sends a notification with the payload to the listeners of the channel.
+func (s *Storage) Notify(ctx context.Context, channel, payload string) error {
+	err := s.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", channel, payload).Error
+	return errors.Wrap(err, "notify")
+}
+
+// Listen calls fn with the payload of every notification sent to the channel
+// until ctx ends or the connection fails. It holds a connection of its own:
+// a pooled connection cannot stay subscribed between queries.
+func (s *Storage) Listen(ctx context.Context, channel string, fn func(payload string)) error {
+	conn, err := pgx.Connect(ctx, s.dsn)
+	if err != nil {
+		return errors.Wrap(err, "connect listener")
+	}
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return errors.Wrap(err, "listen")
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return errors.Wrap(err, "wait for notification")
+		}
+		fn(n.Payload)
+	}
+}
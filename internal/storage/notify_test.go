@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_Notify(t *testing.T) {
+	store, mock := setupVaultDB(t)
+
+	mock.ExpectExec(`SELECT pg_notify\(\$1, \$2\)`).
+		WithArgs("vault_events", `{"user_id":1}`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, store.Notify(context.Background(), "vault_events", `{"user_id":1}`))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
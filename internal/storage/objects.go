@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+)
+
+// defaultObjectsDir is where the fs backend keeps objects when blobs.dir is not set.
+const defaultObjectsDir = "./data/blobs"
+
+// ErrObjectNotFound indicates that the object store has no object with the key.
+var ErrObjectNotFound = errors.New("object not found")
+
+// Object describes an object kept in an ObjectStore.
+type Object struct {
+	Key     string
+	ModTime time.Time // when the object was last written
+}
+
+// ObjectStore keeps the encrypted chunks of blobs outside the database.
+// Objects are addressed by the SHA-256 of their contents, see objectKey, so
+// writing the same key twice stores the same bytes.
+type ObjectStore interface {
+	// Put stores data under the key, replacing an object with the same key.
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Get returns the object with the key or ErrObjectNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Delete removes the object with the key. A missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// List calls fn for every stored object until fn returns an error.
+	List(ctx context.Context, fn func(Object) error) error
+}
+
+// newObjectStore returns the object store selected by blobs.backend.
+func newObjectStore(cfg config.Blobs) (ObjectStore, error) {
+	switch cfg.Backend {
+	case "", "fs":
+		dir := cfg.Dir
+		if dir == "" {
+			dir = defaultObjectsDir
+		}
+		return NewFSObjects(dir)
+	case "s3":
+		return NewS3Objects(cfg.S3)
+	case "memory":
+		return NewMemoryObjects(), nil
+	default:
+		return nil, errors.Errorf("unknown blob backend %q", cfg.Backend)
+	}
+}
+
+// objectKey returns the key of an object with the given contents.
+func objectKey(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// validKey reports whether key can be an object key. Keys are lowercase hex,
+// which keeps them safe to use as file names and in URLs.
+func validKey(key string) bool {
+	if len(key) < 2 {
+		return false
+	}
+	for _, c := range key {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
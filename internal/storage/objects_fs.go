@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FSObjects is an ObjectStore in a local directory. An object is kept in
+// the file dir/<first two characters of the key>/<key>, so that no single
+// directory grows too large.
+type FSObjects struct {
+	dir string
+}
+
+// NewFSObjects returns a store in dir, creating the directory if needed.
+func NewFSObjects(dir string) (*FSObjects, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "create blob dir")
+	}
+	return &FSObjects{dir: dir}, nil
+}
+
+// Put writes the object to a temporary file and renames it into place, so a
+// reader never sees a partly written object.
+func (s *FSObjects) Put(_ context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.Wrap(err, "create object dir")
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".put-*")
+	if err != nil {
+		return errors.Wrap(err, "create object")
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return errors.Wrap(err, "write object")
+	}
+	if err = f.Close(); err != nil {
+		return errors.Wrap(err, "write object")
+	}
+	return errors.Wrap(os.Rename(f.Name(), path), "write object")
+}
+
+// Get reads the object from its file.
+func (s *FSObjects) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.Wrapf(ErrObjectNotFound, "key=%s", key)
+	}
+	return data, errors.Wrap(err, "read object")
+}
+
+// Delete removes the file of the object.
+func (s *FSObjects) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return errors.Wrap(err, "delete object")
+}
+
+// List walks the directory. Temporary files of writes in progress are skipped.
+func (s *FSObjects) List(ctx context.Context, fn func(Object) error) error {
+	return filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") || !validKey(d.Name()) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil // deleted while listing
+		}
+		if err != nil {
+			return err
+		}
+		return fn(Object{Key: d.Name(), ModTime: info.ModTime()})
+	})
+}
+
+// path returns the file of the object.
+func (s *FSObjects) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", errors.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.dir, key[:2], key), nil
+}
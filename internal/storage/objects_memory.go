@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MemoryObjects is an ObjectStore inside the process. Objects are lost on
+// restart, so it only suits tests and trying the server out.
+type MemoryObjects struct {
+	mu      sync.Mutex
+	objects map[string]memoryObject
+	now     func() time.Time
+}
+
+type memoryObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemoryObjects returns an empty store.
+func NewMemoryObjects() *MemoryObjects {
+	return &MemoryObjects{objects: make(map[string]memoryObject), now: time.Now}
+}
+
+// Put stores a copy of data.
+func (s *MemoryObjects) Put(_ context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = memoryObject{data: append([]byte(nil), data...), modTime: s.now()}
+	return nil
+}
+
+// Get returns a copy of the object.
+func (s *MemoryObjects) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.objects[key]
+	if !ok {
+		return nil, errors.Wrapf(ErrObjectNotFound, "key=%s", key)
+	}
+	return append([]byte(nil), o.data...), nil
+}
+
+// Delete removes the object.
+func (s *MemoryObjects) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// List calls fn for the objects in key order. fn may modify the store.
+func (s *MemoryObjects) List(ctx context.Context, fn func(Object) error) error {
+	s.mu.Lock()
+	list := make([]Object, 0, len(s.objects))
+	for key, o := range s.objects {
+		list = append(list, Object{Key: key, ModTime: o.modTime})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	for _, o := range list {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
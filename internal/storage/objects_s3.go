@@ -0,0 +1,309 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+)
+
+// s3Timeout limits a single request to the object storage.
+const s3Timeout = 30 * time.Second
+
+// defaultS3Prefix is where objects are kept when s3.prefix is not set;
+// "/" keeps them in the root of the bucket.
+const defaultS3Prefix = "blobs/"
+
+// S3Objects is an ObjectStore in a bucket of an S3-compatible service such as
+// AWS S3 or MinIO. Requests use path-style URLs, endpoint/bucket/key, which
+// every such service accepts, and are signed with AWS Signature Version 4.
+// Objects are kept under a key prefix, so the bucket can be shared: List, and
+// so the garbage collection, never sees the keys outside of it.
+type S3Objects struct {
+	endpoint  *url.URL
+	bucket    string
+	prefix    string
+	region    string
+	accessKey string
+	secretKey string
+
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Objects returns a store in the configured bucket.
+func NewS3Objects(cfg config.S3) (*S3Objects, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("s3 endpoint and bucket are required")
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, errors.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if cfg.Prefix == "" {
+		prefix = defaultS3Prefix
+	} else if prefix != "" {
+		prefix += "/"
+	}
+
+	return &S3Objects{
+		endpoint:  endpoint,
+		bucket:    cfg.Bucket,
+		prefix:    prefix,
+		region:    region,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		client:    &http.Client{Timeout: s3Timeout},
+		now:       time.Now,
+	}, nil
+}
+
+// Put uploads the object.
+func (s *S3Objects) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, nil, data)
+	if err != nil {
+		return errors.Wrap(err, "put object")
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get downloads the object.
+func (s *S3Objects) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "get object")
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	return data, errors.Wrap(err, "get object")
+}
+
+// Delete removes the object.
+func (s *S3Objects) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "delete object")
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// s3List is the part of a ListObjectsV2 response the store uses.
+type s3List struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through the objects under the prefix with ListObjectsV2. Keys
+// that are not object keys, stored there by someone else, are skipped.
+func (s *S3Objects) List(ctx context.Context, fn func(Object) error) error {
+	query := url.Values{"list-type": {"2"}}
+	if s.prefix != "" {
+		query.Set("prefix", s.prefix)
+	}
+	for {
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return errors.Wrap(err, "list objects")
+		}
+
+		var page s3List
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return errors.Wrap(err, "decode object list")
+		}
+
+		for _, c := range page.Contents {
+			key, ok := strings.CutPrefix(c.Key, s.prefix)
+			if !ok || !validKey(key) {
+				continue
+			}
+			if err = fn(Object{Key: key, ModTime: c.LastModified}); err != nil {
+				return err
+			}
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return nil
+		}
+		query.Set("continuation-token", page.NextContinuationToken)
+	}
+}
+
+// s3Error is the error document of an S3 response.
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do sends a signed request for the key under the prefix, or for the bucket
+// when key is empty.
+// A 404 for a key is reported as ErrObjectNotFound, other failures with the
+// error code of the response.
+func (s *S3Objects) do(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Response, error) {
+	if key != "" && !validKey(key) {
+		return nil, errors.Errorf("invalid object key %q", key)
+	}
+
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket
+	if key != "" {
+		u.Path += "/" + s.prefix + key
+	}
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	s.sign(req, body, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && key != "" {
+		return nil, errors.Wrapf(ErrObjectNotFound, "key=%s", key)
+	}
+
+	var e s3Error
+	if xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&e) != nil || e.Code == "" {
+		e.Code = resp.Status
+	}
+	return nil, errors.Errorf("s3 %s %s: %s %s", method, u.Path, e.Code, e.Message)
+}
+
+// sign adds the AWS Signature Version 4 headers to the request. The signed
+// headers are host, range and every x-amz-* header.
+func (s *S3Objects) sign(req *http.Request, body []byte, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	day := t.Format("20060102")
+
+	payloadHash := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "range" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := day + "/" + s.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range []string{day, s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes the query with sorted keys as Signature Version 4 requires.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but the unreserved characters and,
+// unless encodeSlash is set, the slashes.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/ratelimit"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RateLimit is the persisted attempt state of a rate-limited key such as "ip:10.0.0.1".
+type RateLimit struct {
+	Key         string `gorm:"primaryKey;size:255"`
+	Tokens      float64
+	RefilledAt  time.Time `gorm:"index"`
+	Failures    int
+	LockedUntil time.Time `gorm:"index"`
+}
+
+// UpdateRateLimit loads the state of the key under a row lock, applies fn and saves the result.
+// The state is saved even when fn returns an error, so that refills are not lost.
+func (s *Storage) UpdateRateLimit(ctx context.Context, key string, fn func(st *ratelimit.State) error) error {
+	var fnErr error
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var row RateLimit
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&row, "key = ?", key).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrap(err, "load rate limit")
+		}
+		row.Key = key
+
+		st := ratelimit.State{
+			Tokens:      row.Tokens,
+			RefilledAt:  row.RefilledAt,
+			Failures:    row.Failures,
+			LockedUntil: row.LockedUntil,
+		}
+		fnErr = fn(&st)
+
+		row.Tokens = st.Tokens
+		row.RefilledAt = st.RefilledAt
+		row.Failures = st.Failures
+		row.LockedUntil = st.LockedUntil
+
+		// A concurrent first attempt may have inserted the row in between.
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&row).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return fnErr
+}
+
+// ResetRateLimit clears the failures and the lockout of the key. A key without
+// a row has nothing to clear, so none is created for it.
+func (s *Storage) ResetRateLimit(ctx context.Context, key string) error {
+	err := s.db.WithContext(ctx).Model(&RateLimit{}).
+		Where("key = ?", key).
+		Updates(map[string]any{"failures": 0, "locked_until": time.Time{}}).Error
+	return errors.Wrap(err, "reset rate limit")
+}
+
+// SweepRateLimits deletes the keys that are not locked out at now and had no
+// attempt since idleBefore, and returns how many were deleted.
+func (s *Storage) SweepRateLimits(ctx context.Context, now, idleBefore time.Time) (int64, error) {
+	res := s.db.WithContext(ctx).
+		Where("locked_until < ? AND refilled_at < ?", now, idleBefore).
+		Delete(&RateLimit{})
+	return res.RowsAffected, errors.Wrap(res.Error, "sweep rate limits")
+}
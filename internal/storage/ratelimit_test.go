@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/ratelimit"
+	"gorm.io/gorm"
+)
+
+func TestStorage_UpdateRateLimit(t *testing.T) {
+	t.Run("new key", func(t *testing.T) {
+		s, mock := newTestStorage(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "rate_limits" WHERE key = \$1 .* FOR UPDATE`).
+			WithArgs("ip:10.0.0.1", 1).
+			WillReturnError(gorm.ErrRecordNotFound)
+		mock.ExpectExec(`INSERT INTO "rate_limits" .* ON CONFLICT \("key"\) DO UPDATE SET`).
+			WithArgs("ip:10.0.0.1", 1.0, sqlmock.AnyArg(), 0, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := s.UpdateRateLimit(context.Background(), "ip:10.0.0.1", func(st *ratelimit.State) error {
+			require.Zero(t, *st)
+			st.Tokens = 1
+			st.RefilledAt = time.Now()
+			return nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("limited state is still saved", func(t *testing.T) {
+		s, mock := newTestStorage(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "rate_limits" WHERE key = \$1 .* FOR UPDATE`).
+			WithArgs("login:alice", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"key", "tokens", "failures"}).AddRow("login:alice", 0.5, 2))
+		mock.ExpectExec(`INSERT INTO "rate_limits"`).
+			WithArgs("login:alice", 0.5, sqlmock.AnyArg(), 3, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		limited := &ratelimit.LimitedError{RetryAfter: time.Minute}
+		err := s.UpdateRateLimit(context.Background(), "login:alice", func(st *ratelimit.State) error {
+			require.Equal(t, 2, st.Failures)
+			st.Failures++
+			return limited
+		})
+		require.ErrorIs(t, err, limited)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStorage_ResetRateLimit(t *testing.T) {
+	s, mock := newTestStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "rate_limits" SET "failures"=\$1,"locked_until"=\$2 WHERE key = \$3`).
+		WithArgs(0, time.Time{}, "login:nobody").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	require.NoError(t, s.ResetRateLimit(context.Background(), "login:nobody"), "an unknown key is not inserted")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_SweepRateLimits(t *testing.T) {
+	s, mock := newTestStorage(t)
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "rate_limits" WHERE locked_until < \$1 AND refilled_at < \$2`).
+		WithArgs(now, now.Add(-time.Hour)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	n, err := s.SweepRateLimits(context.Background(), now, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
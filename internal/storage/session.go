@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Session represents a login of a user on one device.
+// It lives as long as its refresh token keeps being rotated and is not revoked.
+type Session struct {
+	ID          uint64     `gorm:"primaryKey"`
+	UserID      uint64     `gorm:"index;not null"`
+	FamilyHash  string     `gorm:"size:64;not null"` // SHA-256 of the secret shared by all refresh tokens of the session
+	RefreshHash string     `gorm:"size:64;not null"` // SHA-256 of the secret of the current refresh token
+	Generation  uint64     `gorm:"not null"`         // Incremented on every refresh token rotation
+	ExpiresAt   time.Time  `gorm:"not null"`
+	RevokedAt   *time.Time `gorm:"index"`
+	Device      Device     `gorm:"embedded;embeddedPrefix:device_"`
+	LastSeenAt  time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// Device describes the client a session was opened from.
+type Device struct {
+	Name          string `gorm:"size:255"`
+	ClientVersion string `gorm:"size:64"`
+	IP            string `gorm:"size:64"` // Last known address, updated together with LastSeenAt
+}
+
+// Active reports whether the session is neither revoked nor expired at the given moment.
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// CreateSession stores a new session.
+func (s *Storage) CreateSession(ctx context.Context, sess *Session) error {
+	return s.db.WithContext(ctx).Create(sess).Error
+}
+
+// Session retrieves a session by its ID.
+func (s *Storage) Session(ctx context.Context, sID uint64) (Session, error) {
+	var sess Session
+	err := s.db.WithContext(ctx).First(&sess, "id = ?", sID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return sess, errors.Wrapf(ErrSessionNotFound, "id=%d", sID)
+	}
+	return sess, err
+}
+
+// ListSessions returns active sessions of the user, most recently seen first.
+func (s *Storage) ListSessions(ctx context.Context, uID uint64) ([]Session, error) {
+	var sessions []Session
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", uID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// TouchSession records that the session was used at the given moment from the given address.
+func (s *Storage) TouchSession(ctx context.Context, sID uint64, ip string, at time.Time) error {
+	updates := map[string]any{"last_seen_at": at}
+	if ip != "" {
+		updates["device_ip"] = ip
+	}
+
+	return s.db.WithContext(ctx).
+		Model(&Session{}).
+		Where("id = ?", sID).
+		Updates(updates).Error
+}
+
+// RotateSession replaces the refresh token hash if the session is still at the given generation.
+// A concurrent rotation or revocation makes it fail with ErrSessionNotFound.
+func (s *Storage) RotateSession(ctx context.Context, sID, generation uint64, refreshHash string, expiresAt time.Time) error {
+	res := s.db.WithContext(ctx).
+		Model(&Session{}).
+		Where("id = ? AND generation = ? AND revoked_at IS NULL", sID, generation).
+		Updates(map[string]any{
+			"refresh_hash": refreshHash,
+			"generation":   generation + 1,
+			"expires_at":   expiresAt,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrapf(ErrSessionNotFound, "id=%d generation=%d", sID, generation)
+	}
+	return nil
+}
+
+// RevokeSession marks the session as revoked. Revoking twice is not an error.
+func (s *Storage) RevokeSession(ctx context.Context, sID uint64) error {
+	res := s.db.WithContext(ctx).
+		Model(&Session{}).
+		Where("id = ? AND revoked_at IS NULL", sID).
+		Update("revoked_at", time.Now())
+	return res.Error
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newTestStorage returns a Storage backed by sqlmock with the regexp query matcher.
+func newTestStorage(t *testing.T) (*Storage, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return &Storage{db: gdb, log: zap.NewNop().Sugar()}, mock
+}
+
+func TestStorage_Session(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		s, mock := newTestStorage(t)
+
+		mock.ExpectQuery(`SELECT \* FROM "sessions" WHERE id = \$1`).
+			WithArgs(7, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "generation"}).AddRow(7, 42, 3))
+
+		sess, err := s.Session(context.Background(), 7)
+		require.NoError(t, err)
+		require.Equal(t, uint64(42), sess.UserID)
+		require.Equal(t, uint64(3), sess.Generation)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		s, mock := newTestStorage(t)
+
+		mock.ExpectQuery(`SELECT \* FROM "sessions" WHERE id = \$1`).
+			WithArgs(7, 1).
+			WillReturnError(gorm.ErrRecordNotFound)
+
+		_, err := s.Session(context.Background(), 7)
+		require.ErrorIs(t, err, ErrSessionNotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStorage_RotateSession(t *testing.T) {
+	tests := []struct {
+		name          string
+		rows          int64
+		expectedError error
+	}{
+		{name: "success", rows: 1},
+		{name: "stale generation", rows: 0, expectedError: ErrSessionNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, mock := newTestStorage(t)
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "sessions" SET .*"generation"=\$2.*WHERE id = \$\d+ AND generation = \$\d+ AND revoked_at IS NULL`).
+				WillReturnResult(sqlmock.NewResult(0, tc.rows))
+			mock.ExpectCommit()
+
+			err := s.RotateSession(context.Background(), 7, 2, "hash", time.Now().Add(time.Hour))
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorage_RevokeSession(t *testing.T) {
+	s, mock := newTestStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "sessions" SET "revoked_at"=\$1,"updated_at"=\$2 WHERE id = \$3 AND revoked_at IS NULL`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, s.RevokeSession(context.Background(), 7))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_ListSessions(t *testing.T) {
+	s, mock := newTestStorage(t)
+
+	mock.ExpectQuery(`SELECT \* FROM "sessions" WHERE user_id = \$1 AND revoked_at IS NULL AND expires_at > \$2 ORDER BY last_seen_at DESC`).
+		WithArgs(42, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "device_name", "device_ip"}).
+			AddRow(5, 42, "laptop", "10.0.0.1").
+			AddRow(6, 42, "phone", ""))
+
+	sessions, err := s.ListSessions(context.Background(), 42)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.Equal(t, "laptop", sessions[0].Device.Name)
+	require.Equal(t, "10.0.0.1", sessions[0].Device.IP)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_TouchSession(t *testing.T) {
+	s, mock := newTestStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "sessions" SET "device_ip"=\$1,"last_seen_at"=\$2,"updated_at"=\$3 WHERE id = \$4`).
+		WithArgs("10.0.0.1", sqlmock.AnyArg(), sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, s.TouchSession(context.Background(), 7, "10.0.0.1", time.Now()))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -15,7 +15,11 @@ import (
 
 // Storage provides access to the database and handles data persistence.
 type Storage struct {
-	db *gorm.DB
+	db  *gorm.DB
+	dsn string // for connections outside the pool, see Listen
+
+	history config.History // retention of vault record versions
+	objects ObjectStore    // chunks of blobs, see BlobChunk
 
 	log *zap.SugaredLogger
 }
@@ -36,6 +40,12 @@ func NewStorage(i do.Injector) (*Storage, error) {
 
 	//
 	postgresDB.db = db
+	postgresDB.dsn = cfg.Database.DSN
+	postgresDB.history = cfg.History
+	postgresDB.objects, err = newObjectStore(cfg.Blobs)
+	if err != nil {
+		return nil, errors.Wrap(err, "object store")
+	}
 	postgresDB.log = do.MustInvoke[*logger.Logger](i).Named("postgres")
 
 	//?
@@ -91,11 +101,33 @@ func (s *Storage) Migrate() error {
 	if err := s.db.AutoMigrate(
 		&User{},
 		&VaultRecord{},
+		&VaultRecordVersion{},
+		&Session{},
+		&UserMFA{},
+		&RecoveryCode{},
+		&RateLimit{},
+		&VaultChange{},
+		&Blob{},
+		&BlobChunk{},
+		&VaultToken{},
 	); err != nil {
 		s.log.Errorf("migration plan error: %v", err)
 		return err
 	}
 
+	if err := s.backfillChanges(); err != nil {
+		return errors.Wrap(err, "backfill change feed")
+	}
+
+	if err := s.moveChunkData(); err != nil {
+		return errors.Wrap(err, "move blob chunks to object store")
+	}
+
+	// records sealed before the history was scrubbed on update
+	if err := scrubHistory(s.db, 0); err != nil {
+		return err
+	}
+
 	s.log.Debug("successfully migrated")
 	return nil
 }
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// VaultToken is a blind index token of a vault record: an HMAC of one of its
+// field values computed by the client with a key the server does not know.
+// Equal values give equal tokens, so records can be looked up by them
+// without the server learning the values.
+type VaultToken struct {
+	VaultID uint64      `gorm:"primaryKey;autoIncrement:false"`
+	Vault   VaultRecord `gorm:"constraint:OnDelete:CASCADE"`
+	Token   string      `gorm:"primaryKey;size:64;index:idx_vault_token_lookup,priority:2"`
+	UserID  uint64      `gorm:"not null;index:idx_vault_token_lookup,priority:1"`
+}
+
+// putTokens replaces the tokens of the record with v.Tokens.
+func putTokens(tx *gorm.DB, v *VaultRecord) error {
+	if err := tx.Where("vault_id = ?", v.ID).Delete(&VaultToken{}).Error; err != nil {
+		return errors.Wrap(err, "drop vault tokens")
+	}
+	return addTokens(tx, v)
+}
+
+// addTokens stores v.Tokens for the record.
+func addTokens(tx *gorm.DB, v *VaultRecord) error {
+	if len(v.Tokens) == 0 {
+		return nil
+	}
+
+	rows := make([]VaultToken, 0, len(v.Tokens))
+	for _, t := range v.Tokens {
+		rows = append(rows, VaultToken{VaultID: v.ID, Token: t, UserID: v.UserID})
+	}
+	if err := tx.Omit("Vault").Create(&rows).Error; err != nil {
+		return errors.Wrap(err, "put vault tokens")
+	}
+	return nil
+}
+
+// vaultTokens returns the tokens of the record.
+func vaultTokens(tx *gorm.DB, vID uint64) ([]string, error) {
+	var tokens []string
+	err := tx.Model(&VaultToken{}).
+		Where("vault_id = ?", vID).
+		Order("token").
+		Pluck("token", &tokens).Error
+	return tokens, errors.Wrap(err, "vault tokens")
+}
+
+// FindVaults returns up to limit records of the user that have all the
+// tokens, ordered by ID. Trashed records are not found.
+func (s *Storage) FindVaults(ctx context.Context, uID uint64, tokens []string, limit int) ([]VaultRecord, error) {
+	matching := s.db.Model(&VaultToken{}).
+		Select("vault_id").
+		Where("user_id = ? AND token IN ?", uID, tokens).
+		Group("vault_id").
+		Having("COUNT(DISTINCT token) = ?", len(tokens))
+
+	var list []VaultRecord
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND id IN (?)", uID, matching).
+		Order("id").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+// joinTokens stores tokens in the column of a version. Tokens are hex, so a
+// space separates them.
+func joinTokens(tokens []string) string {
+	return strings.Join(tokens, " ")
+}
+
+// BlindTokens returns the tokens the record had in this version.
+func (v VaultRecordVersion) BlindTokens() []string {
+	return strings.Fields(v.Tokens)
+}
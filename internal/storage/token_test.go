@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	tokenA = strings.Repeat("a", 64)
+	tokenB = strings.Repeat("b", 64)
+	tokenC = strings.Repeat("c", 64)
+)
+
+func TestStorage_CreateVault_Tokens(t *testing.T) {
+	store, mock := setupVaultDB(t)
+
+	v := &VaultRecord{UserID: 42, Type: RecordTypeLogin, Title: "GitHub", Tokens: []string{tokenA, tokenB}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "vault_records"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectExec(`INSERT INTO "vault_tokens" \("vault_id","token","user_id"\) VALUES \(\$1,\$2,\$3\),\(\$4,\$5,\$6\)`).
+		WithArgs(uint64(3), tokenA, uint64(42), uint64(3), tokenB, uint64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	expectLogChange(mock, 42, 3, false, 1)
+	mock.ExpectCommit()
+
+	require.NoError(t, store.CreateVault(context.Background(), v))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_FindVaults(t *testing.T) {
+	store, mock := setupVaultDB(t)
+
+	mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE \(user_id = \$1 AND id IN \(SELECT "vault_id" FROM "vault_tokens" WHERE user_id = \$2 AND token IN \(\$3,\$4\) GROUP BY "vault_id" HAVING COUNT\(DISTINCT token\) = \$5\)\) AND "vault_records"\."deleted_at" IS NULL ORDER BY id LIMIT \$6`).
+		WithArgs(uint64(42), uint64(42), tokenA, tokenB, 2, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title"}).AddRow(3, 42, "GitHub"))
+
+	list, err := store.FindVaults(context.Background(), 42, []string{tokenA, tokenB}, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, uint64(3), list[0].ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVaultRecordVersion_BlindTokens(t *testing.T) {
+	require.Empty(t, VaultRecordVersion{}.BlindTokens())
+	require.Equal(t, []string{tokenA, tokenB}, VaultRecordVersion{Tokens: joinTokens([]string{tokenA, tokenB})}.BlindTokens())
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ListTrash returns trashed vault records of the user, most recently deleted first.
+func (s *Storage) ListTrash(ctx context.Context, uID uint64) ([]VaultRecord, error) {
+	var list []VaultRecord
+	err := s.db.WithContext(ctx).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", uID).
+		Order("deleted_at DESC").
+		Find(&list).Error
+	return list, err
+}
+
+// TrashedVault retrieves a trashed vault record by its ID.
+func (s *Storage) TrashedVault(ctx context.Context, vID uint64) (VaultRecord, error) {
+	var v VaultRecord
+	err := s.db.WithContext(ctx).Unscoped().
+		First(&v, "id = ? AND deleted_at IS NOT NULL", vID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return v, errors.Wrapf(ErrVaultNotFound, "id=%d", vID)
+	}
+	return v, err
+}
+
+// RestoreVault clears the deletion mark of a trashed vault record.
+func (s *Storage) RestoreVault(ctx context.Context, vID uint64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var v VaultRecord
+		err := tx.Unscoped().Select("id", "user_id").
+			First(&v, "id = ? AND deleted_at IS NOT NULL", vID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrapf(ErrVaultNotFound, "id=%d", vID)
+		}
+		if err != nil {
+			return err
+		}
+
+		res := tx.Unscoped().
+			Model(&VaultRecord{}).
+			Where("id = ? AND deleted_at IS NOT NULL", vID).
+			Update("deleted_at", nil)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.Wrapf(ErrVaultNotFound, "id=%d", vID)
+		}
+
+		return logChange(tx, v.UserID, vID, false)
+	})
+}
+
+// PurgeVault permanently deletes a trashed vault record. Its versions are
+// removed by the foreign key cascade; the change feed already holds its
+// tombstone from the move to the trash.
+func (s *Storage) PurgeVault(ctx context.Context, vID uint64) error {
+	res := s.db.WithContext(ctx).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", vID).
+		Delete(&VaultRecord{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrapf(ErrVaultNotFound, "id=%d", vID)
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes records trashed before the given time and
+// returns how many were removed.
+func (s *Storage) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	res := s.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&VaultRecord{})
+	return res.RowsAffected, res.Error
+}
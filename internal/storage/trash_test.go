@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestStorage_ListTrash(t *testing.T) {
+	store, mock := setupVaultDB(t)
+
+	deleted := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "user_id", "title", "deleted_at"}).
+		AddRow(3, 42, "old note", deleted)
+	mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE user_id = \$1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC`).
+		WithArgs(uint64(42)).
+		WillReturnRows(rows)
+
+	list, err := store.ListTrash(context.Background(), 42)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.True(t, list[0].DeletedAt.Valid)
+}
+
+func TestStorage_TrashedVault(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE id = \$1 AND deleted_at IS NOT NULL`).
+			WithArgs(uint64(3), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "deleted_at"}).AddRow(3, 42, time.Now()))
+
+		v, err := store.TrashedVault(context.Background(), 3)
+		require.NoError(t, err)
+		require.Equal(t, uint64(42), v.UserID)
+	})
+
+	t.Run("not in trash", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE id = \$1 AND deleted_at IS NOT NULL`).
+			WillReturnError(gorm.ErrRecordNotFound)
+
+		_, err := store.TrashedVault(context.Background(), 3)
+		require.ErrorIs(t, err, ErrVaultNotFound)
+	})
+}
+
+func TestStorage_RestoreVault(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT "id","user_id" FROM "vault_records" WHERE id = \$1 AND deleted_at IS NOT NULL`).
+			WithArgs(uint64(3), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(3, 42))
+		mock.ExpectExec(`UPDATE "vault_records" SET "deleted_at"=\$1,"updated_at"=\$2 WHERE id = \$3 AND deleted_at IS NOT NULL`).
+			WithArgs(nil, sqlmock.AnyArg(), uint64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectLogChange(mock, 42, 3, false, 5)
+		mock.ExpectCommit()
+
+		require.NoError(t, store.RestoreVault(context.Background(), 3))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not in trash", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT "id","user_id" FROM "vault_records"`).WillReturnError(gorm.ErrRecordNotFound)
+		mock.ExpectRollback()
+
+		require.ErrorIs(t, store.RestoreVault(context.Background(), 3), ErrVaultNotFound)
+	})
+}
+
+func TestStorage_PurgeVault(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM "vault_records" WHERE id = \$1 AND deleted_at IS NOT NULL`).
+			WithArgs(uint64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.PurgeVault(context.Background(), 3))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not in trash", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM "vault_records"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		require.ErrorIs(t, store.PurgeVault(context.Background(), 3), ErrVaultNotFound)
+	})
+}
+
+func TestStorage_PurgeTrash(t *testing.T) {
+	store, mock := setupVaultDB(t)
+	before := time.Now().Add(-30 * 24 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "vault_records" WHERE deleted_at IS NOT NULL AND deleted_at < \$1`).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectCommit()
+
+	n, err := store.PurgeTrash(context.Background(), before)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), n)
+}
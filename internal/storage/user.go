@@ -12,7 +12,7 @@ import (
 type User struct {
 	ID           uint64    `gorm:"primaryKey"`
 	Login        string    `gorm:"uniqueIndex;size:255;not null"`
-	PasswordHash string    `gorm:"size:255;not null"` // bcrypt hash
+	PasswordHash string    `gorm:"size:255;not null"` // argon2id hash in PHC format
 	CreatedAt    time.Time `gorm:"autoCreateTime"`
 }
 
@@ -56,3 +56,15 @@ func (s *Storage) UserByLogin(ctx context.Context, login string) (User, error) {
 	}
 	return user, nil
 }
+
+// UpdatePasswordHash replaces the password hash of the user with the given ID.
+func (s *Storage) UpdatePasswordHash(ctx context.Context, uID uint64, hash string) error {
+	res := s.db.WithContext(ctx).Model(&User{}).Where("id = ?", uID).Update("password_hash", hash)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
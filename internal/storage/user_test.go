@@ -253,3 +253,60 @@ func TestStorage_UserByLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestStorage_UpdatePasswordHash(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "success",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "users" SET "password_hash"=$1 WHERE id = $2`).
+					WithArgs("new-hash", 42).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "not_found",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "users" SET "password_hash"=$1 WHERE id = $2`).
+					WithArgs("new-hash", 42).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			expectedError: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			require.NoError(t, err)
+
+			s := &Storage{
+				db:  gdb,
+				log: zap.NewNop().Sugar(),
+			}
+
+			tc.setupMock(mock)
+
+			err = s.UpdatePasswordHash(context.Background(), 42, "new-hash")
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
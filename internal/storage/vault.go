@@ -2,8 +2,13 @@ package storage
 
 import (
 	"context"
-	"fmt"
+	"strings"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // RecordType defines the type of vault record, such as login credentials or notes.
@@ -25,49 +30,288 @@ const (
 
 // VaultRecord represents an encrypted data entry belonging to a user.
 type VaultRecord struct {
-	ID            uint64     `gorm:"primaryKey"`
-	UserID        uint64     `gorm:"index;not null"`   // Foreign key to User
-	Type          RecordType `gorm:"size:32;not null"` // One of: "login", "note", "card", "binary"
-	Title         string     `gorm:"size:255;not null"`
-	Metadata      string     `gorm:"type:jsonb"` // Optional metadata, stored as JSON
-	EncryptedData []byte     `gorm:"not null"`   // Encrypted content, handled on the client side
-	CreatedAt     time.Time  `gorm:"autoCreateTime"`
-	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+	ID            uint64         `gorm:"primaryKey"`
+	UserID        uint64         `gorm:"index;not null;uniqueIndex:idx_vault_user_uuid,priority:1"` // Foreign key to User
+	Type          RecordType     `gorm:"size:32;not null"`                                          // One of: "login", "note", "card", "binary"
+	Title         string         `gorm:"size:255;not null"`
+	Metadata      string         `gorm:"type:jsonb"`         // Optional metadata, stored as JSON
+	EncryptedData []byte         `gorm:"not null"`           // Encrypted content, handled on the client side
+	BlobRef       string         `gorm:"size:64"`            // Ref of the blob holding the file of a binary record
+	Revision      uint64         `gorm:"not null;default:1"` // Incremented by every update
+	CreatedAt     time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt `gorm:"index"`                                                               // Set while the record is in the trash
+	UUID          string         `gorm:"size:36;uniqueIndex:idx_vault_user_uuid,priority:2,where:uuid <> ''"` // Chosen by the client, the encrypted data is bound to it
+	SealedMeta    []byte         // Title and metadata encrypted by the client, which then leaves them empty
+	Tokens        []string       `gorm:"-"` // Blind index tokens written along, see VaultToken
 }
 
-// CreateVault stores a new vault record in the database.
+// CreateVault stores a new vault record in the database. A record with the
+// UUID of another record of the user, trashed ones included, fails with
+// ErrVaultExists: it is a create repeated after its response was lost.
 func (s *Storage) CreateVault(ctx context.Context, v *VaultRecord) error {
-	return s.db.WithContext(ctx).Create(v).Error
+	v.Revision = 1
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(v).Error; err != nil {
+			if uuidTaken(err) {
+				return errors.Wrapf(ErrVaultExists, "uuid=%s", v.UUID)
+			}
+			return err
+		}
+		if err := addTokens(tx, v); err != nil {
+			return err
+		}
+		return logChange(tx, v.UserID, v.ID, false)
+	})
 }
 
 // GetVault retrieves a vault record by its ID.
 func (s *Storage) GetVault(ctx context.Context, vID uint64) (VaultRecord, error) {
 	var v VaultRecord
 	err := s.db.WithContext(ctx).First(&v, "id = ?", vID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return v, errors.Wrapf(ErrVaultNotFound, "id=%d", vID)
+	}
 	return v, err
 }
 
-// UpdateVault updates an existing vault record.
+// UpdateVault updates an existing vault record and replaces its tokens with
+// v.Tokens. The previous contents are
+// kept as a new version and versions beyond the retention are pruned.
+// The update only succeeds if the record is still at v.Revision; an update
+// without a revision fails with ErrRevisionRequired. An update with sealed
+// metadata drops the plaintext titles and metadata left in the history.
 func (s *Storage) UpdateVault(ctx context.Context, v *VaultRecord) error {
-	// Убедись, что v.ID задан
-	return s.db.WithContext(ctx).Save(v).Error
+	if v.Revision == 0 {
+		return errors.Wrapf(ErrRevisionRequired, "id=%d", v.ID)
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current VaultRecord
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", v.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrapf(ErrVaultNotFound, "id=%d", v.ID)
+		}
+		if err != nil {
+			return errors.Wrap(err, "lock vault")
+		}
+
+		if v.Revision != current.Revision {
+			return &RevisionConflictError{VaultID: v.ID, Current: current.Revision, Expected: v.Revision}
+		}
+		v.Revision = current.Revision + 1
+
+		version, err := archiveVault(tx, &current)
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Save(v).Error; err != nil {
+			if uuidTaken(err) {
+				return errors.Wrapf(ErrVaultExists, "uuid=%s", v.UUID)
+			}
+			return errors.Wrap(err, "save vault")
+		}
+		if err = putTokens(tx, v); err != nil {
+			return err
+		}
+		if len(v.SealedMeta) > 0 {
+			if err = scrubHistory(tx, v.ID); err != nil {
+				return err
+			}
+		}
+
+		if err = logChange(tx, v.UserID, v.ID, false); err != nil {
+			return err
+		}
+
+		return s.pruneVersions(tx, v.ID, version)
+	})
+}
+
+// uuidTaken reports whether err is a violation of the unique UUIDs of the
+// records of a user.
+func uuidTaken(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_vault_user_uuid"
 }
 
-// ListVaults returns all vault records associated with the specified user.
-func (s *Storage) ListVaults(ctx context.Context, userID uint64) ([]VaultRecord, error) {
+// VaultSort is the column vault records are listed by. Records with equal
+// values are ordered by ID.
+type VaultSort string
+
+const (
+	// SortByID lists records in the order they were created.
+	SortByID VaultSort = "id"
+
+	// SortByTitle lists records by title.
+	SortByTitle VaultSort = "title"
+
+	// SortByUpdated lists records by the time of the last change.
+	SortByUpdated VaultSort = "updated_at"
+
+	// SortByCreated lists records by the creation time.
+	SortByCreated VaultSort = "created_at"
+)
+
+// VaultFilter selects, orders and pages the records returned by ListVaults.
+// Zero fields do not filter.
+type VaultFilter struct {
+	Type         RecordType
+	TitlePrefix  string
+	UpdatedSince time.Time
+	MetadataKeys []string // records whose metadata has all of these keys
+
+	Sort VaultSort // SortByID when empty
+	Desc bool
+
+	// After is the last record of the previous page; only its ID and the
+	// column of Sort are used.
+	After *VaultRecord
+	Limit int // all records when zero
+
+	// Fields are the columns to load, all when empty. The ID and the column
+	// of Sort are always loaded.
+	Fields []string
+}
+
+// VaultSummary describes a vault record without its contents.
+type VaultSummary struct {
+	ID         uint64
+	Type       RecordType
+	Title      string
+	Metadata   string
+	Size       int64 // of the file of a binary record, of the ciphertext otherwise
+	Revision   uint64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	SealedMeta bool // the title and metadata are encrypted in the record
+}
+
+// ListVaults returns the vault records of the user matching the filter.
+func (s *Storage) ListVaults(ctx context.Context, userID uint64, f VaultFilter) ([]VaultRecord, error) {
+	q, err := s.vaultQuery(ctx, userID, f)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(f.Fields) > 0 {
+		sort := string(f.sort())
+		cols := []string{"id"}
+		if sort != "id" {
+			cols = append(cols, sort)
+		}
+		for _, c := range f.Fields {
+			if c != "id" && c != sort {
+				cols = append(cols, c)
+			}
+		}
+		q = q.Select(cols)
+	}
+
 	var list []VaultRecord
-	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Find(&list).Error
+	err = q.Find(&list).Error
 	return list, err
 }
 
-// DeleteVault deletes a vault record by its ID.
-func (s *Storage) DeleteVault(ctx context.Context, vID uint64) error {
-	res := s.db.WithContext(ctx).Delete(&VaultRecord{}, vID)
-	if res.Error != nil {
-		return res.Error
+// ListVaultSummaries returns the summaries of the vault records of the user
+// matching the filter; f.Fields is ignored. The ciphertext is never read:
+// its size comes from the length Postgres keeps next to the value.
+func (s *Storage) ListVaultSummaries(ctx context.Context, userID uint64, f VaultFilter) ([]VaultSummary, error) {
+	q, err := s.vaultQuery(ctx, userID, f)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []VaultSummary
+	err = q.Model(&VaultRecord{}).
+		Select(`id, type, title, metadata, revision, created_at, updated_at,
+COALESCE((SELECT b.size FROM blobs b WHERE b.user_id = vault_records.user_id AND b.ref = vault_records.blob_ref),
+octet_length(encrypted_data)) AS size,
+COALESCE(octet_length(sealed_meta), 0) > 0 AS sealed_meta`).
+		Find(&list).Error
+	return list, err
+}
+
+// sort returns the order of the filter.
+func (f VaultFilter) sort() VaultSort {
+	if f.Sort == "" {
+		return SortByID
+	}
+	return f.Sort
+}
+
+// vaultQuery returns the query of the user's records matching the filter,
+// ordered and limited but with all columns selected.
+func (s *Storage) vaultQuery(ctx context.Context, userID uint64, f VaultFilter) (*gorm.DB, error) {
+	sort := f.sort()
+	dir, cmp := "ASC", ">"
+	if f.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	q := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	if f.Type != "" {
+		q = q.Where("type = ?", f.Type)
 	}
-	if res.RowsAffected == 0 {
-		return fmt.Errorf("запись с id=%d не найдена", vID)
+	if f.TitlePrefix != "" {
+		q = q.Where("title LIKE ?", likeEscaper.Replace(f.TitlePrefix)+"%")
 	}
-	return nil
+	if !f.UpdatedSince.IsZero() {
+		q = q.Where("updated_at >= ?", f.UpdatedSince)
+	}
+	for _, key := range f.MetadataKeys {
+		q = q.Where("metadata -> ? IS NOT NULL", key)
+	}
+
+	if f.After != nil {
+		switch sort {
+		case SortByID:
+			q = q.Where("id "+cmp+" ?", f.After.ID)
+		case SortByTitle:
+			q = q.Where("(title, id) "+cmp+" (?, ?)", f.After.Title, f.After.ID)
+		case SortByUpdated:
+			q = q.Where("(updated_at, id) "+cmp+" (?, ?)", f.After.UpdatedAt, f.After.ID)
+		case SortByCreated:
+			q = q.Where("(created_at, id) "+cmp+" (?, ?)", f.After.CreatedAt, f.After.ID)
+		default:
+			return nil, errors.Errorf("unknown sort %q", sort)
+		}
+	}
+
+	if sort != SortByID {
+		q = q.Order(string(sort) + " " + dir)
+	}
+	q = q.Order("id " + dir)
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	return q, nil
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern with the default escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// DeleteVault moves a vault record to the trash by its ID.
+func (s *Storage) DeleteVault(ctx context.Context, vID uint64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var v VaultRecord
+		err := tx.Select("id", "user_id").First(&v, "id = ?", vID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrapf(ErrVaultNotFound, "id=%d", vID)
+		}
+		if err != nil {
+			return err
+		}
+
+		res := tx.Delete(&VaultRecord{}, vID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.Wrapf(ErrVaultNotFound, "id=%d", vID)
+		}
+
+		return logChange(tx, v.UserID, vID, true)
+	})
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/require"
 
 	"gorm.io/driver/postgres"
@@ -19,7 +20,7 @@ func setupVaultDB(t *testing.T) (*Storage, sqlmock.Sqlmock) {
 	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
 	require.NoError(t, err)
 
-	return &Storage{db: gormDB}, mock
+	return &Storage{db: gormDB, objects: NewMemoryObjects()}, mock
 }
 
 func TestVaultCRUD(t *testing.T) {
@@ -34,18 +35,35 @@ func TestVaultCRUD(t *testing.T) {
 			Title:         "Test Note",
 			Metadata:      `{"tag":"secret"}`,
 			EncryptedData: []byte("encrypted"),
+			UUID:          "0b5e5e9c-7a47-4a8e-9e3c-1d1f2a3b4c5d",
+			SealedMeta:    []byte("sealed"),
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
 		}
 
 		mock.ExpectBegin()
 		mock.ExpectQuery(`INSERT INTO "vault_records"`).
-			WithArgs(vault.UserID, vault.Type, vault.Title, vault.Metadata, vault.EncryptedData, sqlmock.AnyArg(), sqlmock.AnyArg(), vault.ID).
+			WithArgs(vault.UserID, vault.Type, vault.Title, vault.Metadata, vault.EncryptedData, "", uint64(1), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, vault.UUID, vault.SealedMeta, vault.ID).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(vault.ID))
+		expectLogChange(mock, vault.UserID, vault.ID, false, 1)
 		mock.ExpectCommit()
 
 		err := store.CreateVault(ctx, vault)
 		require.NoError(t, err)
+		require.Equal(t, uint64(1), vault.Revision)
+	})
+
+	t.Run("CreateVault/repeated", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "vault_records"`).
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "idx_vault_user_uuid"})
+		mock.ExpectRollback()
+
+		err := store.CreateVault(context.Background(), &VaultRecord{UserID: 42, UUID: "0b5e5e9c-7a47-4a8e-9e3c-1d1f2a3b4c5d"})
+		require.ErrorIs(t, err, ErrVaultExists)
+		require.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("GetVault/success", func(t *testing.T) {
@@ -66,13 +84,23 @@ func TestVaultCRUD(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"id", "user_id", "type", "title", "metadata", "encrypted_data", "created_at", "updated_at"}).
 			AddRow(vault.ID, vault.UserID, vault.Type, vault.Title, vault.Metadata, vault.EncryptedData, vault.CreatedAt, vault.UpdatedAt)
 
-		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE id = \$1 ORDER BY "vault_records"\."id" LIMIT \$2`).WithArgs(vault.ID, 1).WillReturnRows(rows)
+		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE id = \$1 AND "vault_records"\."deleted_at" IS NULL ORDER BY "vault_records"\."id" LIMIT \$2`).WithArgs(vault.ID, 1).WillReturnRows(rows)
 
 		res, err := store.GetVault(ctx, vault.ID)
 		require.NoError(t, err)
 		require.Equal(t, vault.ID, res.ID)
 	})
 
+	t.Run("GetVault/not_found", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+		ctx := context.Background()
+
+		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE id = \$1 AND "vault_records"\."deleted_at" IS NULL ORDER BY "vault_records"\."id" LIMIT \$2`).WithArgs(uint64(7), 1).WillReturnError(gorm.ErrRecordNotFound)
+
+		_, err := store.GetVault(ctx, 7)
+		require.ErrorIs(t, err, ErrVaultNotFound)
+	})
+
 	t.Run("UpdateVault/success", func(t *testing.T) {
 		store, mock := setupVaultDB(t)
 		ctx := context.Background()
@@ -84,18 +112,82 @@ func TestVaultCRUD(t *testing.T) {
 			Title:         "Updated Title",
 			Metadata:      `{"tag":"secret"}`,
 			EncryptedData: []byte("encrypted"),
+			Revision:      4,
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
+			Tokens:        []string{tokenB},
 		}
 
 		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE id = \$1 .* FOR UPDATE`).
+			WithArgs(vault.ID, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "type", "title", "metadata", "encrypted_data", "revision"}).
+				AddRow(vault.ID, vault.UserID, vault.Type, "Old Title", vault.Metadata, []byte("old"), 4))
+		mock.ExpectQuery(`SELECT COALESCE\(MAX\(version\), 0\) FROM "vault_record_versions" WHERE vault_id = \$1`).
+			WithArgs(vault.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(2))
+		mock.ExpectQuery(`SELECT "token" FROM "vault_tokens" WHERE vault_id = \$1 ORDER BY token`).
+			WithArgs(vault.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"token"}).AddRow(tokenA).AddRow(tokenC))
+		mock.ExpectQuery(`INSERT INTO "vault_record_versions"`).
+			WithArgs(vault.ID, uint64(3), vault.Type, "Old Title", vault.Metadata, []byte("old"), "", "", []byte(nil), tokenA+" "+tokenC, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
 		mock.ExpectExec(`UPDATE "vault_records"`).
-			WithArgs(vault.UserID, vault.Type, vault.Title, vault.Metadata, vault.EncryptedData, sqlmock.AnyArg(), sqlmock.AnyArg(), vault.ID).
+			WithArgs(vault.UserID, vault.Type, vault.Title, vault.Metadata, vault.EncryptedData, "", uint64(5), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, vault.UUID, vault.SealedMeta, vault.ID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`DELETE FROM "vault_tokens" WHERE vault_id = \$1`).
+			WithArgs(vault.ID).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(`INSERT INTO "vault_tokens" \("vault_id","token","user_id"\) VALUES \(\$1,\$2,\$3\)`).
+			WithArgs(vault.ID, tokenB, vault.UserID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectLogChange(mock, vault.UserID, vault.ID, false, 8)
 		mock.ExpectCommit()
 
 		err := store.UpdateVault(ctx, vault)
 		require.NoError(t, err)
+		require.Equal(t, uint64(5), vault.Revision)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UpdateVault/revision_conflict", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE id = \$1 .* FOR UPDATE`).
+			WithArgs(uint64(1), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "revision"}).AddRow(1, 42, 6))
+		mock.ExpectRollback()
+
+		err := store.UpdateVault(context.Background(), &VaultRecord{ID: 1, UserID: 42, Revision: 4})
+		require.ErrorIs(t, err, ErrRevisionConflict)
+
+		var conflict *RevisionConflictError
+		require.ErrorAs(t, err, &conflict)
+		require.Equal(t, uint64(6), conflict.Current)
+		require.Equal(t, uint64(4), conflict.Expected)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UpdateVault/not_found", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE id = \$1 .* FOR UPDATE`).
+			WithArgs(uint64(7), 1).
+			WillReturnError(gorm.ErrRecordNotFound)
+		mock.ExpectRollback()
+
+		err := store.UpdateVault(context.Background(), &VaultRecord{ID: 7, Revision: 1})
+		require.ErrorIs(t, err, ErrVaultNotFound)
+	})
+
+	t.Run("UpdateVault/revision_required", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		err := store.UpdateVault(context.Background(), &VaultRecord{ID: 1, UserID: 42})
+		require.ErrorIs(t, err, ErrRevisionRequired)
+		require.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("ListVaults/success", func(t *testing.T) {
@@ -116,22 +208,114 @@ func TestVaultCRUD(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"id", "user_id", "type", "title", "metadata", "encrypted_data", "created_at", "updated_at"}).
 			AddRow(vault.ID, vault.UserID, vault.Type, vault.Title, vault.Metadata, vault.EncryptedData, vault.CreatedAt, vault.UpdatedAt)
 
-		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE user_id = \$1`).WithArgs(vault.UserID).WillReturnRows(rows)
+		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE user_id = \$1 AND "vault_records"\."deleted_at" IS NULL ORDER BY id ASC$`).
+			WithArgs(vault.UserID).
+			WillReturnRows(rows)
 
-		res, err := store.ListVaults(ctx, vault.UserID)
+		res, err := store.ListVaults(ctx, vault.UserID, VaultFilter{})
 		require.NoError(t, err)
 		require.Len(t, res, 1)
 	})
 
+	t.Run("ListVaults/filtered page", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+		since := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+		after := &VaultRecord{ID: 5, Title: "Bank"}
+
+		mock.ExpectQuery(`SELECT "id","title","type","metadata" FROM "vault_records" `+
+			`WHERE user_id = \$1 AND type = \$2 AND title LIKE \$3 AND updated_at >= \$4 `+
+			`AND metadata -> \$5 IS NOT NULL AND \(title, id\) < \(\$6, \$7\) `+
+			`AND "vault_records"\."deleted_at" IS NULL ORDER BY title DESC,id DESC LIMIT \$8`).
+			WithArgs(uint64(42), RecordTypeLogin, `50\%\_off%`, since, "url", "Bank", uint64(5), 11).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(4, "Alpha"))
+
+		res, err := store.ListVaults(context.Background(), 42, VaultFilter{
+			Type:         RecordTypeLogin,
+			TitlePrefix:  "50%_off",
+			UpdatedSince: since,
+			MetadataKeys: []string{"url"},
+			Sort:         SortByTitle,
+			Desc:         true,
+			After:        after,
+			Limit:        11,
+			Fields:       []string{"type", "title", "metadata"},
+		})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Nil(t, res[0].EncryptedData)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ListVaultSummaries/no ciphertext", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+		updated := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		mock.ExpectQuery(`SELECT id, type, title, metadata, revision, created_at, updated_at,\s+`+
+			`COALESCE\(\(SELECT b\.size FROM blobs b WHERE b\.user_id = vault_records\.user_id AND b\.ref = vault_records\.blob_ref\),\s+`+
+			`octet_length\(encrypted_data\)\) AS size,\s+COALESCE\(octet_length\(sealed_meta\), 0\) > 0 AS sealed_meta FROM "vault_records" `+
+			`WHERE user_id = \$1 AND type = \$2 AND "vault_records"\."deleted_at" IS NULL ORDER BY updated_at ASC,id ASC LIMIT \$3$`).
+			WithArgs(uint64(42), RecordTypeBinary, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "type", "title", "metadata", "revision", "created_at", "updated_at", "size", "sealed_meta"}).
+				AddRow(3, RecordTypeBinary, "photo.jpg", `{"filename":"photo.jpg"}`, 2, updated, updated, 5<<20, false).
+				AddRow(4, RecordTypeBinary, "", "{}", 1, updated, updated, 1<<20, true))
+
+		res, err := store.ListVaultSummaries(context.Background(), 42, VaultFilter{
+			Type:   RecordTypeBinary,
+			Sort:   SortByUpdated,
+			Limit:  2,
+			Fields: []string{"encrypted_data"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []VaultSummary{{
+			ID:        3,
+			Type:      RecordTypeBinary,
+			Title:     "photo.jpg",
+			Metadata:  `{"filename":"photo.jpg"}`,
+			Size:      5 << 20,
+			Revision:  2,
+			CreatedAt: updated,
+			UpdatedAt: updated,
+		}, {
+			ID:         4,
+			Type:       RecordTypeBinary,
+			Metadata:   "{}",
+			Size:       1 << 20,
+			Revision:   1,
+			CreatedAt:  updated,
+			UpdatedAt:  updated,
+			SealedMeta: true,
+		}}, res)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("DeleteVault/success", func(t *testing.T) {
 		store, mock := setupVaultDB(t)
 		ctx := context.Background()
 
 		mock.ExpectBegin()
-		mock.ExpectExec(`DELETE FROM "vault_records" WHERE "vault_records"\."id" = \$1`).WithArgs(uint64(1)).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`SELECT "id","user_id" FROM "vault_records" WHERE id = \$1 AND "vault_records"\."deleted_at" IS NULL`).
+			WithArgs(uint64(1), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(1, 42))
+		mock.ExpectExec(`UPDATE "vault_records" SET "deleted_at"=\$1 WHERE "vault_records"\."id" = \$2 AND "vault_records"\."deleted_at" IS NULL`).WithArgs(sqlmock.AnyArg(), uint64(1)).WillReturnResult(sqlmock.NewResult(1, 1))
+		expectLogChange(mock, 42, 1, true, 9)
 		mock.ExpectCommit()
 
 		err := store.DeleteVault(ctx, 1)
 		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DeleteVault/not_found", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+		ctx := context.Background()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT "id","user_id" FROM "vault_records"`).
+			WithArgs(uint64(2), 1).
+			WillReturnError(gorm.ErrRecordNotFound)
+		mock.ExpectRollback()
+
+		err := store.DeleteVault(ctx, 2)
+		require.ErrorIs(t, err, ErrVaultNotFound)
 	})
 }
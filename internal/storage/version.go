@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// VaultRecordVersion is a previous state of a vault record, saved before each update.
+// Versions of a record are numbered from 1 in the order they were replaced.
+type VaultRecordVersion struct {
+	ID            uint64      `gorm:"primaryKey"`
+	VaultID       uint64      `gorm:"not null;uniqueIndex:idx_vault_version"`
+	Vault         VaultRecord `gorm:"constraint:OnDelete:CASCADE"`
+	Version       uint64      `gorm:"not null;uniqueIndex:idx_vault_version"`
+	Type          RecordType  `gorm:"size:32;not null"`
+	Title         string      `gorm:"size:255;not null"`
+	Metadata      string      `gorm:"type:jsonb"`
+	EncryptedData []byte      `gorm:"not null"`
+	BlobRef       string      `gorm:"size:64"`
+	UUID          string      `gorm:"size:36"` // the UUID the contents are bound to, empty before records had UUIDs
+	SealedMeta    []byte      // the encrypted title and metadata, see VaultRecord
+	Tokens        string      `gorm:"type:text"` // blind index tokens, space separated
+	SavedAt       time.Time   // when these contents were written
+	ArchivedAt    time.Time   `gorm:"autoCreateTime;index"` // when they were replaced
+}
+
+// ListVaultVersions returns previous versions of the record, newest first.
+func (s *Storage) ListVaultVersions(ctx context.Context, vID uint64) ([]VaultRecordVersion, error) {
+	var list []VaultRecordVersion
+	err := s.db.WithContext(ctx).
+		Where("vault_id = ?", vID).
+		Order("version DESC").
+		Find(&list).Error
+	return list, err
+}
+
+// VaultVersion returns the given version of the record.
+func (s *Storage) VaultVersion(ctx context.Context, vID, version uint64) (VaultRecordVersion, error) {
+	var v VaultRecordVersion
+	err := s.db.WithContext(ctx).First(&v, "vault_id = ? AND version = ?", vID, version).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return v, errors.Wrapf(ErrVersionNotFound, "id=%d version=%d", vID, version)
+	}
+	return v, err
+}
+
+// scrubHistory blanks the plaintext titles and metadata kept by the versions of
+// records whose title and metadata are now sealed, so a record made private
+// does not keep its old name in the history. A zero vID scrubs every such record.
+func scrubHistory(tx *gorm.DB, vID uint64) error {
+	q := tx.Model(&VaultRecordVersion{}).
+		Where("COALESCE(octet_length(sealed_meta), 0) = 0").
+		Where("title <> '' OR metadata IS DISTINCT FROM '{}'::jsonb")
+	if vID != 0 {
+		q = q.Where("vault_id = ?", vID)
+	} else {
+		q = q.Where("vault_id IN (SELECT id FROM vault_records WHERE COALESCE(octet_length(sealed_meta), 0) > 0)")
+	}
+
+	err := q.Updates(map[string]any{"title": "", "metadata": "{}"}).Error
+	return errors.Wrap(err, "scrub history")
+}
+
+// archiveVault saves the current contents of the record as its next version and returns its number.
+func archiveVault(tx *gorm.DB, current *VaultRecord) (uint64, error) {
+	var last uint64
+	err := tx.Model(&VaultRecordVersion{}).
+		Where("vault_id = ?", current.ID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&last).Error
+	if err != nil {
+		return 0, errors.Wrap(err, "last version")
+	}
+
+	tokens, err := vaultTokens(tx, current.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	version := VaultRecordVersion{
+		VaultID:       current.ID,
+		Version:       last + 1,
+		Type:          current.Type,
+		Title:         current.Title,
+		Metadata:      current.Metadata,
+		EncryptedData: current.EncryptedData,
+		BlobRef:       current.BlobRef,
+		UUID:          current.UUID,
+		SealedMeta:    current.SealedMeta,
+		Tokens:        joinTokens(tokens),
+		SavedAt:       current.UpdatedAt,
+	}
+	if err = tx.Omit("Vault").Create(&version).Error; err != nil {
+		return 0, errors.Wrap(err, "archive vault")
+	}
+
+	return version.Version, nil
+}
+
+// pruneVersions deletes versions of the record that fall out of the retention.
+// latest is the number of the newest version.
+func (s *Storage) pruneVersions(tx *gorm.DB, vID, latest uint64) error {
+	keep, keepFor := s.history.KeepVersions, s.history.KeepFor
+	if keep <= 0 && keepFor <= 0 {
+		return nil
+	}
+
+	q := tx.Where("vault_id = ?", vID)
+	if keep > 0 {
+		if latest <= uint64(keep) {
+			return nil
+		}
+		q = q.Where("version <= ?", latest-uint64(keep))
+	}
+	if keepFor > 0 {
+		q = q.Where("archived_at < ?", time.Now().Add(-keepFor))
+	}
+
+	if err := q.Delete(&VaultRecordVersion{}).Error; err != nil {
+		return errors.Wrap(err, "prune versions")
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+	"gorm.io/gorm"
+)
+
+func TestStorage_ListVaultVersions(t *testing.T) {
+	store, mock := setupVaultDB(t)
+
+	rows := sqlmock.NewRows([]string{"id", "vault_id", "version", "type", "title"}).
+		AddRow(2, 1, 2, RecordTypeNote, "second").
+		AddRow(1, 1, 1, RecordTypeNote, "first")
+	mock.ExpectQuery(`SELECT \* FROM "vault_record_versions" WHERE vault_id = \$1 ORDER BY version DESC`).
+		WithArgs(uint64(1)).
+		WillReturnRows(rows)
+
+	list, err := store.ListVaultVersions(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, uint64(2), list[0].Version)
+}
+
+func TestStorage_VaultVersion(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectQuery(`SELECT \* FROM "vault_record_versions" WHERE vault_id = \$1 AND version = \$2`).
+			WithArgs(uint64(1), uint64(3), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "vault_id", "version", "title"}).AddRow(7, 1, 3, "old"))
+
+		v, err := store.VaultVersion(context.Background(), 1, 3)
+		require.NoError(t, err)
+		require.Equal(t, "old", v.Title)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+
+		mock.ExpectQuery(`SELECT \* FROM "vault_record_versions"`).
+			WillReturnError(gorm.ErrRecordNotFound)
+
+		_, err := store.VaultVersion(context.Background(), 1, 3)
+		require.ErrorIs(t, err, ErrVersionNotFound)
+	})
+}
+
+func TestStorage_UpdateVault_Retention(t *testing.T) {
+	expectUpdate := func(mock sqlmock.Sqlmock, last int) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE id = \$1 .* FOR UPDATE`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "revision"}).AddRow(1, 42, 1))
+		mock.ExpectQuery(`SELECT COALESCE\(MAX\(version\), 0\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(last))
+		mock.ExpectQuery(`SELECT "token" FROM "vault_tokens"`).
+			WillReturnRows(sqlmock.NewRows([]string{"token"}))
+		mock.ExpectQuery(`INSERT INTO "vault_record_versions"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(`UPDATE "vault_records"`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`DELETE FROM "vault_tokens"`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		expectLogChange(mock, 42, 1, false, 2)
+	}
+
+	t.Run("keep versions", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+		store.history = config.History{KeepVersions: 3}
+
+		expectUpdate(mock, 4)
+		mock.ExpectExec(`DELETE FROM "vault_record_versions" WHERE vault_id = \$1 AND version <= \$2`).
+			WithArgs(uint64(1), uint64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.UpdateVault(context.Background(), &VaultRecord{ID: 1, UserID: 42, Revision: 1}))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("keep versions and days", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+		store.history = config.History{KeepVersions: 3, KeepFor: 24 * time.Hour}
+
+		expectUpdate(mock, 9)
+		mock.ExpectExec(`DELETE FROM "vault_record_versions" WHERE vault_id = \$1 AND version <= \$2 AND archived_at < \$3`).
+			WithArgs(uint64(1), uint64(7), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.UpdateVault(context.Background(), &VaultRecord{ID: 1, UserID: 42, Revision: 1}))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("under the limit", func(t *testing.T) {
+		store, mock := setupVaultDB(t)
+		store.history = config.History{KeepVersions: 3}
+
+		expectUpdate(mock, 1)
+		mock.ExpectCommit()
+
+		require.NoError(t, store.UpdateVault(context.Background(), &VaultRecord{ID: 1, UserID: 42, Revision: 1}))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStorage_UpdateVault_ScrubsHistory(t *testing.T) {
+	store, mock := setupVaultDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "vault_records" WHERE id = \$1 .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "revision"}).AddRow(1, 42, "bank", 1))
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(version\), 0\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(1))
+	mock.ExpectQuery(`SELECT "token" FROM "vault_tokens"`).
+		WillReturnRows(sqlmock.NewRows([]string{"token"}))
+	mock.ExpectQuery(`INSERT INTO "vault_record_versions"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectExec(`UPDATE "vault_records"`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`DELETE FROM "vault_tokens"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE "vault_record_versions" SET "metadata"=\$1,"title"=\$2 WHERE COALESCE\(octet_length\(sealed_meta\), 0\) = 0 AND \(title <> '' OR metadata IS DISTINCT FROM '\{\}'::jsonb\) AND vault_id = \$3`).
+		WithArgs("{}", "", uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	expectLogChange(mock, 42, 1, false, 2)
+	mock.ExpectCommit()
+
+	v := &VaultRecord{ID: 1, UserID: 42, Metadata: "{}", Revision: 1, UUID: "0b5e5e9c-7a47-4a8e-9e3c-1d1f2a3b4c5d", SealedMeta: []byte("sealed")}
+	require.NoError(t, store.UpdateVault(context.Background(), v))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
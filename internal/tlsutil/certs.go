@@ -0,0 +1,154 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Files written by GenerateDevCerts.
+const (
+	CAFile        = "ca.pem"
+	CAKeyFile     = "ca-key.pem"
+	ServerFile    = "server.pem"
+	ServerKeyFile = "server-key.pem"
+	ClientFile    = "client.pem"
+	ClientKeyFile = "client-key.pem"
+)
+
+// devCertTTL is the validity of development certificates.
+const devCertTTL = 365 * 24 * time.Hour
+
+// issued is a certificate with its key.
+type issued struct {
+	cert *x509.Certificate
+	der  []byte
+	key  *ecdsa.PrivateKey
+}
+
+// GenerateDevCerts writes a self-signed CA and a server and a client certificate signed by it into dir.
+// The server certificate is valid for the given host names and IP addresses.
+// These certificates are meant for local development and tests only.
+func GenerateDevCerts(dir string, hosts []string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return errors.Wrap(err, "create cert dir")
+	}
+
+	ca, err := issue(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "GophKeeper dev CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, nil)
+	if err != nil {
+		return errors.Wrap(err, "issue ca")
+	}
+
+	serverTmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "gophkeeper-server"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			serverTmpl.IPAddresses = append(serverTmpl.IPAddresses, ip)
+		} else {
+			serverTmpl.DNSNames = append(serverTmpl.DNSNames, h)
+		}
+	}
+
+	server, err := issue(serverTmpl, ca)
+	if err != nil {
+		return errors.Wrap(err, "issue server certificate")
+	}
+
+	client, err := issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: "gophkeeper-client"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+	if err != nil {
+		return errors.Wrap(err, "issue client certificate")
+	}
+
+	for _, f := range []struct {
+		name string
+		c    *issued
+		key  string
+	}{
+		{CAFile, ca, CAKeyFile},
+		{ServerFile, server, ServerKeyFile},
+		{ClientFile, client, ClientKeyFile},
+	} {
+		if err = writeIssued(dir, f.name, f.key, f.c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// issue signs tmpl with parent, or self-signs it when parent is nil.
+func issue(tmpl *x509.Certificate, parent *issued) (*issued, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "generate key")
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.Wrap(err, "generate serial")
+	}
+
+	now := time.Now()
+	tmpl.SerialNumber = serial
+	tmpl.NotBefore = now.Add(-time.Hour)
+	tmpl.NotAfter = now.Add(devCertTTL)
+
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "create certificate")
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse certificate")
+	}
+
+	return &issued{cert: cert, der: der, key: key}, nil
+}
+
+// writeIssued writes the certificate and its key as PEM files.
+func writeIssued(dir, certName, keyName string, c *issued) error {
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		return errors.Wrap(err, "marshal key")
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der})
+	if err = os.WriteFile(filepath.Join(dir, certName), certPEM, 0o644); err != nil {
+		return errors.Wrapf(err, "write %s", certName)
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err = os.WriteFile(filepath.Join(dir, keyName), keyPEM, 0o600); err != nil {
+		return errors.Wrapf(err, "write %s", keyName)
+	}
+
+	return nil
+}
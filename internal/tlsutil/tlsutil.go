@@ -0,0 +1,87 @@
+// Package tlsutil builds TLS configurations for the gRPC transport and issues development certificates.
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+)
+
+// ServerConfig returns the TLS configuration of the server.
+// With cfg.ClientAuth set, clients must present a certificate signed by cfg.CAFile.
+func ServerConfig(cfg config.TLS) (*tls.Config, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("tls certificate and key are required")
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "load server certificate")
+	}
+
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.ClientAuth {
+		if cfg.CAFile == "" {
+			return nil, errors.New("client auth requires a ca file")
+		}
+
+		pool, err := loadPool(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsCfg, nil
+}
+
+// ClientConfig returns the TLS configuration of the client.
+// When cfg.CAFile is set, only that CA is trusted instead of the system roots.
+func ClientConfig(cfg config.TLS) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		ServerName: cfg.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.CAFile != "" {
+		pool, err := loadPool(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "load client certificate")
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+// loadPool reads PEM certificates from the file into a new pool.
+func loadPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read ca file")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.Errorf("no certificates found in %s", path)
+	}
+
+	return pool, nil
+}
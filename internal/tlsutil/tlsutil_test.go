@@ -0,0 +1,118 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wickedv43/go-goph-keeper/internal/config"
+)
+
+// handshake serves one connection with srvCfg and dials it with cliCfg.
+func handshake(t *testing.T, srvCfg, cliCfg *tls.Config) error {
+	t.Helper()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", srvCfg)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.(*tls.Conn).Handshake()
+		_, _ = io.WriteString(conn, "ok")
+	}()
+
+	conn, err := tls.Dial("tcp", ln.Addr().String(), cliCfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// TLS 1.3 reports a rejected client certificate on the first read
+	_, err = io.ReadAll(conn)
+	return err
+}
+
+func TestTLS(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, GenerateDevCerts(dir, []string{"localhost", "127.0.0.1"}))
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	srvCfg, err := ServerConfig(config.TLS{
+		CertFile:   path(ServerFile),
+		KeyFile:    path(ServerKeyFile),
+		CAFile:     path(CAFile),
+		ClientAuth: true,
+	})
+	require.NoError(t, err)
+
+	t.Run("mutual tls", func(t *testing.T) {
+		cliCfg, err := ClientConfig(config.TLS{
+			CAFile:   path(CAFile),
+			CertFile: path(ClientFile),
+			KeyFile:  path(ClientKeyFile),
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, handshake(t, srvCfg, cliCfg))
+	})
+
+	t.Run("client without certificate", func(t *testing.T) {
+		cliCfg, err := ClientConfig(config.TLS{CAFile: path(CAFile)})
+		require.NoError(t, err)
+
+		require.Error(t, handshake(t, srvCfg, cliCfg))
+	})
+
+	t.Run("server signed by another ca", func(t *testing.T) {
+		other := t.TempDir()
+		require.NoError(t, GenerateDevCerts(other, []string{"127.0.0.1"}))
+
+		cliCfg, err := ClientConfig(config.TLS{
+			CAFile:   filepath.Join(other, CAFile),
+			CertFile: path(ClientFile),
+			KeyFile:  path(ClientKeyFile),
+		})
+		require.NoError(t, err)
+
+		require.Error(t, handshake(t, srvCfg, cliCfg))
+	})
+
+	t.Run("server name override", func(t *testing.T) {
+		srvCfg, err := ServerConfig(config.TLS{CertFile: path(ServerFile), KeyFile: path(ServerKeyFile)})
+		require.NoError(t, err)
+
+		cliCfg, err := ClientConfig(config.TLS{CAFile: path(CAFile), ServerName: "localhost"})
+		require.NoError(t, err)
+		require.NoError(t, handshake(t, srvCfg, cliCfg))
+
+		cliCfg, err = ClientConfig(config.TLS{CAFile: path(CAFile), ServerName: "keeper.example.com"})
+		require.NoError(t, err)
+		require.Error(t, handshake(t, srvCfg, cliCfg))
+	})
+}
+
+func TestServerConfig_Errors(t *testing.T) {
+	_, err := ServerConfig(config.TLS{})
+	require.Error(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, GenerateDevCerts(dir, []string{"localhost"}))
+
+	_, err = ServerConfig(config.TLS{
+		CertFile:   filepath.Join(dir, ServerFile),
+		KeyFile:    filepath.Join(dir, ServerKeyFile),
+		ClientAuth: true,
+	})
+	require.Error(t, err, "client auth without ca")
+
+	_, err = ClientConfig(config.TLS{CAFile: filepath.Join(dir, ServerKeyFile)})
+	require.Error(t, err, "no certificates in file")
+}
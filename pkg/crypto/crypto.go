@@ -4,9 +4,7 @@ package crypto
 import (
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/rand"
 	"encoding/hex"
-	"io"
 
 	"github.com/dongri/go-mnemonic"
 	"github.com/pkg/errors"
@@ -29,38 +27,22 @@ func GenerateSeed(words, password string) string {
 	return seed
 }
 
-// EncryptWithSeed encrypts the given data using AES-GCM with a key derived from the hex seed.
+// EncryptWithSeed encrypts the given data into an AES-256-GCM envelope, see Seal.
 func EncryptWithSeed(data []byte, seedHex string) ([]byte, error) {
-	seedBytes, err := hex.DecodeString(seedHex)
-	if err != nil {
-		return nil, err
-	}
-	if len(seedBytes) < 16 {
-		return nil, errors.New("seed слишком короткий для AES-128")
-	}
-	key := seedBytes[:16]
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
-	}
-
-	nonce := make([]byte, 12) // 12 байт для GCM
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
-	}
-
-	aesgcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
-	}
-
-	ciphertext := aesgcm.Seal(nil, nonce, data, nil)
-	return append(nonce, ciphertext...), nil
+	return Seal(data, seedHex, AES256GCM, nil)
 }
 
-// DecryptWithSeed decrypts the given ciphertext using AES-GCM with a key derived from the hex seed.
+// DecryptWithSeed decrypts data encrypted by EncryptWithSeed, including the
+// legacy format without an envelope, see Open.
 func DecryptWithSeed(ciphertext []byte, seedHex string) ([]byte, error) {
+	return Open(ciphertext, seedHex, nil)
+}
+
+// OpenLegacy decrypts the format used before envelopes: a 12-byte nonce and
+// AES-128-GCM ciphertext under the first 16 bytes of the seed. Nothing binds
+// such data to where it is stored, so it is only read where that is checked
+// otherwise or does not matter.
+func OpenLegacy(ciphertext []byte, seedHex string) ([]byte, error) {
 	seedBytes, err := hex.DecodeString(seedHex)
 	if err != nil {
 		return nil, err
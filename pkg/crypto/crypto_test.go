@@ -39,7 +39,7 @@ func TestEncryptWithSeed_Errors(t *testing.T) {
 
 	shortSeed := hex.EncodeToString([]byte("short"))
 	_, err = EncryptWithSeed([]byte("data"), shortSeed)
-	require.EqualError(t, err, "seed слишком короткий для AES-128")
+	require.EqualError(t, err, "seed слишком короткий")
 }
 
 func TestDecryptWithSeed_Errors(t *testing.T) {
@@ -49,7 +49,7 @@ func TestDecryptWithSeed_Errors(t *testing.T) {
 	shortSeed := hex.EncodeToString([]byte("short"))
 	cipher, _ := EncryptWithSeed([]byte("data"), GenerateSeed(mustMnemonic(), ""))
 	_, err = DecryptWithSeed(cipher, shortSeed)
-	require.EqualError(t, err, "seed слишком короткий для AES-128")
+	require.EqualError(t, err, "seed слишком короткий")
 
 	seed := GenerateSeed(mustMnemonic(), "")
 	cipher, _ = EncryptWithSeed([]byte("ok"), seed)
@@ -0,0 +1,222 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Alg identifies the cipher of an envelope.
+type Alg byte
+
+const (
+	// AES256GCM is AES-256 in GCM mode with a random 96-bit nonce, the default.
+	AES256GCM Alg = 1
+
+	// XChaCha20Poly1305 is XChaCha20-Poly1305 with a random 192-bit nonce. It
+	// is fast without AES hardware and its nonces never realistically repeat.
+	XChaCha20Poly1305 Alg = 2
+)
+
+var algNames = map[Alg]string{
+	AES256GCM:         "aes-256-gcm",
+	XChaCha20Poly1305: "xchacha20-poly1305",
+}
+
+// String returns the name of the cipher as it is written in the config.
+func (a Alg) String() string {
+	if name, ok := algNames[a]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseAlg returns the cipher by its name; an empty name is AES256GCM.
+func ParseAlg(name string) (Alg, error) {
+	if name == "" {
+		return AES256GCM, nil
+	}
+	for a, n := range algNames {
+		if n == name {
+			return a, nil
+		}
+	}
+	return 0, errors.Errorf("неизвестный шифр %q", name)
+}
+
+// Errors of Open.
+var (
+	// ErrWrongKey is returned for an envelope sealed with another seed.
+	ErrWrongKey = errors.New("данные зашифрованы другим ключом")
+
+	// ErrNotAuthentic is returned for an envelope that was changed or sealed
+	// with other associated data, for example moved from another record.
+	ErrNotAuthentic = errors.New("данные повреждены или относятся к другой записи")
+)
+
+const (
+	envelopeVersion = 1
+	keyIDSize       = 4
+
+	// envelopeHeaderSize is the version, the cipher and the key ID.
+	envelopeHeaderSize = 2 + keyIDSize
+)
+
+// keyIDInfo separates the key ID from other keys derived from the seed.
+const keyIDInfo = "gophkeeper key id v1"
+
+// Seal encrypts data into an envelope:
+//
+//	version (1) | cipher (1) | key ID (4) | nonce | ciphertext and tag
+//
+// The key is derived from the seed with HKDF for the cipher; the key ID is
+// derived from the seed as well, so a wrong seed is told apart from damaged
+// data. The header and aad are authenticated: the envelope only opens with
+// the same aad, which binds it to its context, see RecordAAD.
+func Seal(data []byte, seedHex string, alg Alg, aad []byte) ([]byte, error) {
+	keyID, err := DeriveKey(seedHex, nil, keyIDInfo, keyIDSize)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := envelopeAEAD(seedHex, alg)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, envelopeHeaderSize+aead.NonceSize()+len(data)+aead.Overhead())
+	out = append(out, envelopeVersion, byte(alg))
+	out = append(out, keyID...)
+	header := out
+
+	nonce := out[len(out) : len(out)+aead.NonceSize()]
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out = out[:len(out)+len(nonce)]
+
+	return aead.Seal(out, nonce, data, envelopeAAD(header, aad)), nil
+}
+
+// Open decrypts an envelope made by Seal with the same aad. Without aad, data
+// encrypted before envelopes were introduced is still read, see OpenLegacy.
+// With aad it is refused: it is not bound to anything, so it could have been
+// moved from anywhere.
+func Open(sealed []byte, seedHex string, aad []byte) ([]byte, error) {
+	if !isEnvelope(sealed) {
+		if aad != nil {
+			return nil, ErrNotAuthentic
+		}
+		return OpenLegacy(sealed, seedHex)
+	}
+
+	keyID, err := DeriveKey(seedHex, nil, keyIDInfo, keyIDSize)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(sealed[2:envelopeHeaderSize], keyID) {
+		// a legacy ciphertext may happen to start like an envelope
+		if aad == nil {
+			if data, legacyErr := OpenLegacy(sealed, seedHex); legacyErr == nil {
+				return data, nil
+			}
+		}
+		return nil, ErrWrongKey
+	}
+
+	aead, err := envelopeAEAD(seedHex, Alg(sealed[1]))
+	if err != nil {
+		return nil, err
+	}
+	header, rest := sealed[:envelopeHeaderSize], sealed[envelopeHeaderSize:]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("слишком короткий ciphertext")
+	}
+
+	data, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], envelopeAAD(header, aad))
+	if err != nil {
+		return nil, ErrNotAuthentic
+	}
+	return data, nil
+}
+
+// RecordAAD returns the associated data binding the contents of a vault
+// record to its identity: the UUID the client gave it, its type and its
+// owner. The contents cannot be moved to another record, read as another
+// type or passed to another user.
+func RecordAAD(uuid, typ, owner string) []byte {
+	return identityAAD("gophkeeper record v2", uuid, typ, owner)
+}
+
+// MetaAAD returns the associated data binding the sealed title and metadata
+// of a vault record to its identity, like RecordAAD. It differs from the
+// associated data of the contents, so neither can be passed off as the other.
+func MetaAAD(uuid, typ, owner string) []byte {
+	return identityAAD("gophkeeper meta v1", uuid, typ, owner)
+}
+
+// identityAAD joins the parts of a record identity after the label, each
+// prefixed with its length so that no two identities give the same bytes.
+func identityAAD(label string, parts ...string) []byte {
+	aad := []byte(label)
+	for _, part := range parts {
+		aad = binary.BigEndian.AppendUint32(aad, uint32(len(part)))
+		aad = append(aad, part...)
+	}
+	return aad
+}
+
+// RecordIDAAD returns the associated data that bound the contents of a record
+// to its server ID and type before records had UUIDs. It is only used to read
+// such contents.
+func RecordIDAAD(id uint64, typ string) []byte {
+	aad := []byte("gophkeeper record\x00")
+	aad = binary.BigEndian.AppendUint64(aad, id)
+	return append(aad, typ...)
+}
+
+// IsEnvelope reports whether the data is an envelope rather than the legacy
+// format, which carries no associated data.
+func IsEnvelope(sealed []byte) bool {
+	return isEnvelope(sealed)
+}
+
+// isEnvelope reports whether the data starts with an envelope header.
+func isEnvelope(sealed []byte) bool {
+	if len(sealed) < envelopeHeaderSize || sealed[0] != envelopeVersion {
+		return false
+	}
+	_, ok := algNames[Alg(sealed[1])]
+	return ok
+}
+
+// envelopeAAD authenticates the header along with the caller's data.
+func envelopeAAD(header, aad []byte) []byte {
+	return append(append(make([]byte, 0, len(header)+len(aad)), header...), aad...)
+}
+
+// envelopeAEAD returns the cipher alg keyed for envelopes of the seed.
+func envelopeAEAD(seedHex string, alg Alg) (cipher.AEAD, error) {
+	if _, ok := algNames[alg]; !ok {
+		return nil, errors.Errorf("неизвестный шифр %d", alg)
+	}
+	// every cipher gets its own key
+	key, err := DeriveKey(seedHex, nil, "gophkeeper record "+alg.String()+" v1", 32)
+	if err != nil {
+		return nil, err
+	}
+
+	if alg == XChaCha20Poly1305 {
+		return chacha20poly1305.NewX(key)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sealLegacy encrypts data the way EncryptWithSeed did before envelopes.
+func sealLegacy(t *testing.T, data []byte, seedHex string) []byte {
+	seed, err := hex.DecodeString(seedHex)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(seed[:16])
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	nonce := make([]byte, 12)
+	nonce[0] = envelopeVersion // looks like the start of an envelope
+	nonce[1] = byte(AES256GCM)
+	return gcm.Seal(nonce, nonce, data, nil)
+}
+
+func TestSealOpen(t *testing.T) {
+	seed := strings.Repeat("ab", 64)
+	aad := RecordAAD("0b5e", "login", "alice")
+
+	for _, alg := range []Alg{AES256GCM, XChaCha20Poly1305} {
+		t.Run(alg.String(), func(t *testing.T) {
+			sealed, err := Seal([]byte("secret"), seed, alg, aad)
+			require.NoError(t, err)
+			require.Equal(t, byte(envelopeVersion), sealed[0])
+			require.Equal(t, byte(alg), sealed[1])
+			require.True(t, IsEnvelope(sealed))
+
+			data, err := Open(sealed, seed, aad)
+			require.NoError(t, err)
+			require.Equal(t, []byte("secret"), data)
+
+			_, err = Open(sealed, seed, RecordAAD("0b5f", "login", "alice"))
+			require.ErrorIs(t, err, ErrNotAuthentic, "bound to the record")
+			_, err = Open(sealed, seed, RecordAAD("0b5e", "note", "alice"))
+			require.ErrorIs(t, err, ErrNotAuthentic, "bound to the type")
+			_, err = Open(sealed, seed, RecordAAD("0b5e", "login", "bob"))
+			require.ErrorIs(t, err, ErrNotAuthentic, "bound to the owner")
+
+			_, err = Open(sealed, strings.Repeat("cd", 64), aad)
+			require.ErrorIs(t, err, ErrWrongKey)
+
+			tampered := append([]byte(nil), sealed...)
+			tampered[len(tampered)-1] ^= 1
+			_, err = Open(tampered, seed, aad)
+			require.Error(t, err)
+
+			downgraded := append([]byte(nil), sealed...)
+			downgraded[1] = byte(AES256GCM + XChaCha20Poly1305 - alg)
+			_, err = Open(downgraded, seed, aad)
+			require.Error(t, err, "the header is authenticated")
+		})
+	}
+
+	t.Run("unknown cipher", func(t *testing.T) {
+		_, err := Seal([]byte("secret"), seed, 0, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestOpenLegacy(t *testing.T) {
+	seed := strings.Repeat("ab", 64)
+	legacy := sealLegacy(t, []byte("old"), seed)
+
+	data, err := DecryptWithSeed(legacy, seed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("old"), data)
+
+	data, err = OpenLegacy(legacy, seed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("old"), data)
+
+	_, err = Open(legacy, seed, RecordIDAAD(7, "note"))
+	require.ErrorIs(t, err, ErrWrongKey, "not bound to the record, so not read as it")
+
+	bare := sealLegacy(t, []byte("old"), seed)
+	bare[0] = 0 // does not look like an envelope
+	_, err = Open(bare, seed, RecordIDAAD(7, "note"))
+	require.ErrorIs(t, err, ErrNotAuthentic)
+}
+
+func TestRecordAAD(t *testing.T) {
+	require.NotEqual(t, RecordAAD("ab", "c", "d"), RecordAAD("a", "bc", "d"), "parts are delimited")
+	require.NotEqual(t, RecordAAD("a", "b", "c"), RecordIDAAD(0, "b"))
+	require.NotEqual(t, RecordAAD("a", "b", "c"), MetaAAD("a", "b", "c"), "contents and metadata are told apart")
+}
+
+func TestParseAlg(t *testing.T) {
+	for name, want := range map[string]Alg{
+		"":                   AES256GCM,
+		"aes-256-gcm":        AES256GCM,
+		"xchacha20-poly1305": XChaCha20Poly1305,
+	} {
+		alg, err := ParseAlg(name)
+		require.NoError(t, err)
+		require.Equal(t, want, alg)
+	}
+
+	_, err := ParseAlg("des")
+	require.Error(t, err)
+}
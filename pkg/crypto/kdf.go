@@ -0,0 +1,64 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
+)
+
+// DeriveKey derives a key of size bytes from the hex seed with HKDF-SHA256.
+// Keys derived with different info strings are independent, so every purpose
+// gets its own key and none of them reveals the seed or the others. The salt
+// may be nil.
+func DeriveKey(seedHex string, salt []byte, info string, size int) ([]byte, error) {
+	seed, err := hex.DecodeString(seedHex)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) < 16 {
+		return nil, errors.New("seed слишком короткий")
+	}
+
+	key := make([]byte, size)
+	if _, err = io.ReadFull(hkdf.New(sha256.New, seed, salt, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// BlindTokenSize is the length of a blind index token.
+const BlindTokenSize = 2 * sha256.Size
+
+// blindInfo separates the blind index key from other keys derived from the seed.
+const blindInfo = "gophkeeper blind index v1"
+
+// BlindIndex computes blind index tokens: keyed HMACs of field values that
+// the server can match for equality without learning the values. The same
+// value of the same field always gives the same token for one seed.
+type BlindIndex struct {
+	key []byte
+}
+
+// NewBlindIndex returns the blind index of the vault of the seed.
+func NewBlindIndex(seedHex string) (*BlindIndex, error) {
+	key, err := DeriveKey(seedHex, nil, blindInfo, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	return &BlindIndex{key: key}, nil
+}
+
+// Token returns the hex token of the value of the field. Values are compared
+// case-insensitively and without surrounding spaces.
+func (b *BlindIndex) Token(field, value string) string {
+	mac := hmac.New(sha256.New, b.key)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveKey(t *testing.T) {
+	seed := strings.Repeat("ab", 32)
+
+	t.Run("deterministic and separated by info", func(t *testing.T) {
+		a, err := DeriveKey(seed, nil, "a", 32)
+		require.NoError(t, err)
+		require.Len(t, a, 32)
+
+		again, err := DeriveKey(seed, nil, "a", 32)
+		require.NoError(t, err)
+		require.Equal(t, a, again)
+
+		b, err := DeriveKey(seed, nil, "b", 32)
+		require.NoError(t, err)
+		require.NotEqual(t, a, b)
+
+		salted, err := DeriveKey(seed, []byte("salt"), "a", 32)
+		require.NoError(t, err)
+		require.NotEqual(t, a, salted)
+	})
+
+	t.Run("bad seed", func(t *testing.T) {
+		_, err := DeriveKey("zz", nil, "a", 32)
+		require.Error(t, err)
+		_, err = DeriveKey("abcd", nil, "a", 32)
+		require.Error(t, err)
+	})
+}
+
+func TestBlindIndex(t *testing.T) {
+	bi, err := NewBlindIndex(strings.Repeat("ab", 32))
+	require.NoError(t, err)
+
+	tok := bi.Token("login", "alice")
+	require.Len(t, tok, BlindTokenSize)
+	require.Equal(t, tok, bi.Token("login", "  Alice "), "normalized")
+	require.NotEqual(t, tok, bi.Token("url", "alice"), "bound to the field")
+	require.NotEqual(t, tok, bi.Token("login", "bob"))
+
+	other, err := NewBlindIndex(strings.Repeat("cd", 32))
+	require.NoError(t, err)
+	require.NotEqual(t, tok, other.Token("login", "alice"), "bound to the seed")
+
+	_, err = NewBlindIndex("")
+	require.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// StreamChunkSize is the number of plaintext bytes in every chunk of a stream but the last.
+const StreamChunkSize = 64 << 10
+
+// SealedChunkSize is the largest size of a sealed chunk.
+const SealedChunkSize = StreamChunkSize + 16
+
+const (
+	streamVersion  = 1
+	streamSaltSize = 16
+	streamPrefix   = 7 // random part of the chunk nonces
+
+	// StreamHeaderSize is the size of the header every stream starts with.
+	StreamHeaderSize = 1 + streamSaltSize + streamPrefix
+)
+
+// streamInfo separates the stream keys from other keys derived from the seed.
+const streamInfo = "gophkeeper stream v1"
+
+// Stream encrypts large data in chunks of StreamChunkSize bytes, so it never
+// has to be held in memory at once. It follows the STREAM construction: every
+// stream gets its own AES-256-GCM key, derived from the seed and a random salt
+// with HKDF, and the nonce of a chunk is the random prefix of the stream, the
+// chunk number and a flag marking the last chunk. Chunks therefore cannot be
+// reordered, dropped or cut off without failing to open.
+//
+// Any chunk can be sealed or opened on its own, which lets transfers resume
+// in the middle. The header holds the salt and the prefix and is not secret.
+type Stream struct {
+	aead   cipher.AEAD
+	header []byte
+}
+
+// NewStream returns a stream with a fresh random header.
+func NewStream(seedHex string) (*Stream, error) {
+	header := make([]byte, StreamHeaderSize)
+	header[0] = streamVersion
+	if _, err := io.ReadFull(rand.Reader, header[1:]); err != nil {
+		return nil, err
+	}
+	return OpenStream(seedHex, header)
+}
+
+// OpenStream returns the stream started with the given header.
+func OpenStream(seedHex string, header []byte) (*Stream, error) {
+	if len(header) != StreamHeaderSize || header[0] != streamVersion {
+		return nil, errors.New("неизвестный формат потока")
+	}
+
+	key, err := DeriveKey(seedHex, header[1:1+streamSaltSize], streamInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stream{aead: aead, header: append([]byte(nil), header...)}, nil
+}
+
+// Header returns the header to store along with the chunks.
+func (s *Stream) Header() []byte {
+	return s.header
+}
+
+// Seal encrypts chunk number seq. Only the last chunk may be shorter than StreamChunkSize.
+func (s *Stream) Seal(seq uint32, chunk []byte, last bool) []byte {
+	return s.aead.Seal(nil, s.nonce(seq, last), chunk, s.header)
+}
+
+// Open decrypts chunk number seq.
+func (s *Stream) Open(seq uint32, sealed []byte, last bool) ([]byte, error) {
+	chunk, err := s.aead.Open(nil, s.nonce(seq, last), sealed, s.header)
+	if err != nil {
+		return nil, errors.Errorf("кусок %d повреждён", seq)
+	}
+	return chunk, nil
+}
+
+func (s *Stream) nonce(seq uint32, last bool) []byte {
+	nonce := make([]byte, s.aead.NonceSize())
+	copy(nonce, s.header[1+streamSaltSize:])
+	binary.BigEndian.PutUint32(nonce[streamPrefix:], seq)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+// StreamChunks returns the number of chunks in a stream of size plaintext bytes.
+// Empty data still takes one, empty, chunk.
+func StreamChunks(size int64) uint32 {
+	if size <= 0 {
+		return 1
+	}
+	return uint32((size + StreamChunkSize - 1) / StreamChunkSize)
+}
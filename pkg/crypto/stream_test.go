@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	seed := GenerateSeed(mustMnemonic(), "")
+
+	s, err := NewStream(seed)
+	require.NoError(t, err)
+	require.Len(t, s.Header(), StreamHeaderSize)
+
+	first := bytes.Repeat([]byte{1}, StreamChunkSize)
+	sealed0 := s.Seal(0, first, false)
+	sealed1 := s.Seal(1, []byte("tail"), true)
+	require.Len(t, sealed0, SealedChunkSize)
+
+	// the reader knows only the header
+	r, err := OpenStream(seed, s.Header())
+	require.NoError(t, err)
+
+	plain, err := r.Open(0, sealed0, false)
+	require.NoError(t, err)
+	require.Equal(t, first, plain)
+	plain, err = r.Open(1, sealed1, true)
+	require.NoError(t, err)
+	require.Equal(t, []byte("tail"), plain)
+
+	t.Run("reordered chunk", func(t *testing.T) {
+		_, err := r.Open(1, sealed0, false)
+		require.Error(t, err)
+	})
+
+	t.Run("truncated stream", func(t *testing.T) {
+		_, err := r.Open(0, sealed0, true)
+		require.Error(t, err)
+	})
+
+	t.Run("other stream", func(t *testing.T) {
+		other, err := NewStream(seed)
+		require.NoError(t, err)
+		require.NotEqual(t, s.Header(), other.Header())
+		_, err = other.Open(1, sealed1, true)
+		require.Error(t, err)
+	})
+
+	t.Run("bad header", func(t *testing.T) {
+		_, err := OpenStream(seed, []byte{1, 2})
+		require.Error(t, err)
+		header := append([]byte(nil), s.Header()...)
+		header[0] = 9
+		_, err = OpenStream(seed, header)
+		require.Error(t, err)
+		_, err = OpenStream("zz", s.Header())
+		require.Error(t, err)
+	})
+}
+
+func TestStreamChunks(t *testing.T) {
+	require.Equal(t, uint32(1), StreamChunks(0))
+	require.Equal(t, uint32(1), StreamChunks(StreamChunkSize))
+	require.Equal(t, uint32(2), StreamChunks(StreamChunkSize+1))
+}